@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"govcon/api/internal/db"
+	"govcon/api/internal/models"
+	"govcon/api/internal/services"
+)
+
+const defaultSampleSize = 500
+
+func main() {
+	sample := flag.Int("sample", defaultSampleSize, "Number of random descriptions to reprocess")
+	flag.Parse()
+	if *sample < 1 {
+		log.Fatal("--sample must be at least 1")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := db.Connect(ctx, dbURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer pool.Close()
+
+	// Read-only: re-extract in memory and compare, nothing is written back
+	rows, err := pool.Query(ctx, `
+		SELECT notice_id, raw_text, ai_meta
+		FROM opportunity_description
+		WHERE raw_text IS NOT NULL AND ai_meta IS NOT NULL
+		ORDER BY random()
+		LIMIT $1
+	`, *sample)
+	if err != nil {
+		log.Fatalf("Failed to query descriptions: %v", err)
+	}
+	defer rows.Close()
+
+	diff := services.NewAiMetaDiff()
+	skipped := 0
+	for rows.Next() {
+		var noticeID, rawText string
+		var storedJSON []byte
+		if err := rows.Scan(&noticeID, &rawText, &storedJSON); err != nil {
+			log.Fatalf("Failed to scan description: %v", err)
+		}
+
+		var stored models.AiMeta
+		if err := json.Unmarshal(storedJSON, &stored); err != nil {
+			log.Printf("Skipping %s: stored ai_meta does not decode: %v", noticeID, err)
+			skipped++
+			continue
+		}
+		current, err := services.ReextractAiMeta(rawText)
+		if err != nil {
+			log.Printf("Skipping %s: re-extraction failed: %v", noticeID, err)
+			skipped++
+			continue
+		}
+		diff.Add(&stored, &current)
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatalf("Failed to iterate descriptions: %v", err)
+	}
+
+	log.Printf("📊 AiMeta diff against normalization version %d (+ gained, - lost, ~ changed value):", services.NORMALIZATION_VERSION)
+	log.Printf("   Descriptions compared: %d", diff.Compared)
+	if skipped > 0 {
+		log.Printf("   Skipped: %d", skipped)
+	}
+	lines := diff.Lines()
+	if len(lines) == 0 {
+		log.Printf("   No field changed")
+	}
+	for _, line := range lines {
+		log.Printf("   %s", line)
+	}
+}
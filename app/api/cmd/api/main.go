@@ -7,7 +7,7 @@ import (
 	"os"
 	"strings"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/db"
 	"govcon/api/internal/handlers"
 	"govcon/api/internal/repositories"
 	"govcon/api/internal/services"
@@ -21,7 +21,7 @@ func main() {
 	}
 
 	ctx := context.Background()
-	pool, err := pgxpool.New(ctx, dbURL)
+	pool, err := db.ConnectServer(ctx, dbURL)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
@@ -30,13 +30,28 @@ func main() {
 	// Initialize repositories
 	opportunityRepo := repositories.NewOpportunityRepository(pool)
 	descriptionRepo := repositories.NewDescriptionRepository(pool)
+	outcomeRepo := repositories.NewOutcomeRepository(pool)
+	tagRepo := repositories.NewTagRepository(pool)
+	jobRunRepo := repositories.NewJobRunRepository(pool)
 
 	// Initialize services
 	samService := services.NewSAMService()
 	descriptionService := services.NewDescriptionService()
 
 	// Initialize handlers
-	opportunitiesHandler := handlers.NewOpportunitiesHandler(opportunityRepo, descriptionRepo, descriptionService, samService, pool)
+	opportunitiesHandler := handlers.NewOpportunitiesHandler(opportunityRepo, descriptionRepo, outcomeRepo, tagRepo, descriptionService, samService, pool)
+	adminHandler := handlers.NewAdminHandler(jobRunRepo)
+	adminHandler.RegisterCache("description_status_column", opportunityRepo)
+	adminHandler.RegisterCache("heal_cooldown", opportunitiesHandler.HealCooldowns())
+
+	// Live ingestion events: ingest publishes via Postgres NOTIFY, SSE clients subscribe to the broker
+	eventBroker := services.NewEventBroker(0)
+	go func() {
+		if err := services.ListenOpportunityEvents(ctx, pool, eventBroker); err != nil {
+			log.Printf("Warning: opportunity event listener stopped: %v", err)
+		}
+	}()
+	streamHandler := handlers.NewStreamHandler(eventBroker)
 
 	// Setup routes
 	mux := http.NewServeMux()
@@ -54,34 +69,89 @@ func main() {
 			`SELECT id, message FROM ping ORDER BY id DESC LIMIT 1`,
 		).Scan(&id, &msg)
 		if err != nil {
-			handlers.WriteJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error()})
+			handlers.WriteError(w, http.StatusInternalServerError, handlers.ErrCodeInternal, err.Error())
 			return
 		}
 		handlers.WriteJSON(w, http.StatusOK, map[string]any{"id": id, "message": msg})
 	})
 
+	// Prometheus-style metrics (description fetch outcomes)
+	mux.HandleFunc("/metrics", handlers.HandleMetrics)
+
+	// Recent ingestion/backfill runs (job_run)
+	mux.HandleFunc("/admin/jobs", adminHandler.HandleListJobs)
+
+	// In-memory caches: sizes and hit rates, and a token-gated purge
+	mux.HandleFunc("/admin/cache/stats", adminHandler.HandleCacheStats)
+	mux.HandleFunc("/admin/cache/purge", adminHandler.HandleCachePurge)
+
+	// Description preview (no persistence) for tuning normalization
+	mux.HandleFunc("/describe/preview", opportunitiesHandler.HandleDescribePreview)
+
+	// Opportunity counts per agency, under the search filters
+	mux.HandleFunc("/stats/agencies", opportunitiesHandler.HandleAgencyStats)
+
 	// Opportunities endpoints
 	// Note: More specific routes must be registered before less specific ones
 	// /opportunities/search must come before /opportunities/ to avoid route conflicts
 	mux.HandleFunc("/opportunities/search", opportunitiesHandler.HandleSearchV2)
+	mux.HandleFunc("/opportunities/stream", streamHandler.HandleStream)
+	mux.HandleFunc("/opportunities/compare", opportunitiesHandler.HandleCompare)
 	mux.HandleFunc("/opportunities", opportunitiesHandler.HandleSearch) // Keep old endpoint for backward compatibility
+	mux.HandleFunc("/tags/bulk", opportunitiesHandler.HandleBulkTags)
 	
-	// Handle /opportunities/:id/description and /opportunities/:id with explicit path parsing
+	// Handle /opportunities/:id/description, /opportunities/:id/outcome, /opportunities/:id/versions, /opportunities/:id/tags and /opportunities/:id with explicit path parsing
 	mux.HandleFunc("/opportunities/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 		
+		// Raw SAM description payload, for debugging
+		if strings.HasSuffix(path, "/description/raw.json") {
+			opportunitiesHandler.HandleGetDescriptionRaw(w, r)
+			return
+		}
+
 		// Check if this is a description request
 		if strings.HasSuffix(path, "/description") {
 			opportunitiesHandler.HandleGetDescription(w, r)
 			return
 		}
+
+		// Outcome tracking (won/lost/no-bid)
+		if strings.HasSuffix(path, "/outcome") {
+			opportunitiesHandler.HandleOutcome(w, r)
+			return
+		}
+
+		// Version history, newest first with keyset pagination
+		if strings.HasSuffix(path, "/versions") {
+			opportunitiesHandler.HandleListVersions(w, r)
+			return
+		}
+
+		// Internal tags: /tags (list, add) and /tags/:tag (remove)
+		if strings.HasSuffix(path, "/tags") || strings.Contains(path, "/tags/") {
+			opportunitiesHandler.HandleTags(w, r)
+			return
+		}
 		
 		// Otherwise, treat as regular opportunity detail
 		opportunitiesHandler.HandleGetOpportunity(w, r)
 	})
 
+	// Cap request bodies (MAX_REQUEST_BODY_BYTES, default 8MB) so large POSTs can't exhaust memory
+	var handler http.Handler = handlers.MaxBytes(0, mux)
+
+	// Gzip text responses of 1KB or more for clients that accept it; SSE streams pass through
+	handler = handlers.Gzip(0, handler)
+
 	// CORS middleware for development
-	handler := corsMiddleware(mux)
+	handler = corsMiddleware(handler)
+
+	// Outermost: a panic anywhere below becomes a 500 instead of crashing the process
+	handler = handlers.Recover(handler)
+
+	// Every response, panics included, carries an X-Request-ID (the client's own, or a generated one)
+	handler = handlers.RequestID(handler)
 
 	log.Println("Go API listening on :4000")
 	log.Fatal(http.ListenAndServe(":4000", handler))
@@ -91,8 +161,9 @@ func main() {
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
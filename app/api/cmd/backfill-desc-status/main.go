@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"govcon/api/internal/db"
+	"govcon/api/internal/repositories"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Report how many statuses are out of date without updating them")
+	flag.Parse()
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := db.Connect(ctx, dbURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer pool.Close()
+
+	descRepo := repositories.NewDescriptionRepository(pool)
+	drifted, err := descRepo.SyncDescriptionStatuses(ctx, *dryRun)
+	if err != nil {
+		pool.Close()
+		log.Fatalf("❌ Description status backfill failed: %v", err)
+	}
+
+	verb := "Updated"
+	if *dryRun {
+		verb = "Would update"
+	}
+	log.Printf("📊 Description status backfill complete:")
+	log.Printf("   %s opportunities: %d", verb, drifted)
+}
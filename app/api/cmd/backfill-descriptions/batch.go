@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+
+	"govcon/api/internal/models"
+)
+
+// descriptionWriter is the part of DescriptionRepository the batcher writes through
+type descriptionWriter interface {
+	BatchUpsertDescriptions(ctx context.Context, descs []*models.OpportunityDescription) error
+	UpsertDescription(ctx context.Context, desc *models.OpportunityDescription) error
+}
+
+// getBatchSize returns the number of descriptions written per round trip (BACKFILL_BATCH_SIZE, default 100)
+func getBatchSize() int {
+	if sizeStr := os.Getenv("BACKFILL_BATCH_SIZE"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size > 0 {
+			return size
+		}
+	}
+	return defaultBatchSize
+}
+
+// upsertBatcher collects the workers' updated descriptions and writes them size at a time with
+// BatchUpsertDescriptions. A chunk that fails is written again one row at a time, so a bad row costs only itself.
+type upsertBatcher struct {
+	repo  descriptionWriter
+	size  int
+	stats *backfillStats
+
+	mu      sync.Mutex
+	pending []*models.OpportunityDescription
+}
+
+func newUpsertBatcher(repo descriptionWriter, size int, stats *backfillStats) *upsertBatcher {
+	return &upsertBatcher{repo: repo, size: size, stats: stats}
+}
+
+// Add queues desc, writing the chunk once it is full
+func (b *upsertBatcher) Add(ctx context.Context, desc *models.OpportunityDescription) {
+	b.mu.Lock()
+	b.pending = append(b.pending, desc)
+	var chunk []*models.OpportunityDescription
+	if len(b.pending) >= b.size {
+		chunk, b.pending = b.pending, nil
+	}
+	b.mu.Unlock()
+
+	if chunk != nil {
+		b.write(ctx, chunk)
+	}
+}
+
+// Flush writes whatever is queued
+func (b *upsertBatcher) Flush(ctx context.Context) {
+	b.mu.Lock()
+	chunk := b.pending
+	b.pending = nil
+	b.mu.Unlock()
+
+	if len(chunk) > 0 {
+		b.write(ctx, chunk)
+	}
+}
+
+func (b *upsertBatcher) write(ctx context.Context, chunk []*models.OpportunityDescription) {
+	if err := b.repo.BatchUpsertDescriptions(ctx, chunk); err != nil {
+		log.Printf("⚠️  Batch of %d failed, writing its records one at a time: %v", len(chunk), err)
+		for _, desc := range chunk {
+			if err := b.repo.UpsertDescription(ctx, desc); err != nil {
+				log.Printf("Failed to upsert description for notice_id %s: %v", desc.NoticeID, err)
+				b.stats.IncrementErrors()
+				continue
+			}
+			b.updated(desc)
+		}
+		return
+	}
+	b.updated(chunk...)
+}
+
+// updated counts written records, logging progress every 100
+func (b *upsertBatcher) updated(descs ...*models.OpportunityDescription) {
+	n := len(descs)
+	for _, desc := range descs {
+		b.stats.IncrementUpdated()
+		b.stats.recordWritten(desc)
+	}
+	b.stats.mu.Lock()
+	updated := b.stats.Updated
+	b.stats.mu.Unlock()
+	if updated/100 > (updated-n)/100 {
+		log.Printf("✅ Processed %d records...", updated)
+	}
+}
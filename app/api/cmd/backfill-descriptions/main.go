@@ -7,11 +7,10 @@ import (
 	"log"
 	"os"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/db"
 	"govcon/api/internal/models"
 	"govcon/api/internal/repositories"
 	"govcon/api/internal/services"
@@ -28,17 +27,33 @@ const (
 	maxRetries = 3
 	// Initial backoff duration
 	initialBackoff = 1 * time.Second
+	// Default number of descriptions written per round trip
+	defaultBatchSize = 100
 )
 
 type backfillStats struct {
-	Total      int
-	Processed  int
-	Updated    int
-	Skipped    int
-	Errors     int
+	Total      int `json:"total"`
+	Processed  int `json:"processed"`
+	Updated    int `json:"updated"`
+	Skipped    int `json:"skipped"`
+	Errors     int `json:"errors"`
+	FetchOutcomes []services.FetchOutcomeCount `json:"fetchOutcomes,omitempty"` // descriptions written, labeled as on /metrics
+	outcomes   *services.FetchMetrics // this run's counters; the API's /metrics can't see another process
 	mu         sync.Mutex
 }
 
+// recordWritten counts a written description's fetch_status, source type and HTTP status in outcomes
+func (s *backfillStats) recordWritten(desc *models.OpportunityDescription) {
+	if s.outcomes == nil {
+		return
+	}
+	httpStatus := 0
+	if desc.HTTPStatus != nil {
+		httpStatus = *desc.HTTPStatus
+	}
+	s.outcomes.RecordFetchOutcome(desc.FetchStatus, desc.SourceType, httpStatus)
+}
+
 func (s *backfillStats) IncrementProcessed() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -115,6 +130,7 @@ func main() {
 	whereClause := flag.String("where", "", "SQL WHERE clause condition (e.g., 'ai_input_text IS NULL AND raw_text_normalized IS NOT NULL')")
 	dryRun := flag.Bool("dry-run", false, "Dry run mode: log what would be updated without making changes")
 	workers := flag.Int("workers", defaultWorkers, "Number of worker goroutines")
+	batchSize := flag.Int("batch-size", getBatchSize(), "Descriptions written per database round trip (default BACKFILL_BATCH_SIZE, or 100)")
 	flag.Parse()
 
 	dbURL := os.Getenv("DATABASE_URL")
@@ -123,7 +139,7 @@ func main() {
 	}
 
 	ctx := context.Background()
-	pool, err := pgxpool.New(ctx, dbURL)
+	pool, err := db.Connect(ctx, dbURL)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
@@ -154,13 +170,30 @@ func main() {
 		log.Println("🔍 DRY RUN MODE: No changes will be made")
 	}
 
+	// Record real runs in job_run; failing to record never stops the backfill
+	jobRuns := repositories.NewJobRunRepository(pool)
+	var runID int64
+	if !*dryRun {
+		if runID, err = jobRuns.StartRun(ctx, models.JobTypeBackfillDescriptions); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+	finishRun := func(status models.JobStatus, stats interface{}, errMsg string) {
+		if runID == 0 {
+			return
+		}
+		if err := jobRuns.FinishRun(ctx, runID, status, stats, errMsg); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
 	// Build WHERE clause
 	whereSQL := "WHERE raw_text_normalized IS NOT NULL"
 	if *whereClause != "" {
 		whereSQL += " AND " + *whereClause
 	} else {
-		// Default: only process records without AI input
-		whereSQL += " AND ai_input_text IS NULL"
+		// Default: only process records without AI input, or with AI input from an older AI_INPUT_VERSION
+		whereSQL += fmt.Sprintf(" AND (ai_input_text IS NULL OR ai_input_version <> %d)", services.AI_INPUT_VERSION)
 	}
 
 	// Count total records
@@ -168,11 +201,13 @@ func main() {
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM opportunity_description %s", whereSQL)
 	err = pool.QueryRow(ctx, countQuery).Scan(&totalCount)
 	if err != nil {
+		finishRun(models.JobStatusFailed, nil, err.Error())
 		log.Fatalf("Failed to count records: %v", err)
 	}
 
 	if totalCount == 0 {
 		log.Println("No records found matching criteria")
+		finishRun(models.JobStatusSucceeded, &backfillStats{}, "")
 		os.Exit(0)
 	}
 
@@ -204,7 +239,11 @@ func main() {
 		*workers = 10
 	}
 
-	stats := &backfillStats{Total: totalCount}
+	stats := &backfillStats{Total: totalCount, outcomes: services.NewFetchMetrics()}
+	if *batchSize < 1 {
+		*batchSize = 1
+	}
+	batcher := newUpsertBatcher(descRepo, *batchSize, stats)
 
 	// Query records
 	query := fmt.Sprintf(`
@@ -219,6 +258,7 @@ func main() {
 
 	rows, err := pool.Query(ctx, query)
 	if err != nil {
+		finishRun(models.JobStatusFailed, stats, err.Error())
 		log.Fatalf("Failed to query records: %v", err)
 	}
 	defer rows.Close()
@@ -234,7 +274,7 @@ func main() {
 		go func(workerID int) {
 			defer wg.Done()
 			for rec := range workChan {
-				processRecord(ctx, rec, descRepo, descService, tokenBucket, stats, *dryRun, workerID)
+				processRecord(ctx, rec, descRepo, batcher, descService, tokenBucket, stats, *dryRun, workerID)
 			}
 			doneChan <- true
 		}(i)
@@ -258,8 +298,10 @@ func main() {
 		}
 	}()
 
-	// Wait for all workers to finish
+	// Wait for all workers to finish, then write what is left of the last chunk
 	wg.Wait()
+	batcher.Flush(ctx)
+	stats.FetchOutcomes = stats.outcomes.Snapshot()
 
 	// Log results
 	log.Println("✅ Backfill completed")
@@ -269,12 +311,17 @@ func main() {
 	log.Printf("   Updated: %d", stats.Updated)
 	log.Printf("   Skipped: %d", stats.Skipped)
 	log.Printf("   Errors: %d", stats.Errors)
+	for _, c := range stats.FetchOutcomes {
+		log.Printf("   Written %s/%s (HTTP %s): %d", c.Status, c.SourceType, c.HTTPStatus, c.Count)
+	}
 
 	if stats.Errors > 0 {
 		log.Printf("⚠️  Warning: %d errors occurred during backfill", stats.Errors)
+		finishRun(models.JobStatusFailed, stats, fmt.Sprintf("%d errors", stats.Errors))
 		os.Exit(1)
 	}
 
+	finishRun(models.JobStatusSucceeded, stats, "")
 	os.Exit(0)
 }
 
@@ -285,7 +332,7 @@ type record struct {
 	SourceType        string
 }
 
-func processRecord(ctx context.Context, rec record, descRepo *repositories.DescriptionRepository, descService *services.DescriptionService, tokenBucket *TokenBucket, stats *backfillStats, dryRun bool, workerID int) {
+func processRecord(ctx context.Context, rec record, descRepo *repositories.DescriptionRepository, batcher *upsertBatcher, descService *services.DescriptionService, tokenBucket *TokenBucket, stats *backfillStats, dryRun bool, workerID int) {
 	stats.IncrementProcessed()
 
 	// Check if we should process this record
@@ -304,6 +351,7 @@ func processRecord(ctx context.Context, rec record, descRepo *repositories.Descr
 	tokenBucket.Wait()
 
 	// Process with retry logic
+	var desc *models.OpportunityDescription
 	var err error
 	backoff := initialBackoff
 	for attempt := 0; attempt < maxRetries; attempt++ {
@@ -313,13 +361,13 @@ func processRecord(ctx context.Context, rec record, descRepo *repositories.Descr
 			backoff *= 2 // Exponential backoff
 		}
 
-		err = processRecordWithRetry(ctx, rec, descRepo, dryRun)
+		desc, err = processRecordWithRetry(ctx, rec, descRepo, dryRun)
 		if err == nil {
 			break
 		}
 
 		// Check if error is retryable (429, 5xx, etc.)
-		if !isRetryableError(err) {
+		if !services.IsRetryableError(err) {
 			break
 		}
 	}
@@ -330,34 +378,37 @@ func processRecord(ctx context.Context, rec record, descRepo *repositories.Descr
 		return
 	}
 
-	stats.IncrementUpdated()
-	if (stats.Updated % 100) == 0 {
-		log.Printf("✅ Processed %d records...", stats.Updated)
+	// Real runs count the record once its chunk is written
+	if desc == nil {
+		stats.IncrementUpdated()
+		return
 	}
+	batcher.Add(ctx, desc)
 }
 
-func processRecordWithRetry(ctx context.Context, rec record, descRepo *repositories.DescriptionRepository, dryRun bool) error {
+// processRecordWithRetry builds the updated description for rec; it returns nil in dry-run mode
+func processRecordWithRetry(ctx context.Context, rec record, descRepo *repositories.DescriptionRepository, dryRun bool) (*models.OpportunityDescription, error) {
 	// Get full description record
 	desc, err := descRepo.GetDescription(ctx, rec.NoticeID)
 	if err != nil {
-		return fmt.Errorf("failed to get description: %w", err)
+		return nil, fmt.Errorf("failed to get description: %w", err)
 	}
 
 	// Generate AI-optimized text
 	rawTextNormalized := *rec.RawTextNormalized
 	aiInputText, excerptText, aiMeta, pocEmailPrimary, err := services.OptimizeForAI(rawTextNormalized)
 	if err != nil {
-		return fmt.Errorf("failed to optimize for AI: %w", err)
+		return nil, fmt.Errorf("failed to optimize for AI: %w", err)
 	}
 
 	if dryRun {
 		log.Printf("[DRY RUN] Would update notice_id %s: ai_input_text=%d chars, excerpt_text=%d chars", rec.NoticeID, len(aiInputText), len(excerptText))
-		return nil
+		return nil, nil
 	}
 
 	// Update description with AI fields
 	aiInputHash := services.ComputeContentHash(aiInputText)
-	aiInputVersion := 1
+	aiInputVersion := services.AI_INPUT_VERSION
 	now := time.Now()
 	desc.AIInputText = &aiInputText
 	desc.AIInputHash = &aiInputHash
@@ -367,27 +418,5 @@ func processRecordWithRetry(ctx context.Context, rec record, descRepo *repositor
 	desc.ExcerptText = &excerptText
 	desc.POCEmailPrimary = pocEmailPrimary
 
-	err = descRepo.UpsertDescription(ctx, desc)
-	if err != nil {
-		return fmt.Errorf("failed to upsert description: %w", err)
-	}
-
-	return nil
+	return desc, nil
 }
-
-func isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-	errStr := err.Error()
-	// Check for HTTP status codes in error message
-	if strings.Contains(errStr, "429") || strings.Contains(errStr, "500") || strings.Contains(errStr, "502") || strings.Contains(errStr, "503") || strings.Contains(errStr, "504") {
-		return true
-	}
-	// Check for network/timeout errors
-	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "connection") || strings.Contains(errStr, "network") {
-		return true
-	}
-	return false
-}
-
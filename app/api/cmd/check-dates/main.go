@@ -6,7 +6,7 @@ import (
 	"log"
 	"os"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/db"
 )
 
 func main() {
@@ -16,7 +16,7 @@ func main() {
 	}
 
 	ctx := context.Background()
-	pool, err := pgxpool.New(ctx, dbURL)
+	pool, err := db.Connect(ctx, dbURL)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
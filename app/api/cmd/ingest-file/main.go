@@ -2,11 +2,10 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"os"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/db"
 	"govcon/api/internal/models"
 	"govcon/api/internal/services"
 )
@@ -24,7 +23,7 @@ func main() {
 	}
 
 	ctx := context.Background()
-	pool, err := pgxpool.New(ctx, dbURL)
+	pool, err := db.Connect(ctx, dbURL)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
@@ -63,7 +62,7 @@ func main() {
 		OpportunitiesData []models.Opportunity     `json:"opportunitiesData"`
 	}
 
-	if err := json.Unmarshal(jsonData, &samResponse); err != nil {
+	if err := models.DecodeJSON(jsonData, &samResponse); err != nil {
 		log.Fatalf("Failed to parse JSON: %v", err)
 	}
 
@@ -79,6 +78,10 @@ func main() {
 	stats := &services.IngestionStats{}
 	for _, opp := range samResponse.OpportunitiesData {
 		stats.Total++
+		if ingestionService.SkipsNoticeType(opp) {
+			stats.Excluded++
+			continue
+		}
 		result, err := ingestionService.ProcessOpportunity(ctx, opp)
 		if err != nil {
 			stats.Errors++
@@ -102,6 +105,9 @@ func main() {
 	log.Printf("   New: %d", stats.New)
 	log.Printf("   Updated: %d", stats.Updated)
 	log.Printf("   Skipped: %d", stats.Skipped)
+	if stats.Excluded > 0 {
+		log.Printf("   Excluded notice types: %d", stats.Excluded)
+	}
 	log.Printf("   Errors: %d", stats.Errors)
 
 	if stats.Errors > 0 {
@@ -2,12 +2,15 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/db"
+	"govcon/api/internal/models"
+	"govcon/api/internal/repositories"
 	"govcon/api/internal/services"
 )
 
@@ -16,8 +19,21 @@ const (
 	ingestionLockKey = 1
 	// Default rolling window days
 	defaultRollingWindowDays = 30
+	// Default exit code when SAM has no opportunities in the window, distinct from success (0) and failure (1)
+	defaultNoRecordsExitCode = 3
 )
 
+// getNoRecordsExitCode returns the exit code for a run that found no opportunities in its window
+// (INGEST_NO_RECORDS_EXIT_CODE); 0 treats it as an ordinary success
+func getNoRecordsExitCode() int {
+	if codeStr := os.Getenv("INGEST_NO_RECORDS_EXIT_CODE"); codeStr != "" {
+		if code, err := strconv.Atoi(codeStr); err == nil && code >= 0 && code <= 125 {
+			return code
+		}
+	}
+	return defaultNoRecordsExitCode
+}
+
 func main() {
 	dbURL := os.Getenv("DATABASE_URL")
 	if dbURL == "" {
@@ -25,7 +41,7 @@ func main() {
 	}
 
 	ctx := context.Background()
-	pool, err := pgxpool.New(ctx, dbURL)
+	pool, err := db.Connect(ctx, dbURL)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
@@ -53,6 +69,21 @@ func main() {
 
 	log.Println("✅ Acquired advisory lock, starting ingestion...")
 
+	// Record the run in job_run; failing to record never stops ingestion
+	jobRuns := repositories.NewJobRunRepository(pool)
+	runID, err := jobRuns.StartRun(ctx, models.JobTypeIngest)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	finishRun := func(status models.JobStatus, stats interface{}, errMsg string) {
+		if runID == 0 {
+			return
+		}
+		if err := jobRuns.FinishRun(ctx, runID, status, stats, errMsg); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
 	// Get rolling window days from environment variable or use default
 	rollingWindowDays := defaultRollingWindowDays
 	if daysStr := os.Getenv("INGESTION_WINDOW_DAYS"); daysStr != "" {
@@ -61,8 +92,8 @@ func main() {
 		}
 	}
 
-	// Calculate rolling window
-	now := time.Now()
+	// Calculate rolling window in the app timezone so the window's days don't depend on the host's TZ
+	now := time.Now().In(models.AppLocation())
 	postedTo := now.Format("01/02/2006")
 	postedFrom := now.AddDate(0, 0, -rollingWindowDays).Format("01/02/2006")
 
@@ -71,13 +102,22 @@ func main() {
 	// Initialize services
 	samService := services.NewSAMService()
 	ingestionService := services.NewIngestionService(pool, samService)
+	ingestionService.SetEventEmitter(services.NewNotifyEmitter(ctx, pool))
 
 	// Run ingestion
 	stats, err := ingestionService.IngestOpportunities(ctx, postedFrom, postedTo)
 	if err != nil {
+		finishRun(models.JobStatusFailed, stats, err.Error())
 		log.Fatalf("❌ Ingestion failed: %v", err)
 	}
 
+	// Nothing posted in the window: say so rather than logging all-zero stats that read like a broken run
+	if stats.NoRecords {
+		log.Printf("ℹ️  No opportunities in window %s to %s; nothing to ingest", postedFrom, postedTo)
+		finishRun(models.JobStatusSucceeded, stats, "")
+		os.Exit(getNoRecordsExitCode())
+	}
+
 	// Log results
 	log.Println("✅ Ingestion completed successfully")
 	log.Printf("📊 Statistics:")
@@ -85,13 +125,24 @@ func main() {
 	log.Printf("   New: %d", stats.New)
 	log.Printf("   Updated: %d", stats.Updated)
 	log.Printf("   Skipped: %d", stats.Skipped)
+	if stats.Excluded > 0 {
+		log.Printf("   Excluded notice types: %d", stats.Excluded)
+	}
 	log.Printf("   Errors: %d", stats.Errors)
+	if len(stats.SkippedPages) > 0 {
+		log.Printf("   Skipped pages: %d", len(stats.SkippedPages))
+		for _, sp := range stats.SkippedPages {
+			log.Printf("     %s-%s offset=%d limit=%d: %s", sp.PostedFrom, sp.PostedTo, sp.Offset, sp.Limit, sp.Error)
+		}
+	}
 
-	if stats.Errors > 0 {
-		log.Printf("⚠️  Warning: %d errors occurred during ingestion", stats.Errors)
+	if stats.Errors > 0 || len(stats.SkippedPages) > 0 {
+		log.Printf("⚠️  Warning: %d errors and %d skipped pages during ingestion", stats.Errors, len(stats.SkippedPages))
+		finishRun(models.JobStatusFailed, stats, fmt.Sprintf("%d errors and %d skipped pages", stats.Errors, len(stats.SkippedPages)))
 		os.Exit(1)
 	}
 
+	finishRun(models.JobStatusSucceeded, stats, "")
 	os.Exit(0)
 }
 
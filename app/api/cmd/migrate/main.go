@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"govcon/api/internal/db"
+	"govcon/api/migrations"
+)
+
+const usage = "Usage: go run ./cmd/migrate <up|status>"
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+	command := os.Args[1]
+	if command != "up" && command != "status" {
+		log.Fatalf("Unknown command %q. %s", command, usage)
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is not set")
+	}
+
+	all, err := db.LoadMigrations(migrations.FS)
+	if err != nil {
+		log.Fatal("Failed to load migrations:", err)
+	}
+
+	ctx := context.Background()
+	pool, err := db.Connect(ctx, dbURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer pool.Close()
+
+	switch command {
+	case "up":
+		ran, err := db.Migrate(ctx, pool, all)
+		for _, m := range ran {
+			log.Printf("✅ Applied %s", m.Name)
+		}
+		if err != nil {
+			pool.Close()
+			log.Fatal("❌ ", err)
+		}
+		if len(ran) == 0 {
+			log.Println("✅ Database is up to date")
+		} else {
+			log.Printf("✅ Applied %d migration(s)", len(ran))
+		}
+	case "status":
+		statuses, err := db.Status(ctx, pool, all)
+		if err != nil {
+			pool.Close()
+			log.Fatal("Failed to read migration status:", err)
+		}
+		pending := 0
+		for _, s := range statuses {
+			if s.AppliedAt != nil {
+				log.Printf("applied  %s (%s)", s.Name, s.AppliedAt.Format("2006-01-02 15:04:05 MST"))
+			} else {
+				pending++
+				log.Printf("pending  %s", s.Name)
+			}
+		}
+		log.Printf("%d applied, %d pending", len(statuses)-pending, pending)
+	}
+}
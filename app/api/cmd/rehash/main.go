@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"govcon/api/internal/db"
+	"govcon/api/internal/services"
+)
+
+// Same advisory lock as cmd/ingest so hashes never change underneath an ingestion run
+const ingestionLockKey = 1
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Report how many hashes would change without updating them")
+	flag.Parse()
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := db.Connect(ctx, dbURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer pool.Close()
+
+	if !*dryRun {
+		// Hold the lock on a dedicated connection; advisory locks are per session
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			log.Fatal("Failed to acquire connection:", err)
+		}
+		defer conn.Release()
+
+		var lockAcquired bool
+		if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", ingestionLockKey).Scan(&lockAcquired); err != nil {
+			log.Fatal("Failed to check advisory lock:", err)
+		}
+		if !lockAcquired {
+			log.Fatal("An ingestion job is running; retry once it finishes")
+		}
+		defer conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", ingestionLockKey)
+	}
+
+	ingestionService := services.NewIngestionService(pool, nil)
+	report, err := ingestionService.RehashContent(ctx, *dryRun)
+	if err != nil {
+		log.Fatalf("❌ Rehash failed: %v", err)
+	}
+
+	verb := "Updated"
+	if *dryRun {
+		verb = "Would update"
+	}
+	log.Printf("📊 Rehash complete:")
+	log.Printf("   Opportunities checked: %d", report.Opportunities)
+	log.Printf("   %s opportunity hashes: %d", verb, report.Changed)
+	log.Printf("   Versions checked: %d", report.Versions)
+	log.Printf("   %s version hashes: %d", verb, report.VersionsChanged)
+	if report.Unparseable > 0 {
+		log.Printf("   ⚠️  Skipped unparseable raw data: %d", report.Unparseable)
+	}
+}
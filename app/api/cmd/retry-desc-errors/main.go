@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"govcon/api/internal/db"
+	"govcon/api/internal/models"
+	"govcon/api/internal/repositories"
+	"govcon/api/internal/services"
+)
+
+const (
+	// Advisory lock key for the retry job (ingest uses 1, backfill 2, migrate 3)
+	retryLockKey = 4
+	// Default rate limit: SAM fetches per second
+	defaultRateLimit = 2.0
+)
+
+type retryStats struct {
+	Due      int `json:"due"`
+	Fetched  int `json:"fetched"`
+	NotFound int `json:"notFound"`
+	Failed   int `json:"failed"`  // still failing; fetch_attempts went up
+	GaveUp   int `json:"gaveUp"`  // of Failed, reached DESC_RETRY_MAX_ATTEMPTS and won't be retried again
+	Skipped  int `json:"skipped"` // being fetched on demand, or no longer in error
+	Errors   int `json:"errors"`  // database errors
+	FetchOutcomes []services.FetchOutcomeCount `json:"fetchOutcomes,omitempty"` // stored fetch results, labeled as on /metrics
+
+	outcomes *services.FetchMetrics // this run's counters; the API's /metrics can't see another process
+}
+
+func main() {
+	limit := flag.Int("limit", 0, "Maximum number of descriptions to retry (0 = no limit)")
+	dryRun := flag.Bool("dry-run", false, "List the descriptions that are due without fetching them")
+	flag.Parse()
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := db.Connect(ctx, dbURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer pool.Close()
+
+	// Hold the job lock and the per-notice fetch locks on a dedicated connection; advisory locks are per session
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		log.Fatal("Failed to acquire connection:", err)
+	}
+	defer conn.Release()
+
+	var lockAcquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", retryLockKey).Scan(&lockAcquired); err != nil {
+		log.Fatal("Failed to check advisory lock:", err)
+	}
+	if !lockAcquired {
+		log.Println("Another retry job is already running. Exiting gracefully.")
+		return
+	}
+	defer conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", retryLockKey)
+
+	policy := services.GetDescriptionRetryPolicy()
+	descRepo := repositories.NewDescriptionRepository(pool)
+	candidates, err := descRepo.ListFetchErrors(ctx, policy.MaxAttempts)
+	if err != nil {
+		log.Printf("❌ Failed to list description errors: %v", err)
+		return
+	}
+
+	now := time.Now()
+	var due []models.OpportunityDescription
+	for _, desc := range candidates {
+		if policy.Due(desc.FetchAttempts, desc.FetchedAt, now) {
+			due = append(due, desc)
+		}
+	}
+	log.Printf("📊 %d descriptions in error below %d attempts, %d past their cooldown", len(candidates), policy.MaxAttempts, len(due))
+	if *limit > 0 && *limit < len(due) {
+		log.Printf("⚠️  Limiting to %d descriptions", *limit)
+		due = due[:*limit]
+	}
+
+	if *dryRun {
+		for _, desc := range due {
+			log.Printf("[DRY RUN] Would retry notice_id %s (%d failed attempts)", desc.NoticeID, desc.FetchAttempts)
+		}
+		return
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	// Record the run in job_run; failing to record never stops the retries
+	jobRuns := repositories.NewJobRunRepository(pool)
+	runID, err := jobRuns.StartRun(ctx, models.JobTypeRetryDescErrors)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	rateLimit := defaultRateLimit
+	if rateStr := os.Getenv("DESC_RETRY_RATE_LIMIT"); rateStr != "" {
+		if r, err := strconv.ParseFloat(rateStr, 64); err == nil && r > 0 {
+			rateLimit = r
+		}
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / rateLimit))
+	defer ticker.Stop()
+
+	stats := &retryStats{Due: len(due), outcomes: services.NewFetchMetrics()}
+	descService := services.NewDescriptionService()
+	for _, candidate := range due {
+		retryDescription(ctx, conn, descRepo, descService, policy, ticker.C, candidate.NoticeID, stats)
+	}
+	stats.FetchOutcomes = stats.outcomes.Snapshot()
+
+	log.Println("✅ Description retry completed")
+	log.Printf("📊 Statistics:")
+	log.Printf("   Due: %d", stats.Due)
+	log.Printf("   Fetched: %d", stats.Fetched)
+	log.Printf("   Not found: %d", stats.NotFound)
+	log.Printf("   Still failing: %d (gave up on %d)", stats.Failed, stats.GaveUp)
+	log.Printf("   Skipped: %d", stats.Skipped)
+	log.Printf("   Errors: %d", stats.Errors)
+
+	if runID != 0 {
+		status, errMsg := models.JobStatusSucceeded, ""
+		if stats.Errors > 0 {
+			status, errMsg = models.JobStatusFailed, fmt.Sprintf("%d errors", stats.Errors)
+		}
+		if err := jobRuns.FinishRun(ctx, runID, status, stats, errMsg); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// retryDescription re-fetches one description under its fetch lock, so it never races an on-demand fetch,
+// retrying transient failures with backoff, and stores the result (which updates fetch_attempts)
+func retryDescription(ctx context.Context, conn *pgxpool.Conn, descRepo *repositories.DescriptionRepository, descService *services.DescriptionService, policy services.DescriptionRetryPolicy, tick <-chan time.Time, noticeID string, stats *retryStats) {
+	lockClass, lockID := db.DescriptionFetchLockKey(noticeID)
+	var lockAcquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1, $2)", lockClass, lockID).Scan(&lockAcquired); err != nil {
+		log.Printf("Failed to check fetch lock for notice_id %s: %v", noticeID, err)
+		stats.Errors++
+		return
+	}
+	if !lockAcquired {
+		stats.Skipped++
+		return
+	}
+	defer conn.Exec(ctx, "SELECT pg_advisory_unlock($1, $2)", lockClass, lockID)
+
+	// Re-read under the lock: an on-demand fetch may have fixed it since ListFetchErrors
+	prev, err := descRepo.GetDescription(ctx, noticeID)
+	if err != nil {
+		log.Printf("Failed to load notice_id %s: %v", noticeID, err)
+		stats.Errors++
+		return
+	}
+	if prev.FetchStatus != models.FetchStatusError || prev.SourceURL == nil {
+		stats.Skipped++
+		return
+	}
+
+	<-tick
+	var fetch services.DescriptionFetch
+	services.Retry(ctx, services.DefaultRetryConfig(), func() error {
+		rawText, rawJSON, httpStatus, contentType, err := descService.FetchDescriptionWithKey(*prev.SourceURL)
+		fetch = services.DescriptionFetch{RawText: rawText, RawJSON: rawJSON, HTTPStatus: httpStatus, ContentType: contentType, Err: err}
+		if services.ClassifyFetchResult(httpStatus, rawText, err) != models.FetchStatusError {
+			return nil
+		}
+		return err
+	})
+
+	desc := services.FetchedDescription(noticeID, *prev.SourceURL, fetch, prev, time.Now(), nil)
+	if err := descRepo.UpsertDescription(ctx, desc); err != nil {
+		log.Printf("Failed to store notice_id %s: %v", noticeID, err)
+		stats.Errors++
+		return
+	}
+
+	stats.outcomes.RecordFetchOutcome(desc.FetchStatus, desc.SourceType, fetch.HTTPStatus)
+	switch desc.FetchStatus {
+	case models.FetchStatusFetched:
+		stats.Fetched++
+	case models.FetchStatusNotFound:
+		stats.NotFound++
+	default:
+		stats.Failed++
+		if desc.FetchAttempts >= policy.MaxAttempts {
+			stats.GaveUp++
+			log.Printf("⚠️  Giving up on notice_id %s after %d failed attempts: %s", noticeID, desc.FetchAttempts, *desc.LastError)
+		}
+	}
+}
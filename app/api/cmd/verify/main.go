@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"govcon/api/internal/db"
+	"govcon/api/internal/services"
+)
+
+// Same advisory lock as cmd/ingest so --fix never races an ingestion run
+const ingestionLockKey = 1
+
+func main() {
+	fix := flag.Bool("fix", false, "Re-derive opportunities whose content hash does not match their raw data")
+	flag.Parse()
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := db.Connect(ctx, dbURL)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer pool.Close()
+
+	if *fix {
+		// Hold the lock on a dedicated connection; advisory locks are per session
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			log.Fatal("Failed to acquire connection:", err)
+		}
+		defer conn.Release()
+
+		var lockAcquired bool
+		if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", ingestionLockKey).Scan(&lockAcquired); err != nil {
+			log.Fatal("Failed to check advisory lock:", err)
+		}
+		if !lockAcquired {
+			log.Fatal("An ingestion job is running; retry --fix once it finishes")
+		}
+		defer conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", ingestionLockKey)
+	}
+
+	ingestionService := services.NewIngestionService(pool, nil)
+	report, err := ingestionService.VerifyIntegrity(ctx, *fix)
+	if err != nil {
+		log.Fatalf("❌ Verification failed: %v", err)
+	}
+
+	for _, issue := range report.Issues {
+		status := ""
+		if issue.Fixed {
+			status = " (fixed)"
+		}
+		log.Printf("%s %s: %s%s", issue.Kind, issue.NoticeID, issue.Detail, status)
+	}
+
+	log.Printf("📊 Verification complete:")
+	log.Printf("   Opportunities checked: %d", report.Checked)
+	log.Printf("   Versions checked: %d", report.Versions)
+	log.Printf("   Discrepancies: %d", len(report.Issues))
+	if *fix {
+		log.Printf("   Fixed: %d", report.Fixed())
+	}
+
+	if len(report.Issues) > report.Fixed() {
+		os.Exit(1)
+	}
+}
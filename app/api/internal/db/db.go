@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultReadyTimeout   = 30 * time.Second
+	defaultInitialBackoff = 500 * time.Millisecond
+	maxBackoff            = 5 * time.Second
+
+	// defaultStatementTimeout fails a runaway query instead of letting it hold a connection indefinitely
+	defaultStatementTimeout = 30 * time.Second
+)
+
+// Pinger is the part of *pgxpool.Pool that WaitForReady needs
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// ReadyConfig bounds how long startup waits for the database
+type ReadyConfig struct {
+	Timeout        time.Duration // Total time to keep trying
+	InitialBackoff time.Duration // Wait after the first failed ping; doubles up to maxBackoff
+}
+
+// getReadyConfig reads DB_READY_TIMEOUT (Go duration, e.g. "60s"; default 30s, "0" pings once)
+func getReadyConfig() ReadyConfig {
+	cfg := ReadyConfig{Timeout: defaultReadyTimeout, InitialBackoff: defaultInitialBackoff}
+	if timeoutStr := os.Getenv("DB_READY_TIMEOUT"); timeoutStr != "" {
+		if timeoutStr == "0" {
+			cfg.Timeout = 0
+		} else if timeout, err := time.ParseDuration(timeoutStr); err == nil && timeout >= 0 {
+			cfg.Timeout = timeout
+		}
+	}
+	return cfg
+}
+
+// getStatementTimeout reads DB_STATEMENT_TIMEOUT (Go duration, e.g. "60s"; default 30s, "0" disables)
+func getStatementTimeout() time.Duration {
+	timeoutStr := os.Getenv("DB_STATEMENT_TIMEOUT")
+	if timeoutStr == "" {
+		return defaultStatementTimeout
+	}
+	if timeoutStr == "0" {
+		return 0
+	}
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil || timeout < 0 {
+		log.Printf("⚠️  Invalid DB_STATEMENT_TIMEOUT %q, using %v", timeoutStr, defaultStatementTimeout)
+		return defaultStatementTimeout
+	}
+	return timeout
+}
+
+// statementTimeoutParam formats a timeout as a statement_timeout value in milliseconds; 0 means no limit
+func statementTimeoutParam(timeout time.Duration) string {
+	if timeout <= 0 {
+		return "0"
+	}
+	ms := timeout.Milliseconds()
+	if ms == 0 {
+		ms = 1 // Sub-millisecond timeouts would otherwise round to "no limit"
+	}
+	return strconv.FormatInt(ms, 10)
+}
+
+// Connect creates a pool for dbURL and waits until the database answers a ping
+// pgxpool.New connects lazily, so without this a process can start serving before Postgres is up
+// Queries run under the server's own statement_timeout (normally none), which the cmd/ batch tools need for
+// their full-table scans; the API server connects with ConnectServer instead
+func Connect(ctx context.Context, dbURL string) (*pgxpool.Pool, error) {
+	return connect(ctx, dbURL, false)
+}
+
+// ConnectServer is Connect for the API server: every connection also gets statement_timeout from
+// DB_STATEMENT_TIMEOUT unless dbURL already sets one, so a runaway request query is cancelled instead of
+// holding a connection; use WithStatementTimeout for queries known to run longer
+func ConnectServer(ctx context.Context, dbURL string) (*pgxpool.Pool, error) {
+	return connect(ctx, dbURL, true)
+}
+
+func connect(ctx context.Context, dbURL string, statementTimeout bool) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	if statementTimeout {
+		applyStatementTimeout(cfg)
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := WaitForReady(ctx, pool, getReadyConfig()); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return pool, nil
+}
+
+// applyStatementTimeout sets the DB_STATEMENT_TIMEOUT default on cfg's connections, keeping a statement_timeout
+// the URL already sets
+func applyStatementTimeout(cfg *pgxpool.Config) {
+	if _, ok := cfg.ConnConfig.RuntimeParams["statement_timeout"]; !ok {
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = statementTimeoutParam(getStatementTimeout())
+	}
+}
+
+// WaitForReady pings until it succeeds, cfg.Timeout elapses, or ctx is done
+func WaitForReady(ctx context.Context, p Pinger, cfg ReadyConfig) error {
+	deadline := time.Now().Add(cfg.Timeout)
+	backoff := cfg.InitialBackoff
+	if backoff <= 0 {
+		backoff = defaultInitialBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := p.Ping(ctx)
+		if err == nil {
+			if attempt > 1 {
+				log.Printf("Database ready after %d attempts", attempt)
+			}
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("database not ready after %d attempts: %w", attempt, err)
+		}
+		wait := backoff
+		if wait > remaining {
+			wait = remaining
+		}
+		log.Printf("Database not ready (attempt %d): %v; retrying in %v", attempt, err, wait)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// TxBeginner is the part of *pgxpool.Pool (or a pgx.Conn) that WithStatementTimeout needs
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WithStatementTimeout runs fn in a transaction whose statement_timeout is timeout (0 for no limit), overriding
+// the pool default for known-long work such as exports and backfills. SET LOCAL ends with the transaction,
+// so the connection goes back to the pool with its default. fn's error rolls the transaction back.
+func WithStatementTimeout(ctx context.Context, db TxBeginner, timeout time.Duration, fn func(tx pgx.Tx) error) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = "+statementTimeoutParam(timeout)); err != nil {
+		return fmt.Errorf("failed to set statement timeout: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// stubPinger fails until failures pings have been made
+type stubPinger struct {
+	failures int
+	calls    int
+}
+
+func (s *stubPinger) Ping(ctx context.Context) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestWaitForReady_WaitsForDelayedDatabase(t *testing.T) {
+	p := &stubPinger{failures: 3}
+	err := WaitForReady(context.Background(), p, ReadyConfig{Timeout: time.Second, InitialBackoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected database to become ready, got %v", err)
+	}
+	if p.calls != 4 {
+		t.Errorf("Expected 4 pings, got %d", p.calls)
+	}
+}
+
+func TestWaitForReady_GivesUpAfterTimeout(t *testing.T) {
+	p := &stubPinger{failures: 1000}
+	start := time.Now()
+	err := WaitForReady(context.Background(), p, ReadyConfig{Timeout: 20 * time.Millisecond, InitialBackoff: time.Millisecond})
+	if err == nil {
+		t.Fatal("Expected error when database never comes up")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected to give up near the timeout, took %v", elapsed)
+	}
+	if p.calls < 2 {
+		t.Errorf("Expected retries before giving up, got %d pings", p.calls)
+	}
+}
+
+func TestWaitForReady_StopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := WaitForReady(ctx, &stubPinger{failures: 1000}, ReadyConfig{Timeout: time.Minute, InitialBackoff: time.Millisecond})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestGetReadyConfig(t *testing.T) {
+	t.Setenv("DB_READY_TIMEOUT", "45s")
+	if cfg := getReadyConfig(); cfg.Timeout != 45*time.Second {
+		t.Errorf("Expected 45s, got %v", cfg.Timeout)
+	}
+	t.Setenv("DB_READY_TIMEOUT", "bogus")
+	if cfg := getReadyConfig(); cfg.Timeout != defaultReadyTimeout {
+		t.Errorf("Expected default %v, got %v", defaultReadyTimeout, cfg.Timeout)
+	}
+}
+
+func TestGetStatementTimeout(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":     defaultStatementTimeout,
+		"0":    0,
+		"5s":   5 * time.Second,
+		"-1s":  defaultStatementTimeout,
+		"junk": defaultStatementTimeout,
+	}
+	for value, expected := range cases {
+		t.Setenv("DB_STATEMENT_TIMEOUT", value)
+		if got := getStatementTimeout(); got != expected {
+			t.Errorf("DB_STATEMENT_TIMEOUT=%q: expected %v, got %v", value, expected, got)
+		}
+	}
+}
+
+func TestStatementTimeoutParam(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                      "0",
+		30 * time.Second:       "30000",
+		250 * time.Millisecond: "250",
+		time.Microsecond:       "1",
+	}
+	for timeout, expected := range cases {
+		if got := statementTimeoutParam(timeout); got != expected {
+			t.Errorf("%v: expected %q, got %q", timeout, expected, got)
+		}
+	}
+}
+
+// isQueryCanceled reports whether err is Postgres cancelling a statement (SQLSTATE 57014)
+func isQueryCanceled(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "57014"
+}
+
+func TestApplyStatementTimeout(t *testing.T) {
+	t.Setenv("DB_STATEMENT_TIMEOUT", "45s")
+	cfg, err := pgxpool.ParseConfig("postgres://govcon@localhost/govcon")
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	applyStatementTimeout(cfg)
+	if got := cfg.ConnConfig.RuntimeParams["statement_timeout"]; got != "45000" {
+		t.Errorf("Expected statement_timeout 45000, got %q", got)
+	}
+
+	// A timeout in the URL wins
+	cfg, err = pgxpool.ParseConfig("postgres://govcon@localhost/govcon?statement_timeout=5000")
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	applyStatementTimeout(cfg)
+	if got := cfg.ConnConfig.RuntimeParams["statement_timeout"]; got != "5000" {
+		t.Errorf("Expected the URL's statement_timeout 5000, got %q", got)
+	}
+}
+
+func TestConnect_NoStatementTimeoutForBatchTools(t *testing.T) {
+	dbURL := os.Getenv("TESTDB_URL")
+	if dbURL == "" {
+		t.Skip("TESTDB_URL not set; skipping database test")
+	}
+	t.Setenv("DB_STATEMENT_TIMEOUT", "100ms")
+	ctx := context.Background()
+	pool, err := Connect(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Close()
+
+	if _, err := pool.Exec(ctx, "SELECT pg_sleep(0.3)"); err != nil {
+		t.Errorf("Expected batch tool connections to have no statement timeout, got %v", err)
+	}
+}
+
+func TestConnectServer_StatementTimeoutCancelsSlowQuery(t *testing.T) {
+	dbURL := os.Getenv("TESTDB_URL")
+	if dbURL == "" {
+		t.Skip("TESTDB_URL not set; skipping database test")
+	}
+	t.Setenv("DB_STATEMENT_TIMEOUT", "100ms")
+	ctx := context.Background()
+	pool, err := ConnectServer(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Close()
+
+	start := time.Now()
+	_, err = pool.Exec(ctx, "SELECT pg_sleep(5)")
+	if !isQueryCanceled(err) {
+		t.Fatalf("Expected statement timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the query to fail fast, took %v", elapsed)
+	}
+
+	// A per-query override lets known-long work finish, and the pool default applies again afterwards
+	err = WithStatementTimeout(ctx, pool, 0, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, "SELECT pg_sleep(0.3)")
+		return err
+	})
+	if err != nil {
+		t.Errorf("Expected overridden timeout to allow the query, got %v", err)
+	}
+	if _, err := pool.Exec(ctx, "SELECT pg_sleep(5)"); !isQueryCanceled(err) {
+		t.Errorf("Expected pool default to apply after the override, got %v", err)
+	}
+}
+
+func TestWithStatementTimeout_CancelsSlowQuery(t *testing.T) {
+	dbURL := os.Getenv("TESTDB_URL")
+	if dbURL == "" {
+		t.Skip("TESTDB_URL not set; skipping database test")
+	}
+	t.Setenv("DB_STATEMENT_TIMEOUT", "0")
+	ctx := context.Background()
+	pool, err := Connect(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Close()
+
+	err = WithStatementTimeout(ctx, pool, 100*time.Millisecond, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, "SELECT pg_sleep(5)")
+		return err
+	})
+	if !isQueryCanceled(err) {
+		t.Errorf("Expected statement timeout error, got %v", err)
+	}
+}
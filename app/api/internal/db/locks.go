@@ -0,0 +1,21 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// Advisory locks come in two key spaces that Postgres keeps apart: the single bigint form, pg_advisory_lock(key),
+// and the two int4 form, pg_advisory_lock(class, id). Job locks (ingest 1, backfill 2, migrate 3, retry 4) use the
+// bigint form; per-object locks use the two-key form with a class per kind of object, so no object key can ever equal
+// a job key, and objects of different kinds can't collide with each other.
+
+// LockClassDescriptionFetch is the class of the per-notice locks that stop duplicate on-demand description fetches
+const LockClassDescriptionFetch int32 = 1
+
+// DescriptionFetchLockKey returns the (class, id) pair for pg_try_advisory_lock($1, $2) guarding noticeID's fetch
+// The id is 32 bits of a SHA-256 of the notice ID; two notices sharing one only serialize their fetches
+func DescriptionFetchLockKey(noticeID string) (int32, int32) {
+	hash := sha256.Sum256([]byte(noticeID))
+	return LockClassDescriptionFetch, int32(binary.BigEndian.Uint32(hash[:4]))
+}
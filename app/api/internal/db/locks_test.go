@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestDescriptionFetchLockKey_Deterministic(t *testing.T) {
+	class, id := DescriptionFetchLockKey("abc123")
+	class2, id2 := DescriptionFetchLockKey("abc123")
+	if class != class2 || id != id2 {
+		t.Errorf("Expected the same key for the same notice, got (%d, %d) and (%d, %d)", class, id, class2, id2)
+	}
+	if class != LockClassDescriptionFetch {
+		t.Errorf("Expected class %d, got %d", LockClassDescriptionFetch, class)
+	}
+	if _, other := DescriptionFetchLockKey("abc124"); other == id {
+		t.Errorf("Expected different notices to get different ids, both got %d", id)
+	}
+}
+
+// Documents the namespacing: while a session holds the ingest, backfill and migrate job locks, another session
+// can still take any description lock, including one whose two int4 keys spell out a job key (class 0, id 1),
+// and a held description lock still excludes a second taker
+func TestAdvisoryLocks_DescriptionLocksDoNotCollideWithJobLocks(t *testing.T) {
+	dbURL := os.Getenv("TESTDB_URL")
+	if dbURL == "" {
+		t.Skip("TESTDB_URL not set; skipping database test")
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Close()
+
+	jobs, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Failed to acquire connection: %v", err)
+	}
+	defer jobs.Release()
+	other, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Failed to acquire connection: %v", err)
+	}
+	defer other.Release()
+
+	for _, key := range []int64{1, 2, migrationLockKey} {
+		if _, err := jobs.Exec(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+			t.Fatalf("Failed to take job lock %d: %v", key, err)
+		}
+		defer jobs.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+	}
+
+	class, id := DescriptionFetchLockKey("abc123")
+	for _, pair := range [][2]int32{{class, id}, {0, 1}} {
+		var acquired bool
+		if err := other.QueryRow(ctx, "SELECT pg_try_advisory_lock($1, $2)", pair[0], pair[1]).Scan(&acquired); err != nil {
+			t.Fatalf("Failed to try lock %v: %v", pair, err)
+		}
+		if !acquired {
+			t.Errorf("Expected two-key lock %v not to conflict with job locks", pair)
+			continue
+		}
+		defer other.Exec(context.Background(), "SELECT pg_advisory_unlock($1, $2)", pair[0], pair[1])
+	}
+
+	var acquired bool
+	if err := jobs.QueryRow(ctx, "SELECT pg_try_advisory_lock($1, $2)", class, id).Scan(&acquired); err != nil {
+		t.Fatalf("Failed to try lock: %v", err)
+	}
+	if acquired {
+		jobs.Exec(ctx, "SELECT pg_advisory_unlock($1, $2)", class, id)
+		t.Error("Expected a held description lock to exclude another session")
+	}
+}
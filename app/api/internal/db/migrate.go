@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// migrationLockKey serializes concurrent migrate runs (ingest uses 1, backfill 2)
+const migrationLockKey = 3
+
+// migrationFilePattern matches NNN_description.sql; the numeric prefix is the version
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_]+)\.sql$`)
+
+const createSchemaMigrationsSQL = `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`
+
+// Migration is one versioned SQL file
+type Migration struct {
+	Version int
+	Name    string // File name, e.g. 002_search_indexes.sql
+	SQL     string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time // nil while pending
+}
+
+// LoadMigrations reads the NNN_description.sql files at the root of fsys, ordered by version
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		match := migrationFilePattern.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, prev, name)
+		}
+		seen[version] = name
+
+		sql, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(sql)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrate applies every migration not yet recorded in schema_migrations, in version order,
+// and returns the ones it applied. Each migration and its schema_migrations row commit in one
+// transaction, and an advisory lock keeps two runs from applying the same migration.
+func Migrate(ctx context.Context, pool *pgxpool.Pool, migrations []Migration) ([]Migration, error) {
+	// Session-level advisory locks belong to one connection, so hold the same one throughout
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+	if _, err := conn.Exec(ctx, createSchemaMigrationsSQL); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return ran, fmt.Errorf("failed to begin migration %s: %w", m.Name, err)
+		}
+		// Backfills and index builds can outlast the pool's statement_timeout; lift it for this transaction only
+		if _, err := tx.Exec(ctx, "SET LOCAL statement_timeout = 0"); err != nil {
+			tx.Rollback(ctx)
+			return ran, fmt.Errorf("failed to lift statement timeout for migration %s: %w", m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, m.SQL); err != nil {
+			tx.Rollback(ctx)
+			return ran, fmt.Errorf("migration %s failed: %w", m.Name, err)
+		}
+		if _, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", m.Version, m.Name); err != nil {
+			tx.Rollback(ctx)
+			return ran, fmt.Errorf("failed to record migration %s: %w", m.Name, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return ran, fmt.Errorf("failed to commit migration %s: %w", m.Name, err)
+		}
+		ran = append(ran, m)
+	}
+	return ran, nil
+}
+
+// Status reports each migration with the time it was applied; nothing is created,
+// so a database that has never been migrated shows every migration as pending
+func Status(ctx context.Context, pool *pgxpool.Pool, migrations []Migration) ([]MigrationStatus, error) {
+	var exists bool
+	if err := pool.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check schema_migrations: %w", err)
+	}
+
+	applied := map[int]time.Time{}
+	if exists {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire connection: %w", err)
+		}
+		defer conn.Release()
+		if applied, err = appliedVersions(ctx, conn); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Migration: m}
+		if at, ok := applied[m.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// appliedVersions returns the applied_at of every version in schema_migrations
+func appliedVersions(ctx context.Context, conn *pgxpool.Conn) (map[int]time.Time, error) {
+	rows, err := conn.Query(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"govcon/api/migrations"
+)
+
+func TestLoadMigrations_OrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_later.sql":  {Data: []byte("SELECT 10")},
+		"002_second.sql": {Data: []byte("SELECT 2")},
+		"001_first.sql":  {Data: []byte("SELECT 1")},
+		"README.md":      {Data: []byte("not a migration")},
+		"migrations.go":  {Data: []byte("package migrations")},
+	}
+
+	got, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"001_first.sql", "002_second.sql", "010_later.sql"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d migrations, got %d", len(want), len(got))
+	}
+	for i, m := range got {
+		if m.Name != want[i] {
+			t.Errorf("Expected migration %d to be %q, got %q", i, want[i], m.Name)
+		}
+	}
+	if got[2].Version != 10 || got[2].SQL != "SELECT 10" {
+		t.Errorf("Expected version 10 with its SQL, got %d %q", got[2].Version, got[2].SQL)
+	}
+}
+
+func TestLoadMigrations_DuplicateVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"003_a.sql": {Data: []byte("SELECT 1")},
+		"003_b.sql": {Data: []byte("SELECT 2")},
+	}
+	if _, err := LoadMigrations(fsys); err == nil {
+		t.Error("Expected error for duplicate migration version")
+	}
+}
+
+func TestLoadMigrations_EmbeddedFiles(t *testing.T) {
+	got, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(got) == 0 || got[0].Name != "001_initial_schema.sql" {
+		t.Fatalf("Expected embedded migrations to start with 001_initial_schema.sql, got %v", got)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].Version <= got[i-1].Version {
+			t.Errorf("Expected increasing versions, got %d after %d", got[i].Version, got[i-1].Version)
+		}
+	}
+}
+
+// openTestSchema connects to TESTDB_URL with a throwaway schema first on the search_path
+// Tests using it are skipped when TESTDB_URL is not set
+func openTestSchema(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dbURL := os.Getenv("TESTDB_URL")
+	if dbURL == "" {
+		t.Skip("TESTDB_URL not set; skipping database test")
+	}
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+
+	admin, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		admin.Close()
+		t.Fatalf("Failed to create test schema: %v", err)
+	}
+
+	cfg, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		admin.Close()
+		t.Fatalf("Failed to parse TESTDB_URL: %v", err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema + ", public"
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		admin.Close()
+		t.Fatalf("Failed to connect to test schema: %v", err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+		_, _ = admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		admin.Close()
+	})
+	return pool
+}
+
+func TestMigrate_FreshSchema(t *testing.T) {
+	pool := openTestSchema(t)
+	ctx := context.Background()
+
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ran, err := Migrate(ctx, pool, all)
+	if err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+	if len(ran) != len(all) {
+		t.Errorf("Expected %d migrations applied, got %d", len(all), len(ran))
+	}
+
+	// Columns queries rely on must exist in the new schema
+	columns := []struct{ table, column string }{
+		{"opportunity", "solicitation_number"},
+		{"opportunity", "agency_path_name"},
+		{"opportunity", "search_tsv"},
+		{"opportunity_description", "brief_summary"},
+		{"opportunity_description", "ai_meta"},
+		{"opportunity_description", "normalization_version"},
+		{"opportunity_tag", "tag"},
+	}
+	for _, c := range columns {
+		var exists bool
+		err := pool.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM information_schema.columns
+				WHERE table_schema = current_schema() AND table_name = $1 AND column_name = $2
+			)`, c.table, c.column).Scan(&exists)
+		if err != nil {
+			t.Fatalf("Failed to check column: %v", err)
+		}
+		if !exists {
+			t.Errorf("Expected column %s.%s to exist", c.table, c.column)
+		}
+	}
+
+	// A second run is a no-op
+	ran, err = Migrate(ctx, pool, all)
+	if err != nil {
+		t.Fatalf("Failed to re-run migrations: %v", err)
+	}
+	if len(ran) != 0 {
+		t.Errorf("Expected no migrations on second run, got %d", len(ran))
+	}
+
+	statuses, err := Status(ctx, pool, all)
+	if err != nil {
+		t.Fatalf("Failed to read status: %v", err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			t.Errorf("Expected %s to be applied", s.Name)
+		}
+	}
+}
+
+func TestMigrate_FailedMigrationIsNotRecorded(t *testing.T) {
+	pool := openTestSchema(t)
+	ctx := context.Background()
+
+	all := []Migration{
+		{Version: 1, Name: "001_ok.sql", SQL: "CREATE TABLE migrate_ok (id INT)"},
+		{Version: 2, Name: "002_broken.sql", SQL: "CREATE TABLE migrate_broken (id INT); SELECT * FROM missing_table"},
+	}
+	ran, err := Migrate(ctx, pool, all)
+	if err == nil {
+		t.Fatal("Expected error from broken migration")
+	}
+	if len(ran) != 1 {
+		t.Errorf("Expected only the first migration applied, got %d", len(ran))
+	}
+
+	statuses, err := Status(ctx, pool, all)
+	if err != nil {
+		t.Fatalf("Failed to read status: %v", err)
+	}
+	if statuses[0].AppliedAt == nil || statuses[1].AppliedAt != nil {
+		t.Errorf("Expected 001 applied and 002 pending, got %+v", statuses)
+	}
+
+	var exists bool
+	pool.QueryRow(ctx, "SELECT to_regclass('migrate_broken') IS NOT NULL").Scan(&exists)
+	if exists {
+		t.Error("Expected broken migration to be rolled back")
+	}
+}
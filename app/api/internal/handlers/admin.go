@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/repositories"
+)
+
+const (
+	defaultJobRunsLimit = 20
+	maxJobRunsLimit     = 100
+)
+
+type AdminHandler struct {
+	jobRepo *repositories.JobRunRepository
+	caches  map[string]Cache // Registered in-memory caches, by name
+}
+
+func NewAdminHandler(jobRepo *repositories.JobRunRepository) *AdminHandler {
+	return &AdminHandler{jobRepo: jobRepo, caches: make(map[string]Cache)}
+}
+
+// Cache is an in-memory cache the admin endpoints can report on and clear
+type Cache interface {
+	CacheStats() models.CacheStats
+	PurgeCache()
+}
+
+// RegisterCache makes c visible to /admin/cache/stats and purgeable by name
+func (h *AdminHandler) RegisterCache(name string, c Cache) {
+	h.caches[name] = c
+}
+
+// getAdminToken returns the bearer token admin write endpoints require (ADMIN_TOKEN); "" disables them
+func getAdminToken() string {
+	return strings.TrimSpace(os.Getenv("ADMIN_TOKEN"))
+}
+
+// requireAdminToken checks the request's Authorization: Bearer header against ADMIN_TOKEN
+// Writes 403 when no token is configured and 401 when it doesn't match; returns false if an error was written
+func requireAdminToken(w http.ResponseWriter, r *http.Request) bool {
+	token := getAdminToken()
+	if token == "" {
+		WriteError(w, http.StatusForbidden, ErrCodeForbidden, "admin endpoints are disabled; set ADMIN_TOKEN to enable them")
+		return false
+	}
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(given)), []byte(token)) != 1 {
+		WriteError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing or invalid admin token")
+		return false
+	}
+	return true
+}
+
+// parseJobRunsLimit returns the limit param, defaulting when missing or invalid and capped at maxJobRunsLimit
+func parseJobRunsLimit(limitStr string) int {
+	limit := defaultJobRunsLimit
+	if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+		limit = parsed
+	}
+	if limit > maxJobRunsLimit {
+		limit = maxJobRunsLimit
+	}
+	return limit
+}
+
+// HandleListJobs handles GET /admin/jobs?type=ingest&limit=20
+// Returns recent ingestion/backfill runs, newest first; a run still "running" with no finishedAt may have been killed
+func (h *AdminHandler) HandleListJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	runs, err := h.jobRepo.ListRuns(r.Context(), strings.TrimSpace(query.Get("type")), parseJobRunsLimit(query.Get("limit")))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := ErrCodeInternal
+		if strings.Contains(err.Error(), "database migration required") {
+			statusCode = http.StatusServiceUnavailable
+			errorCode = ErrCodeMigrationRequired
+		}
+		WriteError(w, statusCode, errorCode, err.Error())
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{"items": runs})
+}
+
+// HandleCacheStats handles GET /admin/cache/stats
+// Reports each registered in-memory cache's size and hit/miss counts, by name
+func (h *AdminHandler) HandleCacheStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	items := make([]models.CacheStats, 0, len(h.caches))
+	for name, c := range h.caches {
+		stats := c.CacheStats()
+		stats.Name = name
+		items = append(items, stats)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// HandleCachePurge handles POST /admin/cache/purge?name=heal_cooldown
+// Clears one registered cache, e.g. after a data fix, without restarting the API. Requires ADMIN_TOKEN
+func (h *AdminHandler) HandleCachePurge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !requireAdminToken(w, r) {
+		return
+	}
+
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if name == "" {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "name is required")
+		return
+	}
+	c, ok := h.caches[name]
+	if !ok {
+		WriteError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("unknown cache %q", name))
+		return
+	}
+
+	purged := c.CacheStats().Size
+	c.PurgeCache()
+	WriteJSON(w, http.StatusOK, map[string]any{"name": name, "purged": purged})
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"govcon/api/internal/models"
+)
+
+func TestParseJobRunsLimit(t *testing.T) {
+	cases := map[string]int{
+		"":    defaultJobRunsLimit,
+		"5":   5,
+		"0":   defaultJobRunsLimit,
+		"-3":  defaultJobRunsLimit,
+		"abc": defaultJobRunsLimit,
+		"500": maxJobRunsLimit,
+	}
+	for input, expected := range cases {
+		if got := parseJobRunsLimit(input); got != expected {
+			t.Errorf("parseJobRunsLimit(%q): expected %d, got %d", input, expected, got)
+		}
+	}
+}
+
+func TestHandleListJobs_MethodNotAllowed(t *testing.T) {
+	h := &AdminHandler{}
+	req := httptest.NewRequest(http.MethodPost, "/admin/jobs", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleListJobs(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
+
+// newCacheTestAdmin returns an AdminHandler with two heal trackers registered as caches
+func newCacheTestAdmin() (*AdminHandler, *healTracker, *healTracker) {
+	h := NewAdminHandler(nil)
+	first, second := newHealTracker(time.Minute), newHealTracker(time.Minute)
+	h.RegisterCache("first", first)
+	h.RegisterCache("second", second)
+	return h, first, second
+}
+
+func TestHandleCacheStats(t *testing.T) {
+	h, first, _ := newCacheTestAdmin()
+	first.RecordFailure("N1")
+	first.RecordFailure("N2")
+	first.ShouldAttempt("N1") // hit: in cooldown
+	first.ShouldAttempt("N3") // miss
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/cache/stats", nil)
+	rec := httptest.NewRecorder()
+	h.HandleCacheStats(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	var resp struct {
+		Items []models.CacheStats `json:"items"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	expected := []models.CacheStats{
+		{Name: "first", Size: 2, Hits: 1, Misses: 1},
+		{Name: "second"},
+	}
+	if len(resp.Items) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, resp.Items)
+	}
+	for i := range expected {
+		if resp.Items[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v", expected[i], resp.Items[i])
+		}
+	}
+}
+
+func TestHandleCachePurge_Targeted(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "secret")
+	h, first, second := newCacheTestAdmin()
+	first.RecordFailure("N1")
+	second.RecordFailure("N2")
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/cache/purge?name=first", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	h.HandleCachePurge(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Name   string `json:"name"`
+		Purged int    `json:"purged"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Name != "first" || resp.Purged != 1 {
+		t.Errorf("Expected first with 1 purged, got %+v", resp)
+	}
+	if !first.ShouldAttempt("N1") {
+		t.Error("Expected the purged cache to have forgotten N1")
+	}
+	if second.ShouldAttempt("N2") {
+		t.Error("Expected the other cache to keep N2")
+	}
+}
+
+func TestHandleCachePurge_Errors(t *testing.T) {
+	cases := []struct {
+		name   string
+		token  string // ADMIN_TOKEN
+		auth   string // Authorization header
+		query  string
+		status int
+	}{
+		{"disabled without ADMIN_TOKEN", "", "Bearer secret", "?name=first", http.StatusForbidden},
+		{"missing token", "secret", "", "?name=first", http.StatusUnauthorized},
+		{"wrong token", "secret", "Bearer nope", "?name=first", http.StatusUnauthorized},
+		{"missing name", "secret", "Bearer secret", "", http.StatusBadRequest},
+		{"unknown cache", "secret", "Bearer secret", "?name=opportunities", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Setenv("ADMIN_TOKEN", c.token)
+		h, first, _ := newCacheTestAdmin()
+		first.RecordFailure("N1")
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/cache/purge"+c.query, nil)
+		if c.auth != "" {
+			req.Header.Set("Authorization", c.auth)
+		}
+		rec := httptest.NewRecorder()
+		h.HandleCachePurge(rec, req)
+
+		if rec.Code != c.status {
+			t.Errorf("%s: Expected status %d, got %d", c.name, c.status, rec.Code)
+		}
+		if first.CacheStats().Size != 1 {
+			t.Errorf("%s: Expected nothing purged", c.name)
+		}
+	}
+}
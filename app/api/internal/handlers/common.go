@@ -2,12 +2,60 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 )
 
+// Machine-readable error codes returned in ErrorResponse.Code
+const (
+	ErrCodeBadRequest         = "bad_request"
+	ErrCodeNotFound           = "not_found"
+	ErrCodeMethodNotAllowed   = "method_not_allowed"
+	ErrCodePayloadTooLarge    = "payload_too_large"
+	ErrCodeInternal           = "internal_error"
+	ErrCodeServiceUnavailable = "service_unavailable"
+	ErrCodeMigrationRequired  = "migration_required"
+	ErrCodeFetchInProgress    = "fetch_in_progress"
+	ErrCodeUnauthorized       = "unauthorized"
+	ErrCodeForbidden          = "forbidden"
+)
+
+// ErrorResponse is the envelope for every error returned by the API
+type ErrorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// WriteJSON encodes v straight into the ResponseWriter rather than an intermediate buffer;
+// response size is bounded by the handlers themselves (pagination limits, capped description text)
 func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(v)
 }
 
+// WriteError writes an ErrorResponse with the given status
+// The request ID is picked up from the X-Request-ID response header when one has been set
+func WriteError(w http.ResponseWriter, status int, code, msg string) {
+	WriteJSON(w, status, ErrorResponse{
+		Code:      code,
+		Message:   msg,
+		RequestID: w.Header().Get("X-Request-ID"),
+	})
+}
+
+// decodeJSONBody decodes the request body into v, writing 413 when the body exceeds its
+// MaxBytesReader limit and 400 for malformed JSON. Returns false if an error was written.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			WriteError(w, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge, "request body too large")
+			return false
+		}
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid JSON body")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func decodeErrorResponse(t *testing.T, rec *httptest.ResponseRecorder) ErrorResponse {
+	t.Helper()
+	var resp ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	return resp
+}
+
+func TestWriteError_NotFound(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, http.StatusNotFound, ErrCodeNotFound, "opportunity not found")
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected Content-Type %q, got %q", "application/json", ct)
+	}
+
+	resp := decodeErrorResponse(t, rec)
+	if resp.Code != ErrCodeNotFound {
+		t.Errorf("Expected code %q, got %q", ErrCodeNotFound, resp.Code)
+	}
+	if resp.Message != "opportunity not found" {
+		t.Errorf("Expected message %q, got %q", "opportunity not found", resp.Message)
+	}
+	if resp.RequestID != "" {
+		t.Errorf("Expected empty requestId, got %q", resp.RequestID)
+	}
+}
+
+func TestWriteError_IncludesRequestID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("X-Request-ID", "req-123")
+	WriteError(rec, http.StatusNotFound, ErrCodeNotFound, "opportunity not found")
+
+	resp := decodeErrorResponse(t, rec)
+	if resp.RequestID != "req-123" {
+		t.Errorf("Expected requestId %q, got %q", "req-123", resp.RequestID)
+	}
+}
+
+func TestHandleGetOpportunity_MissingNoticeID(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleGetOpportunity(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if resp.Code != ErrCodeBadRequest {
+		t.Errorf("Expected code %q, got %q", ErrCodeBadRequest, resp.Code)
+	}
+	if resp.Message != "noticeId is required" {
+		t.Errorf("Expected message %q, got %q", "noticeId is required", resp.Message)
+	}
+}
+
+func TestHandleGetOpportunity_InvalidInclude(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/N1?include=provenance,history", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleGetOpportunity(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if resp := decodeErrorResponse(t, rec); !strings.Contains(resp.Message, `"history"`) {
+		t.Errorf("Expected the unknown include to be named, got %q", resp.Message)
+	}
+}
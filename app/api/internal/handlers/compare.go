@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"govcon/api/internal/models"
+)
+
+// maxCompareIDs caps how many notices one comparison may request
+const maxCompareIDs = 10
+
+// compareRow is one notice's column in the comparison; every key is always present (null when unknown)
+type compareRow struct {
+	NoticeID         string   `json:"noticeId"`
+	Title            string   `json:"title"`
+	Agency           string   `json:"agency"`
+	SetAside         string   `json:"setAside"`
+	SetAsideDesc     string   `json:"setAsideDesc"`
+	NAICS            []string `json:"naics"`
+	ResponseDeadline string   `json:"responseDeadline"`
+	EstimatedValue   *float64 `json:"estimatedValue"`
+	KeyRequirements  []string `json:"keyRequirements"`
+}
+
+// compareResponse lists found notices in request order and reports the IDs that matched nothing
+type compareResponse struct {
+	Items   []compareRow `json:"items"`
+	Missing []string     `json:"missing"`
+}
+
+// parseCompareIDs splits the comma-separated ids param, dropping blanks and duplicates
+// Returns a client-facing error message when none are given or there are more than maxCompareIDs
+func parseCompareIDs(raw string) ([]string, string) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		id := strings.TrimSpace(part)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, "ids is required (comma-separated notice IDs)"
+	}
+	if len(ids) > maxCompareIDs {
+		return nil, fmt.Sprintf("at most %d ids can be compared, got %d", maxCompareIDs, len(ids))
+	}
+	return ids, ""
+}
+
+// buildComparison assembles the rows for ids in order; IDs missing from opps are listed in Missing
+func buildComparison(ids []string, opps map[string]*models.Opportunity, metas map[string]*models.AiMeta) compareResponse {
+	resp := compareResponse{Items: []compareRow{}, Missing: []string{}}
+	for _, id := range ids {
+		opp, ok := opps[id]
+		if !ok {
+			resp.Missing = append(resp.Missing, id)
+			continue
+		}
+
+		row := compareRow{
+			NoticeID:         opp.NoticeID,
+			Title:            opp.Title,
+			SetAside:         opp.TypeOfSetAside,
+			SetAsideDesc:     opp.TypeOfSetAsideDesc,
+			NAICS:            []string{},
+			ResponseDeadline: models.FormatAPIDate(opp.ResponseDeadline),
+			EstimatedValue:   awardAmount(opp.Award),
+			KeyRequirements:  []string{},
+		}
+		row.Agency = opp.Department
+		if opp.AgencyPathName != nil && *opp.AgencyPathName != "" {
+			row.Agency = *opp.AgencyPathName
+		}
+		if row.SetAsideDesc == "" && opp.TypeOfSetAsideDescription != nil {
+			row.SetAsideDesc = *opp.TypeOfSetAsideDescription
+		}
+		for _, n := range opp.NAICS {
+			if n.Code != "" {
+				row.NAICS = append(row.NAICS, n.Code)
+			}
+		}
+		if len(row.NAICS) == 0 && opp.NAICSCode != nil && *opp.NAICSCode != "" {
+			row.NAICS = append(row.NAICS, *opp.NAICSCode)
+		}
+		if meta := metas[id]; meta != nil && meta.KeyRequirements != nil {
+			row.KeyRequirements = meta.KeyRequirements
+		}
+		resp.Items = append(resp.Items, row)
+	}
+	return resp
+}
+
+// awardAmount reads the dollar amount from SAM's award object, which sends it as a number or a string
+func awardAmount(award interface{}) *float64 {
+	obj, ok := award.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	switch v := obj["amount"].(type) {
+	case json.Number:
+		if amount, err := v.Float64(); err == nil {
+			return &amount
+		}
+	case float64:
+		return &v
+	case string:
+		if amount, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimPrefix(strings.TrimSpace(v), "$"), ",", ""), 64); err == nil {
+			return &amount
+		}
+	}
+	return nil
+}
+
+// HandleCompare handles GET /opportunities/compare?ids=a,b,c
+// Returns key fields side by side for up to maxCompareIDs notices, listing IDs that weren't found
+func (h *OpportunitiesHandler) HandleCompare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	ids, errMsg := parseCompareIDs(r.URL.Query().Get("ids"))
+	if errMsg != "" {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, errMsg)
+		return
+	}
+
+	opps, err := h.repo.GetOpportunitiesByNoticeIDs(r.Context(), ids)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		errorCode := ErrCodeInternal
+		if strings.Contains(err.Error(), "database migration required") {
+			statusCode = http.StatusServiceUnavailable
+			errorCode = ErrCodeMigrationRequired
+		}
+		WriteError(w, statusCode, errorCode, err.Error())
+		return
+	}
+
+	// Key requirements are optional; a failure here still returns the rest of the comparison
+	metas, err := h.descRepo.GetAIMetas(r.Context(), ids)
+	if err != nil {
+		log.Printf("Failed to get ai_meta for comparison: %v", err)
+		metas = nil
+	}
+
+	WriteJSON(w, http.StatusOK, buildComparison(ids, opps, metas))
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"govcon/api/internal/models"
+)
+
+func TestAwardAmount(t *testing.T) {
+	tests := []struct {
+		name  string
+		award interface{}
+		want  *float64
+	}{
+		{"json number", map[string]interface{}{"amount": json.Number("1250000.50")}, floatPtr(1250000.50)},
+		{"float", map[string]interface{}{"amount": 99.5}, floatPtr(99.5)},
+		{"string", map[string]interface{}{"amount": "$1,000"}, floatPtr(1000)},
+		{"missing", map[string]interface{}{}, nil},
+		{"not an object", "n/a", nil},
+	}
+	for _, tt := range tests {
+		got := awardAmount(tt.award)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("%s: Expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestParseCompareIDs(t *testing.T) {
+	ids, errMsg := parseCompareIDs(" N1, N2,,N1 ")
+	if errMsg != "" || strings.Join(ids, ",") != "N1,N2" {
+		t.Errorf("Expected [N1 N2], got %v (%s)", ids, errMsg)
+	}
+	if _, errMsg := parseCompareIDs(" , "); errMsg == "" {
+		t.Error("Expected error when no ids are given")
+	}
+	var many []string
+	for i := 0; i <= maxCompareIDs; i++ {
+		many = append(many, fmt.Sprintf("N%d", i))
+	}
+	if _, errMsg := parseCompareIDs(strings.Join(many, ",")); !strings.Contains(errMsg, "at most") {
+		t.Errorf("Expected cap error for %d ids, got %q", len(many), errMsg)
+	}
+}
+
+func TestBuildComparison_OneMissing(t *testing.T) {
+	opp := &models.Opportunity{
+		NoticeID:         "N1",
+		Title:            "Janitorial Services",
+		AgencyPathName:   strPtr("DEPT OF DEFENSE.ARMY"),
+		TypeOfSetAside:   "SBA",
+		ResponseDeadline: "2025-04-01T23:59:59-04:00",
+		Award:            map[string]interface{}{"amount": "$1,250,000.50"},
+	}
+	opp.NAICS = append(opp.NAICS, struct {
+		Code        string `json:"code"`
+		Description string `json:"description"`
+	}{Code: "561720"})
+	metas := map[string]*models.AiMeta{"N1": {KeyRequirements: []string{"Delivery: 30 calendar days ARO"}}}
+
+	resp := buildComparison([]string{"N1", "MISSING"}, map[string]*models.Opportunity{"N1": opp}, metas)
+
+	if len(resp.Items) != 1 || resp.Items[0].NoticeID != "N1" {
+		t.Fatalf("Expected one row for N1, got %+v", resp.Items)
+	}
+	if len(resp.Missing) != 1 || resp.Missing[0] != "MISSING" {
+		t.Errorf("Expected MISSING to be reported, got %v", resp.Missing)
+	}
+	row := resp.Items[0]
+	if row.Agency != "DEPT OF DEFENSE.ARMY" || row.SetAside != "SBA" || strings.Join(row.NAICS, ",") != "561720" {
+		t.Errorf("Unexpected row fields: %+v", row)
+	}
+	if row.EstimatedValue == nil || *row.EstimatedValue != 1250000.50 {
+		t.Errorf("Expected estimated value 1250000.50, got %v", row.EstimatedValue)
+	}
+	if len(row.KeyRequirements) != 1 {
+		t.Errorf("Expected key requirements from ai_meta, got %v", row.KeyRequirements)
+	}
+}
+
+func TestBuildComparison_ResponseDeadlineInAPIFormat(t *testing.T) {
+	cases := map[string]string{
+		"04/01/2025":                "2025-04-01",
+		"2025-04-01 17:00:00":       "2025-04-01T17:00:00Z",
+		"2025-04-01T23:59:59-04:00": "2025-04-01T23:59:59-04:00",
+		"":                          "",
+	}
+	for stored, expected := range cases {
+		opps := map[string]*models.Opportunity{"N1": {NoticeID: "N1", ResponseDeadline: stored}}
+		resp := buildComparison([]string{"N1"}, opps, nil)
+		if got := resp.Items[0].ResponseDeadline; got != expected {
+			t.Errorf("%q: expected %q, got %q", stored, expected, got)
+		}
+	}
+}
+
+func TestBuildComparison_UnknownFieldsAreNullOrEmpty(t *testing.T) {
+	resp := buildComparison([]string{"N1"}, map[string]*models.Opportunity{"N1": {NoticeID: "N1", Department: "GSA"}}, nil)
+
+	body, _ := json.Marshal(resp)
+	for _, want := range []string{`"estimatedValue":null`, `"naics":[]`, `"keyRequirements":[]`, `"missing":[]`, `"agency":"GSA"`} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %s in %s", want, body)
+		}
+	}
+}
+
+func TestHandleCompare_Validation(t *testing.T) {
+	h := &OpportunitiesHandler{}
+
+	rec := httptest.NewRecorder()
+	h.HandleCompare(rec, httptest.NewRequest(http.MethodGet, "/opportunities/compare", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without ids, got %d", http.StatusBadRequest, rec.Code)
+	}
+	if resp := decodeErrorResponse(t, rec); resp.Code != ErrCodeBadRequest {
+		t.Errorf("Expected code %q, got %q", ErrCodeBadRequest, resp.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.HandleCompare(rec, httptest.NewRequest(http.MethodPost, "/opportunities/compare?ids=N1", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
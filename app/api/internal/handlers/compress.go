@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultGzipMinBytes is the smallest body worth compressing; smaller responses are sent as-is
+const defaultGzipMinBytes = 1024
+
+// gzipResponseWriter holds back the first minBytes of a response to decide whether to compress it,
+// then either streams through gzip or passes the body through untouched
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minBytes int
+	status   int
+	buf      []byte
+	decided  bool
+	gz       *gzip.Writer
+}
+
+func (g *gzipResponseWriter) WriteHeader(status int) {
+	if g.decided || g.status != 0 {
+		return
+	}
+	g.status = status
+	// Bodyless responses go straight out
+	if status == http.StatusNoContent || status == http.StatusNotModified || status < 200 {
+		g.start(false)
+	}
+}
+
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+	if g.status == 0 {
+		g.status = http.StatusOK
+	}
+	if !g.decided {
+		g.buf = append(g.buf, b...)
+		if len(g.buf) < g.minBytes {
+			return len(b), nil
+		}
+		if err := g.start(g.compressible()); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if g.gz != nil {
+		return g.gz.Write(b)
+	}
+	return g.ResponseWriter.Write(b)
+}
+
+// Flush sends what's buffered so streaming responses (SSE, streamed exports) aren't held back;
+// a stream flushed before reaching minBytes is decided then, by content type alone
+func (g *gzipResponseWriter) Flush() {
+	if !g.decided {
+		if g.status == 0 {
+			g.status = http.StatusOK
+		}
+		g.start(g.compressible())
+	}
+	if g.gz != nil {
+		g.gz.Flush()
+	}
+	if f, ok := g.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// compressible reports whether the response should be gzipped once it is large enough
+func (g *gzipResponseWriter) compressible() bool {
+	h := g.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := h.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(g.buf)
+	}
+	return isCompressibleType(contentType)
+}
+
+// start writes the header and buffered bytes, compressing from here on if compress is set
+func (g *gzipResponseWriter) start(compress bool) error {
+	g.decided = true
+	h := g.Header()
+	if compress {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		// The compressed body differs byte-for-byte, so a strong validator no longer applies
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		g.gz = gzip.NewWriter(g.ResponseWriter)
+	}
+	if g.status != 0 {
+		g.ResponseWriter.WriteHeader(g.status)
+	}
+	if len(g.buf) == 0 {
+		return nil
+	}
+	buf := g.buf
+	g.buf = nil
+	var err error
+	if g.gz != nil {
+		_, err = g.gz.Write(buf)
+	} else {
+		_, err = g.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// finish sends a response that never reached minBytes uncompressed, or closes the gzip stream
+func (g *gzipResponseWriter) finish() {
+	if !g.decided {
+		g.start(false)
+	}
+	if g.gz != nil {
+		g.gz.Close()
+	}
+}
+
+// isCompressibleType reports whether a Content-Type is text-like; event streams are excluded
+// so SSE stays unbuffered, and already-compressed media (images, archives) is left alone
+func isCompressibleType(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]))
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", mediaType == "application/x-ndjson", mediaType == "application/javascript",
+		mediaType == "application/xml", strings.HasSuffix(mediaType, "+json"), strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	return false
+}
+
+// acceptsGzip reports whether the request's Accept-Encoding allows gzip (an explicit q=0 refuses it)
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		fields := strings.Split(part, ";")
+		if coding := strings.ToLower(strings.TrimSpace(fields[0])); coding != "gzip" && coding != "*" {
+			continue
+		}
+		for _, param := range fields[1:] {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if q, err := strconv.ParseFloat(value, 64); strings.TrimSpace(name) == "q" && err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Gzip compresses text-like responses of at least minBytes (0 for the 1KB default) for clients that
+// accept gzip. Only the first minBytes are held back, so large and streamed responses are never
+// buffered whole; responses that already set Content-Encoding and SSE streams pass through.
+func Gzip(minBytes int, next http.Handler) http.Handler {
+	if minBytes <= 0 {
+		minBytes = defaultGzipMinBytes
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if r.Method == http.MethodHead || !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w, minBytes: minBytes}
+		next.ServeHTTP(gw, r)
+		// Not deferred: on panic the held-back bytes are dropped so Recover can still send a clean 500
+		gw.finish()
+	})
+}
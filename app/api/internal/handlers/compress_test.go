@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func largeJSONHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		items := make([]map[string]string, 200)
+		for i := range items {
+			items[i] = map[string]string{"title": "Janitorial Services", "office": "Facilities"}
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"items": items})
+	})
+}
+
+func TestGzip_CompressesLargeJSON(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	rec := httptest.NewRecorder()
+	Gzip(0, largeJSONHandler()).ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Expected Content-Encoding gzip, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	if rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("Expected Vary Accept-Encoding, got %q", rec.Header().Get("Vary"))
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Expected gzip body: %v", err)
+	}
+	var body struct {
+		Items []map[string]string `json:"items"`
+	}
+	if err := json.NewDecoder(gz).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode gunzipped body: %v", err)
+	}
+	if len(body.Items) != 200 {
+		t.Errorf("Expected 200 items, got %d", len(body.Items))
+	}
+}
+
+func TestGzip_SkipsClientsWithoutGzip(t *testing.T) {
+	for _, acceptEncoding := range []string{"", "br", "gzip;q=0"} {
+		req := httptest.NewRequest(http.MethodGet, "/opportunities/search", nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rec := httptest.NewRecorder()
+		Gzip(0, largeJSONHandler()).ServeHTTP(rec, req)
+
+		if rec.Header().Get("Content-Encoding") != "" {
+			t.Errorf("Accept-Encoding %q: expected no compression, got %q", acceptEncoding, rec.Header().Get("Content-Encoding"))
+		}
+		if !json.Valid(rec.Body.Bytes()) {
+			t.Errorf("Accept-Encoding %q: expected plain JSON body", acceptEncoding)
+		}
+	}
+}
+
+func TestGzip_SkipsSmallBodies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	Gzip(0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusCreated, map[string]any{"ok": true})
+	})).ServeHTTP(rec, req)
+
+	if rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Expected small body uncompressed, got %q", rec.Header().Get("Content-Encoding"))
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d", http.StatusCreated, rec.Code)
+	}
+	if strings.TrimSpace(rec.Body.String()) != `{"ok":true}` {
+		t.Errorf("Expected body %q, got %q", `{"ok":true}`, rec.Body.String())
+	}
+}
+
+func TestGzip_SkipsAlreadyEncodedAndEventStreams(t *testing.T) {
+	big := strings.Repeat("x", 4096)
+	handlers := map[string]http.HandlerFunc{
+		"already encoded": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", "br")
+			io.WriteString(w, big)
+		},
+		"image": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, big)
+		},
+		"event stream": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			io.WriteString(w, "data: "+big+"\n\n")
+		},
+	}
+	for name, h := range handlers {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		Gzip(0, h).ServeHTTP(rec, req)
+
+		if rec.Header().Get("Content-Encoding") == "gzip" {
+			t.Errorf("%s: expected no gzip", name)
+		}
+		if !strings.Contains(rec.Body.String(), big) {
+			t.Errorf("%s: expected body passed through", name)
+		}
+	}
+}
+
+func TestGzip_StreamedResponseIsNotBufferedWhole(t *testing.T) {
+	chunk := strings.Repeat("{\"title\":\"Janitorial Services\"}\n", 64)
+	flushedBeforeEnd := false
+	var rec *httptest.ResponseRecorder
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, chunk)
+		w.(http.Flusher).Flush()
+		flushedBeforeEnd = rec.Body.Len() > 0
+		io.WriteString(w, chunk)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/export", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec = httptest.NewRecorder()
+	Gzip(0, h).ServeHTTP(rec, req)
+
+	if !flushedBeforeEnd {
+		t.Error("Expected compressed bytes to reach the client on Flush")
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("Expected gzip body: %v", err)
+	}
+	body, _ := io.ReadAll(gz)
+	if string(body) != chunk+chunk {
+		t.Errorf("Expected both chunks after gunzip, got %d bytes", len(body))
+	}
+}
+
+func TestGzip_WeakensStrongETag(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	Gzip(0, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		largeJSONHandler().ServeHTTP(w, r)
+	})).ServeHTTP(rec, req)
+
+	if rec.Header().Get("ETag") != `W/"abc"` {
+		t.Errorf("Expected weak ETag, got %q", rec.Header().Get("ETag"))
+	}
+}
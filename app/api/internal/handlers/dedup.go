@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/services"
+)
+
+// getDescDedup reports whether identical descriptions share AI output (DESC_DEDUP, default false)
+func getDescDedup() bool {
+	if enabled, err := strconv.ParseBool(os.Getenv("DESC_DEDUP")); err == nil {
+		return enabled
+	}
+	return false
+}
+
+// aiFieldLookup returns a lookup that finds another notice's AI output for the same content hash,
+// or nil when dedup is disabled. Lookup errors are logged and treated as no match.
+func (h *OpportunitiesHandler) aiFieldLookup(ctx context.Context, noticeID string) services.AIFieldLookup {
+	if !getDescDedup() {
+		return nil
+	}
+	return func(contentHash string) *models.OpportunityDescription {
+		match, err := h.descRepo.FindAIFieldsByContentHash(ctx, contentHash, services.NORMALIZATION_VERSION, services.AI_INPUT_VERSION, noticeID)
+		if err != nil {
+			log.Printf("Description dedup lookup failed for noticeId=%s: %v", noticeID, err)
+			return nil
+		}
+		if match != nil {
+			log.Printf("Description dedup: noticeId=%s reusing AI output from noticeId=%s", noticeID, match.NoticeID)
+		}
+		return match
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/services"
+)
+
+// HandleDescribePreview handles POST /describe/preview
+// Runs ad-hoc text through the same unwrap/normalize/AI pipeline as stored descriptions
+// without touching the database, which makes tuning normalization much faster.
+func (h *OpportunitiesHandler) HandleDescribePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// Cap the body the same way fetched descriptions are capped
+	r.Body = http.MaxBytesReader(w, r.Body, services.MaxDescriptionBodySize())
+
+	var req models.DescriptionPreviewRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	rawText := services.UnwrapDescriptionText(req.RawText)
+	rawTextNormalized := services.NormalizeRaw(rawText)
+	textNormalized := services.Normalize(rawTextNormalized)
+
+	aiInputText, excerptText, aiMeta, _, err := services.OptimizeForAI(rawTextNormalized)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, models.DescriptionPreviewResponse{
+		RawTextNormalized: rawTextNormalized,
+		TextNormalized:    textNormalized,
+		AIInputText:       aiInputText,
+		ExcerptText:       excerptText,
+		AIMeta:            aiMeta,
+	})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"os"
+	"time"
+
+	"govcon/api/internal/models"
+)
+
+// getDescMaxAge returns how long a fetched URL description is served before it is re-fetched from SAM
+// (DESC_MAX_AGE, e.g. 720h). Zero, the default, means never: descriptions refresh only on refresh=true.
+func getDescMaxAge() time.Duration {
+	if ageStr := os.Getenv("DESC_MAX_AGE"); ageStr != "" {
+		if age, err := time.ParseDuration(ageStr); err == nil && age > 0 {
+			return age
+		}
+	}
+	return 0
+}
+
+// descriptionStale reports whether a fetched URL description is older than maxAge and should be
+// re-validated against SAM. Only URL sources can be re-fetched; a missing fetchedAt counts as stale.
+func descriptionStale(desc *models.OpportunityDescription, maxAge time.Duration, now time.Time) bool {
+	if maxAge <= 0 || desc == nil || desc.SourceType != models.SourceTypeURL {
+		return false
+	}
+	if desc.FetchedAt == nil {
+		return true
+	}
+	return now.Sub(*desc.FetchedAt) > maxAge
+}
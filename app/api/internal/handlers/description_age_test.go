@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"govcon/api/internal/models"
+)
+
+func TestDescriptionStale(t *testing.T) {
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+	fetchedAt := func(age time.Duration) *models.OpportunityDescription {
+		at := now.Add(-age)
+		return &models.OpportunityDescription{NoticeID: "N1", SourceType: models.SourceTypeURL, FetchStatus: models.FetchStatusFetched, FetchedAt: &at}
+	}
+	maxAge := 30 * 24 * time.Hour
+
+	if !descriptionStale(fetchedAt(31*24*time.Hour), maxAge, now) {
+		t.Error("Expected description older than max age to be re-fetched")
+	}
+	if descriptionStale(fetchedAt(24*time.Hour), maxAge, now) {
+		t.Error("Expected fresh description to be served from cache")
+	}
+	if descriptionStale(fetchedAt(365*24*time.Hour), 0, now) {
+		t.Error("Expected no max age to never force a re-fetch")
+	}
+
+	inline := fetchedAt(365 * 24 * time.Hour)
+	inline.SourceType = models.SourceTypeInline
+	if descriptionStale(inline, maxAge, now) {
+		t.Error("Expected inline descriptions never to be re-fetched")
+	}
+
+	noFetchedAt := fetchedAt(0)
+	noFetchedAt.FetchedAt = nil
+	if !descriptionStale(noFetchedAt, maxAge, now) {
+		t.Error("Expected a description without fetchedAt to count as stale")
+	}
+}
+
+func TestGetDescMaxAge(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"720h", 720 * time.Hour},
+		{"-1h", 0},
+		{"bogus", 0},
+	}
+	for _, tt := range tests {
+		t.Setenv("DESC_MAX_AGE", tt.value)
+		if got := getDescMaxAge(); got != tt.want {
+			t.Errorf("DESC_MAX_AGE=%q: Expected %s, got %s", tt.value, tt.want, got)
+		}
+	}
+}
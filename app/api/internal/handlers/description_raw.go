@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// HandleGetDescriptionRaw handles GET /opportunities/:noticeId/description/raw.json
+// Returns the stored SAM description response body exactly as received, for debugging
+func (h *OpportunitiesHandler) HandleGetDescriptionRaw(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// Path format: /opportunities/{noticeId}/description/raw.json
+	path := strings.TrimPrefix(r.URL.Path, "/opportunities/")
+	path = strings.TrimSuffix(path, "/description/raw.json")
+	noticeID := strings.Trim(path, "/")
+	if noticeID == "" {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "noticeId is required")
+		return
+	}
+
+	raw, err := h.descRepo.GetRawJSONResponse(r.Context(), noticeID)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	writeRawDescription(w, raw)
+}
+
+// writeRawDescription writes the stored body, or 404 when nothing was stored (inline/none sources, never fetched)
+// SAM occasionally returns non-JSON bodies; those are served as text rather than mislabeled
+func writeRawDescription(w http.ResponseWriter, raw *string) {
+	if raw == nil {
+		WriteError(w, http.StatusNotFound, ErrCodeNotFound, "no raw SAM response stored for this notice")
+		return
+	}
+	if json.Valid([]byte(*raw)) {
+		w.Header().Set("Content-Type", "application/json")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(*raw))
+}
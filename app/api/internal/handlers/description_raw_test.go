@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteRawDescription_URLSourced(t *testing.T) {
+	raw := `{"description":"<p>Scope of work</p>"}`
+	rec := httptest.NewRecorder()
+
+	writeRawDescription(rec, &raw)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected Content-Type %q, got %q", "application/json", ct)
+	}
+	if rec.Body.String() != raw {
+		t.Errorf("Expected body %q, got %q", raw, rec.Body.String())
+	}
+}
+
+func TestWriteRawDescription_InlineSourced(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	// Inline descriptions never store a SAM response
+	writeRawDescription(rec, nil)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+	if resp := decodeErrorResponse(t, rec); resp.Code != ErrCodeNotFound {
+		t.Errorf("Expected code %q, got %q", ErrCodeNotFound, resp.Code)
+	}
+}
+
+func TestWriteRawDescription_NonJSONBody(t *testing.T) {
+	raw := "Description not found"
+	rec := httptest.NewRecorder()
+
+	writeRawDescription(rec, &raw)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Expected text/plain for a non-JSON body, got %q", ct)
+	}
+}
+
+func TestHandleGetDescriptionRaw_MethodNotAllowed(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	rec := httptest.NewRecorder()
+
+	h.HandleGetDescriptionRaw(rec, httptest.NewRequest(http.MethodPost, "/opportunities/N1/description/raw.json", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
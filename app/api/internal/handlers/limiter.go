@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+)
+
+const (
+	defaultDescFetchConcurrency = 4 // Default max concurrent on-demand SAM description fetches
+	defaultDescHealConcurrency  = 2 // Default max concurrent self-heal reprocessing runs
+	fetchRetryAfterSeconds      = 2 // Retry-After sent when all fetch slots are busy
+)
+
+// fetchLimiter is a non-blocking semaphore capping concurrent SAM description fetches
+// The advisory lock stops duplicate fetches of one notice; this caps distinct fetches overall.
+// A second one caps self-heal reprocessing, which is CPU-bound rather than quota-bound.
+type fetchLimiter struct {
+	slots chan struct{}
+}
+
+func newFetchLimiter(size int) *fetchLimiter {
+	if size <= 0 {
+		size = defaultDescFetchConcurrency
+	}
+	return &fetchLimiter{slots: make(chan struct{}, size)}
+}
+
+// TryAcquire takes a slot if one is free; it never blocks
+func (l *fetchLimiter) TryAcquire() bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release frees a slot taken by TryAcquire
+func (l *fetchLimiter) Release() {
+	<-l.slots
+}
+
+// TryRun runs fn holding a slot, or returns false without running it when every slot is taken
+func (l *fetchLimiter) TryRun(fn func()) bool {
+	if !l.TryAcquire() {
+		return false
+	}
+	defer l.Release()
+	fn()
+	return true
+}
+
+// getDescFetchConcurrency returns the fetch concurrency limit (from env or default)
+func getDescFetchConcurrency() int {
+	if sizeStr := os.Getenv("DESC_FETCH_CONCURRENCY"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size > 0 {
+			return size
+		}
+	}
+	return defaultDescFetchConcurrency
+}
+
+// getDescHealConcurrency returns the self-heal reprocessing limit (DESC_HEAL_CONCURRENCY or default)
+func getDescHealConcurrency() int {
+	if sizeStr := os.Getenv("DESC_HEAL_CONCURRENCY"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size > 0 {
+			return size
+		}
+	}
+	return defaultDescHealConcurrency
+}
+
+// writeFetchSaturated tells the client to come back shortly instead of queueing behind other fetches
+func writeFetchSaturated(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(fetchRetryAfterSeconds))
+	WriteError(w, http.StatusServiceUnavailable, ErrCodeServiceUnavailable, "too many description fetches in progress, retry shortly")
+}
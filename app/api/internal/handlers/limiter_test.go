@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"govcon/api/internal/services"
+)
+
+func TestFetchLimiter_RejectsNPlusOne(t *testing.T) {
+	const n = 3
+	l := newFetchLimiter(n)
+
+	// Hold n slots from concurrent "fetches"
+	var wg sync.WaitGroup
+	acquired := make(chan bool, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			acquired <- l.TryAcquire()
+		}()
+	}
+	wg.Wait()
+	close(acquired)
+	for ok := range acquired {
+		if !ok {
+			t.Fatal("Expected the first n fetches to acquire a slot")
+		}
+	}
+
+	if l.TryAcquire() {
+		t.Error("Expected fetch n+1 to be rejected")
+	}
+
+	// Once a fetch finishes, the next one gets in
+	l.Release()
+	if !l.TryAcquire() {
+		t.Error("Expected a slot after release")
+	}
+}
+
+func TestHealLimiter_BoundsConcurrentReprocessing(t *testing.T) {
+	const n, requests = 2, 8
+	l := newFetchLimiter(n)
+
+	// Every request tries to heal while the first n are still reprocessing
+	gate := make(chan struct{})
+	var active, peak, ran atomic.Int32
+	var attempted, done sync.WaitGroup
+	attempted.Add(requests)
+	done.Add(requests)
+	for i := 0; i < requests; i++ {
+		go func() {
+			defer done.Done()
+			healed := l.TryRun(func() {
+				cur := active.Add(1)
+				for {
+					old := peak.Load()
+					if cur <= old || peak.CompareAndSwap(old, cur) {
+						break
+					}
+				}
+				ran.Add(1)
+				attempted.Done()
+				<-gate
+				active.Add(-1)
+			})
+			if !healed {
+				attempted.Done()
+			}
+		}()
+	}
+	attempted.Wait()
+	close(gate)
+	done.Wait()
+
+	if got := peak.Load(); got > n {
+		t.Errorf("Expected at most %d concurrent reprocessing runs, got %d", n, got)
+	}
+	if got := ran.Load(); got != n {
+		t.Errorf("Expected %d requests to reprocess and the rest to serve stored text, got %d", n, got)
+	}
+
+	// Slots are returned once reprocessing finishes
+	if !l.TryRun(func() {}) {
+		t.Error("Expected a free slot after the burst")
+	}
+}
+
+func TestFetchDescriptionLimited_RejectsNPlusOneAndFreesSlots(t *testing.T) {
+	const n = 2
+	// SAM holds every fetch open until the gate closes
+	gate := make(chan struct{})
+	arrived := make(chan struct{}, n+1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-gate
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("Furnish replacement valves."))
+	}))
+	defer srv.Close()
+	h := &OpportunitiesHandler{descService: services.NewDescriptionService(), fetchLimiter: newFetchLimiter(n)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := h.fetchDescriptionLimited(httptest.NewRecorder(), srv.URL); !ok {
+				t.Error("Expected the first n fetches to get a slot")
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		<-arrived
+	}
+
+	rec := httptest.NewRecorder()
+	if _, ok := h.fetchDescriptionLimited(rec, srv.URL); ok {
+		t.Error("Expected fetch n+1 to be rejected")
+	}
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+
+	// Finished fetches give their slots back
+	close(gate)
+	wg.Wait()
+	fetch, ok := h.fetchDescriptionLimited(httptest.NewRecorder(), srv.URL)
+	if !ok {
+		t.Fatal("Expected a slot once the fetches finished")
+	}
+	if fetch.Err != nil || fetch.HTTPStatus != http.StatusOK {
+		t.Errorf("Expected a successful fetch, got status %d err %v", fetch.HTTPStatus, fetch.Err)
+	}
+	for i := 0; i < n; i++ {
+		if !h.fetchLimiter.TryAcquire() {
+			t.Fatalf("Expected all %d slots free, only %d were", n, i)
+		}
+	}
+}
+
+func TestWriteFetchSaturated(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeFetchSaturated(rec)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got == "" {
+		t.Error("Expected Retry-After header")
+	}
+	resp := decodeErrorResponse(t, rec)
+	if resp.Code != ErrCodeServiceUnavailable {
+		t.Errorf("Expected code %q, got %q", ErrCodeServiceUnavailable, resp.Code)
+	}
+}
+
+func TestGetDescFetchConcurrency(t *testing.T) {
+	t.Setenv("DESC_FETCH_CONCURRENCY", "")
+	if got := getDescFetchConcurrency(); got != defaultDescFetchConcurrency {
+		t.Errorf("Expected default %d, got %d", defaultDescFetchConcurrency, got)
+	}
+	t.Setenv("DESC_FETCH_CONCURRENCY", "10")
+	if got := getDescFetchConcurrency(); got != 10 {
+		t.Errorf("Expected 10, got %d", got)
+	}
+}
+
+func TestGetDescHealConcurrency(t *testing.T) {
+	t.Setenv("DESC_HEAL_CONCURRENCY", "")
+	if got := getDescHealConcurrency(); got != defaultDescHealConcurrency {
+		t.Errorf("Expected default %d, got %d", defaultDescHealConcurrency, got)
+	}
+	t.Setenv("DESC_HEAL_CONCURRENCY", "6")
+	if got := getDescHealConcurrency(); got != 6 {
+		t.Errorf("Expected 6, got %d", got)
+	}
+	t.Setenv("DESC_HEAL_CONCURRENCY", "-1")
+	if got := getDescHealConcurrency(); got != defaultDescHealConcurrency {
+		t.Errorf("Expected default for invalid value, got %d", got)
+	}
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"govcon/api/internal/services"
+)
+
+// HandleMetrics handles GET /metrics
+// Exposes in-process counters (description fetches, stream events) in Prometheus text format
+func HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+	_ = services.DefaultFetchMetrics.WritePrometheus(w)
+	_ = services.DefaultEventMetrics.WritePrometheus(w)
+}
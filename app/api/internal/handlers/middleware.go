@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+)
+
+// defaultMaxRequestBodyBytes caps request bodies when MAX_REQUEST_BODY_BYTES is not set
+const defaultMaxRequestBodyBytes = 8 * 1024 * 1024 // 8MB
+
+// getMaxRequestBodyBytes returns the request body cap (from env or default)
+func getMaxRequestBodyBytes() int64 {
+	if maxStr := os.Getenv("MAX_REQUEST_BODY_BYTES"); maxStr != "" {
+		if max, err := strconv.ParseInt(maxStr, 10, 64); err == nil && max > 0 {
+			return max
+		}
+	}
+	return defaultMaxRequestBodyBytes
+}
+
+// MaxBytes wraps every request body in http.MaxBytesReader
+// Requests that declare a Content-Length over the limit are rejected with 413 up front;
+// bodies without a length fail on read, which handlers surface as 413 via decodeJSONBody
+func MaxBytes(limit int64, next http.Handler) http.Handler {
+	if limit <= 0 {
+		limit = getMaxRequestBodyBytes()
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			WriteError(w, http.StatusRequestEntityTooLarge, ErrCodePayloadTooLarge, "request body too large")
+			return
+		}
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// headerTracker records whether the wrapped handler has already started its response
+type headerTracker struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (t *headerTracker) WriteHeader(status int) {
+	t.wroteHeader = true
+	t.ResponseWriter.WriteHeader(status)
+}
+
+func (t *headerTracker) Write(b []byte) (int, error) {
+	t.wroteHeader = true
+	return t.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming handlers (SSE) working behind Recover
+func (t *headerTracker) Flush() {
+	if f, ok := t.ResponseWriter.(http.Flusher); ok {
+		t.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (t *headerTracker) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
+
+// Recover turns a panic in next into a logged 500 so one bad request can't take down the server
+// The panic is logged with its stack and the request ID (X-Request-ID response header, else request header).
+// If the handler already started writing, the response is left as-is; http.ErrAbortHandler is re-panicked
+// so net/http can abort the connection as intended.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracker := &headerTracker{ResponseWriter: w}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			requestID := w.Header().Get("X-Request-ID")
+			if requestID == "" {
+				requestID = r.Header.Get("X-Request-ID")
+			}
+			log.Printf("❌ Panic serving %s %s (request %q): %v\n%s", r.Method, r.URL.Path, requestID, rec, debug.Stack())
+			if !tracker.wroteHeader {
+				WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "internal server error")
+			}
+		}()
+		next.ServeHTTP(tracker, r)
+	})
+}
+
+// maxRequestIDLength caps a client-supplied X-Request-ID; longer ones are replaced
+const maxRequestIDLength = 128
+
+// RequestID gives every request an ID, set as the X-Request-ID response header so WriteError includes it in
+// the error envelope and Recover logs it. A client's own X-Request-ID is passed through when it is printable
+// ASCII without spaces and at most 128 characters; otherwise a random one is generated.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validRequestID reports whether a client-supplied request ID is safe to echo and log
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns 16 random bytes, hex encoded
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBytes_RejectsOversizedContentLength(t *testing.T) {
+	called := false
+	h := MaxBytes(16, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/describe/preview", strings.NewReader(strings.Repeat("x", 64)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+	if called {
+		t.Error("Expected handler not to be called")
+	}
+	resp := decodeErrorResponse(t, rec)
+	if resp.Code != ErrCodePayloadTooLarge {
+		t.Errorf("Expected code %q, got %q", ErrCodePayloadTooLarge, resp.Code)
+	}
+}
+
+func TestMaxBytes_RejectsOversizedChunkedBody(t *testing.T) {
+	h := MaxBytes(32, http.HandlerFunc((&OpportunitiesHandler{}).HandleDescribePreview))
+
+	body := `{"rawText":"` + strings.Repeat("x", 256) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/describe/preview", io.NopCloser(strings.NewReader(body)))
+	req.ContentLength = -1 // Unknown length, as with chunked encoding
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status %d, got %d", http.StatusRequestEntityTooLarge, rec.Code)
+	}
+}
+
+func TestMaxBytes_AllowsBodyWithinLimit(t *testing.T) {
+	h := MaxBytes(1024, http.HandlerFunc((&OpportunitiesHandler{}).HandleDescribePreview))
+
+	req := httptest.NewRequest(http.MethodPost, "/describe/preview", strings.NewReader(`{"rawText":"Hello world"}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+}
+
+func TestRecover_PanicReturns500AndServerStaysUp(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		var desc *struct{ Text string }
+		_ = desc.Text // nil pointer dereference
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, map[string]any{"ok": true})
+	})
+	srv := httptest.NewServer(Recover(mux))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/panic", nil)
+	req.Header.Set("X-Request-ID", "req-panic")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var errResp ErrorResponse
+	json.NewDecoder(resp.Body).Decode(&errResp)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+	if errResp.Code != ErrCodeInternal {
+		t.Errorf("Expected code %q, got %q", ErrCodeInternal, errResp.Code)
+	}
+
+	// The server keeps serving after the panic
+	resp, err = http.Get(srv.URL + "/ok")
+	if err != nil {
+		t.Fatalf("Expected server to stay up, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestRecover_PanicAfterWriteKeepsResponse(t *testing.T) {
+	h := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("late failure")
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status %d, got %d", http.StatusAccepted, rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected no error body after headers were written, got %q", rec.Body.String())
+	}
+}
+
+func TestRequestID_PassesThroughClientID(t *testing.T) {
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, http.StatusNotFound, ErrCodeNotFound, "opportunity not found")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/N1", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("X-Request-ID"); got != "req-123" {
+		t.Errorf("Expected X-Request-ID %q, got %q", "req-123", got)
+	}
+	if resp := decodeErrorResponse(t, rec); resp.RequestID != "req-123" {
+		t.Errorf("Expected requestId %q in the error envelope, got %q", "req-123", resp.RequestID)
+	}
+}
+
+func TestRequestID_GeneratesMissingOrInvalidID(t *testing.T) {
+	h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "bad")
+	}))
+	seen := map[string]bool{}
+	for _, clientID := range []string{"", "has space", "line\nbreak", strings.Repeat("x", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if clientID != "" {
+			req.Header.Set("X-Request-ID", clientID)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		got := rec.Header().Get("X-Request-ID")
+		if len(got) != 32 || got == clientID {
+			t.Errorf("%q: Expected a generated 32-character ID, got %q", clientID, got)
+		}
+		if seen[got] {
+			t.Errorf("%q: Expected a fresh ID, got repeated %q", clientID, got)
+		}
+		seen[got] = true
+		if resp := decodeErrorResponse(t, rec); resp.RequestID != got {
+			t.Errorf("%q: Expected requestId %q in the error envelope, got %q", clientID, got, resp.RequestID)
+		}
+	}
+}
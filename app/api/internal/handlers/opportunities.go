@@ -1,16 +1,18 @@
 package handlers
 
 import (
-	"crypto/sha256"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/db"
 	"govcon/api/internal/models"
 	"govcon/api/internal/repositories"
 	"govcon/api/internal/services"
@@ -19,24 +21,34 @@ import (
 type OpportunitiesHandler struct {
 	repo            *repositories.OpportunityRepository
 	descRepo        *repositories.DescriptionRepository
+	outcomeRepo     *repositories.OutcomeRepository
+	tagRepo         *repositories.TagRepository
 	descService     *services.DescriptionService
 	samService      *services.SAMService
 	db              *pgxpool.Pool
+	fetchLimiter    *fetchLimiter // Caps concurrent on-demand SAM description fetches
+	healLimiter     *fetchLimiter // Caps concurrent self-heal reprocessing
+	healTracker     *healTracker  // Cooldown for self-heals that failed to persist
 }
 
-func NewOpportunitiesHandler(repo *repositories.OpportunityRepository, descRepo *repositories.DescriptionRepository, descService *services.DescriptionService, samService *services.SAMService, db *pgxpool.Pool) *OpportunitiesHandler {
+func NewOpportunitiesHandler(repo *repositories.OpportunityRepository, descRepo *repositories.DescriptionRepository, outcomeRepo *repositories.OutcomeRepository, tagRepo *repositories.TagRepository, descService *services.DescriptionService, samService *services.SAMService, db *pgxpool.Pool) *OpportunitiesHandler {
 	return &OpportunitiesHandler{
 		repo:        repo,
 		descRepo:    descRepo,
+		outcomeRepo: outcomeRepo,
+		tagRepo:     tagRepo,
 		descService: descService,
 		samService:  samService,
 		db:          db,
+		fetchLimiter: newFetchLimiter(getDescFetchConcurrency()),
+		healLimiter:  newFetchLimiter(getDescHealConcurrency()),
+		healTracker:  newHealTracker(getHealCooldown()),
 	}
 }
 
 func (h *OpportunitiesHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		WriteJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -45,6 +57,11 @@ func (h *OpportunitiesHandler) HandleSearch(w http.ResponseWriter, r *http.Reque
 	postedTo := r.URL.Query().Get("postedTo")
 	searchText := r.URL.Query().Get("search")
 
+	// Bound unfiltered searches to the default window unless all=true
+	if defaultFrom := defaultPostedFrom(r.URL.Query(), "postedFrom", "postedTo"); defaultFrom != "" {
+		postedFrom = defaultFrom
+	}
+
 	limit := 10
 	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
 		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
@@ -83,9 +100,7 @@ func (h *OpportunitiesHandler) HandleSearch(w http.ResponseWriter, r *http.Reque
 	// Query repository
 	result, err := h.repo.SearchOpportunities(r.Context(), params)
 	if err != nil {
-		WriteJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": err.Error(),
-		})
+		WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
@@ -95,6 +110,22 @@ func (h *OpportunitiesHandler) HandleSearch(w http.ResponseWriter, r *http.Reque
 		items = []models.Opportunity{}
 	}
 
+	// Populate description status with one bulk lookup (the legacy query has no description join)
+	if len(items) > 0 {
+		noticeIDs := make([]string, len(items))
+		for i := range items {
+			noticeIDs[i] = items[i].NoticeID
+		}
+		statuses, err := h.descRepo.GetDescriptionStatuses(r.Context(), noticeIDs)
+		if err != nil {
+			log.Printf("Failed to look up description statuses: %v", err)
+		} else {
+			for i := range items {
+				items[i].DescriptionStatus = statuses[items[i].NoticeID]
+			}
+		}
+	}
+
 	// Return response with pagination metadata
 	response := map[string]interface{}{
 		"items":        items,
@@ -107,26 +138,214 @@ func (h *OpportunitiesHandler) HandleSearch(w http.ResponseWriter, r *http.Reque
 	WriteJSON(w, http.StatusOK, response)
 }
 
+// parseSearchFiltersV2 reads the V2 search filters shared by every endpoint that narrows opportunities the
+// way search does: text, NAICS, set-aside, location, agency, classification, dates, description status, tag,
+// archive status and notice types. Unfiltered requests get the default posted window unless all=true.
+// Errors describe the offending parameter and are meant for a 400 response.
+func parseSearchFiltersV2(query url.Values) (repositories.SearchParamsV2, error) {
+	params := repositories.SearchParamsV2{
+		Q:          query.Get("q"),
+		NAICS:      query.Get("naics"),
+		SetAside:   query.Get("setAside"),
+		State:      query.Get("state"),
+		Agency:     query.Get("agency"),
+		Classification: strings.ToUpper(strings.TrimSpace(query.Get("classification"))),
+		PostedFrom: query.Get("postedFrom"),
+		PostedTo:     query.Get("postedTo"),
+		DueFrom:    query.Get("dueFrom"),
+		DueTo:      query.Get("dueTo"),
+		DescriptionStatus: query.Get("descriptionStatus"),
+		Tag:        normalizeTag(query.Get("tag")),
+	}
+
+	// Validate date params up front - an unparseable date would otherwise silently drop the filter
+	dateParams := []struct{ name, value string }{
+		{"postedFrom", params.PostedFrom},
+		{"postedTo", params.PostedTo},
+		{"dueFrom", params.DueFrom},
+		{"dueTo", params.DueTo},
+	}
+	for _, p := range dateParams {
+		if err := repositories.ValidateDateParam(p.name, p.value); err != nil {
+			return params, err
+		}
+	}
+
+	// Notice types in EXCLUDED_NOTICE_TYPES are left out unless includeAllTypes=true
+	var includeAllTypes bool
+
+	// Archive status and filter flags
+	for _, flag := range []struct {
+		name   string
+		target *bool
+	}{
+		{"includeArchived", &params.IncludeArchived},
+		{"archivedOnly", &params.ArchivedOnly},
+		{"classificationPrefix", &params.ClassificationPrefix},
+		{"includeAllTypes", &includeAllTypes},
+	} {
+		if value := query.Get(flag.name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return params, fmt.Errorf("invalid %s %q: expected true or false", flag.name, value)
+			}
+			*flag.target = parsed
+		}
+	}
+	if !includeAllTypes {
+		params.ExcludeTypes = models.ExcludedNoticeTypes()
+	}
+
+	// Notice type (this notice's category, by name) and procurement type (SAM ptype code) are separate filters
+	params.NoticeTypes = models.ParseNoticeTypeList(query.Get("noticeType"))
+	params.ProcurementTypes = models.ParseNoticeTypeList(query.Get("ptype"))
+	for _, code := range params.ProcurementTypes {
+		if !models.IsValidProcurementType(code) {
+			return params, fmt.Errorf("invalid ptype %q: expected u, p, a, r, s, o, g, k, or i", code)
+		}
+	}
+
+	// Attachments (SAM resourceLinks) - true keeps opportunities with any, false those with none
+	if value := query.Get("hasAttachments"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return params, fmt.Errorf("invalid hasAttachments %q: expected true or false", value)
+		}
+		params.HasAttachments = &parsed
+	}
+
+	// Bound unfiltered searches to the default window unless all=true
+	if defaultFrom := defaultPostedFrom(query, "postedFrom", "postedTo", "dueFrom", "dueTo"); defaultFrom != "" {
+		params.PostedFrom = defaultFrom
+	}
+
+	if params.DescriptionStatus != "" && !repositories.IsValidDescriptionStatus(params.DescriptionStatus) {
+		return params, fmt.Errorf("invalid descriptionStatus %q: expected none, ready, not_found, error, or available_unfetched", params.DescriptionStatus)
+	}
+
+	// Keyword search scope - comma-separated fields; omitted searches all of them
+	if value := query.Get("searchFields"); value != "" {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			if !repositories.IsValidSearchField(field) {
+				return params, fmt.Errorf("invalid searchFields %q: expected title, solicitationNumber, agency, or description", field)
+			}
+			params.SearchFields = append(params.SearchFields, field)
+		}
+	}
+	return params, nil
+}
+
+// fetchDescriptionLimited fetches sourceURL from SAM holding one of the fetchLimiter slots, released however the
+// fetch ends. When every slot is taken it writes a 503 and returns false without fetching.
+func (h *OpportunitiesHandler) fetchDescriptionLimited(w http.ResponseWriter, sourceURL string) (services.DescriptionFetch, bool) {
+	if !h.fetchLimiter.TryAcquire() {
+		writeFetchSaturated(w)
+		return services.DescriptionFetch{}, false
+	}
+	defer h.fetchLimiter.Release()
+
+	rawText, rawJsonResponse, httpStatus, contentType, err := h.descService.FetchDescriptionWithKey(sourceURL)
+	return services.DescriptionFetch{
+		RawText:     rawText,
+		RawJSON:     rawJsonResponse,
+		HTTPStatus:  httpStatus,
+		ContentType: contentType,
+		Err:         err,
+	}, true
+}
+
+// parseSeenSince parses the seenSince search param, an RFC3339 timestamp. An unescaped "+" in the offset
+// arrives as a space after query decoding, so "2026-01-05T09:00:00 05:00" is read as "+05:00".
+func parseSeenSince(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	seenSince, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		seenSince, err = time.Parse(time.RFC3339, strings.Replace(value, " ", "+", 1))
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid seenSince %q: expected an RFC3339 timestamp, e.g. 2026-01-05T09:00:00Z", value)
+	}
+	return seenSince, nil
+}
+
 // HandleSearchV2 handles the new search endpoint with keyset pagination
 func (h *OpportunitiesHandler) HandleSearchV2(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		WriteJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
 		return
 	}
 
-	// Parse query parameters
-	params := repositories.SearchParamsV2{
-		Q:          r.URL.Query().Get("q"),
-		NAICS:      r.URL.Query().Get("naics"),
-		SetAside:   r.URL.Query().Get("setAside"),
-		State:      r.URL.Query().Get("state"),
-		Agency:     r.URL.Query().Get("agency"),
-		PostedFrom: r.URL.Query().Get("postedFrom"),
-		PostedTo:     r.URL.Query().Get("postedTo"),
-		DueFrom:    r.URL.Query().Get("dueFrom"),
-		DueTo:      r.URL.Query().Get("dueTo"),
-		Sort:       r.URL.Query().Get("sort"),
-		Cursor:     r.URL.Query().Get("cursor"),
+	params, err := parseSearchFiltersV2(r.URL.Query())
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+	params.Sort = r.URL.Query().Get("sort")
+	params.Cursor = r.URL.Query().Get("cursor")
+
+	// Relevance recency boost defaults from config; the recencyBoost param overrides it
+	params.RecencyBoost = getSearchRecencyBoost()
+
+	// Search-only flags
+	for _, flag := range []struct {
+		name   string
+		target *bool
+	}{
+		{"recencyBoost", &params.RecencyBoost},
+		{"explain", &params.Explain},
+		{"explainRelevance", &params.ExplainRelevance},
+		{"highlight", &params.Highlight},
+	} {
+		if value := r.URL.Query().Get(flag.name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("invalid %s %q: expected true or false", flag.name, value))
+				return
+			}
+			*flag.target = parsed
+		}
+	}
+
+	if params.Explain && !getSearchExplainEnabled() {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "explain is disabled; set SEARCH_EXPLAIN=true to enable it")
+		return
+	}
+
+	// Snippet markup defaults from config; the highlightMarkup param overrides it
+	params.HighlightMarkup = getSearchHighlightMarkup()
+	if markup := r.URL.Query().Get("highlightMarkup"); markup != "" {
+		if !repositories.IsValidHighlightMarkup(markup) {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("invalid highlightMarkup %q: expected html or markdown", markup))
+			return
+		}
+		params.HighlightMarkup = markup
+	}
+
+	// Freshness badges relative to the caller's last visit
+	if value := r.URL.Query().Get("seenSince"); value != "" {
+		seenSince, err := parseSeenSince(value)
+		if err != nil {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+			return
+		}
+		params.SeenSince = &seenSince
+	}
+
+	// Scores only exist for ranked searches
+	if params.ExplainRelevance && (params.Sort != "relevance" || strings.TrimSpace(params.Q) == "") {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "explainRelevance requires sort=relevance and q")
+		return
+	}
+
+	// Optional facet counts alongside the results
+	facets := r.URL.Query().Get("facets")
+	if facets != "" && facets != "classification" {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("invalid facets %q: expected classification", facets))
+		return
 	}
 
 	// Parse limit with defaults
@@ -142,16 +361,16 @@ func (h *OpportunitiesHandler) HandleSearchV2(w http.ResponseWriter, r *http.Req
 	result, err := h.repo.SearchOpportunitiesV2(r.Context(), params)
 	if err != nil {
 		statusCode := http.StatusInternalServerError
+		errorCode := ErrCodeInternal
 		errorMsg := err.Error()
 		
 		// If it's a migration error, return 503 (Service Unavailable) with helpful message
 		if strings.Contains(errorMsg, "database migration required") {
 			statusCode = http.StatusServiceUnavailable
+			errorCode = ErrCodeMigrationRequired
 		}
 		
-		WriteJSON(w, statusCode, map[string]string{
-			"error": errorMsg,
-		})
+		WriteError(w, statusCode, errorCode, errorMsg)
 		return
 	}
 
@@ -167,6 +386,15 @@ func (h *OpportunitiesHandler) HandleSearchV2(w http.ResponseWriter, r *http.Req
 		"nextCursor": result.NextCursor,
 	}
 
+	if facets == "classification" {
+		classificationFacets, err := h.repo.ClassificationFacets(r.Context(), params, 0)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+			return
+		}
+		response["facets"] = map[string]interface{}{"classification": classificationFacets}
+	}
+
 	// Include debug info in dev (check if we're in dev mode - for now always include)
 	response["debug"] = result.Debug
 
@@ -176,7 +404,7 @@ func (h *OpportunitiesHandler) HandleSearchV2(w http.ResponseWriter, r *http.Req
 // HandleGetOpportunity handles GET /opportunities/:noticeId
 func (h *OpportunitiesHandler) HandleGetOpportunity(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		WriteJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -185,26 +413,62 @@ func (h *OpportunitiesHandler) HandleGetOpportunity(w http.ResponseWriter, r *ht
 	path := r.URL.Path
 	noticeID := strings.TrimPrefix(path, "/opportunities/")
 	if noticeID == "" || noticeID == path {
-		WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "noticeId is required"})
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "noticeId is required")
 		return
 	}
 
+	// Optional extras: include=provenance marks which enrichable fields are SAM's and which we derived
+	var includeProvenance bool
+	if include := r.URL.Query().Get("include"); include != "" {
+		for _, part := range strings.Split(include, ",") {
+			switch strings.TrimSpace(part) {
+			case "provenance":
+				includeProvenance = true
+			default:
+				WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, fmt.Sprintf("invalid include %q: expected provenance", part))
+				return
+			}
+		}
+	}
+
 	// Query repository
 	opportunity, err := h.repo.GetOpportunityByNoticeID(r.Context(), noticeID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		WriteError(w, http.StatusNotFound, ErrCodeNotFound, "opportunity not found")
+		return
+	}
 	if err != nil {
-		WriteJSON(w, http.StatusNotFound, map[string]string{
-			"error": "opportunity not found",
-		})
+		WriteError(w, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("failed to get opportunity: %v", err))
 		return
 	}
 
+	// Include the recorded outcome, if any
+	outcome, err := h.outcomeRepo.GetOutcome(r.Context(), noticeID)
+	if err != nil {
+		log.Printf("Failed to get outcome for noticeId=%s: %v", noticeID, err)
+	} else {
+		opportunity.Outcome = outcome
+	}
+
+	// Include tags
+	tags, err := h.tagRepo.ListTags(r.Context(), noticeID)
+	if err != nil {
+		log.Printf("Failed to get tags for noticeId=%s: %v", noticeID, err)
+	} else {
+		opportunity.Tags = tags
+	}
+
+	if !includeProvenance {
+		opportunity.Provenance = nil
+	}
+
 	WriteJSON(w, http.StatusOK, opportunity)
 }
 
 // HandleGetDescription handles GET /opportunities/:noticeId/description?refresh=false
 func (h *OpportunitiesHandler) HandleGetDescription(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		WriteJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -216,19 +480,22 @@ func (h *OpportunitiesHandler) HandleGetDescription(w http.ResponseWriter, r *ht
 	noticeID := strings.Trim(path, "/")
 	
 	if noticeID == "" {
-		WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "noticeId is required"})
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "noticeId is required")
 		return
 	}
 
 	ctx := r.Context()
 	refresh := r.URL.Query().Get("refresh") == "true"
+	maxAge := getDescMaxAge()
 
 	// Get opportunity to check description source
 	opportunity, err := h.repo.GetOpportunityByNoticeID(ctx, noticeID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		WriteError(w, http.StatusNotFound, ErrCodeNotFound, "opportunity not found")
+		return
+	}
 	if err != nil {
-		WriteJSON(w, http.StatusNotFound, map[string]string{
-			"error": "opportunity not found",
-		})
+		WriteError(w, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("failed to get opportunity: %v", err))
 		return
 	}
 
@@ -237,131 +504,62 @@ func (h *OpportunitiesHandler) HandleGetDescription(w http.ResponseWriter, r *ht
 
 	// Get existing description if any
 	existingDesc, err := h.descRepo.GetDescription(ctx, noticeID)
-	if err != nil && !strings.Contains(err.Error(), "not found") {
-		WriteJSON(w, http.StatusInternalServerError, map[string]string{
-			"error": fmt.Sprintf("failed to get description: %v", err),
-		})
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		WriteError(w, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("failed to get description: %v", err))
 		return
 	}
 
+	// Past DESC_MAX_AGE a fetched description is re-validated against SAM, as if refresh=true
+	stale := !refresh && existingDesc != nil && existingDesc.FetchStatus == models.FetchStatusFetched && descriptionStale(existingDesc, maxAge, time.Now())
+	if stale {
+		log.Printf("Description older than DESC_MAX_AGE (%s) for noticeId=%s, re-fetching", maxAge, noticeID)
+	}
+
 	// If we have a cached description and not refreshing, check and self-heal if needed
-	if existingDesc != nil && existingDesc.FetchStatus == models.FetchStatusFetched && !refresh {
-		currentNormalizationVersion := services.NORMALIZATION_VERSION
-		needsReprocessing := false
-		var sourceText string
-		
-		// Check normalization version - if mismatch, re-process from raw JSON or raw text
-		if existingDesc.NormalizationVersion == nil || *existingDesc.NormalizationVersion != currentNormalizationVersion {
-			needsReprocessing = true
-			log.Printf("Description version mismatch: noticeId=%s, stored version=%v, current version=%d, re-processing", 
-				noticeID, existingDesc.NormalizationVersion, currentNormalizationVersion)
-			
-			// Prefer raw_json_response if available, fall back to raw_text
-			if existingDesc.RawJsonResponse != nil && *existingDesc.RawJsonResponse != "" {
-				// Parse JSON to extract description
-				var jsonResponse map[string]interface{}
-				if err := json.Unmarshal([]byte(*existingDesc.RawJsonResponse), &jsonResponse); err == nil {
-					if descValue, ok := jsonResponse["description"]; ok {
-						if desc, ok := descValue.(string); ok && desc != "" {
-							sourceText = desc
-						}
-					}
-				}
-				// If JSON parsing failed or no description field, use raw JSON as-is
-				if sourceText == "" {
-					sourceText = *existingDesc.RawJsonResponse
-				}
-			} else if existingDesc.RawText != nil {
-				sourceText = *existingDesc.RawText
-			}
-		} else if existingDesc.RawText != nil {
-			// Self-heal: unwrap JSON wrappers and strip HTML tags in cached descriptions
-			rawTextBefore := *existingDesc.RawText
-			
-			// Unwrap any JSON wrapper
-			fixedRaw := services.UnwrapDescriptionText(rawTextBefore)
-			
-			// Check if text contains HTML tags (need to re-normalize)
-			hasHTMLTags := strings.Contains(fixedRaw, "<") && strings.Contains(fixedRaw, ">")
-			
-			// Also check if normalized fields contain HTML tags (indicates old cached data)
-			hasHTMLInNormalized := false
-			if existingDesc.RawTextNormalized != nil {
-				hasHTMLInNormalized = strings.Contains(*existingDesc.RawTextNormalized, "<") && strings.Contains(*existingDesc.RawTextNormalized, ">")
-			}
-			if !hasHTMLInNormalized && existingDesc.TextNormalized != nil {
-				hasHTMLInNormalized = strings.Contains(*existingDesc.TextNormalized, "<") && strings.Contains(*existingDesc.TextNormalized, ">")
-			}
-			
-			// If unwrapping changed the text OR HTML tags are present, re-process all normalized fields
-			if fixedRaw != rawTextBefore || hasHTMLTags || hasHTMLInNormalized {
-				needsReprocessing = true
-				sourceText = fixedRaw
-				// Log when re-processing
-				if hasHTMLTags || hasHTMLInNormalized {
-					log.Printf("Description self-heal: HTML tags detected for noticeId=%s, re-processing normalized fields", noticeID)
-				} else {
-					log.Printf("Description self-heal: unwrapping changed text for noticeId=%s, re-processing normalized fields", noticeID)
-				}
-				log.Printf("  BEFORE: %q", previewText(&rawTextBefore, 120))
-				log.Printf("  AFTER unwrap:  %q", previewText(&fixedRaw, 120))
+	if existingDesc != nil && existingDesc.FetchStatus == models.FetchStatusFetched && !refresh && !stale {
+		// Decide from whatever the row has; any of its text fields may be nil on partially populated rows
+		sourceText, healReason := healSourceText(existingDesc)
+		needsReprocessing := healReason != healNone
+		switch healReason {
+		case healVersionMismatch:
+			log.Printf("Description version mismatch: noticeId=%s, stored version=%s, current version=%d, re-processing",
+				noticeID, previewVersion(existingDesc.NormalizationVersion), services.NORMALIZATION_VERSION)
+		case healMissingRawText:
+			log.Printf("Description self-heal: raw text missing for noticeId=%s, re-processing from raw JSON", noticeID)
+		case healAIVersion:
+			log.Printf("Description self-heal: AI input version mismatch: noticeId=%s, stored version=%s, current version=%d, regenerating AI fields",
+				noticeID, previewVersion(existingDesc.AIInputVersion), services.AI_INPUT_VERSION)
+		case healHTMLTags, healUnwrapped:
+			if healReason == healHTMLTags {
+				log.Printf("Description self-heal: HTML tags detected for noticeId=%s, re-processing normalized fields", noticeID)
+			} else {
+				log.Printf("Description self-heal: unwrapping changed text for noticeId=%s, re-processing normalized fields", noticeID)
 			}
+			log.Printf("  BEFORE: %q", previewText(existingDesc.RawText, 120))
+			log.Printf("  AFTER unwrap:  %q", previewText(&sourceText, 120))
 		}
 		
 		// Re-process if needed
 		if needsReprocessing && sourceText != "" {
-			// Unwrap description text
-			unwrappedText := services.UnwrapDescriptionText(sourceText)
-			
-			// Re-process normalized fields
-			rawTextNormalized := services.NormalizeRaw(unwrappedText)
-			textNormalized := services.Normalize(rawTextNormalized)
-			contentHash := services.ComputeContentHash(textNormalized)
-			
-			// Re-process AI-optimized fields
-			aiInputText, excerptText, aiMeta, pocEmailPrimary, err := services.OptimizeForAI(rawTextNormalized)
-			
-			// Update fetchedAt to indicate it was fixed
-			now := time.Now()
-			existingDesc.FetchedAt = &now
-			
-			// Update the description with fixed values
-			existingDesc.RawText = &unwrappedText
-			existingDesc.RawTextNormalized = &rawTextNormalized
-			existingDesc.TextNormalized = &textNormalized
-			existingDesc.ContentHash = &contentHash
-			existingDesc.NormalizationVersion = &currentNormalizationVersion
-			
-			// Set AI-optimized fields if optimization succeeded
-			if err == nil {
-				aiInputHash := services.ComputeContentHash(aiInputText)
-				aiInputVersion := 1
-				existingDesc.AIInputText = &aiInputText
-				existingDesc.AIInputHash = &aiInputHash
-				existingDesc.AIInputVersion = &aiInputVersion
-				existingDesc.AIGeneratedAt = &now
-				existingDesc.AIMeta = &aiMeta
-				existingDesc.ExcerptText = &excerptText
-				existingDesc.POCEmailPrimary = pocEmailPrimary
-			} else {
-				log.Printf("Description self-heal: failed to optimize for AI for noticeId=%s: %v", noticeID, err)
-				// If AI optimization fails, preserve existing AI fields or set defaults
-				// Other AI fields can remain as-is (they may be nil, which is fine)
-			}
-			
-			// Safety check: ensure ai_input_version is never nil before persisting (required NOT NULL constraint)
-			if existingDesc.AIInputVersion == nil {
-				aiInputVersion := 1
-				existingDesc.AIInputVersion = &aiInputVersion
-				log.Printf("Description self-heal: set default ai_input_version=1 for noticeId=%s", noticeID)
+			// A recent heal of this record failed to persist: serve the re-normalized text
+			// but skip AI optimization and persistence until the cooldown passes
+			if !h.healTracker.ShouldAttempt(noticeID) {
+				log.Printf("Description self-heal: noticeId=%s in cooldown after failed persist, skipping AI re-optimization", noticeID)
+				unwrappedText := services.UnwrapDescriptionText(sourceText)
+				rawTextNormalized := services.NormalizeRaw(unwrappedText)
+				textNormalized := services.Normalize(rawTextNormalized)
+				existingDesc.RawText = &unwrappedText
+				existingDesc.RawTextNormalized = &rawTextNormalized
+				existingDesc.TextNormalized = &textNormalized
+				response := buildDescriptionResponse(existingDesc)
+				WriteJSON(w, http.StatusOK, response)
+				return
 			}
 			
-			// Persist the fix so it's corrected next time
-			if err := h.descRepo.UpsertDescription(ctx, existingDesc); err != nil {
-				log.Printf("Description self-heal: failed to persist fix for noticeId=%s: %v", noticeID, err)
-				// Continue anyway - we'll return the fixed version even if persistence fails
-			} else {
-				log.Printf("Description self-heal: successfully persisted fix for noticeId=%s", noticeID)
+			// A burst of heals (e.g. right after a NORMALIZATION_VERSION bump) would reprocess on every
+			// request goroutine at once; past DESC_HEAL_CONCURRENCY, serve the stored text and heal on a later read
+			if !h.healLimiter.TryRun(func() { h.healDescription(ctx, noticeID, existingDesc, sourceText) }) {
+				log.Printf("Description self-heal: noticeId=%s skipped, all reprocessing slots busy; serving stored text", noticeID)
 			}
 		}
 		
@@ -384,19 +582,29 @@ func (h *OpportunitiesHandler) HandleGetDescription(w http.ResponseWriter, r *ht
 			UpdatedAt:    time.Now(),
 		}
 		h.descRepo.UpsertDescription(ctx, desc)
+		services.DefaultFetchMetrics.RecordFetchOutcome(desc.FetchStatus, desc.SourceType, 0)
+		response := buildDescriptionResponse(desc)
+		WriteJSON(w, http.StatusOK, response)
+		return
+
+	case models.SourceTypeAttachment:
+		// No description text, only attachments we don't extract: point at the first one rather than report none
+		desc = &models.OpportunityDescription{
+			NoticeID:    noticeID,
+			SourceType:  models.SourceTypeAttachment,
+			SourceURL:   &sourceURL,
+			FetchStatus: models.FetchStatusNotFound,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+		h.descRepo.UpsertDescription(ctx, desc)
+		services.DefaultFetchMetrics.RecordFetchOutcome(desc.FetchStatus, desc.SourceType, 0)
 		response := buildDescriptionResponse(desc)
 		WriteJSON(w, http.StatusOK, response)
 		return
 
 	case models.SourceTypeInline:
 		// Inline text - normalize and store immediately
-		rawText := sourceInline
-		rawText = services.UnwrapDescriptionText(rawText)
-		rawTextNormalized := services.NormalizeRaw(rawText)
-		textNormalized := services.Normalize(rawTextNormalized)
-		contentHash := services.ComputeContentHash(textNormalized)
-		currentNormalizationVersion := services.NORMALIZATION_VERSION
-
 		now := time.Now()
 		desc = &models.OpportunityDescription{
 			NoticeID:          noticeID,
@@ -404,30 +612,16 @@ func (h *OpportunitiesHandler) HandleGetDescription(w http.ResponseWriter, r *ht
 			SourceInline:      &sourceInline,
 			FetchStatus:       models.FetchStatusFetched,
 			FetchedAt:         &now,
-			RawText:           &rawText,
-			RawTextNormalized: &rawTextNormalized,
-			TextNormalized:    &textNormalized,
-			ContentHash:       &contentHash,
-			NormalizationVersion: &currentNormalizationVersion,
 			CreatedAt:         time.Now(),
 			UpdatedAt:         time.Now(),
 		}
 		
-		// Generate AI-optimized text (inline text is always fetched)
-		aiInputText, excerptText, aiMeta, pocEmailPrimary, err := services.OptimizeForAI(rawTextNormalized)
-		if err == nil {
-			aiInputHash := services.ComputeContentHash(aiInputText)
-			aiInputVersion := 1
-			desc.AIInputText = &aiInputText
-			desc.AIInputHash = &aiInputHash
-			desc.AIInputVersion = &aiInputVersion
-			desc.AIGeneratedAt = &now
-			desc.AIMeta = &aiMeta
-			desc.ExcerptText = &excerptText
-			desc.POCEmailPrimary = pocEmailPrimary
-		}
+		// Normalize, and generate AI-optimized text unless the stored copy (or, with DESC_DEDUP, another
+		// notice with identical content) already has it
+		services.ApplyNormalizationWithLookup(desc, sourceInline, existingDesc, now, h.aiFieldLookup(ctx, noticeID))
 		
 		h.descRepo.UpsertDescription(ctx, desc)
+		services.DefaultFetchMetrics.RecordFetchOutcome(desc.FetchStatus, desc.SourceType, 0)
 		response := buildDescriptionResponse(desc)
 		WriteJSON(w, http.StatusOK, response)
 		return
@@ -448,18 +642,27 @@ func (h *OpportunitiesHandler) HandleGetDescription(w http.ResponseWriter, r *ht
 		}
 
 		// Use advisory lock to prevent concurrent fetches
-		lockKey := computeAdvisoryLockKey(noticeID)
+		// Two-key form, so it can never collide with the ingest/backfill job locks
+		lockClass, lockID := db.DescriptionFetchLockKey(noticeID)
 		
+		// Lock and unlock on one pooled connection: advisory locks belong to the session, so an unlock
+		// sent through the pool could land on another connection and leave the lock held
+		lockConn, err := h.db.Acquire(ctx)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("failed to acquire lock: %v", err))
+			return
+		}
+
 		var lockAcquired bool
-		err := h.db.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", lockKey).Scan(&lockAcquired)
+		err = lockConn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1, $2)", lockClass, lockID).Scan(&lockAcquired)
 		if err != nil {
-			WriteJSON(w, http.StatusInternalServerError, map[string]string{
-				"error": fmt.Sprintf("failed to acquire lock: %v", err),
-			})
+			lockConn.Release()
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("failed to acquire lock: %v", err))
 			return
 		}
 
 		if !lockAcquired {
+			lockConn.Release()
 			// Another request is fetching, wait a bit and check again
 			time.Sleep(500 * time.Millisecond)
 			existingDesc, err := h.descRepo.GetDescription(ctx, noticeID)
@@ -468,95 +671,59 @@ func (h *OpportunitiesHandler) HandleGetDescription(w http.ResponseWriter, r *ht
 				WriteJSON(w, http.StatusOK, response)
 				return
 			}
-			WriteJSON(w, http.StatusServiceUnavailable, map[string]string{
-				"error": "description is being fetched by another request",
-			})
+			WriteError(w, http.StatusServiceUnavailable, ErrCodeFetchInProgress, "description is being fetched by another request")
 			return
 		}
 
-		// Ensure lock is released
+		// Ensure lock is released on the same session before the connection goes back to the pool
+		// Background context: a cancelled request must still unlock
 		defer func() {
-			h.db.Exec(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+			if _, err := lockConn.Exec(context.Background(), "SELECT pg_advisory_unlock($1, $2)", lockClass, lockID); err != nil {
+				// The session may still hold the lock; close it so the pool discards it instead of reusing it
+				log.Printf("Failed to release description fetch lock for %s: %v", noticeID, err)
+				lockConn.Conn().Close(context.Background())
+			}
+			lockConn.Release()
 		}()
 
-		// Check again after acquiring lock (another request might have finished)
+		// Check again after acquiring lock (another request might have finished, including a stale re-fetch)
 		if !refresh {
 			existingDesc, err := h.descRepo.GetDescription(ctx, noticeID)
-			if err == nil && existingDesc.FetchStatus == models.FetchStatusFetched {
+			if err == nil && existingDesc.FetchStatus == models.FetchStatusFetched && !descriptionStale(existingDesc, maxAge, time.Now()) {
 				response := buildDescriptionResponse(existingDesc)
 				WriteJSON(w, http.StatusOK, response)
 				return
 			}
 		}
 
-		// Fetch from SAM API
-		rawText, rawJsonResponse, httpStatus, contentType, err := h.descService.FetchDescriptionWithKey(sourceURL)
-
-		now := time.Now()
-		currentNormalizationVersion := services.NORMALIZATION_VERSION
-		desc = &models.OpportunityDescription{
-			NoticeID:    noticeID,
-			SourceType:   models.SourceTypeURL,
-			SourceURL:    &sourceURL,
-			HTTPStatus:   &httpStatus,
-			FetchedAt:    &now,
-			ContentType:  &contentType,
-			CreatedAt:    time.Now(),
-			UpdatedAt:    time.Now(),
+		// Fetch from SAM API, capped to protect the quota; reject rather than pile up
+		fetch, ok := h.fetchDescriptionLimited(w, sourceURL)
+		if !ok {
+			return
 		}
-
-		if err != nil {
-			// Fetch error
-			errorMsg := err.Error()
-			desc.FetchStatus = models.FetchStatusError
-			desc.LastError = &errorMsg
-		} else if httpStatus == http.StatusNotFound || strings.Contains(strings.ToLower(rawText), "description not found") {
-			// Not found
-			desc.FetchStatus = models.FetchStatusNotFound
-			desc.RawText = &rawText
-			if rawJsonResponse != "" {
-				desc.RawJsonResponse = &rawJsonResponse
-			}
-		} else {
-			// Success - store raw JSON response, then unwrap, normalize and store
-			if rawJsonResponse != "" {
-				desc.RawJsonResponse = &rawJsonResponse
-			}
-			
-			// Unwrap, normalize and store
-			rawText = services.UnwrapDescriptionText(rawText)
-			rawTextNormalized := services.NormalizeRaw(rawText)
-			textNormalized := services.Normalize(rawTextNormalized)
-			contentHash := services.ComputeContentHash(textNormalized)
-
-			desc.FetchStatus = models.FetchStatusFetched
-			desc.RawText = &rawText
-			desc.RawTextNormalized = &rawTextNormalized
-			desc.TextNormalized = &textNormalized
-			desc.ContentHash = &contentHash
-			desc.NormalizationVersion = &currentNormalizationVersion
-			
-			// Generate AI-optimized text (only for successfully fetched descriptions)
-			aiInputText, excerptText, aiMeta, pocEmailPrimary, err := services.OptimizeForAI(rawTextNormalized)
-			if err == nil {
-				aiInputHash := services.ComputeContentHash(aiInputText)
-				aiInputVersion := 1
-				desc.AIInputText = &aiInputText
-				desc.AIInputHash = &aiInputHash
-				desc.AIInputVersion = &aiInputVersion
-				desc.AIGeneratedAt = &now
-				desc.AIMeta = &aiMeta
-				desc.ExcerptText = &excerptText
-				desc.POCEmailPrimary = pocEmailPrimary
+		httpStatus := fetch.HTTPStatus
+		desc = services.FetchedDescription(noticeID, sourceURL, fetch, existingDesc, time.Now(), h.aiFieldLookup(ctx, noticeID))
+		services.DefaultFetchMetrics.RecordFetchOutcome(desc.FetchStatus, models.SourceTypeURL, httpStatus)
+
+		// A failed re-fetch (stale or refresh=true) of a description we already have, e.g. SAM timing out or
+		// answering 5xx, must not wipe the good copy: count the attempt and keep serving the stored text
+		if desc.FetchStatus == models.FetchStatusError && existingDesc != nil && existingDesc.FetchStatus == models.FetchStatusFetched {
+			log.Printf("Description re-fetch failed for noticeId=%s, keeping the stored copy: %s", noticeID, *desc.LastError)
+			attempts, err := h.descRepo.RecordRefetchError(ctx, noticeID, *desc.LastError)
+			if err != nil {
+				WriteError(w, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("failed to store description: %v", err))
+				return
 			}
+			existingDesc.FetchAttempts = attempts
+			existingDesc.LastError = desc.LastError
+			WriteJSON(w, http.StatusOK, buildDescriptionResponse(existingDesc))
+			return
 		}
 
 		// Store in database
 		err = h.descRepo.UpsertDescription(ctx, desc)
 		if err != nil {
-			WriteJSON(w, http.StatusInternalServerError, map[string]string{
-				"error": fmt.Sprintf("failed to store description: %v", err),
-			})
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, fmt.Sprintf("failed to store description: %v", err))
 			return
 		}
 
@@ -605,25 +772,14 @@ func buildDescriptionResponse(desc *models.OpportunityDescription) models.Descri
 
 	// Set lastError if present
 	response.LastError = desc.LastError
+	response.Oversize = desc.Oversize
+	if desc.FetchStatus == models.FetchStatusError {
+		response.FetchAttempts = desc.FetchAttempts
+	}
 
 	return response
 }
 
-// computeAdvisoryLockKey computes a lock key from notice_id
-func computeAdvisoryLockKey(noticeID string) int64 {
-	hash := sha256.Sum256([]byte(noticeID))
-	// Use first 8 bytes as int64 (PostgreSQL advisory locks use int8)
-	var key int64
-	for i := 0; i < 8; i++ {
-		key = (key << 8) | int64(hash[i])
-	}
-	// Ensure positive (PostgreSQL uses signed int8, but we want positive)
-	if key < 0 {
-		key = -key
-	}
-	return key
-}
-
 // previewText returns a preview of a string for logging purposes
 func previewText(s *string, maxLen int) string {
 	if s == nil {
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/models"
+	"govcon/api/internal/repositories"
+)
+
+func TestHandleSearchV2_InvalidDate(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search?postedFrom=2025-13-40", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleSearchV2(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "postedFrom") {
+		t.Errorf("Expected message to name postedFrom, got %q", resp.Message)
+	}
+}
+
+func TestHandleSearchV2_InvalidDescriptionStatus(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search?descriptionStatus=fetched", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleSearchV2(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "descriptionStatus") {
+		t.Errorf("Expected message to name descriptionStatus, got %q", resp.Message)
+	}
+}
+
+func TestHandleSearchV2_InvalidArchiveFlag(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search?archivedOnly=maybe", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleSearchV2(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "archivedOnly") {
+		t.Errorf("Expected message to name archivedOnly, got %q", resp.Message)
+	}
+}
+
+func TestHandleSearchV2_InvalidClassificationPrefix(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search?classification=R4&classificationPrefix=sometimes", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleSearchV2(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "classificationPrefix") {
+		t.Errorf("Expected message to name classificationPrefix, got %q", resp.Message)
+	}
+}
+
+func TestHandleSearchV2_InvalidFacet(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search?facets=color", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleSearchV2(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "facets") {
+		t.Errorf("Expected message to name facets, got %q", resp.Message)
+	}
+}
+
+func TestHandleSearchV2_ExplainDisabled(t *testing.T) {
+	t.Setenv("SEARCH_EXPLAIN", "")
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search?explain=true", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleSearchV2(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "SEARCH_EXPLAIN") {
+		t.Errorf("Expected message to name SEARCH_EXPLAIN, got %q", resp.Message)
+	}
+}
+
+func TestHandleSearchV2_ExplainRelevanceRequiresRankedSearch(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	for _, query := range []string{
+		"/opportunities/search?explainRelevance=true&q=janitorial",
+		"/opportunities/search?explainRelevance=true&sort=relevance",
+	} {
+		req := httptest.NewRequest(http.MethodGet, query, nil)
+		rec := httptest.NewRecorder()
+
+		h.HandleSearchV2(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: Expected status %d, got %d", query, http.StatusBadRequest, rec.Code)
+			continue
+		}
+		resp := decodeErrorResponse(t, rec)
+		if !strings.Contains(resp.Message, "explainRelevance") {
+			t.Errorf("%s: Expected message to name explainRelevance, got %q", query, resp.Message)
+		}
+	}
+}
+
+func TestHandleSearchV2_InvalidHighlightMarkup(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search?q=janitorial&highlight=true&highlightMarkup=bbcode", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleSearchV2(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "highlightMarkup") {
+		t.Errorf("Expected message to name highlightMarkup, got %q", resp.Message)
+	}
+}
+
+func TestBuildDescriptionResponse_Oversize(t *testing.T) {
+	lastError := "response body exceeds maximum size of 5242880 bytes (DESC_MAX_BODY_BYTES)"
+	desc := &models.OpportunityDescription{
+		NoticeID:    "N1",
+		SourceType:  models.SourceTypeURL,
+		FetchStatus: models.FetchStatusError,
+		LastError:   &lastError,
+		Oversize:    true,
+	}
+
+	resp := buildDescriptionResponse(desc)
+
+	if resp.Status != "error" || !resp.Oversize {
+		t.Errorf("Expected an oversize error, got status %q oversize %v", resp.Status, resp.Oversize)
+	}
+	if resp.LastError == nil || *resp.LastError != lastError {
+		t.Errorf("Expected lastError %q, got %v", lastError, resp.LastError)
+	}
+}
+
+func TestBuildDescriptionResponse_FetchAttempts(t *testing.T) {
+	lastError := "timeout"
+	desc := &models.OpportunityDescription{
+		NoticeID:      "N1",
+		SourceType:    models.SourceTypeURL,
+		FetchStatus:   models.FetchStatusError,
+		LastError:     &lastError,
+		FetchAttempts: 3,
+	}
+
+	resp := buildDescriptionResponse(desc)
+	if resp.FetchAttempts != 3 || resp.LastError == nil || *resp.LastError != "timeout" {
+		t.Errorf("Expected 3 attempts and lastError timeout, got %d %v", resp.FetchAttempts, resp.LastError)
+	}
+
+	// Only error states report attempts
+	desc.FetchStatus = models.FetchStatusNotRequested
+	if resp := buildDescriptionResponse(desc); resp.FetchAttempts != 0 {
+		t.Errorf("Expected no attempts outside the error state, got %d", resp.FetchAttempts)
+	}
+}
+
+func TestBuildDescriptionResponse_Attachment(t *testing.T) {
+	link := "https://sam.gov/api/prod/opps/v3/opportunities/resources/files/abc/download"
+	desc := &models.OpportunityDescription{
+		NoticeID:    "N1",
+		SourceType:  models.SourceTypeAttachment,
+		SourceURL:   &link,
+		FetchStatus: models.FetchStatusNotFound,
+	}
+
+	resp := buildDescriptionResponse(desc)
+	if resp.SourceType != "attachment" || resp.Status != "not_found" {
+		t.Errorf("Expected attachment/not_found, got %s/%s", resp.SourceType, resp.Status)
+	}
+	if resp.SourceURL == nil || *resp.SourceURL != link {
+		t.Errorf("Expected sourceUrl %q, got %v", link, resp.SourceURL)
+	}
+}
+
+func TestParseSearchFiltersV2_SearchFields(t *testing.T) {
+	params, err := parseSearchFiltersV2(url.Values{"q": {"janitorial"}, "searchFields": {"title, agency,,"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(params.SearchFields) != 2 || params.SearchFields[0] != "title" || params.SearchFields[1] != "agency" {
+		t.Errorf("Expected [title agency], got %v", params.SearchFields)
+	}
+
+	params, err = parseSearchFiltersV2(url.Values{"q": {"janitorial"}})
+	if err != nil || params.SearchFields != nil {
+		t.Errorf("Expected no scope by default, got %v (err %v)", params.SearchFields, err)
+	}
+}
+
+func TestHandleSearchV2_InvalidSearchFields(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search?q=janitorial&searchFields=title,naics", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleSearchV2(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "searchFields") || !strings.Contains(resp.Message, "naics") {
+		t.Errorf("Expected message to name searchFields and naics, got %q", resp.Message)
+	}
+}
+
+func TestParseSearchFiltersV2_NoticeTypeAndPType(t *testing.T) {
+	params, err := parseSearchFiltersV2(url.Values{"noticeType": {"Combined Synopsis/Solicitation, Presolicitation"}, "ptype": {"K,p"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(params.NoticeTypes, "|") != "combined synopsis/solicitation|presolicitation" {
+		t.Errorf("Expected both notice types lower-cased, got %v", params.NoticeTypes)
+	}
+	if strings.Join(params.ProcurementTypes, "|") != "k|p" {
+		t.Errorf("Expected ptype codes k and p, got %v", params.ProcurementTypes)
+	}
+
+	_, err = parseSearchFiltersV2(url.Values{"ptype": {"combined"}})
+	if err == nil || !strings.Contains(err.Error(), "ptype") {
+		t.Errorf("Expected an invalid ptype error, got %v", err)
+	}
+}
+
+func TestParseSearchFiltersV2_HasAttachments(t *testing.T) {
+	params, err := parseSearchFiltersV2(url.Values{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if params.HasAttachments != nil {
+		t.Errorf("Expected no attachment filter by default, got %v", *params.HasAttachments)
+	}
+
+	for value, want := range map[string]bool{"true": true, "false": false} {
+		params, err := parseSearchFiltersV2(url.Values{"hasAttachments": {value}})
+		if err != nil {
+			t.Fatalf("Unexpected error for %q: %v", value, err)
+		}
+		if params.HasAttachments == nil || *params.HasAttachments != want {
+			t.Errorf("Expected hasAttachments=%s to give %v, got %v", value, want, params.HasAttachments)
+		}
+	}
+
+	_, err = parseSearchFiltersV2(url.Values{"hasAttachments": {"yes"}})
+	if err == nil || !strings.Contains(err.Error(), "hasAttachments") {
+		t.Errorf("Expected an invalid hasAttachments error, got %v", err)
+	}
+}
+
+func TestParseSeenSince(t *testing.T) {
+	want := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	for _, value := range []string{
+		"2026-01-05T09:00:00Z",
+		"2026-01-05T14:00:00+05:00",
+		"2026-01-05T14:00:00 05:00", // "+" decoded to a space
+		"2026-01-05T04:00:00-05:00",
+	} {
+		got, err := parseSeenSince(value)
+		if err != nil {
+			t.Errorf("%q: Unexpected error: %v", value, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("%q: Expected %v, got %v", value, want, got)
+		}
+	}
+
+	for _, value := range []string{"2026-01-05", "yesterday", "1736067600"} {
+		if _, err := parseSeenSince(value); err == nil || !strings.Contains(err.Error(), "seenSince") {
+			t.Errorf("%q: Expected an invalid seenSince error, got %v", value, err)
+		}
+	}
+}
+
+func TestHandleSearchV2_InvalidSeenSince(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodGet, "/opportunities/search?seenSince=last-tuesday", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleSearchV2(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "seenSince") {
+		t.Errorf("Expected message to name seenSince, got %q", resp.Message)
+	}
+}
+
+func TestHandleGetOpportunity_DatabaseErrorIsNot404(t *testing.T) {
+	// Nothing listens on port 1, so every query fails; that is a server error, not a missing opportunity
+	pool, err := pgxpool.New(context.Background(), "postgres://govcon@127.0.0.1:1/govcon?connect_timeout=1&sslmode=disable")
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+	h := &OpportunitiesHandler{repo: repositories.NewOpportunityRepository(pool)}
+
+	for _, c := range []struct {
+		path   string
+		handle func(http.ResponseWriter, *http.Request)
+	}{
+		{"/opportunities/N1", h.HandleGetOpportunity},
+		{"/opportunities/N1/description", h.HandleGetDescription},
+	} {
+		rec := httptest.NewRecorder()
+		c.handle(rec, httptest.NewRequest(http.MethodGet, c.path, nil))
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("%s: expected status %d, got %d", c.path, http.StatusInternalServerError, rec.Code)
+		}
+	}
+}
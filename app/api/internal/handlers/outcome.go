@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/repositories"
+)
+
+// HandleOutcome handles GET and PUT /opportunities/:noticeId/outcome
+func (h *OpportunitiesHandler) HandleOutcome(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// Path format: /opportunities/{noticeId}/outcome
+	path := strings.TrimPrefix(r.URL.Path, "/opportunities/")
+	path = strings.TrimSuffix(path, "/outcome")
+	noticeID := strings.Trim(path, "/")
+	if noticeID == "" {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "noticeId is required")
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		outcome, err := h.outcomeRepo.GetOutcome(r.Context(), noticeID)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+			return
+		}
+		if outcome == nil {
+			WriteError(w, http.StatusNotFound, ErrCodeNotFound, "no outcome recorded")
+			return
+		}
+		WriteJSON(w, http.StatusOK, outcome)
+		return
+	}
+
+	var req models.OutcomeRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	outcome, errMsg := validateOutcomeRequest(req, noticeID)
+	if errMsg != "" {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, errMsg)
+		return
+	}
+
+	if err := h.outcomeRepo.UpsertOutcome(r.Context(), outcome); err != nil {
+		if errors.Is(err, repositories.ErrOpportunityNotFound) {
+			WriteError(w, http.StatusNotFound, ErrCodeNotFound, "opportunity not found")
+			return
+		}
+		WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, outcome)
+}
+
+// validateOutcomeRequest validates the PUT body
+// Returns a client-facing error message when the body is invalid
+func validateOutcomeRequest(req models.OutcomeRequest, noticeID string) (*models.OpportunityOutcome, string) {
+	if !req.Status.Valid() {
+		return nil, "status must be one of won, lost, no_bid"
+	}
+	if req.AwardAmount != nil && *req.AwardAmount < 0 {
+		return nil, "awardAmount must not be negative"
+	}
+
+	return &models.OpportunityOutcome{
+		NoticeID:    noticeID,
+		Status:      req.Status,
+		Awardee:     req.Awardee,
+		AwardAmount: req.AwardAmount,
+		Note:        req.Note,
+	}, ""
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleOutcome_InvalidStatus(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodPut, "/opportunities/N1/outcome", strings.NewReader(`{"status":"maybe"}`))
+	rec := httptest.NewRecorder()
+
+	h.HandleOutcome(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "status") {
+		t.Errorf("Expected message about status, got %q", resp.Message)
+	}
+}
+
+func TestHandleOutcome_NegativeAmount(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodPut, "/opportunities/N1/outcome", strings.NewReader(`{"status":"won","awardAmount":-5}`))
+	rec := httptest.NewRecorder()
+
+	h.HandleOutcome(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestHandleOutcome_MethodNotAllowed(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodDelete, "/opportunities/N1/outcome", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleOutcome(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"govcon/api/internal/repositories"
+)
+
+// defaultSearchWindowDays bounds unfiltered searches to recent postings
+const defaultSearchWindowDays = 90
+
+// getSearchDefaultWindowDays returns the default search window (from env or default)
+// SEARCH_DEFAULT_WINDOW_DAYS=0 disables the default window
+func getSearchDefaultWindowDays() int {
+	if daysStr := os.Getenv("SEARCH_DEFAULT_WINDOW_DAYS"); daysStr != "" {
+		if days, err := strconv.Atoi(daysStr); err == nil && days >= 0 {
+			return days
+		}
+	}
+	return defaultSearchWindowDays
+}
+
+// defaultPostedFrom returns the postedFrom to apply when the caller gave no date filters,
+// as a relative expression (e.g. "-90d") resolved by the repository
+// Returns "" when any date param is set, all=true is passed, or the window is disabled
+func defaultPostedFrom(query url.Values, dateParams ...string) string {
+	if all, err := strconv.ParseBool(query.Get("all")); err == nil && all {
+		return ""
+	}
+	for _, name := range dateParams {
+		if query.Get(name) != "" {
+			return ""
+		}
+	}
+	days := getSearchDefaultWindowDays()
+	if days == 0 {
+		return ""
+	}
+	return fmt.Sprintf("-%dd", days)
+}
+
+// getSearchRecencyBoost returns whether relevance sort favors recent postings when the caller
+// doesn't pass recencyBoost (SEARCH_RECENCY_BOOST, default false: pure relevance)
+func getSearchRecencyBoost() bool {
+	if boost, err := strconv.ParseBool(os.Getenv("SEARCH_RECENCY_BOOST")); err == nil {
+		return boost
+	}
+	return false
+}
+
+// getSearchExplainEnabled returns whether V2 search accepts explain=true (SEARCH_EXPLAIN, default false)
+// Off by default: EXPLAIN ANALYZE runs the query a second time and exposes the SQL
+func getSearchExplainEnabled() bool {
+	if enabled, err := strconv.ParseBool(os.Getenv("SEARCH_EXPLAIN")); err == nil {
+		return enabled
+	}
+	return false
+}
+
+// getSearchHighlightMarkup returns how snippets mark matches when the caller doesn't pass highlightMarkup
+// (SEARCH_HIGHLIGHT_MARKUP: html or markdown, default html)
+func getSearchHighlightMarkup() string {
+	if markup := strings.ToLower(strings.TrimSpace(os.Getenv("SEARCH_HIGHLIGHT_MARKUP"))); repositories.IsValidHighlightMarkup(markup) {
+		return markup
+	}
+	return repositories.HighlightMarkupHTML
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestDefaultPostedFrom_AppliedWhenNoDates(t *testing.T) {
+	t.Setenv("SEARCH_DEFAULT_WINDOW_DAYS", "")
+	q := url.Values{"q": {"widgets"}}
+	if got := defaultPostedFrom(q, "postedFrom", "postedTo", "dueFrom", "dueTo"); got != "-90d" {
+		t.Errorf("Expected %q, got %q", "-90d", got)
+	}
+
+	t.Setenv("SEARCH_DEFAULT_WINDOW_DAYS", "30")
+	if got := defaultPostedFrom(q, "postedFrom", "postedTo"); got != "-30d" {
+		t.Errorf("Expected %q, got %q", "-30d", got)
+	}
+}
+
+func TestDefaultPostedFrom_OverriddenByDateParam(t *testing.T) {
+	t.Setenv("SEARCH_DEFAULT_WINDOW_DAYS", "")
+	for _, name := range []string{"postedFrom", "postedTo", "dueFrom", "dueTo"} {
+		q := url.Values{name: {"2025-01-01"}}
+		if got := defaultPostedFrom(q, "postedFrom", "postedTo", "dueFrom", "dueTo"); got != "" {
+			t.Errorf("Expected no default when %s is set, got %q", name, got)
+		}
+	}
+}
+
+func TestDefaultPostedFrom_Escaped(t *testing.T) {
+	t.Setenv("SEARCH_DEFAULT_WINDOW_DAYS", "")
+	if got := defaultPostedFrom(url.Values{"all": {"true"}}, "postedFrom"); got != "" {
+		t.Errorf("Expected no default with all=true, got %q", got)
+	}
+	if got := defaultPostedFrom(url.Values{"all": {"false"}}, "postedFrom"); got != "-90d" {
+		t.Errorf("Expected default with all=false, got %q", got)
+	}
+
+	t.Setenv("SEARCH_DEFAULT_WINDOW_DAYS", "0")
+	if got := defaultPostedFrom(url.Values{}, "postedFrom"); got != "" {
+		t.Errorf("Expected no default when window is disabled, got %q", got)
+	}
+}
+
+func TestGetSearchHighlightMarkup(t *testing.T) {
+	tests := []struct {
+		env  string
+		want string
+	}{
+		{"", "html"},
+		{"markdown", "markdown"},
+		{" Markdown ", "markdown"},
+		{"bbcode", "html"},
+	}
+	for _, tt := range tests {
+		t.Setenv("SEARCH_HIGHLIGHT_MARKUP", tt.env)
+		if got := getSearchHighlightMarkup(); got != tt.want {
+			t.Errorf("SEARCH_HIGHLIGHT_MARKUP=%q: Expected %q, got %q", tt.env, tt.want, got)
+		}
+	}
+}
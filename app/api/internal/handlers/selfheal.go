@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/services"
+)
+
+const (
+	defaultHealCooldown = 10 * time.Minute       // How long to skip AI re-optimization after a failed heal
+	healPersistAttempts = 3                      // Upsert attempts for a self-heal before giving up
+	healPersistBackoff  = 100 * time.Millisecond // Initial backoff between self-heal upsert attempts
+)
+
+// healTracker remembers when a self-heal failed to persist (heal_attempted_at) so a record
+// stuck behind a DB problem isn't re-optimized for AI on every read
+// In-memory on purpose: the failure it guards against is the database being unavailable
+type healTracker struct {
+	mu              sync.Mutex
+	healAttemptedAt map[string]time.Time
+	cooldown        time.Duration
+	now             func() time.Time
+	hits            int64 // ShouldAttempt calls that found a failure on record
+	misses          int64 // ShouldAttempt calls that found none
+}
+
+func newHealTracker(cooldown time.Duration) *healTracker {
+	if cooldown <= 0 {
+		cooldown = defaultHealCooldown
+	}
+	return &healTracker{
+		healAttemptedAt: make(map[string]time.Time),
+		cooldown:        cooldown,
+		now:             time.Now,
+	}
+}
+
+// HealCooldowns exposes the self-heal cooldowns as an admin-purgeable cache
+func (h *OpportunitiesHandler) HealCooldowns() Cache {
+	return h.healTracker
+}
+
+// getHealCooldown returns the self-heal cooldown (from env or default), e.g. HEAL_COOLDOWN=5m
+func getHealCooldown() time.Duration {
+	if cooldownStr := os.Getenv("HEAL_COOLDOWN"); cooldownStr != "" {
+		if cooldown, err := time.ParseDuration(cooldownStr); err == nil && cooldown > 0 {
+			return cooldown
+		}
+	}
+	return defaultHealCooldown
+}
+
+// ShouldAttempt reports whether a full heal (including AI optimization) should run for noticeID
+func (t *healTracker) ShouldAttempt(noticeID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	attemptedAt, ok := t.healAttemptedAt[noticeID]
+	if !ok {
+		t.misses++
+		return true
+	}
+	t.hits++
+	if t.now().Sub(attemptedAt) >= t.cooldown {
+		delete(t.healAttemptedAt, noticeID)
+		return true
+	}
+	return false
+}
+
+// RecordFailure starts the cooldown for noticeID
+func (t *healTracker) RecordFailure(noticeID string) {
+	t.mu.Lock()
+	t.healAttemptedAt[noticeID] = t.now()
+	t.mu.Unlock()
+}
+
+// CacheStats reports how many notices are in cooldown and how often reads found one
+func (t *healTracker) CacheStats() models.CacheStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return models.CacheStats{Size: len(t.healAttemptedAt), Hits: t.hits, Misses: t.misses}
+}
+
+// PurgeCache ends every cooldown, so the next read of each notice attempts a full heal
+func (t *healTracker) PurgeCache() {
+	t.mu.Lock()
+	t.healAttemptedAt = make(map[string]time.Time)
+	t.mu.Unlock()
+}
+
+// Clear forgets a previous failure once a heal persists
+func (t *healTracker) Clear(noticeID string) {
+	t.mu.Lock()
+	delete(t.healAttemptedAt, noticeID)
+	t.mu.Unlock()
+}
+
+// Reasons a cached description is re-processed on read
+const (
+	healNone            = ""
+	healVersionMismatch = "version_mismatch" // stored normalization version is missing or outdated
+	healMissingRawText  = "missing_raw_text" // raw text is empty but the raw JSON response survived
+	healHTMLTags        = "html_tags"        // raw or normalized text still contains HTML
+	healUnwrapped       = "unwrapped"        // raw text was still wrapped in JSON
+	healAIVersion       = "ai_version"       // AI fields were generated by an older AI_INPUT_VERSION
+)
+
+// healSourceText decides whether a cached, fetched description needs re-processing and returns the
+// text to re-process from. Any of the row's text fields may be nil (e.g. a row with only a raw JSON
+// response); a non-empty reason with empty source text means there is nothing to heal from.
+func healSourceText(desc *models.OpportunityDescription) (string, string) {
+	rawText := ""
+	if desc.RawText != nil {
+		rawText = *desc.RawText
+	}
+
+	// Outdated normalization: prefer raw_json_response, fall back to raw_text
+	if desc.NormalizationVersion == nil || *desc.NormalizationVersion != services.NORMALIZATION_VERSION {
+		if sourceText := rawJSONDescription(desc.RawJsonResponse); sourceText != "" {
+			return sourceText, healVersionMismatch
+		}
+		return rawText, healVersionMismatch
+	}
+
+	if rawText == "" {
+		if sourceText := rawJSONDescription(desc.RawJsonResponse); sourceText != "" {
+			return sourceText, healMissingRawText
+		}
+		return "", healNone
+	}
+
+	// Unwrap JSON wrappers and strip HTML tags left in older cached descriptions
+	fixedRaw := services.UnwrapDescriptionText(rawText)
+	if containsHTMLTags(&fixedRaw) || containsHTMLTags(desc.RawTextNormalized) || containsHTMLTags(desc.TextNormalized) {
+		return fixedRaw, healHTMLTags
+	}
+	if fixedRaw != rawText {
+		return fixedRaw, healUnwrapped
+	}
+	// Text is current but its AI fields predate an OptimizeForAI or AiMeta change
+	if desc.AIInputText != nil && (desc.AIInputVersion == nil || *desc.AIInputVersion != services.AI_INPUT_VERSION) {
+		return rawText, healAIVersion
+	}
+	return "", healNone
+}
+
+// rawJSONDescription extracts the description from a stored SAM JSON response,
+// or returns the response as-is when it isn't JSON with a string description. Nil-safe.
+func rawJSONDescription(rawJSON *string) string {
+	if rawJSON == nil || *rawJSON == "" {
+		return ""
+	}
+	var jsonResponse map[string]interface{}
+	if err := models.DecodeJSON([]byte(*rawJSON), &jsonResponse); err == nil {
+		if desc, ok := jsonResponse["description"].(string); ok && desc != "" {
+			return desc
+		}
+	}
+	return *rawJSON
+}
+
+// containsHTMLTags reports whether s looks like it still has HTML tags. Nil-safe.
+func containsHTMLTags(s *string) bool {
+	return s != nil && strings.Contains(*s, "<") && strings.Contains(*s, ">")
+}
+
+// previewVersion formats a possibly nil normalization version for logging
+func previewVersion(v *int) string {
+	if v == nil {
+		return "<nil>"
+	}
+	return strconv.Itoa(*v)
+}
+
+// persistHeal upserts a healed description with a short retry, bounded by a timeout
+func persistHeal(ctx context.Context, upsert func(context.Context, *models.OpportunityDescription) error, desc *models.OpportunityDescription) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	cfg := services.RetryConfig{
+		MaxAttempts:    healPersistAttempts,
+		InitialBackoff: healPersistBackoff,
+		IsRetryable:    func(error) bool { return true }, // Any DB error is worth a quick retry here
+	}
+	return services.Retry(ctx, cfg, func() error {
+		return upsert(ctx, desc)
+	})
+}
+
+// healDescription re-normalizes a stored description from sourceText in place, regenerating AI fields only when
+// the content changed, and persists the result; a failed persist starts the notice's cooldown
+func (h *OpportunitiesHandler) healDescription(ctx context.Context, noticeID string, existingDesc *models.OpportunityDescription, sourceText string) {
+	// Snapshot before re-processing so unchanged content reuses its AI fields
+	prevDesc := *existingDesc
+
+	// fetchedAt is left alone: the text wasn't re-fetched, and DESC_MAX_AGE is measured from it
+	now := time.Now()
+
+	// Re-process normalized fields, and AI-optimized fields only if the content changed
+	aiRan, err := services.ApplyNormalization(existingDesc, sourceText, &prevDesc, now)
+	if err != nil {
+		log.Printf("Description self-heal: failed to optimize for AI for noticeId=%s: %v", noticeID, err)
+		// If AI optimization fails, preserve existing AI fields or set defaults
+		// Other AI fields can remain as-is (they may be nil, which is fine)
+	} else if !aiRan {
+		log.Printf("Description self-heal: content hash unchanged for noticeId=%s, reusing AI fields", noticeID)
+	}
+
+	// Safety check: ensure ai_input_version is never nil before persisting (required NOT NULL constraint)
+	if existingDesc.AIInputVersion == nil {
+		aiInputVersion := 1
+		existingDesc.AIInputVersion = &aiInputVersion
+		log.Printf("Description self-heal: set default ai_input_version=1 for noticeId=%s", noticeID)
+	}
+
+	// Persist the fix so it's corrected next time (retried briefly; on failure start the cooldown)
+	if err := persistHeal(ctx, h.descRepo.UpsertDescription, existingDesc); err != nil {
+		h.healTracker.RecordFailure(noticeID)
+		log.Printf("Description self-heal: failed to persist fix for noticeId=%s: %v", noticeID, err)
+		// Continue anyway - we'll return the fixed version even if persistence fails
+	} else {
+		h.healTracker.Clear(noticeID)
+		log.Printf("Description self-heal: successfully persisted fix for noticeId=%s", noticeID)
+	}
+}
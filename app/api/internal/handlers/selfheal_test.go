@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/services"
+)
+
+func TestPersistHeal_UpsertFailureStartsCooldown(t *testing.T) {
+	tracker := newHealTracker(time.Minute)
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	attempts := 0
+	failingUpsert := func(ctx context.Context, desc *models.OpportunityDescription) error {
+		attempts++
+		return errors.New("connection reset by peer")
+	}
+
+	desc := &models.OpportunityDescription{NoticeID: "N1"}
+	if !tracker.ShouldAttempt("N1") {
+		t.Fatal("Expected first heal to be attempted")
+	}
+	if err := persistHeal(context.Background(), failingUpsert, desc); err == nil {
+		t.Fatal("Expected persistHeal to return the upsert error")
+	}
+	if attempts != healPersistAttempts {
+		t.Errorf("Expected %d upsert attempts, got %d", healPersistAttempts, attempts)
+	}
+	tracker.RecordFailure("N1")
+
+	// Next read within the cooldown skips AI re-optimization
+	now = now.Add(30 * time.Second)
+	if tracker.ShouldAttempt("N1") {
+		t.Error("Expected heal to be skipped within cooldown")
+	}
+	// Other records are unaffected
+	if !tracker.ShouldAttempt("N2") {
+		t.Error("Expected other notice IDs to heal normally")
+	}
+
+	// After the cooldown the heal is retried
+	now = now.Add(time.Minute)
+	if !tracker.ShouldAttempt("N1") {
+		t.Error("Expected heal to be attempted after cooldown")
+	}
+}
+
+func TestPersistHeal_RetriesTransientFailure(t *testing.T) {
+	attempts := 0
+	flakyUpsert := func(ctx context.Context, desc *models.OpportunityDescription) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("connection reset by peer")
+		}
+		return nil
+	}
+
+	if err := persistHeal(context.Background(), flakyUpsert, &models.OpportunityDescription{NoticeID: "N1"}); err != nil {
+		t.Errorf("Expected success after retry, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestHealTracker_ClearAfterSuccess(t *testing.T) {
+	tracker := newHealTracker(time.Hour)
+	tracker.RecordFailure("N1")
+	tracker.Clear("N1")
+	if !tracker.ShouldAttempt("N1") {
+		t.Error("Expected heal to be attempted after Clear")
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestHealSourceText_PartialRows(t *testing.T) {
+	current := services.NORMALIZATION_VERSION
+	outdated := current - 1
+	currentAI := services.AI_INPUT_VERSION
+	oldAI := currentAI - 1
+
+	tests := []struct {
+		name       string
+		desc       models.OpportunityDescription
+		wantText   string
+		wantReason string
+	}{
+		{
+			name:       "only raw_json, current version",
+			desc:       models.OpportunityDescription{RawJsonResponse: strPtr(`{"description":"Scope of work."}`), NormalizationVersion: &current},
+			wantText:   "Scope of work.",
+			wantReason: healMissingRawText,
+		},
+		{
+			name:       "only raw_json, no version",
+			desc:       models.OpportunityDescription{RawJsonResponse: strPtr(`{"description":"Scope of work."}`)},
+			wantText:   "Scope of work.",
+			wantReason: healVersionMismatch,
+		},
+		{
+			name:       "only raw_json, not JSON",
+			desc:       models.OpportunityDescription{RawJsonResponse: strPtr("plain text body"), NormalizationVersion: &current},
+			wantText:   "plain text body",
+			wantReason: healMissingRawText,
+		},
+		{
+			name:       "only raw_text, current version",
+			desc:       models.OpportunityDescription{RawText: strPtr("Scope of work."), NormalizationVersion: &current},
+			wantText:   "",
+			wantReason: healNone,
+		},
+		{
+			name:       "only raw_text, outdated version",
+			desc:       models.OpportunityDescription{RawText: strPtr("Scope of work."), NormalizationVersion: &outdated},
+			wantText:   "Scope of work.",
+			wantReason: healVersionMismatch,
+		},
+		{
+			name:       "only raw_text with HTML",
+			desc:       models.OpportunityDescription{RawText: strPtr("<p>Scope</p>"), NormalizationVersion: &current},
+			wantText:   "<p>Scope</p>",
+			wantReason: healHTMLTags,
+		},
+		{
+			name:       "HTML left in normalized text only",
+			desc:       models.OpportunityDescription{RawText: strPtr("Scope"), TextNormalized: strPtr("<b>Scope</b>"), NormalizationVersion: &current},
+			wantText:   "Scope",
+			wantReason: healHTMLTags,
+		},
+		{
+			name:       "AI fields from an older AI input version",
+			desc:       models.OpportunityDescription{RawText: strPtr("Scope of work."), NormalizationVersion: &current, AIInputText: strPtr("Scope of work."), AIInputVersion: &oldAI},
+			wantText:   "Scope of work.",
+			wantReason: healAIVersion,
+		},
+		{
+			name:       "AI fields at the current AI input version",
+			desc:       models.OpportunityDescription{RawText: strPtr("Scope of work."), NormalizationVersion: &current, AIInputText: strPtr("Scope of work."), AIInputVersion: &currentAI},
+			wantText:   "",
+			wantReason: healNone,
+		},
+		{
+			name:       "neither, current version",
+			desc:       models.OpportunityDescription{NormalizationVersion: &current},
+			wantText:   "",
+			wantReason: healNone,
+		},
+		{
+			name:       "neither, no version",
+			desc:       models.OpportunityDescription{},
+			wantText:   "",
+			wantReason: healVersionMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desc := tt.desc
+			desc.NoticeID = "N1"
+			desc.FetchStatus = models.FetchStatusFetched
+			text, reason := healSourceText(&desc)
+			if text != tt.wantText {
+				t.Errorf("Expected source text %q, got %q", tt.wantText, text)
+			}
+			if reason != tt.wantReason {
+				t.Errorf("Expected reason %q, got %q", tt.wantReason, reason)
+			}
+			// The response for the row as stored must build without panicking
+			resp := buildDescriptionResponse(&desc)
+			if resp.Status != "fetched" {
+				t.Errorf("Expected status %q, got %q", "fetched", resp.Status)
+			}
+		})
+	}
+}
+
+func TestHealSourceText_OnlyRawJSONHealsIntoRawText(t *testing.T) {
+	current := services.NORMALIZATION_VERSION
+	desc := &models.OpportunityDescription{
+		NoticeID:             "N1",
+		FetchStatus:          models.FetchStatusFetched,
+		RawJsonResponse:      strPtr(`{"description":"Scope of work."}`),
+		NormalizationVersion: &current,
+	}
+	sourceText, reason := healSourceText(desc)
+	if reason == healNone {
+		t.Fatal("Expected a heal for a row without raw text")
+	}
+	if _, err := services.ApplyNormalization(desc, sourceText, desc, time.Now()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if desc.RawText == nil || desc.TextNormalized == nil {
+		t.Fatal("Expected raw and normalized text to be filled in")
+	}
+	if *desc.TextNormalized != "Scope of work." {
+		t.Errorf("Expected normalized text %q, got %q", "Scope of work.", *desc.TextNormalized)
+	}
+	// A healed row needs no further healing
+	if _, reason := healSourceText(desc); reason != healNone {
+		t.Errorf("Expected no further heal, got reason %q", reason)
+	}
+}
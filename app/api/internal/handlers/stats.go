@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultAgencyStatsLimit = 20
+	maxAgencyStatsLimit     = 100
+)
+
+// HandleAgencyStats handles GET /stats/agencies?naics=336413&limit=10
+// Counts opportunities per agency under the same filters as /opportunities/search (archived opportunities are
+// left out unless includeArchived=true), most opportunities first
+func (h *OpportunitiesHandler) HandleAgencyStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	params, err := parseSearchFiltersV2(r.URL.Query())
+	if err != nil {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, err.Error())
+		return
+	}
+
+	counts, err := h.repo.AgencyCounts(r.Context(), params, agencyStatsLimit(r.URL.Query().Get("limit")))
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]any{"items": counts})
+}
+
+// agencyStatsLimit parses the limit parameter, capped at maxAgencyStatsLimit; like the other list endpoints,
+// a missing or invalid limit gets the default
+func agencyStatsLimit(limitStr string) int {
+	if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+		return min(parsed, maxAgencyStatsLimit)
+	}
+	return defaultAgencyStatsLimit
+}
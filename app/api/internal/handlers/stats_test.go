@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleAgencyStats_InvalidParams(t *testing.T) {
+	cases := []struct {
+		query string
+		param string
+	}{
+		{"?postedFrom=2025-13-40", "postedFrom"},
+		{"?descriptionStatus=fetched", "descriptionStatus"},
+		{"?includeArchived=maybe", "includeArchived"},
+	}
+	for _, c := range cases {
+		h := &OpportunitiesHandler{}
+		req := httptest.NewRequest(http.MethodGet, "/stats/agencies"+c.query, nil)
+		rec := httptest.NewRecorder()
+
+		h.HandleAgencyStats(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: Expected status %d, got %d", c.query, http.StatusBadRequest, rec.Code)
+			continue
+		}
+		resp := decodeErrorResponse(t, rec)
+		if !strings.Contains(resp.Message, c.param) {
+			t.Errorf("%s: Expected message to name %s, got %q", c.query, c.param, resp.Message)
+		}
+	}
+}
+
+func TestHandleAgencyStats_MethodNotAllowed(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodPost, "/stats/agencies", nil)
+	rec := httptest.NewRecorder()
+
+	h.HandleAgencyStats(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
+
+func TestAgencyStatsLimit(t *testing.T) {
+	cases := map[string]int{
+		"":    defaultAgencyStatsLimit,
+		"10":  10,
+		"500": maxAgencyStatsLimit,
+		"0":   defaultAgencyStatsLimit,
+		"-5":  defaultAgencyStatsLimit,
+		"ten": defaultAgencyStatsLimit,
+	}
+	for in, want := range cases {
+		if got := agencyStatsLimit(in); got != want {
+			t.Errorf("agencyStatsLimit(%q): expected %d, got %d", in, want, got)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"govcon/api/internal/services"
+)
+
+// streamHeartbeatInterval keeps idle SSE connections from being closed by proxies
+const streamHeartbeatInterval = 30 * time.Second
+
+type StreamHandler struct {
+	broker *services.EventBroker
+}
+
+func NewStreamHandler(broker *services.EventBroker) *StreamHandler {
+	return &StreamHandler{broker: broker}
+}
+
+// HandleStream handles GET /opportunities/stream
+// Server-Sent Events stream of opportunities as ingestion marks them new or updated
+func (h *StreamHandler) HandleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteError(w, http.StatusInternalServerError, ErrCodeInternal, "streaming not supported")
+		return
+	}
+
+	events, unsubscribe := h.broker.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			// Client disconnected
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(ev)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: opportunity\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
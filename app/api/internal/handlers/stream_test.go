@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"govcon/api/internal/services"
+)
+
+func TestHandleStream_ReceivesEvent(t *testing.T) {
+	broker := services.NewEventBroker(4)
+	h := NewStreamHandler(broker)
+	srv := httptest.NewServer(http.HandlerFunc(h.HandleStream))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected Content-Type %q, got %q", "text/event-stream", ct)
+	}
+
+	// Headers are flushed after subscribing, so the subscriber is registered by now
+	if broker.SubscriberCount() != 1 {
+		t.Fatalf("Expected 1 subscriber, got %d", broker.SubscriberCount())
+	}
+	broker.Publish(services.OpportunityEvent{NoticeID: "N123", Title: "Widgets", Action: "new"})
+
+	reader := bufio.NewReader(resp.Body)
+	eventLine, _ := reader.ReadString('\n')
+	dataLine, _ := reader.ReadString('\n')
+
+	if strings.TrimSpace(eventLine) != "event: opportunity" {
+		t.Errorf("Expected event line, got %q", eventLine)
+	}
+	if !strings.HasPrefix(dataLine, "data: ") || !strings.Contains(dataLine, `"noticeId":"N123"`) {
+		t.Errorf("Expected data line with N123, got %q", dataLine)
+	}
+}
+
+func TestHandleStream_UnsubscribesOnDisconnect(t *testing.T) {
+	broker := services.NewEventBroker(4)
+	h := NewStreamHandler(broker)
+	srv := httptest.NewServer(http.HandlerFunc(h.HandleStream))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	cancel()
+	resp.Body.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for broker.SubscriberCount() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if broker.SubscriberCount() != 0 {
+		t.Errorf("Expected subscriber to be removed after disconnect, got %d", broker.SubscriberCount())
+	}
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/repositories"
+)
+
+// defaultTagVocabulary is the set of tags teams may apply when OPPORTUNITY_TAGS is unset
+const defaultTagVocabulary = "rebid,incumbent-known,strategic"
+
+// getTagVocabulary returns the allowed tags (OPPORTUNITY_TAGS, comma-separated, or the default)
+func getTagVocabulary() []string {
+	tags := parseTagList(os.Getenv("OPPORTUNITY_TAGS"))
+	if len(tags) == 0 {
+		tags = parseTagList(defaultTagVocabulary)
+	}
+	return tags
+}
+
+func parseTagList(s string) []string {
+	var tags []string
+	for _, part := range strings.Split(s, ",") {
+		if tag := normalizeTag(part); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// normalizeTag makes tags case- and whitespace-insensitive
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// validateTag normalizes tag and checks it against the vocabulary
+// Returns a client-facing error message when the tag is not allowed
+func validateTag(tag string) (string, string) {
+	tag = normalizeTag(tag)
+	vocabulary := getTagVocabulary()
+	for _, allowed := range vocabulary {
+		if tag == allowed {
+			return tag, ""
+		}
+	}
+	return "", fmt.Sprintf("invalid tag %q: expected one of %s", tag, strings.Join(vocabulary, ", "))
+}
+
+// HandleTags handles GET and POST /opportunities/:noticeId/tags and DELETE /opportunities/:noticeId/tags/:tag
+func (h *OpportunitiesHandler) HandleTags(w http.ResponseWriter, r *http.Request) {
+	// Path format: /opportunities/{noticeId}/tags[/{tag}]
+	path := strings.TrimPrefix(r.URL.Path, "/opportunities/")
+	noticeID, rest, _ := strings.Cut(path, "/tags")
+	noticeID = strings.Trim(noticeID, "/")
+	tagParam := strings.Trim(rest, "/")
+	if noticeID == "" {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "noticeId is required")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && tagParam == "":
+		tags, err := h.tagRepo.ListTags(r.Context(), noticeID)
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+			return
+		}
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"noticeId": noticeID, "tags": tags})
+
+	case r.Method == http.MethodPost && tagParam == "":
+		var req models.TagRequest
+		if !decodeJSONBody(w, r, &req) {
+			return
+		}
+		tag, errMsg := validateTag(req.Tag)
+		if errMsg != "" {
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, errMsg)
+			return
+		}
+		result, err := h.tagRepo.AddTag(r.Context(), noticeID, tag)
+		if err != nil {
+			if errors.Is(err, repositories.ErrOpportunityNotFound) {
+				WriteError(w, http.StatusNotFound, ErrCodeNotFound, "opportunity not found")
+				return
+			}
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+			return
+		}
+		WriteJSON(w, http.StatusOK, result)
+
+	case r.Method == http.MethodDelete && tagParam != "":
+		removed, err := h.tagRepo.RemoveTag(r.Context(), noticeID, normalizeTag(tagParam))
+		if err != nil {
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+			return
+		}
+		if !removed {
+			WriteError(w, http.StatusNotFound, ErrCodeNotFound, "tag not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+	}
+}
+
+// maxBulkTagIDs caps how many notices one bulk tag request may name
+const maxBulkTagIDs = 500
+
+// parseBulkTagIDs trims the notice IDs, dropping blanks and duplicates
+// Returns a client-facing error message when none are given or there are more than maxBulkTagIDs
+func parseBulkTagIDs(raw []string) ([]string, string) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, part := range raw {
+		id := strings.TrimSpace(part)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, "noticeIds is required"
+	}
+	if len(ids) > maxBulkTagIDs {
+		return nil, fmt.Sprintf("at most %d noticeIds can be tagged at once, got %d", maxBulkTagIDs, len(ids))
+	}
+	return ids, ""
+}
+
+// bulkTagResults reports each of ids in request order, failing those that were not tagged
+func bulkTagResults(ids []string, tagged map[string]bool) []models.BulkTagResult {
+	results := make([]models.BulkTagResult, len(ids))
+	for i, id := range ids {
+		results[i] = models.BulkTagResult{NoticeID: id, Success: tagged[id]}
+		if !tagged[id] {
+			results[i].Error = "opportunity not found"
+		}
+	}
+	return results
+}
+
+// HandleBulkTags handles POST /tags/bulk
+// Applies one tag to many opportunities at once; unknown notice IDs are reported per ID without failing the rest
+func (h *OpportunitiesHandler) HandleBulkTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req models.BulkTagRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	tag, errMsg := validateTag(req.Tag)
+	if errMsg != "" {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, errMsg)
+		return
+	}
+	ids, errMsg := parseBulkTagIDs(req.NoticeIDs)
+	if errMsg != "" {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, errMsg)
+		return
+	}
+
+	tagged, err := h.tagRepo.AddTagBulk(r.Context(), ids, tag)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"tag":     tag,
+		"results": bulkTagResults(ids, tagged),
+		"tagged":  len(tagged),
+		"failed":  len(ids) - len(tagged),
+	})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"govcon/api/internal/models"
+)
+
+func TestHandleTags_TagOutsideVocabulary(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	req := httptest.NewRequest(http.MethodPost, "/opportunities/N1/tags", strings.NewReader(`{"tag":"maybe-later"}`))
+	rec := httptest.NewRecorder()
+
+	h.HandleTags(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+	resp := decodeErrorResponse(t, rec)
+	if !strings.Contains(resp.Message, "rebid") {
+		t.Errorf("Expected message to list allowed tags, got %q", resp.Message)
+	}
+}
+
+func TestHandleTags_MethodNotAllowed(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	for _, c := range []struct{ method, path string }{
+		{http.MethodDelete, "/opportunities/N1/tags"},
+		{http.MethodPost, "/opportunities/N1/tags/rebid"},
+		{http.MethodPut, "/opportunities/N1/tags"},
+	} {
+		rec := httptest.NewRecorder()
+		h.HandleTags(rec, httptest.NewRequest(c.method, c.path, nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: expected status %d, got %d", c.method, c.path, http.StatusMethodNotAllowed, rec.Code)
+		}
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	if tag, errMsg := validateTag("  Strategic "); errMsg != "" || tag != "strategic" {
+		t.Errorf("Expected strategic, got %q (%s)", tag, errMsg)
+	}
+
+	t.Setenv("OPPORTUNITY_TAGS", "teaming, watch")
+	if _, errMsg := validateTag("rebid"); errMsg == "" {
+		t.Error("Expected rebid to be rejected with a custom vocabulary")
+	}
+	if tag, errMsg := validateTag("watch"); errMsg != "" || tag != "watch" {
+		t.Errorf("Expected watch, got %q (%s)", tag, errMsg)
+	}
+}
+
+func TestHandleBulkTags_Validation(t *testing.T) {
+	h := &OpportunitiesHandler{}
+	tooMany := make([]string, maxBulkTagIDs+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("N%d", i)
+	}
+	tooManyBody, _ := json.Marshal(models.BulkTagRequest{Tag: "rebid", NoticeIDs: tooMany})
+
+	for _, c := range []struct{ body, want string }{
+		{`{"tag":"maybe-later","noticeIds":["N1"]}`, "rebid"},
+		{`{"tag":"rebid","noticeIds":[" ",""]}`, "noticeIds is required"},
+		{`{"tag":"rebid"}`, "noticeIds is required"},
+		{string(tooManyBody), "at most 500"},
+		{`not json`, "invalid JSON body"},
+	} {
+		rec := httptest.NewRecorder()
+		h.HandleBulkTags(rec, httptest.NewRequest(http.MethodPost, "/tags/bulk", strings.NewReader(c.body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%.40s: Expected status %d, got %d", c.body, http.StatusBadRequest, rec.Code)
+			continue
+		}
+		if resp := decodeErrorResponse(t, rec); !strings.Contains(resp.Message, c.want) {
+			t.Errorf("%.40s: Expected message containing %q, got %q", c.body, c.want, resp.Message)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.HandleBulkTags(rec, httptest.NewRequest(http.MethodGet, "/tags/bulk", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
+
+func TestParseBulkTagIDs_TrimsAndDedupes(t *testing.T) {
+	ids, errMsg := parseBulkTagIDs([]string{" N1", "N2", "N1 ", ""})
+	if errMsg != "" || len(ids) != 2 || ids[0] != "N1" || ids[1] != "N2" {
+		t.Errorf("Expected [N1 N2], got %v (%s)", ids, errMsg)
+	}
+}
+
+func TestBulkTagResults_ReportsMissingPerID(t *testing.T) {
+	results := bulkTagResults([]string{"N1", "MISSING", "N2"}, map[string]bool{"N1": true, "N2": true})
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !results[0].Success || !results[2].Success {
+		t.Errorf("Expected N1 and N2 to succeed, got %+v", results)
+	}
+	if results[1].NoticeID != "MISSING" || results[1].Success || results[1].Error != "opportunity not found" {
+		t.Errorf("Expected MISSING to fail as not found, got %+v", results[1])
+	}
+}
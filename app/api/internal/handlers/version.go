@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"govcon/api/internal/repositories"
+)
+
+// HandleListVersions handles GET /opportunities/:noticeId/versions
+// Versions come newest first; pass the response's nextCursor as cursor to get the next page
+func (h *OpportunitiesHandler) HandleListVersions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		WriteError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// Path format: /opportunities/{noticeId}/versions
+	path := strings.TrimPrefix(r.URL.Path, "/opportunities/")
+	path = strings.TrimSuffix(path, "/versions")
+	noticeID := strings.Trim(path, "/")
+	if noticeID == "" {
+		WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "noticeId is required")
+		return
+	}
+
+	params := repositories.ListVersionsParams{
+		NoticeID: noticeID,
+		Cursor:   r.URL.Query().Get("cursor"),
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			params.Limit = parsed
+		}
+	}
+
+	result, err := h.repo.ListVersions(r.Context(), params)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrOpportunityNotFound):
+			WriteError(w, http.StatusNotFound, ErrCodeNotFound, "opportunity not found")
+		case errors.Is(err, repositories.ErrInvalidCursor):
+			WriteError(w, http.StatusBadRequest, ErrCodeBadRequest, "invalid cursor")
+		default:
+			WriteError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		}
+		return
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"items":      result.Items,
+		"nextCursor": result.NextCursor,
+	})
+}
@@ -0,0 +1,332 @@
+// Package integration runs the API end to end against a real Postgres: migrations as cmd/setup-db applies them,
+// fixtures ingested through IngestionService from a mock SAM server, and requests served by the handlers.
+// Every test is skipped unless TESTDB_URL points at a database the tests may create schemas in.
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"govcon/api/internal/db"
+	"govcon/api/internal/handlers"
+	"govcon/api/internal/models"
+	"govcon/api/internal/repositories"
+	"govcon/api/internal/samtest"
+	"govcon/api/internal/services"
+	"govcon/api/internal/testdb"
+)
+
+// openTestDB connects to TESTDB_URL with a throwaway schema and applies every migration
+// Tests using it are skipped when TESTDB_URL is not set
+func openTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	pool := testdb.Open(t)
+	testdb.Migrate(t, pool)
+	return pool
+}
+
+// addFixture adds an open solicitation posted daysAgo days ago whose description is served by sam
+func addFixture(sam *samtest.Server, noticeID string, daysAgo int) map[string]any {
+	today := time.Now().In(models.AppLocation())
+	opp := samtest.Opportunity(noticeID, today.AddDate(0, 0, -daysAgo).Format("2006-01-02"))
+	opp["title"] = "Janitorial services " + noticeID
+	opp["archiveType"] = "autocustom"
+	opp["archiveDate"] = today.AddDate(0, 0, 60).Format("2006-01-02")
+	opp["responseDeadline"] = today.AddDate(0, 0, 30).Format("2006-01-02") + "T17:00:00-05:00"
+	opp["naics"] = []map[string]any{{"code": "561720", "description": "Janitorial Services"}}
+	opp["classificationCode"] = "S201"
+	opp["description"] = sam.DescriptionURL(noticeID)
+	opp["fullParentPathName"] = "DEPT OF DEFENSE.DEPT OF THE ARMY"
+	sam.AddOpportunities(opp)
+	return opp
+}
+
+// newAPI wires the repositories, services and handlers as cmd/api does, routing the endpoints under test
+func newAPI(t *testing.T, pool *pgxpool.Pool) *httptest.Server {
+	t.Helper()
+	h := handlers.NewOpportunitiesHandler(
+		repositories.NewOpportunityRepository(pool),
+		repositories.NewDescriptionRepository(pool),
+		repositories.NewOutcomeRepository(pool),
+		repositories.NewTagRepository(pool),
+		services.NewDescriptionService(),
+		services.NewSAMService(),
+		pool,
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/opportunities/search", h.HandleSearchV2)
+	mux.HandleFunc("/opportunities/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/description") {
+			h.HandleGetDescription(w, r)
+			return
+		}
+		h.HandleGetOpportunity(w, r)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// ingestFixtures runs IngestionService against the mock SAM server
+func ingestFixtures(t *testing.T, pool *pgxpool.Pool, sam *samtest.Server) *services.IngestionStats {
+	t.Helper()
+	samService := &services.SAMService{APIKey: "test-key", BaseURL: sam.SearchURL()}
+	today := time.Now().In(models.AppLocation())
+	stats, err := services.NewIngestionService(pool, samService).IngestOpportunities(context.Background(),
+		today.AddDate(0, 0, -30).Format("01/02/2006"), today.Format("01/02/2006"))
+	if err != nil {
+		t.Fatalf("Ingestion failed: %v", err)
+	}
+	return stats
+}
+
+// getJSON GETs path from srv, failing the test unless it answers with want, and decodes the body into v
+func getJSON(t *testing.T, srv *httptest.Server, path string, want int, v any) {
+	t.Helper()
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != want {
+		t.Fatalf("GET %s: Expected status %d, got %d", path, want, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: failed to decode response: %v", path, err)
+	}
+}
+
+func TestIngestAndSearchV2_PaginatesEveryRowOnce(t *testing.T) {
+	pool := openTestDB(t)
+	t.Setenv("SAM_PAGE_SIZE", "3")
+	t.Setenv("EXCLUDED_NOTICE_TYPES", "")
+
+	sam := samtest.NewServer(t)
+	// Ties on posted date exercise the notice_id tie-breaker across page boundaries
+	for i, daysAgo := range []int{1, 1, 1, 2, 2, 3, 5} {
+		addFixture(sam, fmt.Sprintf("N%02d", 7-i), daysAgo)
+	}
+
+	stats := ingestFixtures(t, pool, sam)
+	if stats.New != 7 || stats.Errors != 0 {
+		t.Fatalf("Expected 7 new and no errors, got %+v", stats)
+	}
+
+	// Re-ingesting unchanged data is detected by the content hash
+	stats = ingestFixtures(t, pool, sam)
+	if stats.New != 0 || stats.Updated != 0 || stats.Skipped != 7 {
+		t.Errorf("Expected all 7 skipped on re-ingest, got %+v", stats)
+	}
+
+	api := newAPI(t, pool)
+	var got []string
+	cursor := ""
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatalf("Pagination did not terminate, got %v so far", got)
+		}
+		query := url.Values{"limit": {"3"}, "all": {"true"}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		var resp struct {
+			Items      []models.Opportunity `json:"items"`
+			NextCursor string               `json:"nextCursor"`
+		}
+		getJSON(t, api, "/opportunities/search?"+query.Encode(), http.StatusOK, &resp)
+		if len(resp.Items) > 3 {
+			t.Errorf("Expected at most 3 items per page, got %d", len(resp.Items))
+		}
+		for _, item := range resp.Items {
+			got = append(got, item.NoticeID)
+		}
+		if resp.NextCursor == "" {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+
+	// posted_date DESC, then notice_id ASC
+	want := "N05,N06,N07,N03,N04,N02,N01"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Expected %s, got %s", want, strings.Join(got, ","))
+	}
+}
+
+func TestSearchV2_FiltersIngestedData(t *testing.T) {
+	pool := openTestDB(t)
+	t.Setenv("EXCLUDED_NOTICE_TYPES", "")
+
+	sam := samtest.NewServer(t)
+	addFixture(sam, "N1", 1)
+	addFixture(sam, "N2", 2)["naics"] = []map[string]any{{"code": "541512", "description": "Computer Systems Design Services"}}
+	ingestFixtures(t, pool, sam)
+
+	api := newAPI(t, pool)
+	var resp struct {
+		Items []models.Opportunity `json:"items"`
+	}
+	getJSON(t, api, "/opportunities/search?all=true&naics=541512", http.StatusOK, &resp)
+	if len(resp.Items) != 1 || resp.Items[0].NoticeID != "N2" {
+		t.Errorf("Expected only N2 for naics=541512, got %+v", resp.Items)
+	}
+
+	var errResp handlers.ErrorResponse
+	getJSON(t, api, "/opportunities/search?postedFrom=not-a-date", http.StatusBadRequest, &errResp)
+	if errResp.Code != handlers.ErrCodeBadRequest {
+		t.Errorf("Expected %s, got %+v", handlers.ErrCodeBadRequest, errResp)
+	}
+}
+
+func TestGetDescription_FetchesFromSAMOnceAndCaches(t *testing.T) {
+	pool := openTestDB(t)
+	t.Setenv("SAM_API_KEY", "desc-key")
+	t.Setenv("EXCLUDED_NOTICE_TYPES", "")
+
+	sam := samtest.NewServer(t)
+	addFixture(sam, "N1", 1)
+	sam.SetDescription("N1", samtest.Description("<p>Scope of work for N1.</p><p>Provide   janitorial&nbsp;services.</p>"))
+	ingestFixtures(t, pool, sam)
+
+	api := newAPI(t, pool)
+	var first models.DescriptionResponse
+	getJSON(t, api, "/opportunities/N1/description", http.StatusOK, &first)
+	if first.Status != "fetched" || first.SourceType != string(models.SourceTypeURL) {
+		t.Fatalf("Expected a fetched url description, got status=%q sourceType=%q", first.Status, first.SourceType)
+	}
+	if first.NormalizedText == nil || !strings.Contains(*first.NormalizedText, "Scope of work for N1.") {
+		t.Errorf("Expected normalized SAM text, got %v", first.NormalizedText)
+	}
+	if first.NormalizedText != nil && strings.Contains(*first.NormalizedText, "<p>") {
+		t.Errorf("Expected HTML to be stripped, got %q", *first.NormalizedText)
+	}
+	if first.NormalizationVersion == nil || *first.NormalizationVersion != services.NORMALIZATION_VERSION {
+		t.Errorf("Expected normalization version %d, got %v", services.NORMALIZATION_VERSION, first.NormalizationVersion)
+	}
+
+	// The stored row answers the second request without going back to SAM
+	var second models.DescriptionResponse
+	getJSON(t, api, "/opportunities/N1/description", http.StatusOK, &second)
+	if second.NormalizedText == nil || first.NormalizedText == nil || *second.NormalizedText != *first.NormalizedText {
+		t.Errorf("Expected the cached description, got %v", second.NormalizedText)
+	}
+	if n := sam.DescriptionFetches("N1"); n != 1 {
+		t.Errorf("Expected 1 SAM description fetch, got %d", n)
+	}
+	for _, req := range sam.Requests() {
+		if req.Path == samtest.DescriptionPath && req.Query.Get("api_key") != "desc-key" {
+			t.Errorf("Expected the description fetch to send SAM_API_KEY, got %q", req.Query.Get("api_key"))
+		}
+	}
+
+	// The materialized status follows the stored description
+	var status string
+	if err := pool.QueryRow(context.Background(),
+		`SELECT description_status FROM opportunity WHERE notice_id = 'N1'`).Scan(&status); err != nil {
+		t.Fatalf("Failed to read description_status: %v", err)
+	}
+	if status != "ready" {
+		t.Errorf("Expected description_status ready, got %q", status)
+	}
+
+	var notFound handlers.ErrorResponse
+	getJSON(t, api, "/opportunities/MISSING/description", http.StatusNotFound, &notFound)
+	if notFound.Code != handlers.ErrCodeNotFound {
+		t.Errorf("Expected %s, got %+v", handlers.ErrCodeNotFound, notFound)
+	}
+}
+
+func TestGetDescription_ReleasesFetchLock(t *testing.T) {
+	pool := openTestDB(t)
+	t.Setenv("EXCLUDED_NOTICE_TYPES", "")
+
+	sam := samtest.NewServer(t)
+	addFixture(sam, "N1", 1)
+	sam.SetDescription("N1", samtest.Description("Scope of work for N1."))
+	ingestFixtures(t, pool, sam)
+
+	// Every refresh takes the fetch lock; one left held by a pooled session would turn the next into a 503
+	api := newAPI(t, pool)
+	const refreshes = 8
+	for i := 0; i < refreshes; i++ {
+		var resp models.DescriptionResponse
+		getJSON(t, api, "/opportunities/N1/description?refresh=true", http.StatusOK, &resp)
+	}
+	if n := sam.DescriptionFetches("N1"); n != refreshes {
+		t.Errorf("Expected %d SAM description fetches, got %d", refreshes, n)
+	}
+
+	lockClass, lockID := db.DescriptionFetchLockKey("N1")
+	var held int
+	if err := pool.QueryRow(context.Background(), `
+		SELECT count(*) FROM pg_locks
+		WHERE locktype = 'advisory' AND classid = $1::int::oid AND objid = $2::int::oid AND objsubid = 2
+	`, lockClass, lockID).Scan(&held); err != nil {
+		t.Fatalf("Failed to read pg_locks: %v", err)
+	}
+	if held != 0 {
+		t.Errorf("Expected the fetch lock released, got %d holders", held)
+	}
+}
+
+func TestGetDescription_StaleRefetchErrorKeepsCachedText(t *testing.T) {
+	pool := openTestDB(t)
+	t.Setenv("EXCLUDED_NOTICE_TYPES", "")
+	t.Setenv("DESC_MAX_AGE", "24h")
+
+	sam := samtest.NewServer(t)
+	addFixture(sam, "N1", 1)
+	// The first fetch succeeds; SAM is down for every one after it
+	sam.SetDescription("N1", samtest.Description("Scope of work for N1."), samtest.Status(http.StatusServiceUnavailable))
+	ingestFixtures(t, pool, sam)
+
+	api := newAPI(t, pool)
+	var first models.DescriptionResponse
+	getJSON(t, api, "/opportunities/N1/description", http.StatusOK, &first)
+	if first.Status != "fetched" || first.NormalizedText == nil {
+		t.Fatalf("Expected a fetched description, got status=%q", first.Status)
+	}
+
+	ctx := context.Background()
+	if _, err := pool.Exec(ctx, `UPDATE opportunity_description SET fetched_at = now() - interval '48 hours' WHERE notice_id = 'N1'`); err != nil {
+		t.Fatalf("Failed to age description: %v", err)
+	}
+
+	// Past DESC_MAX_AGE the re-fetch hits SAM's 503, and the cached text is still served
+	var stale models.DescriptionResponse
+	getJSON(t, api, "/opportunities/N1/description", http.StatusOK, &stale)
+	if n := sam.DescriptionFetches("N1"); n != 2 {
+		t.Errorf("Expected the stale description to be re-fetched, got %d SAM fetches", n)
+	}
+	if stale.Status != "fetched" || stale.NormalizedText == nil || *stale.NormalizedText != *first.NormalizedText {
+		t.Errorf("Expected the cached text served after a failed re-fetch, got status=%q text=%v", stale.Status, stale.NormalizedText)
+	}
+
+	// ... and still stored, with the failed attempt counted
+	var status string
+	var text *string
+	var attempts int
+	var lastError *string
+	if err := pool.QueryRow(ctx, `
+		SELECT fetch_status, text_normalized, fetch_attempts, last_error
+		FROM opportunity_description WHERE notice_id = 'N1'
+	`).Scan(&status, &text, &attempts, &lastError); err != nil {
+		t.Fatalf("Failed to read description: %v", err)
+	}
+	if status != string(models.FetchStatusFetched) || text == nil || *text != *first.NormalizedText {
+		t.Errorf("Expected the stored description kept, got status=%q text=%v", status, text)
+	}
+	if attempts != 1 || lastError == nil {
+		t.Errorf("Expected 1 failed attempt with its error recorded, got %d and %v", attempts, lastError)
+	}
+}
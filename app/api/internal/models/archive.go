@@ -0,0 +1,15 @@
+package models
+
+// IsArchived reports whether opp counts as archived on today (YYYY-MM-DD in the app timezone): SAM marks it
+// inactive, or its archive date is before today. archive_date is compared as text on its first 10 characters,
+// as search's archive filter does.
+func IsArchived(opp Opportunity, today string) bool {
+	if !opp.Active.Bool() {
+		return true
+	}
+	archiveDate := opp.ArchiveDate
+	if len(archiveDate) > 10 {
+		archiveDate = archiveDate[:10]
+	}
+	return archiveDate != "" && archiveDate < today
+}
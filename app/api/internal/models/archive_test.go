@@ -0,0 +1,26 @@
+package models
+
+import "testing"
+
+func TestIsArchived(t *testing.T) {
+	cases := []struct {
+		name     string
+		active   bool
+		archive  string
+		expected bool
+	}{
+		{"inactive", false, "2025-12-31", true},
+		{"inactive without archive date", false, "", true},
+		{"archive date passed", true, "2025-03-14", true},
+		{"archive date passed with time", true, "2025-03-14T17:00:00-04:00", true},
+		{"archive date today", true, "2025-03-15", false},
+		{"archive date ahead", true, "2025-04-01", false},
+		{"no archive date", true, "", false},
+	}
+	for _, c := range cases {
+		opp := Opportunity{Active: FlexibleBool(c.active), ArchiveDate: c.archive}
+		if got := IsArchived(opp, "2025-03-15"); got != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package models
+
+// CacheStats reports an in-memory cache's current size and its lookups since the process started
+// Hits and misses are not reset by a purge, so hit rates stay comparable across purges
+type CacheStats struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	Hits   int64  `json:"hits"`
+	Misses int64  `json:"misses"`
+}
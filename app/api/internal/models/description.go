@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"sort"
+	"time"
+)
 
 // DescriptionSourceType represents the source type of a description
 type DescriptionSourceType string
@@ -9,6 +12,7 @@ const (
 	SourceTypeNone   DescriptionSourceType = "none"
 	SourceTypeInline DescriptionSourceType = "inline"
 	SourceTypeURL    DescriptionSourceType = "url"
+	SourceTypeAttachment DescriptionSourceType = "attachment" // no description text, but the notice links attachments (resourceLinks)
 )
 
 // FetchStatus represents the fetch status of a description
@@ -22,6 +26,7 @@ const (
 )
 
 // AiMeta represents structured metadata extracted from opportunity descriptions
+// Slice fields are sorted and de-duplicated (see Canonicalize), not kept in discovery order
 type AiMeta struct {
 	POCEmails          []string `json:"poc_emails"`
 	POCPhones          []string `json:"poc_phones"`
@@ -33,7 +38,42 @@ type AiMeta struct {
 	QuoteValidityDays  *int     `json:"quote_validity_days,omitempty"`
 	DORated            *bool    `json:"do_rated,omitempty"`
 	RequiresIRPODReview *bool   `json:"requires_irpod_review,omitempty"`
+	DeliveryDaysARO    *int     `json:"delivery_days_aro,omitempty"`      // delivery lead time in days after receipt of order
+	DeliveryDayType    *string  `json:"delivery_day_type,omitempty"`      // "calendar" or "business" days for DeliveryDaysARO
+	PeriodOfPerformance *string `json:"period_of_performance,omitempty"` // e.g. "12 months", or "10/01/2025 to 09/30/2026"
+	QuestionsDueDetected *string `json:"questions_due_detected,omitempty"` // deadline for submitting questions as written, e.g. "Jan 5" (not the response deadline)
+	IsAmendment        *bool   `json:"is_amendment,omitempty"`     // the description is an amendment or modification notice
+	AmendmentNumber    *string `json:"amendment_number,omitempty"` // amendment or modification number as written, e.g. "0002" or "P00001"
 	KeyRequirements    []string `json:"key_requirements"`
+	ClauseNumbers      []string `json:"clause_numbers"` // FAR/DFARS clause numbers, e.g. "52.212-1", "252.204-7012"
+}
+
+// Canonicalize sorts and de-duplicates every slice field so the same findings always serialize to
+// identical ai_meta JSON, whatever order they were discovered in. Slices are copied, not sorted in place.
+func (m *AiMeta) Canonicalize() {
+	m.POCEmails = sortedUnique(m.POCEmails)
+	m.POCPhones = sortedUnique(m.POCPhones)
+	m.ImportantURLs = sortedUnique(m.ImportantURLs)
+	m.ClausesKept = sortedUnique(m.ClausesKept)
+	m.CertsRequired = sortedUnique(m.CertsRequired)
+	m.KeyRequirements = sortedUnique(m.KeyRequirements)
+	m.ClauseNumbers = sortedUnique(m.ClauseNumbers)
+}
+
+// sortedUnique returns a sorted copy of values without duplicates (nil stays nil)
+func sortedUnique(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	out := append([]string{}, values...)
+	sort.Strings(out)
+	unique := out[:0]
+	for i, v := range out {
+		if i == 0 || v != out[i-1] {
+			unique = append(unique, v)
+		}
+	}
+	return unique
 }
 
 // OpportunityDescription represents a description record in the database
@@ -51,6 +91,8 @@ type OpportunityDescription struct {
 	ContentHash        *string             `json:"contentHash,omitempty"`
 	ContentType        *string             `json:"contentType,omitempty"`
 	LastError          *string             `json:"lastError,omitempty"`
+	Oversize           bool                `json:"oversize,omitempty"` // last fetch exceeded a description size limit
+	FetchAttempts      int                 `json:"fetchAttempts,omitempty"` // consecutive failed fetches; maintained by UpsertDescription
 	BriefSummary       *string             `json:"briefSummary,omitempty"`
 	BriefSummaryModel  *string             `json:"briefSummaryModel,omitempty"`
 	BriefSummaryHash   *string             `json:"briefSummaryHash,omitempty"`
@@ -72,7 +114,7 @@ type OpportunityDescription struct {
 type DescriptionResponse struct {
 	NoticeID          string    `json:"noticeId"`
 	Status            string    `json:"status"` // fetched|not_found|none|error
-	SourceType        string    `json:"sourceType"` // url|inline|none
+	SourceType        string    `json:"sourceType"` // url|inline|attachment|none
 	SourceURL         *string   `json:"sourceUrl,omitempty"`
 	RawText           *string   `json:"rawText,omitempty"`
 	RawPostParseText  *string   `json:"rawPostParseText,omitempty"` // raw_text_normalized
@@ -81,5 +123,21 @@ type DescriptionResponse struct {
 	NormalizationVersion *int    `json:"normalizationVersion,omitempty"` // normalization_version
 	FetchedAt         *string   `json:"fetchedAt,omitempty"`
 	LastError         *string   `json:"lastError,omitempty"` // Error message if status is "error"
+	Oversize          bool      `json:"oversize,omitempty"`  // Description too large to display; link to SAM instead
+	FetchAttempts     int       `json:"fetchAttempts,omitempty"` // Consecutive failed fetches if status is "error"
+}
+
+// DescriptionPreviewRequest represents the request body for POST /describe/preview
+type DescriptionPreviewRequest struct {
+	RawText string `json:"rawText"`
 }
 
+// DescriptionPreviewResponse shows what the normalization pipeline produces for ad-hoc text
+// Nothing in it is persisted
+type DescriptionPreviewResponse struct {
+	RawTextNormalized string `json:"rawTextNormalized"`
+	TextNormalized    string `json:"textNormalized"`
+	AIInputText       string `json:"aiInputText"`
+	ExcerptText       string `json:"excerptText"`
+	AIMeta            AiMeta `json:"aiMeta"`
+}
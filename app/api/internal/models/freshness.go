@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Freshness values for Opportunity.Freshness: how an opportunity changed since the caller's last visit
+// (V2 search seenSince)
+const (
+	FreshnessNew       = "new"       // first stored after the visit
+	FreshnessUpdated   = "updated"   // stored before the visit, content changed after it
+	FreshnessUnchanged = "unchanged" // no change since the visit
+)
+
+// ClassifyFreshness compares an opportunity's first_seen and last_updated with seenSince. Only times after
+// seenSince count, so a row stored at the exact moment of the last visit was already seen then. last_updated
+// moves only when ingestion stores a content change, not on every run that sees the notice.
+func ClassifyFreshness(firstSeen, lastUpdated, seenSince time.Time) string {
+	switch {
+	case firstSeen.After(seenSince):
+		return FreshnessNew
+	case lastUpdated.After(seenSince):
+		return FreshnessUpdated
+	default:
+		return FreshnessUnchanged
+	}
+}
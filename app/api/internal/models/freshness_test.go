@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClassifyFreshness(t *testing.T) {
+	visit := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	before := visit.Add(-time.Second)
+	after := visit.Add(time.Second)
+
+	tests := []struct {
+		name                   string
+		firstSeen, lastUpdated time.Time
+		want                   string
+	}{
+		{"stored after the visit", after, after, FreshnessNew},
+		{"stored after, updated later", after, after.Add(time.Hour), FreshnessNew},
+		{"stored at the visit", visit, visit, FreshnessUnchanged},
+		{"stored before, updated after", before, after, FreshnessUpdated},
+		{"stored before, updated at the visit", before, visit, FreshnessUnchanged},
+		{"stored and updated before", before.Add(-time.Hour), before, FreshnessUnchanged},
+	}
+	for _, tt := range tests {
+		if got := ClassifyFreshness(tt.firstSeen, tt.lastUpdated, visit); got != tt.want {
+			t.Errorf("%s: Expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestClassifyFreshness_ComparesInstantsAcrossZones(t *testing.T) {
+	visit := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	// 04:00:01 in New York (UTC-5) is one second after the visit
+	ny := time.FixedZone("EST", -5*60*60)
+	firstSeen := time.Date(2026, 1, 5, 4, 0, 1, 0, ny)
+
+	if got := ClassifyFreshness(firstSeen, firstSeen, visit); got != FreshnessNew {
+		t.Errorf("Expected %q, got %q", FreshnessNew, got)
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job types recorded in job_run
+const (
+	JobTypeIngest               = "ingest"
+	JobTypeBackfillDescriptions = "backfill_descriptions"
+	JobTypeRetryDescErrors      = "retry_desc_errors"
+)
+
+// JobStatus is the state of a job run
+type JobStatus string
+
+const (
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// JobRun represents a row in job_run
+type JobRun struct {
+	ID         int64           `json:"id"`
+	JobType    string          `json:"jobType"`
+	Status     JobStatus       `json:"status"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+	DurationMs *int64          `json:"durationMs,omitempty"` // Set once the run has finished
+	Stats      json.RawMessage `json:"stats,omitempty"`
+	Error      *string         `json:"error,omitempty"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// DecodeJSON unmarshals data into v like json.Unmarshal, except that numbers landing in interface{} values
+// (generic maps, Award, raw SAM payloads) decode as json.Number instead of float64, so integers past 2^53
+// keep every digit when they are read back or re-marshaled. Typed numeric fields decode as usual.
+func DecodeJSON(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	// json.Unmarshal rejects trailing data; keep that behavior
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("invalid character after top-level JSON value")
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"os"
+	"strings"
+)
+
+// ExcludedNoticeTypes returns the SAM notice types to leave out of results (EXCLUDED_NOTICE_TYPES, comma-separated,
+// e.g. "Justification,Award Notice,Sources Sought"), lower-cased with empties and duplicates dropped.
+// Unset means nothing is excluded.
+func ExcludedNoticeTypes() []string {
+	return ParseNoticeTypeList(os.Getenv("EXCLUDED_NOTICE_TYPES"))
+}
+
+// ParseNoticeTypeList splits a comma-separated list of notice types into trimmed, lower-cased, unique values
+func ParseNoticeTypeList(value string) []string {
+	var types []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		noticeType := strings.ToLower(strings.TrimSpace(part))
+		if noticeType == "" || seen[noticeType] {
+			continue
+		}
+		seen[noticeType] = true
+		types = append(types, noticeType)
+	}
+	return types
+}
+
+// IsExcludedNoticeType reports whether opp's type or base type is in excluded (as returned by ParseNoticeTypeList)
+// Both are checked because SAM keeps the original type in baseType when a notice is amended or re-typed
+func IsExcludedNoticeType(opp Opportunity, excluded []string) bool {
+	for _, noticeType := range excluded {
+		if strings.EqualFold(strings.TrimSpace(opp.Type), noticeType) || strings.EqualFold(strings.TrimSpace(opp.BaseType), noticeType) {
+			return true
+		}
+	}
+	return false
+}
+
+// procurementTypeCodes maps SAM notice type names, lower-cased, to SAM's procurement type (ptype) codes
+var procurementTypeCodes = map[string]string{
+	"justification":                              "u",
+	"justification and approval (j&a)":           "u",
+	"presolicitation":                            "p",
+	"award notice":                               "a",
+	"sources sought":                             "r",
+	"special notice":                             "s",
+	"solicitation":                               "o",
+	"sale of surplus property":                   "g",
+	"combined synopsis/solicitation":             "k",
+	"intent to bundle requirements (dod-funded)": "i",
+}
+
+// ProcurementType returns SAM's ptype code for the procurement opp belongs to: that of its base type, which SAM
+// keeps when a notice is amended or re-typed, falling back to its type. Empty when neither is a known notice type.
+// Unlike the notice type, an award notice for a combined synopsis/solicitation keeps procurement type "k".
+func ProcurementType(opp Opportunity) string {
+	for _, noticeType := range []string{opp.BaseType, opp.Type} {
+		if code, ok := procurementTypeCodes[strings.ToLower(strings.TrimSpace(noticeType))]; ok {
+			return code
+		}
+	}
+	return ""
+}
+
+// IsValidProcurementType reports whether code is one of SAM's ptype codes (u, p, a, r, s, o, g, k, i)
+func IsValidProcurementType(code string) bool {
+	for _, known := range procurementTypeCodes {
+		if code == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package models
+
+import "testing"
+
+func TestParseNoticeTypeList(t *testing.T) {
+	got := ParseNoticeTypeList(" Justification, Award Notice,,award notice ,Sources Sought")
+	expected := []string{"justification", "award notice", "sources sought"}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %q at %d, got %q", expected[i], i, got[i])
+		}
+	}
+	if got := ParseNoticeTypeList(""); len(got) != 0 {
+		t.Errorf("Expected nothing excluded for an empty list, got %v", got)
+	}
+}
+
+func TestIsExcludedNoticeType(t *testing.T) {
+	excluded := ParseNoticeTypeList("Award Notice,Justification")
+	cases := []struct {
+		opp      Opportunity
+		expected bool
+	}{
+		{Opportunity{Type: "Solicitation", BaseType: "Solicitation"}, false},
+		{Opportunity{Type: "Award Notice", BaseType: "Award Notice"}, true},
+		{Opportunity{Type: "AWARD NOTICE"}, true},
+		{Opportunity{Type: "Special Notice", BaseType: "Justification"}, true},
+		{Opportunity{}, false},
+	}
+	for _, c := range cases {
+		if got := IsExcludedNoticeType(c.opp, excluded); got != c.expected {
+			t.Errorf("type %q/base %q: expected %v, got %v", c.opp.Type, c.opp.BaseType, c.expected, got)
+		}
+	}
+	if IsExcludedNoticeType(Opportunity{Type: "Award Notice"}, nil) {
+		t.Error("Expected nothing excluded without a list")
+	}
+}
+
+func TestProcurementType(t *testing.T) {
+	cases := []struct {
+		opp      Opportunity
+		expected string
+	}{
+		{Opportunity{Type: "Combined Synopsis/Solicitation", BaseType: "Combined Synopsis/Solicitation"}, "k"},
+		{Opportunity{Type: "Presolicitation", BaseType: "Presolicitation"}, "p"},
+		// An award notice keeps the procurement type of the notice it awards
+		{Opportunity{Type: "Award Notice", BaseType: "Combined Synopsis/Solicitation"}, "k"},
+		{Opportunity{Type: " sources sought "}, "r"},
+		{Opportunity{Type: "Solicitation", BaseType: "Something New"}, "o"},
+		{Opportunity{Type: "Something New"}, ""},
+	}
+	for _, c := range cases {
+		if got := ProcurementType(c.opp); got != c.expected {
+			t.Errorf("type %q/base %q: expected %q, got %q", c.opp.Type, c.opp.BaseType, c.expected, got)
+		}
+	}
+	if !IsValidProcurementType("k") || IsValidProcurementType("x") || IsValidProcurementType("") {
+		t.Error("Expected only SAM ptype codes to be valid")
+	}
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"strings"
+	"time"
 )
 
 // FlexibleBool handles both string and bool JSON values
@@ -58,7 +59,7 @@ func (fs *FlexibleString) UnmarshalJSON(data []byte) error {
 
 	// If not a string, try to unmarshal as an object and extract a value
 	var obj map[string]interface{}
-	if err := json.Unmarshal(data, &obj); err == nil {
+	if err := DecodeJSON(data, &obj); err == nil {
 		// Try common field names that might contain the actual value
 		commonFields := []string{"value", "code", "name", "description", "text", "label"}
 		for _, field := range commonFields {
@@ -99,13 +100,13 @@ type Opportunity struct {
 	ArchiveDate       string `json:"archiveDate"`
 	TypeOfSetAside    string `json:"typeOfSetAside"`
 	TypeOfSetAsideDesc string `json:"typeOfSetAsideDesc"`
-	TypeOfSetAsideDescription string `json:"typeOfSetAsideDescription,omitempty"`
+	TypeOfSetAsideDescription *string `json:"typeOfSetAsideDescription,omitempty"`
 	ResponseDeadline  string `json:"responseDeadline"`
 	NAICS             []struct {
 		Code        string `json:"code"`
 		Description string `json:"description"`
 	} `json:"naics"`
-	NAICSCode         string   `json:"naicsCode,omitempty"`
+	NAICSCode         *string  `json:"naicsCode,omitempty"`
 	NAICSCodes        []string `json:"naicsCodes,omitempty"`
 	ClassificationCode string `json:"classificationCode"`
 	Active             FlexibleBool `json:"active"`
@@ -136,12 +137,13 @@ type Opportunity struct {
 	Department         string `json:"department"`
 	SubTier            string `json:"subTier"`
 	Office            string `json:"office"`
-	SolicitationNumber string `json:"solicitationNumber,omitempty"`
-	FullParentPathName string `json:"fullParentPathName,omitempty"`
-	FullParentPathCode string `json:"fullParentPathCode,omitempty"`
-	AgencyPathName     string `json:"agencyPathName,omitempty"`
+	SolicitationNumber *string `json:"solicitationNumber,omitempty"` // nil when absent, "" when SAM sent an empty value
+	FullParentPathName *string `json:"fullParentPathName,omitempty"`
+	FullParentPathCode *string `json:"fullParentPathCode,omitempty"`
+	AgencyPathName     *string `json:"agencyPathName,omitempty"`
 	AdditionalInfoLink *string `json:"additionalInfoLink,omitempty"`
-	UILink             string `json:"uiLink,omitempty"`
+	UILink             *string `json:"uiLink,omitempty"`
+	SAMUIURL           *string `json:"samUiUrl,omitempty"` // "View on SAM.gov" link (opportunity.sam_ui_url, see SAMUIURL); nil for rows not yet backfilled
 	Links              []struct {
 		Rel  string `json:"rel"`
 		Href string `json:"href"`
@@ -149,6 +151,27 @@ type Opportunity struct {
 	} `json:"links"`
 	ResourceLinks      []string `json:"resourceLinks,omitempty"`
 	DescriptionStatus string `json:"descriptionStatus,omitempty"` // none | ready | not_found | error | available_unfetched
+	TitleSynthesized   bool `json:"titleSynthesized,omitempty"` // Detail endpoint only: SAM sent no title, Title is a placeholder
+	Outcome            *OpportunityOutcome `json:"outcome,omitempty"` // Detail endpoint only
+	Tags               []string `json:"tags,omitempty"` // Detail endpoint only
+	Relevance          *RelevanceExplanation `json:"relevance,omitempty"` // Search with explainRelevance=true only
+	Snippet            string `json:"snippet,omitempty"` // Search with highlight=true only: escaped description excerpt, matches marked per highlightMarkup
+	FirstSeen          *time.Time `json:"firstSeen,omitempty"`   // V2 search only: when ingestion first stored the notice (opportunity.first_seen)
+	LastUpdated        *time.Time `json:"lastUpdated,omitempty"` // V2 search only: when ingestion last stored a content change (opportunity.last_updated)
+	Freshness          string `json:"freshness,omitempty"` // V2 search with seenSince only: new | updated | unchanged (see ClassifyFreshness)
+	Provenance         map[string]string `json:"provenance,omitempty"` // Detail endpoint with include=provenance only: field -> ProvenanceSAM or ProvenanceDerived
+}
+
+// Provenance values: whether a field we may enrich holds SAM's value or one we filled in or rewrote
+const (
+	ProvenanceSAM     = "sam"
+	ProvenanceDerived = "derived"
+)
+
+// RelevanceExplanation says why a search result ranked where it did under the relevance sort
+type RelevanceExplanation struct {
+	Score   float64             `json:"score"`   // the value results are ordered by: ts_rank, times the recency decay when boosted
+	Matches map[string][]string `json:"matches"` // searched field -> query terms (stemmed) it contains; fields without matches omitted
 }
 
 // OpportunitiesResponse represents the SAM.gov API response
@@ -166,3 +189,137 @@ type OpportunitiesRequest struct {
 	PType      string `json:"ptype"`
 }
 
+
+// apiDateLayouts are the stored date formats we accept, with whether each carries a time of day
+// Stored values come straight from SAM (ISO) or from file imports (MM/DD/YYYY)
+var apiDateLayouts = []struct {
+	layout  string
+	hasTime bool
+}{
+	{time.RFC3339, true},
+	{"2006-01-02T15:04:05-0700", true},
+	{"2006-01-02T15:04:05", true},
+	{"2006-01-02 15:04:05", true},
+	{"2006-01-02", false},
+	{"01/02/2006", false},
+}
+
+// FormatAPIDate converts a stored date string to the single format the API returns:
+// RFC3339 full-date (YYYY-MM-DD) for dates, RFC3339 timestamps for values with a time of day
+// (UTC when the stored value had no offset). Empty or unparseable values are returned unchanged.
+func FormatAPIDate(s string) string {
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	for _, l := range apiDateLayouts {
+		t, err := time.Parse(l.layout, trimmed)
+		if err != nil {
+			continue
+		}
+		if !l.hasTime {
+			return t.Format("2006-01-02")
+		}
+		return t.Format(time.RFC3339)
+	}
+	return s
+}
+
+// opportunityJSON has Opportunity's fields without its MarshalJSON, to avoid recursion
+type opportunityJSON Opportunity
+
+// opportunityResponse is the API shape of an Opportunity. Its fields shadow the embedded
+// opportunityJSON fields of the same JSON name (shallower fields win in encoding/json),
+// so the API can omit empty values without changing the raw storage format.
+type opportunityResponse struct {
+	opportunityJSON
+	OrganizationType   string      `json:"organizationType,omitempty"`
+	BaseType           string      `json:"baseType,omitempty"`
+	ArchiveType        string      `json:"archiveType,omitempty"`
+	ArchiveDate        string      `json:"archiveDate,omitempty"`
+	TypeOfSetAside     string      `json:"typeOfSetAside,omitempty"`
+	TypeOfSetAsideDesc string      `json:"typeOfSetAsideDesc,omitempty"`
+	ResponseDeadline   string      `json:"responseDeadline,omitempty"`
+	ClassificationCode string      `json:"classificationCode,omitempty"`
+	Description        string      `json:"description,omitempty"`
+	Department         string      `json:"department,omitempty"`
+	SubTier            string      `json:"subTier,omitempty"`
+	Office             string      `json:"office,omitempty"`
+	NAICS              interface{} `json:"naics"`          // always an array, never null
+	PointOfContact     interface{} `json:"pointOfContact"` // always an array, never null
+	Links              interface{} `json:"links"`          // always an array, never null
+	PlaceOfPerformance interface{} `json:"placeOfPerformance,omitempty"`
+	OfficeAddress      interface{} `json:"officeAddress,omitempty"`
+}
+
+// MarshalJSON serializes the API response shape: dates in API format (see FormatAPIDate) so clients
+// parse one format, empty scalars and empty nested objects omitted, and arrays as [] rather than null.
+// noticeId, title, postedDate, type and active are always present.
+func (o Opportunity) MarshalJSON() ([]byte, error) {
+	out := opportunityResponse{
+		opportunityJSON:    opportunityJSON(o),
+		OrganizationType:   o.OrganizationType,
+		BaseType:           o.BaseType,
+		ArchiveType:        o.ArchiveType,
+		ArchiveDate:        FormatAPIDate(o.ArchiveDate),
+		TypeOfSetAside:     o.TypeOfSetAside,
+		TypeOfSetAsideDesc: o.TypeOfSetAsideDesc,
+		ResponseDeadline:   FormatAPIDate(o.ResponseDeadline),
+		ClassificationCode: o.ClassificationCode,
+		Description:        o.Description,
+		Department:         o.Department,
+		SubTier:            o.SubTier,
+		Office:             o.Office,
+		NAICS:              []struct{}{},
+		PointOfContact:     []struct{}{},
+		Links:              []struct{}{},
+	}
+	out.opportunityJSON.PostedDate = FormatAPIDate(o.PostedDate)
+	if len(o.NAICS) > 0 {
+		out.NAICS = o.NAICS
+	}
+	if len(o.PointOfContact) > 0 {
+		out.PointOfContact = o.PointOfContact
+	}
+	if len(o.Links) > 0 {
+		out.Links = o.Links
+	}
+	if !o.placeOfPerformanceEmpty() {
+		out.PlaceOfPerformance = o.PlaceOfPerformance
+	}
+	if o.OfficeAddress.Zipcode != "" || o.OfficeAddress.City != "" || o.OfficeAddress.CountryCode != "" || o.OfficeAddress.State != "" {
+		out.OfficeAddress = o.OfficeAddress
+	}
+	return json.Marshal(out)
+}
+
+// placeOfPerformanceEmpty reports whether no place of performance field carries a value
+func (o Opportunity) placeOfPerformanceEmpty() bool {
+	p := o.PlaceOfPerformance
+	return p.StreetAddress == "" && p.Zip == "" &&
+		isEmptyJSONValue(p.City) && isEmptyJSONValue(p.State) && isEmptyJSONValue(p.Country)
+}
+
+// isEmptyJSONValue reports whether a decoded string-or-object value is nil, blank, or an object of blanks
+func isEmptyJSONValue(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case map[string]interface{}:
+		for _, field := range val {
+			if !isEmptyJSONValue(field) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// MarshalRaw serializes the opportunity exactly as received, for opportunity_raw and version snapshots
+func (o Opportunity) MarshalRaw() ([]byte, error) {
+	return json.Marshal(opportunityJSON(o))
+}
@@ -0,0 +1,320 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFormatAPIDate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"01/15/2025", "2025-01-15"},
+		{"2025-01-15", "2025-01-15"},
+		{"2025-02-01T17:00:00-05:00", "2025-02-01T17:00:00-05:00"},
+		{"2025-02-01T17:00:00-0500", "2025-02-01T17:00:00-05:00"},
+		{"2025-02-01T17:00:00", "2025-02-01T17:00:00Z"},
+		{"", ""},
+		{"TBD", "TBD"},
+	}
+	for _, tt := range tests {
+		if got := FormatAPIDate(tt.in); got != tt.want {
+			t.Errorf("FormatAPIDate(%q): Expected %q, got %q", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestOpportunityMarshalJSON_IngestionFormatsSerializeIdentically(t *testing.T) {
+	fromFile := Opportunity{NoticeID: "N1", PostedDate: "01/15/2025", ArchiveDate: "03/01/2025"}
+	fromSAM := Opportunity{NoticeID: "N1", PostedDate: "2025-01-15", ArchiveDate: "2025-03-01"}
+
+	a, err := json.Marshal(fromFile)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b, err := json.Marshal(fromSAM)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(a) != string(b) {
+		t.Errorf("Expected identical JSON, got %s and %s", a, b)
+	}
+
+	var out map[string]interface{}
+	json.Unmarshal(a, &out)
+	if out["postedDate"] != "2025-01-15" {
+		t.Errorf("Expected postedDate %q, got %v", "2025-01-15", out["postedDate"])
+	}
+}
+
+func TestOpportunityMarshalRaw_PreservesStoredFormat(t *testing.T) {
+	opp := Opportunity{NoticeID: "N1", PostedDate: "01/15/2025"}
+	data, err := opp.MarshalRaw()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+	if out["postedDate"] != "01/15/2025" {
+		t.Errorf("Expected raw postedDate %q, got %v", "01/15/2025", out["postedDate"])
+	}
+}
+
+func TestOpportunityMarshalJSON_EmptyOpportunityShape(t *testing.T) {
+	data, err := json.Marshal(Opportunity{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, key := range []string{"noticeId", "title", "postedDate", "type", "active"} {
+		if _, ok := out[key]; !ok {
+			t.Errorf("Expected %q to be present, got %s", key, data)
+		}
+	}
+	for _, key := range []string{"naics", "pointOfContact", "links"} {
+		arr, ok := out[key].([]interface{})
+		if !ok || len(arr) != 0 {
+			t.Errorf("Expected %q to be an empty array, got %v", key, out[key])
+		}
+	}
+	for _, key := range []string{
+		"organizationType", "baseType", "archiveType", "archiveDate", "typeOfSetAside", "typeOfSetAsideDesc",
+		"responseDeadline", "classificationCode", "description", "department", "subTier", "office",
+		"placeOfPerformance", "officeAddress", "award", "tags", "outcome",
+	} {
+		if v, ok := out[key]; ok {
+			t.Errorf("Expected %q to be omitted, got %v", key, v)
+		}
+	}
+}
+
+func TestOpportunityMarshalJSON_PopulatedNestedFields(t *testing.T) {
+	var opp Opportunity
+	raw := `{
+		"noticeId": "N1",
+		"baseType": "Solicitation",
+		"naics": [{"code": "541511", "description": "Custom Computer Programming"}],
+		"pointOfContact": [{"fullName": "Jane Doe", "email": "jane@example.gov"}],
+		"placeOfPerformance": {"city": {"code": "", "name": ""}, "state": {"code": "VA", "name": "Virginia"}},
+		"officeAddress": {"city": "Arlington"}
+	}`
+	if err := json.Unmarshal([]byte(raw), &opp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	data, err := json.Marshal(opp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+
+	if out["baseType"] != "Solicitation" {
+		t.Errorf("Expected baseType %q, got %v", "Solicitation", out["baseType"])
+	}
+	if naics, _ := out["naics"].([]interface{}); len(naics) != 1 {
+		t.Errorf("Expected 1 naics entry, got %v", out["naics"])
+	}
+	if contacts, _ := out["pointOfContact"].([]interface{}); len(contacts) != 1 {
+		t.Errorf("Expected 1 pointOfContact entry, got %v", out["pointOfContact"])
+	}
+	place, ok := out["placeOfPerformance"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected placeOfPerformance to be present, got %s", data)
+	}
+	if state, _ := place["state"].(map[string]interface{}); state["code"] != "VA" {
+		t.Errorf("Expected state code %q, got %v", "VA", place["state"])
+	}
+	if office, _ := out["officeAddress"].(map[string]interface{}); office["city"] != "Arlington" {
+		t.Errorf("Expected officeAddress city %q, got %v", "Arlington", out["officeAddress"])
+	}
+}
+
+func TestOpportunityMarshalJSON_BlankPlaceOfPerformanceOmitted(t *testing.T) {
+	var opp Opportunity
+	json.Unmarshal([]byte(`{"noticeId": "N1", "placeOfPerformance": {"city": {"code": "", "name": ""}, "zip": ""}}`), &opp)
+	data, _ := json.Marshal(opp)
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+	if v, ok := out["placeOfPerformance"]; ok {
+		t.Errorf("Expected blank placeOfPerformance to be omitted, got %v", v)
+	}
+}
+
+func TestOpportunityMarshalRaw_KeepsEmptyFields(t *testing.T) {
+	data, err := Opportunity{NoticeID: "N1"}.MarshalRaw()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out map[string]interface{}
+	json.Unmarshal(data, &out)
+	if _, ok := out["baseType"]; !ok {
+		t.Errorf("Expected raw JSON to keep empty baseType, got %s", data)
+	}
+}
+
+func TestOpportunityMarshalJSON_OptionalFieldsNullVsEmpty(t *testing.T) {
+	var opp Opportunity
+	raw := `{"noticeId": "N1", "solicitationNumber": "", "naicsCode": null, "uiLink": "https://sam.gov/opp/N1/view"}`
+	if err := json.Unmarshal([]byte(raw), &opp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if opp.SolicitationNumber == nil || *opp.SolicitationNumber != "" {
+		t.Errorf("Expected empty solicitationNumber to decode as \"\", got %v", opp.SolicitationNumber)
+	}
+	if opp.NAICSCode != nil {
+		t.Errorf("Expected null naicsCode to decode as nil, got %q", *opp.NAICSCode)
+	}
+
+	for name, marshal := range map[string]func() ([]byte, error){
+		"MarshalJSON": opp.MarshalJSON,
+		"MarshalRaw":  opp.MarshalRaw,
+	} {
+		data, err := marshal()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		var out map[string]interface{}
+		json.Unmarshal(data, &out)
+
+		if v, ok := out["solicitationNumber"]; !ok || v != "" {
+			t.Errorf("%s: expected solicitationNumber to be \"\", got %v (present=%v)", name, v, ok)
+		}
+		if out["uiLink"] != "https://sam.gov/opp/N1/view" {
+			t.Errorf("%s: expected uiLink to be kept, got %v", name, out["uiLink"])
+		}
+		for _, key := range []string{"naicsCode", "agencyPathName", "fullParentPathName", "fullParentPathCode", "typeOfSetAsideDescription"} {
+			if v, ok := out[key]; ok {
+				t.Errorf("%s: expected absent %q to be omitted, got %v", name, key, v)
+			}
+		}
+	}
+}
+
+func TestAiMetaCanonicalize_SortsAndDedupesCopies(t *testing.T) {
+	emails := []string{"b@x.gov", "a@x.gov", "b@x.gov"}
+	meta := AiMeta{POCEmails: emails, KeyRequirements: []string{}}
+	meta.Canonicalize()
+
+	if strings.Join(meta.POCEmails, ",") != "a@x.gov,b@x.gov" {
+		t.Errorf("Expected sorted, de-duplicated emails, got %v", meta.POCEmails)
+	}
+	if emails[0] != "b@x.gov" {
+		t.Errorf("Expected input slice to be left untouched, got %v", emails)
+	}
+	if meta.POCPhones != nil || meta.KeyRequirements == nil {
+		t.Errorf("Expected nil and empty slices to keep their JSON shape")
+	}
+}
+
+func TestDecodeJSON_LargeIntegerRoundTrip(t *testing.T) {
+	raw := []byte(`{"noticeId":"N1","award":{"amount":12345678901234567890,"awardee":{"ueiSAM":"X"}}}`)
+
+	var opp Opportunity
+	if err := DecodeJSON(raw, &opp); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out, err := opp.MarshalRaw()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(out), `"amount":12345678901234567890`) {
+		t.Errorf("Expected award amount to keep every digit, got %s", out)
+	}
+
+	// Plain json.Unmarshal is what lost the digits
+	var lossy Opportunity
+	if err := json.Unmarshal(raw, &lossy); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if out, _ := lossy.MarshalRaw(); strings.Contains(string(out), "12345678901234567890") {
+		t.Errorf("Expected float64 decoding to round the amount, got %s", out)
+	}
+}
+
+func TestDecodeJSON_RejectsTrailingData(t *testing.T) {
+	var v map[string]interface{}
+	if err := DecodeJSON([]byte(`{"a":1} {"b":2}`), &v); err == nil {
+		t.Error("Expected error for trailing data")
+	}
+	if err := DecodeJSON([]byte(" {\"a\":1}\n "), &v); err != nil {
+		t.Errorf("Expected surrounding whitespace to be accepted, got %v", err)
+	}
+}
+
+func TestFlexibleString_ObjectKeepsLargeInteger(t *testing.T) {
+	var fs FlexibleString
+	if err := json.Unmarshal([]byte(`{"id":9007199254740993}`), &fs); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(fs) != `{"id":9007199254740993}` {
+		t.Errorf("Expected %q, got %q", `{"id":9007199254740993}`, fs)
+	}
+}
+
+func TestDecodePlaceOfPerformance_Shapes(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"object", `{"city": {"code": "03000", "name": "Arlington"}, "state": {"code": "VA", "name": "Virginia"}}`},
+		{"stringified object", `"{\"city\":{\"code\":\"03000\",\"name\":\"Arlington\"},\"state\":{\"code\":\"VA\",\"name\":\"Virginia\"}}"`},
+		{"stringified fields", `{"city": "{\"code\":\"03000\",\"name\":\"Arlington\"}", "state": "{\"code\":\"VA\",\"name\":\"Virginia\"}"}`},
+	}
+	for _, tt := range tests {
+		var opp Opportunity
+		if err := DecodePlaceOfPerformance([]byte(tt.data), &opp); err != nil {
+			t.Errorf("%s: Unexpected error: %v", tt.name, err)
+			continue
+		}
+		state, _ := opp.PlaceOfPerformance.State.(map[string]interface{})
+		city, _ := opp.PlaceOfPerformance.City.(map[string]interface{})
+		if state["code"] != "VA" || state["name"] != "Virginia" || city["name"] != "Arlington" {
+			t.Errorf("%s: Expected Arlington, VA as objects, got city %v state %v", tt.name, opp.PlaceOfPerformance.City, opp.PlaceOfPerformance.State)
+		}
+	}
+}
+
+func TestDecodePlaceOfPerformance_NullAndEmpty(t *testing.T) {
+	for _, data := range []string{``, `null`, `""`, `"null"`} {
+		var opp Opportunity
+		if err := DecodePlaceOfPerformance([]byte(data), &opp); err != nil {
+			t.Errorf("%q: Unexpected error: %v", data, err)
+		}
+		if !opp.placeOfPerformanceEmpty() {
+			t.Errorf("%q: Expected an empty place of performance, got %+v", data, opp.PlaceOfPerformance)
+		}
+	}
+
+	var opp Opportunity
+	if err := DecodePlaceOfPerformance([]byte(`"Arlington, VA"`), &opp); err == nil {
+		t.Error("Expected an error for a string that isn't an object")
+	}
+}
+
+func TestPlaceOfPerformanceJSON_Canonical(t *testing.T) {
+	var opp Opportunity
+	json.Unmarshal([]byte(`{"placeOfPerformance": {"city": "Arlington", "state": "VA", "country": " ", "zip": "22201"}}`), &opp)
+	data, err := opp.PlaceOfPerformanceJSON()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{"streetAddress":"","city":{"name":"Arlington"},"state":{"code":"VA"},"zip":"22201","country":null}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+	// The opportunity itself keeps SAM's values for the raw snapshot and content hash
+	if opp.PlaceOfPerformance.State != "VA" {
+		t.Errorf("Expected the original state to be untouched, got %v", opp.PlaceOfPerformance.State)
+	}
+
+	var blank Opportunity
+	if data, err := blank.PlaceOfPerformanceJSON(); err != nil || data != nil {
+		t.Errorf("Expected nil for a blank place of performance, got %s (err %v)", data, err)
+	}
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// OutcomeStatus represents what happened with an opportunity
+type OutcomeStatus string
+
+const (
+	OutcomeStatusWon   OutcomeStatus = "won"
+	OutcomeStatusLost  OutcomeStatus = "lost"
+	OutcomeStatusNoBid OutcomeStatus = "no_bid"
+)
+
+// Valid reports whether s is a known outcome status
+func (s OutcomeStatus) Valid() bool {
+	switch s {
+	case OutcomeStatusWon, OutcomeStatusLost, OutcomeStatusNoBid:
+		return true
+	}
+	return false
+}
+
+// OpportunityOutcome represents a row in opportunity_outcome
+type OpportunityOutcome struct {
+	NoticeID    string        `json:"noticeId"`
+	Status      OutcomeStatus `json:"status"`
+	Awardee     *string       `json:"awardee,omitempty"`
+	AwardAmount *float64      `json:"awardAmount,omitempty"`
+	Note        *string       `json:"note,omitempty"`
+	UpdatedAt   time.Time     `json:"updatedAt"`
+}
+
+// OutcomeRequest represents the request body for PUT /opportunities/:noticeId/outcome
+type OutcomeRequest struct {
+	Status      OutcomeStatus `json:"status"`
+	Awardee     *string       `json:"awardee"`
+	AwardAmount *float64      `json:"awardAmount"`
+	Note        *string       `json:"note"`
+}
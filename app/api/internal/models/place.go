@@ -0,0 +1,69 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Place of performance city, state and country are stored in SAM's object shape, {"code": ..., "name": ...}.
+// SAM sends plain strings for some notices, and rows written before the model decoded these fields as objects
+// hold the object re-encoded as a JSON string; CanonicalizePlaceOfPerformance folds both into the object shape
+// so the state filter (and clients) see one shape.
+
+// canonicalPlaceValue returns v in object shape: a stringified object is decoded, a plain string becomes
+// {plainKey: value}, and a blank string becomes nil. Objects and other values are returned as-is.
+func canonicalPlaceValue(v interface{}, plainKey string) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if strings.HasPrefix(s, "{") {
+		var obj map[string]interface{}
+		if err := DecodeJSON([]byte(s), &obj); err == nil {
+			return obj
+		}
+	}
+	return map[string]interface{}{plainKey: s}
+}
+
+// CanonicalizePlaceOfPerformance rewrites the place of performance city, state and country into object shape.
+// State and country strings are codes ("VA", "USA"); city strings are names.
+func (o *Opportunity) CanonicalizePlaceOfPerformance() {
+	p := &o.PlaceOfPerformance
+	p.City = canonicalPlaceValue(p.City, "name")
+	p.State = canonicalPlaceValue(p.State, "code")
+	p.Country = canonicalPlaceValue(p.Country, "code")
+}
+
+// PlaceOfPerformanceJSON returns the place of performance to store in opportunity.place_of_performance, in
+// canonical shape, or nil (SQL NULL) when it carries no value
+func (o Opportunity) PlaceOfPerformanceJSON() ([]byte, error) {
+	o.CanonicalizePlaceOfPerformance()
+	if o.placeOfPerformanceEmpty() {
+		return nil, nil
+	}
+	return json.Marshal(o.PlaceOfPerformance)
+}
+
+// DecodePlaceOfPerformance decodes a stored place_of_performance value into o in canonical shape. Besides an
+// object it accepts the legacy shapes: an object re-encoded as a JSON string, and null or empty (left unset).
+func DecodePlaceOfPerformance(data []byte, o *Opportunity) error {
+	data = bytes.TrimSpace(data)
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		data = []byte(strings.TrimSpace(s))
+	}
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	if err := DecodeJSON(data, &o.PlaceOfPerformance); err != nil {
+		return err
+	}
+	o.CanonicalizePlaceOfPerformance()
+	return nil
+}
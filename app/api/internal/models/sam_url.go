@@ -0,0 +1,41 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SAMOpportunityURL is SAM.gov's public page for a notice, e.g. https://sam.gov/opp/<noticeId>/view
+func SAMOpportunityURL(noticeID string) string {
+	noticeID = strings.TrimSpace(noticeID)
+	if noticeID == "" {
+		return ""
+	}
+	return "https://sam.gov/opp/" + url.PathEscape(noticeID) + "/view"
+}
+
+// IsSAMUIURL reports whether s is an https link into the SAM.gov site (sam.gov or www.sam.gov). The API hosts
+// (api.sam.gov) and lookalikes such as sam.gov.example.com don't count.
+func IsSAMUIURL(s string) bool {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return (host == "sam.gov" || host == "www.sam.gov") && u.Port() == ""
+}
+
+// SAMUIURL returns the "view on SAM.gov" link to store for opp: SAM's uiLink, or failing that a links entry
+// that points at the SAM.gov site (not the API's self link), when it is one (IsSAMUIURL); otherwise the page
+// derived from the notice ID (SAMOpportunityURL). It is "" only when the notice ID is blank.
+func SAMUIURL(opp Opportunity) string {
+	if opp.UILink != nil && IsSAMUIURL(*opp.UILink) {
+		return strings.TrimSpace(*opp.UILink)
+	}
+	for _, link := range opp.Links {
+		if !strings.EqualFold(link.Rel, "self") && IsSAMUIURL(link.Href) {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return SAMOpportunityURL(opp.NoticeID)
+}
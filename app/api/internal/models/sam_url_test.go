@@ -0,0 +1,63 @@
+package models
+
+import "testing"
+
+func TestSAMOpportunityURL(t *testing.T) {
+	cases := map[string]string{
+		"a1b2c3d4e5f60718293a4b5c6d7e8f90": "https://sam.gov/opp/a1b2c3d4e5f60718293a4b5c6d7e8f90/view",
+		" N001 ":                           "https://sam.gov/opp/N001/view",
+		"A/B C":                            "https://sam.gov/opp/A%2FB%20C/view",
+		"":                                 "",
+	}
+	for noticeID, expected := range cases {
+		if got := SAMOpportunityURL(noticeID); got != expected {
+			t.Errorf("SAMOpportunityURL(%q): expected %q, got %q", noticeID, expected, got)
+		}
+	}
+}
+
+func TestIsSAMUIURL(t *testing.T) {
+	cases := map[string]bool{
+		"https://sam.gov/opp/N001/view":                     true,
+		"https://www.sam.gov/opp/N001/view":                 true,
+		"https://SAM.gov/opp/N001/view":                     true,
+		"http://sam.gov/opp/N001/view":                      false,
+		"https://api.sam.gov/prod/opportunities/v2/search":  false,
+		"https://sam.gov.example.com/opp/N001/view":         false,
+		"https://evil.example.com/?u=https://sam.gov/opp/1": false,
+		"https://user@sam.gov/opp/N001/view":                false,
+		"https://sam.gov:8443/opp/N001/view":                false,
+		"null":                                              false,
+		"":                                                  false,
+	}
+	for link, expected := range cases {
+		if got := IsSAMUIURL(link); got != expected {
+			t.Errorf("IsSAMUIURL(%q): expected %v, got %v", link, expected, got)
+		}
+	}
+}
+
+func TestSAMUIURL(t *testing.T) {
+	uiLink := "https://sam.gov/opp/N001/view"
+	bad := "https://example.com/opp/N001/view"
+
+	opp := Opportunity{NoticeID: "N001", UILink: &uiLink}
+	if got := SAMUIURL(opp); got != uiLink {
+		t.Errorf("Expected SAM's uiLink, got %q", got)
+	}
+
+	// A uiLink off sam.gov is ignored; the API self link is not a UI link either
+	opp = Opportunity{NoticeID: "N002", UILink: &bad}
+	opp.Links = append(opp.Links, struct {
+		Rel  string `json:"rel"`
+		Href string `json:"href"`
+		Type string `json:"type"`
+	}{Rel: "self", Href: "https://api.sam.gov/prod/opportunities/v2/search?noticeid=N002"})
+	if got := SAMUIURL(opp); got != "https://sam.gov/opp/N002/view" {
+		t.Errorf("Expected the deep link derived from the notice ID, got %q", got)
+	}
+
+	if got := SAMUIURL(Opportunity{}); got != "" {
+		t.Errorf("Expected no link without a notice ID, got %q", got)
+	}
+}
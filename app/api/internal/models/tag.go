@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// OpportunityTag represents a row in opportunity_tag
+type OpportunityTag struct {
+	NoticeID  string    `json:"noticeId"`
+	Tag       string    `json:"tag"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TagRequest represents the request body for POST /opportunities/:noticeId/tags
+type TagRequest struct {
+	Tag string `json:"tag"`
+}
+
+// BulkTagRequest represents the request body for POST /tags/bulk
+type BulkTagRequest struct {
+	Tag       string   `json:"tag"`
+	NoticeIDs []string `json:"noticeIds"`
+}
+
+// BulkTagResult is one notice's outcome in a bulk tag; Error is set when Success is false
+type BulkTagResult struct {
+	NoticeID string `json:"noticeId"`
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
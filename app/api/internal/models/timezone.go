@@ -0,0 +1,33 @@
+package models
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+	_ "time/tzdata" // Zone database fallback so APP_TIMEZONE loads on hosts without tzdata
+)
+
+// DefaultAppTimezone is the zone dates are interpreted in when APP_TIMEZONE is unset; SAM publishes in Eastern time
+const DefaultAppTimezone = "America/New_York"
+
+// AppLocation returns the timezone used to resolve "today", relative dates and date-only deadlines, so behavior
+// doesn't depend on the host's TZ. It reads APP_TIMEZONE (an IANA name), then the older DEADLINE_TIMEZONE,
+// then DefaultAppTimezone, falling back to UTC if the zone can't be loaded.
+func AppLocation() *time.Location {
+	name := strings.TrimSpace(os.Getenv("APP_TIMEZONE"))
+	source := "APP_TIMEZONE"
+	if name == "" {
+		name = strings.TrimSpace(os.Getenv("DEADLINE_TIMEZONE"))
+		source = "DEADLINE_TIMEZONE"
+	}
+	if name == "" {
+		name = DefaultAppTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("⚠️  Unknown %s %q, using UTC: %v", source, name, err)
+		return time.UTC
+	}
+	return loc
+}
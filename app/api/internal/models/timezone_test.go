@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAppLocation(t *testing.T) {
+	t.Setenv("APP_TIMEZONE", "")
+	t.Setenv("DEADLINE_TIMEZONE", "")
+	if loc := AppLocation(); loc.String() != DefaultAppTimezone {
+		t.Errorf("Expected %s by default, got %s", DefaultAppTimezone, loc)
+	}
+
+	t.Setenv("DEADLINE_TIMEZONE", "America/Chicago")
+	if loc := AppLocation(); loc.String() != "America/Chicago" {
+		t.Errorf("Expected DEADLINE_TIMEZONE fallback, got %s", loc)
+	}
+
+	t.Setenv("APP_TIMEZONE", "Asia/Tokyo")
+	if loc := AppLocation(); loc.String() != "Asia/Tokyo" {
+		t.Errorf("Expected APP_TIMEZONE to win, got %s", loc)
+	}
+
+	t.Setenv("APP_TIMEZONE", "Not/AZone")
+	if loc := AppLocation(); loc != time.UTC {
+		t.Errorf("Expected UTC fallback for unknown zone, got %s", loc)
+	}
+}
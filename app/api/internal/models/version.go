@@ -0,0 +1,16 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OpportunityVersion represents a row in opportunity_version: the raw SAM record as of a detected change
+// The snapshot itself is left out of listings; it can be large and most callers only need the timeline
+type OpportunityVersion struct {
+	ID            int             `json:"id"`
+	NoticeID      string          `json:"noticeId"`
+	ContentHash   string          `json:"contentHash"`
+	FetchedAt     time.Time       `json:"fetchedAt"`
+	ChangedFields json.RawMessage `json:"changedFields,omitempty"`
+}
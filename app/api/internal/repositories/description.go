@@ -3,10 +3,14 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/db"
 	"govcon/api/internal/models"
 )
 
@@ -18,39 +22,22 @@ func NewDescriptionRepository(db *pgxpool.Pool) *DescriptionRepository {
 	return &DescriptionRepository{db: db}
 }
 
-// UpsertDescription upserts a description record with conflict handling on notice_id
-func (r *DescriptionRepository) UpsertDescription(ctx context.Context, desc *models.OpportunityDescription) error {
-	now := time.Now()
-	
-	// Marshal ai_meta to JSONB (if present)
-	var aiMetaJSON []byte
-	var err error
-	if desc.AIMeta != nil {
-		aiMetaJSON, err = json.Marshal(desc.AIMeta)
-		if err != nil {
-			return fmt.Errorf("failed to marshal ai_meta: %w", err)
-		}
-	}
-	
-	// Ensure AIInputVersion is always set to satisfy NOT NULL constraint
-	// PostgreSQL's DEFAULT only applies when column is omitted, not when NULL is explicitly provided
-	if desc.AIInputVersion == nil {
-		defaultVersion := 1
-		desc.AIInputVersion = &defaultVersion
-	}
-	
-	query := `
+// upsertDescriptionQuery inserts one description or updates it in place on notice_id, returning the stored
+// fetch_attempts. created_at is left to the column default; migration 008 keeps it fixed on the ON CONFLICT path.
+// fetch_attempts counts consecutive errors: an error adds one, fetched or not_found resets it, and anything
+// else (e.g. the not_requested placeholder) leaves it alone.
+const upsertDescriptionQuery = `
 		INSERT INTO opportunity_description (
 			notice_id, source_type, source_url, source_inline,
 			fetch_status, http_status, fetched_at,
 			raw_text, raw_text_normalized, text_normalized,
-			content_hash, content_type, last_error,
+			content_hash, content_type, last_error, oversize,
 			ai_input_text, ai_input_hash, ai_input_version, ai_generated_at, ai_meta,
 			excerpt_text, poc_email_primary,
 			raw_json_response, normalization_version,
-			updated_at
+			updated_at, fetch_attempts
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25
 		)
 		ON CONFLICT (notice_id) DO UPDATE SET
 			source_type = EXCLUDED.source_type,
@@ -65,6 +52,7 @@ func (r *DescriptionRepository) UpsertDescription(ctx context.Context, desc *mod
 			content_hash = EXCLUDED.content_hash,
 			content_type = EXCLUDED.content_type,
 			last_error = EXCLUDED.last_error,
+			oversize = EXCLUDED.oversize,
 			ai_input_text = EXCLUDED.ai_input_text,
 			ai_input_hash = EXCLUDED.ai_input_hash,
 			ai_input_version = EXCLUDED.ai_input_version,
@@ -74,10 +62,42 @@ func (r *DescriptionRepository) UpsertDescription(ctx context.Context, desc *mod
 			poc_email_primary = EXCLUDED.poc_email_primary,
 			raw_json_response = EXCLUDED.raw_json_response,
 			normalization_version = EXCLUDED.normalization_version,
-			updated_at = EXCLUDED.updated_at
+			updated_at = EXCLUDED.updated_at,
+			fetch_attempts = CASE EXCLUDED.fetch_status
+				WHEN 'error' THEN opportunity_description.fetch_attempts + 1
+				WHEN 'fetched' THEN 0
+				WHEN 'not_found' THEN 0
+				ELSE opportunity_description.fetch_attempts
+			END
+		RETURNING fetch_attempts
 	`
-	
-	_, err = r.db.Exec(ctx, query,
+
+// upsertDescriptionArgs returns the upsertDescriptionQuery arguments for desc, defaulting its AIInputVersion
+func upsertDescriptionArgs(desc *models.OpportunityDescription, now time.Time) ([]interface{}, error) {
+	// Marshal ai_meta to JSONB (if present)
+	var aiMetaJSON []byte
+	if desc.AIMeta != nil {
+		var err error
+		aiMetaJSON, err = json.Marshal(desc.AIMeta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal ai_meta: %w", err)
+		}
+	}
+
+	// Ensure AIInputVersion is always set to satisfy NOT NULL constraint
+	// PostgreSQL's DEFAULT only applies when column is omitted, not when NULL is explicitly provided
+	if desc.AIInputVersion == nil {
+		defaultVersion := 1
+		desc.AIInputVersion = &defaultVersion
+	}
+
+	// The count to insert with; on conflict the query derives it from the stored count instead
+	fetchAttempts := 0
+	if desc.FetchStatus == models.FetchStatusError {
+		fetchAttempts = 1
+	}
+
+	return []interface{}{
 		desc.NoticeID,
 		desc.SourceType,
 		desc.SourceURL,
@@ -91,6 +111,7 @@ func (r *DescriptionRepository) UpsertDescription(ctx context.Context, desc *mod
 		desc.ContentHash,
 		desc.ContentType,
 		desc.LastError,
+		desc.Oversize,
 		desc.AIInputText,
 		desc.AIInputHash,
 		desc.AIInputVersion,
@@ -101,16 +122,86 @@ func (r *DescriptionRepository) UpsertDescription(ctx context.Context, desc *mod
 		desc.RawJsonResponse,
 		desc.NormalizationVersion,
 		now,
-	)
-	
+		fetchAttempts,
+	}, nil
+}
+
+// UpsertDescription upserts a description record with conflict handling on notice_id.
+// desc.FetchAttempts is set to the stored count.
+func (r *DescriptionRepository) UpsertDescription(ctx context.Context, desc *models.OpportunityDescription) error {
+	args, err := upsertDescriptionArgs(desc, time.Now())
 	if err != nil {
+		return err
+	}
+
+	if err := r.db.QueryRow(ctx, upsertDescriptionQuery, args...).Scan(&desc.FetchAttempts); err != nil {
 		return fmt.Errorf("failed to upsert description: %w", err)
 	}
-	
+	return nil
+}
+
+// RecordRefetchError records a failed re-fetch of a fetched description without touching the stored text:
+// fetch_attempts goes up by one and last_error is set, while fetch_status, fetched_at and the text and AI fields
+// are kept, so the cached copy is still served and the next read past DESC_MAX_AGE tries SAM again. It returns
+// the stored fetch_attempts, or ErrNotFound when noticeID has no description.
+func (r *DescriptionRepository) RecordRefetchError(ctx context.Context, noticeID, lastError string) (int, error) {
+	var attempts int
+	err := r.db.QueryRow(ctx, `
+		UPDATE opportunity_description
+		SET fetch_attempts = fetch_attempts + 1, last_error = $2, updated_at = now()
+		WHERE notice_id = $1
+		RETURNING fetch_attempts
+	`, noticeID, lastError).Scan(&attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to record re-fetch error: %w", err)
+	}
+	return attempts, nil
+}
+
+// BatchUpsertDescriptions upserts descs as UpsertDescription does, row by row in one transaction sent as a single
+// pgx batch, so a chunk costs one round trip instead of one per record. Each desc gets the AIInputVersion default
+// and its stored FetchAttempts. If any row fails, none are written; the error names the failing notice so the
+// caller can retry the rest. The same notice may appear more than once; later entries win.
+func (r *DescriptionRepository) BatchUpsertDescriptions(ctx context.Context, descs []*models.OpportunityDescription) error {
+	if len(descs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	batch := &pgx.Batch{}
+	for _, desc := range descs {
+		args, err := upsertDescriptionArgs(desc, now)
+		if err != nil {
+			return fmt.Errorf("notice %s: %w", desc.NoticeID, err)
+		}
+		batch.Queue(upsertDescriptionQuery, args...).QueryRow(func(row pgx.Row) error {
+			if err := row.Scan(&desc.FetchAttempts); err != nil {
+				return fmt.Errorf("failed to upsert description for notice %s: %w", desc.NoticeID, err)
+			}
+			return nil
+		})
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin description batch: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit description batch: %w", err)
+	}
 	return nil
 }
 
 // GetDescription retrieves a full description record by notice_id
+// Returns ErrDescriptionNotFound if the opportunity has no description row
 func (r *DescriptionRepository) GetDescription(ctx context.Context, noticeID string) (*models.OpportunityDescription, error) {
 	var desc models.OpportunityDescription
 	var sourceType, fetchStatus string
@@ -123,7 +214,7 @@ func (r *DescriptionRepository) GetDescription(ctx context.Context, noticeID str
 			notice_id, source_type, source_url, source_inline,
 			fetch_status, http_status, fetched_at,
 			raw_text, raw_text_normalized, text_normalized,
-			content_hash, content_type, last_error,
+			content_hash, content_type, last_error, oversize, fetch_attempts,
 			brief_summary, brief_summary_model, brief_summary_hash, summary_updated_at,
 			ai_input_text, ai_input_hash, ai_input_version, ai_generated_at, ai_meta,
 			excerpt_text, poc_email_primary,
@@ -145,6 +236,8 @@ func (r *DescriptionRepository) GetDescription(ctx context.Context, noticeID str
 		&desc.ContentHash,
 		&desc.ContentType,
 		&desc.LastError,
+		&desc.Oversize,
+		&desc.FetchAttempts,
 		&desc.BriefSummary,
 		&desc.BriefSummaryModel,
 		&desc.BriefSummaryHash,
@@ -162,10 +255,10 @@ func (r *DescriptionRepository) GetDescription(ctx context.Context, noticeID str
 		&updatedAt,
 	)
 	
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrDescriptionNotFound
+	}
 	if err != nil {
-		if err.Error() == "no rows in result set" {
-			return nil, fmt.Errorf("description not found")
-		}
 		return nil, fmt.Errorf("failed to get description: %w", err)
 	}
 	
@@ -192,6 +285,76 @@ func (r *DescriptionRepository) GetDescription(ctx context.Context, noticeID str
 	return &desc, nil
 }
 
+// FindAIFieldsByContentHash returns the AI-derived fields of another description with the same normalized content,
+// for reuse instead of re-running AI optimization on identical boilerplate
+// Only rows at normalizationVersion and aiInputVersion with AI output qualify; returns nil if there is none
+func (r *DescriptionRepository) FindAIFieldsByContentHash(ctx context.Context, contentHash string, normalizationVersion, aiInputVersion int, excludeNoticeID string) (*models.OpportunityDescription, error) {
+	var desc models.OpportunityDescription
+	var aiMetaJSON []byte
+
+	err := r.db.QueryRow(ctx, `
+		SELECT 
+			notice_id, content_hash, normalization_version,
+			ai_input_text, ai_input_hash, ai_input_version, ai_generated_at, ai_meta,
+			excerpt_text, poc_email_primary
+		FROM opportunity_description
+		WHERE content_hash = $1
+			AND normalization_version = $2
+			AND ai_input_version = $3
+			AND ai_input_text IS NOT NULL
+			AND notice_id <> $4
+		ORDER BY ai_generated_at DESC NULLS LAST
+		LIMIT 1
+	`, contentHash, normalizationVersion, aiInputVersion, excludeNoticeID).Scan(
+		&desc.NoticeID,
+		&desc.ContentHash,
+		&desc.NormalizationVersion,
+		&desc.AIInputText,
+		&desc.AIInputHash,
+		&desc.AIInputVersion,
+		&desc.AIGeneratedAt,
+		&aiMetaJSON,
+		&desc.ExcerptText,
+		&desc.POCEmailPrimary,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find description by content hash: %w", err)
+	}
+
+	if len(aiMetaJSON) > 0 {
+		var aiMeta models.AiMeta
+		if err := json.Unmarshal(aiMetaJSON, &aiMeta); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ai_meta: %w", err)
+		}
+		desc.AIMeta = &aiMeta
+	}
+	return &desc, nil
+}
+
+// GetRawJSONResponse returns the stored SAM response body for a notice, without loading the rest of the record
+// Returns nil if there is no description row or nothing was stored (inline and none sources never store one)
+func (r *DescriptionRepository) GetRawJSONResponse(ctx context.Context, noticeID string) (*string, error) {
+	var raw *string
+	err := r.db.QueryRow(ctx, `
+		SELECT raw_json_response
+		FROM opportunity_description
+		WHERE notice_id = $1
+	`, noticeID).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raw json response: %w", err)
+	}
+	if raw != nil && *raw == "" {
+		return nil, nil
+	}
+	return raw, nil
+}
+
 // GetDescriptionStatus computes description status from source_type and fetch_status
 // This is a helper that can be used for list endpoints
 func (r *DescriptionRepository) GetDescriptionStatus(ctx context.Context, noticeID string) (string, error) {
@@ -203,33 +366,192 @@ func (r *DescriptionRepository) GetDescriptionStatus(ctx context.Context, notice
 		WHERE notice_id = $1
 	`, noticeID).Scan(&sourceType, &fetchStatus)
 	
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "none", nil // No record means no description
+	}
 	if err != nil {
-		if err.Error() == "no rows in result set" {
-			return "none", nil // No record means no description
-		}
 		return "", fmt.Errorf("failed to get description status: %w", err)
 	}
 	
-	// Compute status using same logic as SQL CASE statement
+	return computeDescriptionStatus(sourceType, fetchStatus), nil
+}
+
+// computeDescriptionStatus derives the status using the same logic as the SQL CASE statement in search
+func computeDescriptionStatus(sourceType, fetchStatus *string) string {
 	if sourceType == nil || *sourceType == "none" {
-		return "none", nil
+		return "none"
 	}
 	
 	if fetchStatus == nil {
-		return "available_unfetched", nil
+		return "available_unfetched"
 	}
 	
 	switch *fetchStatus {
 	case "fetched":
-		return "ready", nil
+		return "ready"
 	case "not_found":
-		return "not_found", nil
+		return "not_found"
 	case "error":
-		return "error", nil
+		return "error"
 	case "not_requested":
-		return "available_unfetched", nil
+		return "available_unfetched"
 	default:
-		return "available_unfetched", nil
+		return "available_unfetched"
+	}
+}
+
+// descriptionStatusRow is one row of the bulk status lookup
+type descriptionStatusRow struct {
+	NoticeID    string
+	SourceType  *string
+	FetchStatus *string
+}
+
+// resolveDescriptionStatuses maps every requested ID to a status, defaulting IDs with no row to "none"
+func resolveDescriptionStatuses(ids []string, rows []descriptionStatusRow) map[string]string {
+	statuses := make(map[string]string, len(ids))
+	for _, id := range ids {
+		statuses[id] = "none"
+	}
+	for _, row := range rows {
+		statuses[row.NoticeID] = computeDescriptionStatus(row.SourceType, row.FetchStatus)
+	}
+	return statuses
+}
+
+// GetDescriptionStatuses computes description status for many notice IDs in a single query
+// IDs without an opportunity_description row are reported as "none"
+func (r *DescriptionRepository) GetDescriptionStatuses(ctx context.Context, noticeIDs []string) (map[string]string, error) {
+	if len(noticeIDs) == 0 {
+		return map[string]string{}, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT notice_id, source_type, fetch_status
+		FROM opportunity_description
+		WHERE notice_id = ANY($1)
+	`, noticeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get description statuses: %w", err)
+	}
+	defer rows.Close()
+
+	var found []descriptionStatusRow
+	for rows.Next() {
+		var row descriptionStatusRow
+		if err := rows.Scan(&row.NoticeID, &row.SourceType, &row.FetchStatus); err != nil {
+			return nil, fmt.Errorf("failed to scan description status: %w", err)
+		}
+		found = append(found, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating description statuses: %w", err)
+	}
+
+	return resolveDescriptionStatuses(noticeIDs, found), nil
+}
+
+// SyncDescriptionStatuses recomputes opportunity.description_status (migration 011) from opportunity_description
+// and fixes rows that drifted, returning how many differed. With dryRun, rows are counted but not updated.
+// The trigger keeps the column current, so this only finds work after writes that bypassed it.
+func (r *DescriptionRepository) SyncDescriptionStatuses(ctx context.Context, dryRun bool) (int64, error) {
+	drifted := fmt.Sprintf(`
+		SELECT o.notice_id, %s AS status
+		FROM opportunity o
+		LEFT JOIN opportunity_description od ON o.notice_id = od.notice_id
+		WHERE o.description_status IS DISTINCT FROM %s
+	`, descriptionStatusExpr, descriptionStatusExpr)
+
+	// Scans every opportunity, so it runs without the pool's statement_timeout
+	var count int64
+	err := db.WithStatementTimeout(ctx, r.db, 0, func(tx pgx.Tx) error {
+		if dryRun {
+			return tx.QueryRow(ctx, "SELECT COUNT(*) FROM ("+drifted+") d").Scan(&count)
+		}
+		tag, err := tx.Exec(ctx, `
+			UPDATE opportunity o SET description_status = d.status
+			FROM (`+drifted+`) d
+			WHERE o.notice_id = d.notice_id
+		`)
+		count = tag.RowsAffected()
+		return err
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "description_status does not exist") {
+			return 0, fmt.Errorf("database migration required: %w. Run: pnpm --filter api db:migrate", err)
+		}
+		return 0, fmt.Errorf("failed to sync description statuses: %w", err)
+	}
+	return count, nil
+}
+
+// ListFetchErrors returns the URL-sourced descriptions in fetch_status error with fewer than maxAttempts failed
+// fetches, least recently tried first. Oversize rows are left out: a retry would hit the same limit.
+// Only the fields needed to schedule a retry are filled (notice_id, source_url, fetch_attempts, fetched_at).
+func (r *DescriptionRepository) ListFetchErrors(ctx context.Context, maxAttempts int) ([]models.OpportunityDescription, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT notice_id, source_url, fetch_attempts, fetched_at
+		FROM opportunity_description
+		WHERE fetch_status = 'error'
+		  AND source_type = 'url'
+		  AND NOT oversize
+		  AND fetch_attempts < $1
+		ORDER BY fetched_at NULLS FIRST, notice_id
+	`, maxAttempts)
+	if err != nil {
+		if strings.Contains(err.Error(), "fetch_attempts") {
+			return nil, fmt.Errorf("database migration required: %w. Run: pnpm --filter api db:migrate", err)
+		}
+		return nil, fmt.Errorf("failed to query fetch errors: %w", err)
+	}
+	defer rows.Close()
+
+	var descs []models.OpportunityDescription
+	for rows.Next() {
+		desc := models.OpportunityDescription{SourceType: models.SourceTypeURL, FetchStatus: models.FetchStatusError}
+		if err := rows.Scan(&desc.NoticeID, &desc.SourceURL, &desc.FetchAttempts, &desc.FetchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan fetch error: %w", err)
+		}
+		descs = append(descs, desc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating fetch errors: %w", err)
 	}
+	return descs, nil
 }
 
+// GetAIMetas returns the stored ai_meta for many notice IDs in a single query
+// IDs without a description or without ai_meta are absent from the map
+func (r *DescriptionRepository) GetAIMetas(ctx context.Context, noticeIDs []string) (map[string]*models.AiMeta, error) {
+	metas := make(map[string]*models.AiMeta, len(noticeIDs))
+	if len(noticeIDs) == 0 {
+		return metas, nil
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT notice_id, ai_meta
+		FROM opportunity_description
+		WHERE notice_id = ANY($1) AND ai_meta IS NOT NULL
+	`, noticeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ai_meta: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var noticeID string
+		var aiMetaJSON []byte
+		if err := rows.Scan(&noticeID, &aiMetaJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan ai_meta: %w", err)
+		}
+		var aiMeta models.AiMeta
+		if err := json.Unmarshal(aiMetaJSON, &aiMeta); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ai_meta for %s: %w", noticeID, err)
+		}
+		metas[noticeID] = &aiMeta
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ai_meta: %w", err)
+	}
+	return metas, nil
+}
@@ -0,0 +1,441 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/testdb"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestComputeDescriptionStatus(t *testing.T) {
+	cases := []struct {
+		sourceType, fetchStatus *string
+		expected                string
+	}{
+		{nil, nil, "none"},
+		{strPtr("none"), strPtr("not_found"), "none"},
+		{strPtr("url"), nil, "available_unfetched"},
+		{strPtr("url"), strPtr("fetched"), "ready"},
+		{strPtr("inline"), strPtr("fetched"), "ready"},
+		{strPtr("url"), strPtr("not_found"), "not_found"},
+		{strPtr("url"), strPtr("error"), "error"},
+		{strPtr("url"), strPtr("not_requested"), "available_unfetched"},
+	}
+	for _, c := range cases {
+		if got := computeDescriptionStatus(c.sourceType, c.fetchStatus); got != c.expected {
+			t.Errorf("computeDescriptionStatus(%v, %v): expected %q, got %q", c.sourceType, c.fetchStatus, c.expected, got)
+		}
+	}
+}
+
+func TestResolveDescriptionStatuses_Mixed(t *testing.T) {
+	ids := []string{"FETCHED", "ERRORED", "ABSENT"}
+	rows := []descriptionStatusRow{
+		{NoticeID: "FETCHED", SourceType: strPtr("url"), FetchStatus: strPtr("fetched")},
+		{NoticeID: "ERRORED", SourceType: strPtr("url"), FetchStatus: strPtr("error")},
+	}
+
+	statuses := resolveDescriptionStatuses(ids, rows)
+
+	expected := map[string]string{"FETCHED": "ready", "ERRORED": "error", "ABSENT": "none"}
+	if len(statuses) != len(expected) {
+		t.Errorf("Expected %d statuses, got %d: %v", len(expected), len(statuses), statuses)
+	}
+	for id, want := range expected {
+		if got := statuses[id]; got != want {
+			t.Errorf("Expected %s to be %q, got %q", id, want, got)
+		}
+	}
+}
+
+func TestDescriptionRepository_GetRawJSONResponse(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestOpportunityTable(t, pool)
+	execMigrationFile(t, pool, "003_opportunity_description.sql")
+	execMigrationFile(t, pool, "005_add_raw_json_and_normalization_version.sql")
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id) VALUES ('URL1'), ('INLINE1');
+		INSERT INTO opportunity_description (notice_id, source_type, fetch_status, raw_json_response) VALUES
+			('URL1', 'url', 'fetched', '{"description":"Scope"}'),
+			('INLINE1', 'inline', 'fetched', NULL);
+	`)
+	repo := NewDescriptionRepository(pool)
+	ctx := context.Background()
+
+	raw, err := repo.GetRawJSONResponse(ctx, "URL1")
+	if err != nil || raw == nil || *raw != `{"description":"Scope"}` {
+		t.Errorf("Expected stored raw response for URL-sourced notice, got %v (err %v)", raw, err)
+	}
+	for _, id := range []string{"INLINE1", "MISSING"} {
+		raw, err := repo.GetRawJSONResponse(ctx, id)
+		if err != nil || raw != nil {
+			t.Errorf("%s: expected nil raw response, got %v (err %v)", id, raw, err)
+		}
+	}
+}
+
+func TestDescriptionRepository_FindAIFieldsByContentHash(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestOpportunityTable(t, pool)
+	execMigrationFile(t, pool, "003_opportunity_description.sql")
+	execMigrationFile(t, pool, "004_add_ai_processing_fields.sql")
+	execMigrationFile(t, pool, "005_add_raw_json_and_normalization_version.sql")
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id) VALUES ('N1'), ('N2'), ('N3'), ('N4');
+		INSERT INTO opportunity_description (notice_id, source_type, fetch_status, content_hash, normalization_version, ai_input_text, ai_input_version) VALUES
+			('N1', 'url', 'fetched', 'h1', 3, 'ai text', 2),
+			('N2', 'url', 'fetched', 'h1', 2, 'old ai text', 2),
+			('N3', 'url', 'fetched', 'h2', 3, NULL, 2),
+			('N4', 'url', 'fetched', 'h3', 3, 'old optimizer text', 1);
+	`)
+	repo := NewDescriptionRepository(pool)
+	ctx := context.Background()
+
+	match, err := repo.FindAIFieldsByContentHash(ctx, "h1", 3, 2, "N9")
+	if err != nil || match == nil || match.NoticeID != "N1" || *match.AIInputText != "ai text" {
+		t.Errorf("Expected N1's AI fields, got %+v (err %v)", match, err)
+	}
+	// The notice itself, other normalization or AI input versions, and rows without AI output don't count
+	for _, c := range []struct {
+		hash      string
+		version   int
+		aiVersion int
+		exclude   string
+	}{{"h1", 3, 2, "N1"}, {"h1", 1, 2, "N9"}, {"h2", 3, 2, "N9"}, {"h3", 3, 2, "N9"}} {
+		match, err := repo.FindAIFieldsByContentHash(ctx, c.hash, c.version, c.aiVersion, c.exclude)
+		if err != nil || match != nil {
+			t.Errorf("%+v: expected no match, got %+v (err %v)", c, match, err)
+		}
+	}
+}
+
+func TestDescriptionRepository_UpsertGetRoundTrip(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+
+	testdb.Migrate(t, pool)
+	execTestSQL(t, pool, `INSERT INTO opportunity (notice_id, title, content_hash) VALUES ('N1', 'Title', 'h')`)
+
+	repo := NewDescriptionRepository(pool)
+	fetchedAt := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+	httpStatus := 200
+	version := 3
+	in := &models.OpportunityDescription{
+		NoticeID:             "N1",
+		SourceType:           models.SourceTypeURL,
+		SourceURL:            strPtr("https://api.sam.gov/desc/N1"),
+		FetchStatus:          models.FetchStatusFetched,
+		HTTPStatus:           &httpStatus,
+		FetchedAt:            &fetchedAt,
+		RawText:              strPtr("<p>Scope</p>"),
+		RawTextNormalized:    strPtr("Scope"),
+		TextNormalized:       strPtr("scope"),
+		ContentHash:          strPtr("hash-1"),
+		AIInputText:          strPtr("Scope"),
+		AIGeneratedAt:        &fetchedAt,
+		AIMeta:               &models.AiMeta{POCEmails: []string{"a@agency.gov"}},
+		ExcerptText:          strPtr("Scope"),
+		POCEmailPrimary:      strPtr("a@agency.gov"),
+		RawJsonResponse:      strPtr(`{"description":"<p>Scope</p>"}`),
+		NormalizationVersion: &version,
+	}
+	if err := repo.UpsertDescription(ctx, in); err != nil {
+		t.Fatalf("UpsertDescription failed: %v", err)
+	}
+
+	got, err := repo.GetDescription(ctx, "N1")
+	if err != nil {
+		t.Fatalf("GetDescription failed: %v", err)
+	}
+	if got.SourceType != models.SourceTypeURL || got.FetchStatus != models.FetchStatusFetched {
+		t.Errorf("Expected url/fetched, got %s/%s", got.SourceType, got.FetchStatus)
+	}
+	if got.HTTPStatus == nil || *got.HTTPStatus != 200 || got.FetchedAt == nil || !got.FetchedAt.Equal(fetchedAt) {
+		t.Errorf("Expected HTTP status and fetched_at round-tripped, got %v %v", got.HTTPStatus, got.FetchedAt)
+	}
+	if got.AIMeta == nil || len(got.AIMeta.POCEmails) != 1 || got.AIMeta.POCEmails[0] != "a@agency.gov" {
+		t.Errorf("Expected ai_meta round-tripped, got %+v", got.AIMeta)
+	}
+	if got.AIInputVersion == nil || *got.AIInputVersion != 1 {
+		t.Errorf("Expected default ai_input_version 1, got %v", got.AIInputVersion)
+	}
+	if got.NormalizationVersion == nil || *got.NormalizationVersion != 3 {
+		t.Errorf("Expected normalization_version 3, got %v", got.NormalizationVersion)
+	}
+	if got.BriefSummary != nil || got.CreatedAt.IsZero() {
+		t.Errorf("Expected no brief summary and a defaulted created_at, got %v %v", got.BriefSummary, got.CreatedAt)
+	}
+
+	// A second upsert updates in place and keeps created_at
+	in.FetchStatus = models.FetchStatusError
+	in.LastError = strPtr("timeout")
+	in.Oversize = true
+	if err := repo.UpsertDescription(ctx, in); err != nil {
+		t.Fatalf("Second UpsertDescription failed: %v", err)
+	}
+	updated, err := repo.GetDescription(ctx, "N1")
+	if err != nil {
+		t.Fatalf("GetDescription failed: %v", err)
+	}
+	if updated.FetchStatus != models.FetchStatusError || updated.LastError == nil || *updated.LastError != "timeout" {
+		t.Errorf("Expected updated fetch status and error, got %s %v", updated.FetchStatus, updated.LastError)
+	}
+	if !updated.Oversize || got.Oversize {
+		t.Errorf("Expected oversize set only by the second upsert, got %v then %v", got.Oversize, updated.Oversize)
+	}
+	if !updated.CreatedAt.Equal(got.CreatedAt) {
+		t.Errorf("Expected created_at unchanged, got %v then %v", got.CreatedAt, updated.CreatedAt)
+	}
+}
+
+func TestDescriptionRepository_UpsertPreservesCreatedAt(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+
+	testdb.Migrate(t, pool)
+	execTestSQL(t, pool, `INSERT INTO opportunity (notice_id, title, content_hash) VALUES ('N1', 'Title', 'h')`)
+
+	repo := NewDescriptionRepository(pool)
+	desc := &models.OpportunityDescription{NoticeID: "N1", SourceType: models.SourceTypeNone, FetchStatus: models.FetchStatusNotRequested}
+	if err := repo.UpsertDescription(ctx, desc); err != nil {
+		t.Fatalf("UpsertDescription failed: %v", err)
+	}
+	first, err := repo.GetDescription(ctx, "N1")
+	if err != nil {
+		t.Fatalf("GetDescription failed: %v", err)
+	}
+	if first.CreatedAt.IsZero() {
+		t.Fatal("Expected insert to set created_at")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	desc.FetchStatus = models.FetchStatusError
+	if err := repo.UpsertDescription(ctx, desc); err != nil {
+		t.Fatalf("Second UpsertDescription failed: %v", err)
+	}
+	second, err := repo.GetDescription(ctx, "N1")
+	if err != nil {
+		t.Fatalf("GetDescription failed: %v", err)
+	}
+	if !second.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("Expected created_at %v preserved, got %v", first.CreatedAt, second.CreatedAt)
+	}
+	if !second.UpdatedAt.After(first.UpdatedAt) {
+		t.Errorf("Expected updated_at bumped past %v, got %v", first.UpdatedAt, second.UpdatedAt)
+	}
+
+	// The trigger also guards direct updates
+	execTestSQL(t, pool, `UPDATE opportunity_description SET created_at = '2000-01-01' WHERE notice_id = 'N1'`)
+	third, _ := repo.GetDescription(ctx, "N1")
+	if third == nil || !third.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("Expected created_at to stay %v after a direct update, got %+v", first.CreatedAt, third)
+	}
+}
+
+func TestDescriptionStatus_MaterializedMatchesComputed(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+
+	testdb.Migrate(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, content_hash, active, posted_date) VALUES
+			('N1', 'Fetched', 'h', true, '2025-03-01'), ('N2', 'Not found', 'h', true, '2025-03-01'),
+			('N3', 'Error', 'h', true, '2025-03-01'), ('N4', 'Unfetched', 'h', true, '2025-03-01'),
+			('N5', 'No source', 'h', true, '2025-03-01'), ('N6', 'No row', 'h', true, '2025-03-01');
+		INSERT INTO opportunity_description (notice_id, source_type, fetch_status) VALUES
+			('N1', 'url', 'fetched'), ('N2', 'url', 'not_found'), ('N3', 'url', 'error'),
+			('N4', 'url', 'not_requested'), ('N5', 'none', 'not_requested');
+		UPDATE opportunity_description SET fetch_status = 'fetched' WHERE notice_id = 'N4';
+		DELETE FROM opportunity_description WHERE notice_id = 'N3';
+	`)
+	ids := []string{"N1", "N2", "N3", "N4", "N5", "N6"}
+	repo := NewDescriptionRepository(pool)
+
+	assertMatches := func(when string) {
+		t.Helper()
+		computed, err := repo.GetDescriptionStatuses(ctx, ids)
+		if err != nil {
+			t.Fatalf("GetDescriptionStatuses failed: %v", err)
+		}
+		rows, err := pool.Query(ctx, `SELECT notice_id, description_status FROM opportunity`)
+		if err != nil {
+			t.Fatalf("Failed to read description_status: %v", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var noticeID, materialized string
+			if err := rows.Scan(&noticeID, &materialized); err != nil {
+				t.Fatalf("Failed to scan description_status: %v", err)
+			}
+			if materialized != computed[noticeID] {
+				t.Errorf("%s %s: Expected materialized status %q, got %q", when, noticeID, computed[noticeID], materialized)
+			}
+		}
+	}
+	assertMatches("after trigger")
+
+	// A write that bypassed the trigger is found and repaired
+	execTestSQL(t, pool, `UPDATE opportunity SET description_status = 'ready' WHERE notice_id = 'N6'`)
+	if n, err := repo.SyncDescriptionStatuses(ctx, true); err != nil || n != 1 {
+		t.Fatalf("Expected dry run to find 1 drifted row, got %d (err %v)", n, err)
+	}
+	if n, err := repo.SyncDescriptionStatuses(ctx, false); err != nil || n != 1 {
+		t.Fatalf("Expected 1 repaired row, got %d (err %v)", n, err)
+	}
+	assertMatches("after sync")
+	if n, err := repo.SyncDescriptionStatuses(ctx, false); err != nil || n != 0 {
+		t.Errorf("Expected nothing left to repair, got %d (err %v)", n, err)
+	}
+
+	// Search filters on the column
+	result, err := NewOpportunityRepository(pool).SearchOpportunitiesV2(ctx, SearchParamsV2{DescriptionStatus: "ready", IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if len(result.Items) != 2 || result.Items[0].DescriptionStatus != "ready" {
+		t.Errorf("Expected N1 and N4 as ready, got %+v", result.Items)
+	}
+}
+
+func TestDescriptionRepository_FetchAttempts(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+
+	testdb.Migrate(t, pool)
+	execTestSQL(t, pool, `INSERT INTO opportunity (notice_id, title, content_hash) VALUES ('N1', 'Title', 'h'), ('N2', 'Other', 'h')`)
+
+	repo := NewDescriptionRepository(pool)
+	desc := &models.OpportunityDescription{
+		NoticeID:    "N1",
+		SourceType:  models.SourceTypeURL,
+		SourceURL:   strPtr("https://api.sam.gov/desc/N1"),
+		FetchStatus: models.FetchStatusNotRequested,
+	}
+	attempts := func() int {
+		t.Helper()
+		got, err := repo.GetDescription(ctx, "N1")
+		if err != nil {
+			t.Fatalf("GetDescription failed: %v", err)
+		}
+		return got.FetchAttempts
+	}
+	upsert := func(status models.FetchStatus) {
+		t.Helper()
+		desc.FetchStatus = status
+		desc.FetchAttempts = 99 // Ignored: the count is kept by the database
+		if err := repo.UpsertDescription(ctx, desc); err != nil {
+			t.Fatalf("UpsertDescription failed: %v", err)
+		}
+		if n := attempts(); desc.FetchAttempts != n {
+			t.Errorf("Expected upsert to report the stored %d attempts, got %d", n, desc.FetchAttempts)
+		}
+	}
+
+	upsert(models.FetchStatusNotRequested)
+	if n := attempts(); n != 0 {
+		t.Errorf("Expected 0 attempts for a new row, got %d", n)
+	}
+	upsert(models.FetchStatusError)
+	upsert(models.FetchStatusError)
+	if n := attempts(); n != 2 {
+		t.Errorf("Expected 2 attempts after two errors, got %d", n)
+	}
+	upsert(models.FetchStatusNotRequested)
+	if n := attempts(); n != 2 {
+		t.Errorf("Expected a placeholder upsert to keep 2 attempts, got %d", n)
+	}
+
+	// Only error rows below the max attempts are listed; oversize rows never are
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity_description (notice_id, source_type, source_url, fetch_status, oversize, fetch_attempts)
+		VALUES ('N2', 'url', 'https://api.sam.gov/desc/N2', 'error', true, 1)
+	`)
+	upsert(models.FetchStatusError)
+	for _, c := range []struct {
+		maxAttempts int
+		expected    int
+	}{{3, 0}, {4, 1}} {
+		errs, err := repo.ListFetchErrors(ctx, c.maxAttempts)
+		if err != nil {
+			t.Fatalf("ListFetchErrors failed: %v", err)
+		}
+		if len(errs) != c.expected || (c.expected == 1 && (errs[0].NoticeID != "N1" || errs[0].FetchAttempts != 3)) {
+			t.Errorf("maxAttempts %d: expected %d rows (N1 with 3 attempts), got %+v", c.maxAttempts, c.expected, errs)
+		}
+	}
+
+	upsert(models.FetchStatusFetched)
+	if n := attempts(); n != 0 {
+		t.Errorf("Expected a successful fetch to reset attempts, got %d", n)
+	}
+}
+
+func TestDescriptionRepository_BatchUpsertDescriptions(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+
+	testdb.Migrate(t, pool)
+	execTestSQL(t, pool, `INSERT INTO opportunity (notice_id, title, content_hash) VALUES ('N1', 'One', 'h'), ('N2', 'Two', 'h'), ('N3', 'Three', 'h')`)
+
+	repo := NewDescriptionRepository(pool)
+	existing := &models.OpportunityDescription{NoticeID: "N1", SourceType: models.SourceTypeURL, FetchStatus: models.FetchStatusError, LastError: strPtr("timeout")}
+	if err := repo.UpsertDescription(ctx, existing); err != nil {
+		t.Fatalf("UpsertDescription failed: %v", err)
+	}
+	before, err := repo.GetDescription(ctx, "N1")
+	if err != nil {
+		t.Fatalf("GetDescription failed: %v", err)
+	}
+
+	version := 2
+	descs := []*models.OpportunityDescription{
+		// Conflicts with the stored N1 and updates it in place
+		{NoticeID: "N1", SourceType: models.SourceTypeURL, FetchStatus: models.FetchStatusError, LastError: strPtr("503")},
+		{NoticeID: "N2", SourceType: models.SourceTypeInline, FetchStatus: models.FetchStatusFetched, AIInputText: strPtr("Scope")},
+		{NoticeID: "N3", SourceType: models.SourceTypeInline, FetchStatus: models.FetchStatusFetched, AIInputVersion: &version},
+	}
+	if err := repo.BatchUpsertDescriptions(ctx, descs); err != nil {
+		t.Fatalf("BatchUpsertDescriptions failed: %v", err)
+	}
+
+	n1, _ := repo.GetDescription(ctx, "N1")
+	if n1 == nil || n1.LastError == nil || *n1.LastError != "503" || n1.FetchAttempts != 2 || descs[0].FetchAttempts != 2 {
+		t.Errorf("Expected N1 updated with 2 fetch attempts, got %+v (reported %d)", n1, descs[0].FetchAttempts)
+	}
+	if n1 != nil && !n1.CreatedAt.Equal(before.CreatedAt) {
+		t.Errorf("Expected N1 created_at %v kept, got %v", before.CreatedAt, n1.CreatedAt)
+	}
+	// The AIInputVersion default applies to each row without one, and an explicit version is kept
+	for _, c := range []struct {
+		noticeID string
+		version  int
+	}{{"N2", 1}, {"N3", 2}} {
+		got, err := repo.GetDescription(ctx, c.noticeID)
+		if err != nil || got == nil {
+			t.Fatalf("GetDescription %s failed: %v", c.noticeID, err)
+		}
+		if got.AIInputVersion == nil || *got.AIInputVersion != c.version {
+			t.Errorf("Expected %s ai_input_version %d, got %v", c.noticeID, c.version, got.AIInputVersion)
+		}
+	}
+	if descs[1].AIInputVersion == nil || *descs[1].AIInputVersion != 1 {
+		t.Errorf("Expected the default version set on the record, got %v", descs[1].AIInputVersion)
+	}
+
+	// A row that can't be written (no opportunity) fails the whole batch and is named in the error
+	err = repo.BatchUpsertDescriptions(ctx, []*models.OpportunityDescription{
+		{NoticeID: "N2", SourceType: models.SourceTypeInline, FetchStatus: models.FetchStatusNotFound},
+		{NoticeID: "MISSING", SourceType: models.SourceTypeNone, FetchStatus: models.FetchStatusNotRequested},
+	})
+	if err == nil || !strings.Contains(err.Error(), "MISSING") {
+		t.Errorf("Expected an error naming MISSING, got %v", err)
+	}
+	if n2, _ := repo.GetDescription(ctx, "N2"); n2 == nil || n2.FetchStatus != models.FetchStatusFetched {
+		t.Errorf("Expected N2 left as fetched after the failed batch, got %+v", n2)
+	}
+}
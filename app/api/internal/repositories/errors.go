@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is the common cause of every "no such row" error the repositories return, so callers can map
+// any of them to a 404 with errors.Is(err, ErrNotFound). Query failures never wrap it.
+var ErrNotFound = errors.New("not found")
+
+var (
+	// ErrOpportunityNotFound is returned when a notice ID has no opportunity
+	ErrOpportunityNotFound = fmt.Errorf("opportunity %w", ErrNotFound)
+	// ErrDescriptionNotFound is returned when an opportunity has no description row
+	ErrDescriptionNotFound = fmt.Errorf("description %w", ErrNotFound)
+)
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"govcon/api/internal/testdb"
+)
+
+func TestNotFoundErrors_WrapErrNotFound(t *testing.T) {
+	for _, err := range []error{ErrOpportunityNotFound, ErrDescriptionNotFound} {
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected %q to be an ErrNotFound", err)
+		}
+	}
+	if ErrOpportunityNotFound.Error() != "opportunity not found" {
+		t.Errorf("Expected message %q, got %q", "opportunity not found", ErrOpportunityNotFound.Error())
+	}
+}
+
+func TestGetByID_MissingRowIsErrNotFound(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+	testdb.Migrate(t, pool)
+
+	_, err := NewOpportunityRepository(pool).GetOpportunityByNoticeID(ctx, "MISSING")
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, ErrOpportunityNotFound) {
+		t.Errorf("Expected ErrOpportunityNotFound for a missing opportunity, got %v", err)
+	}
+	_, err = NewDescriptionRepository(pool).GetDescription(ctx, "MISSING")
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, ErrDescriptionNotFound) {
+		t.Errorf("Expected ErrDescriptionNotFound for a missing description, got %v", err)
+	}
+}
+
+func TestGetByID_DatabaseErrorIsNotErrNotFound(t *testing.T) {
+	pool := unreachableTestPool(t)
+	ctx := context.Background()
+
+	_, err := NewOpportunityRepository(pool).GetOpportunityByNoticeID(ctx, "N1")
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a database error that is not ErrNotFound, got %v", err)
+	}
+	_, err = NewDescriptionRepository(pool).GetDescription(ctx, "N1")
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a database error that is not ErrNotFound, got %v", err)
+	}
+}
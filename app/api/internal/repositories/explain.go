@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// IndexWarning flags a plan node that filters a table with a sequential scan
+type IndexWarning struct {
+	Relation string   `json:"relation"`
+	Filter   string   `json:"filter"`
+	Params   []string `json:"params,omitempty"` // search params whose conditions appear in the filter
+	Rows     float64  `json:"rows"`             // rows the scan actually read (before the filter)
+	Message  string   `json:"message"`
+}
+
+// indexedRelations are the tables search filters run against; seq scans on other relations aren't flagged
+var indexedRelations = map[string]bool{
+	"opportunity":             true,
+	"opportunity_description": true,
+	"opportunity_tag":         true,
+}
+
+// filterParamColumns maps a column (as it appears in a plan filter) to the search params that filter on it
+var filterParamColumns = []struct {
+	column string
+	params []string
+}{
+	{"to_tsvector", []string{"q"}},
+	{"title", []string{"q"}},
+	{"naics", []string{"naics"}},
+	{"type_of_set_aside", []string{"setAside"}},
+	{"procurement_type", []string{"ptype"}},
+	{"place_of_performance", []string{"state"}},
+	{"agency_path_name", []string{"agency"}},
+	{"classification_code", []string{"classification"}},
+	{"posted_date", []string{"postedFrom", "postedTo"}},
+	{"response_deadline", []string{"dueFrom", "dueTo"}},
+	{"archive_date", []string{"includeArchived", "archivedOnly"}},
+	{"tag", []string{"tag"}},
+}
+
+// planNode is the subset of an EXPLAIN (FORMAT JSON) node we inspect
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	Filter       string     `json:"Filter"`
+	ActualRows   float64    `json:"Actual Rows"`
+	RowsRemoved  float64    `json:"Rows Removed by Filter"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// IndexWarnings walks an EXPLAIN (FORMAT JSON) plan and reports every sequential scan
+// on a search table that applies a filter, i.e. a filter that could not use an index
+func IndexWarnings(plan json.RawMessage) ([]IndexWarning, error) {
+	var roots []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &roots); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	warnings := []IndexWarning{}
+	var walk func(node planNode)
+	walk = func(node planNode) {
+		if node.NodeType == "Seq Scan" && node.Filter != "" && indexedRelations[node.RelationName] {
+			params := filterParams(node.Filter)
+			message := fmt.Sprintf("sequential scan on %s", node.RelationName)
+			if len(params) > 0 {
+				message += fmt.Sprintf(" for %s; consider an index", strings.Join(params, ", "))
+			}
+			warnings = append(warnings, IndexWarning{
+				Relation: node.RelationName,
+				Filter:   node.Filter,
+				Params:   params,
+				Rows:     node.ActualRows + node.RowsRemoved,
+				Message:  message,
+			})
+		}
+		for _, child := range node.Plans {
+			walk(child)
+		}
+	}
+	for _, root := range roots {
+		walk(root.Plan)
+	}
+	return warnings, nil
+}
+
+// filterParams returns the search params whose columns appear in a plan filter
+func filterParams(filter string) []string {
+	var params []string
+	seen := make(map[string]bool)
+	for _, fc := range filterParamColumns {
+		if !strings.Contains(filter, fc.column) {
+			continue
+		}
+		for _, param := range fc.params {
+			if !seen[param] {
+				seen[param] = true
+				params = append(params, param)
+			}
+		}
+	}
+	return params
+}
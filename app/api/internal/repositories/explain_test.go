@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"govcon/api/internal/testdb"
+)
+
+func TestIndexWarnings_SeqScanWithFilter(t *testing.T) {
+	plan := json.RawMessage(`[{"Plan": {
+		"Node Type": "Limit",
+		"Plans": [{
+			"Node Type": "Hash Right Join",
+			"Plans": [
+				{"Node Type": "Seq Scan", "Relation Name": "opportunity_description"},
+				{"Node Type": "Seq Scan", "Relation Name": "opportunity",
+				 "Filter": "((place_of_performance ->> 'state'::text) = 'VA'::text)",
+				 "Actual Rows": 12, "Rows Removed by Filter": 4988}
+			]
+		}]
+	}}]`)
+
+	warnings, err := IndexWarnings(plan)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 warning, got %d: %+v", len(warnings), warnings)
+	}
+	w := warnings[0]
+	if w.Relation != "opportunity" {
+		t.Errorf("Expected relation %q, got %q", "opportunity", w.Relation)
+	}
+	if len(w.Params) != 1 || w.Params[0] != "state" {
+		t.Errorf("Expected params [state], got %v", w.Params)
+	}
+	if w.Rows != 5000 {
+		t.Errorf("Expected 5000 rows scanned, got %v", w.Rows)
+	}
+}
+
+func TestIndexWarnings_IndexScanNoWarning(t *testing.T) {
+	plan := json.RawMessage(`[{"Plan": {"Node Type": "Index Scan", "Relation Name": "opportunity",
+		"Index Name": "idx_opportunity_posted_date", "Filter": "(active = true)"}}]`)
+
+	warnings, err := IndexWarnings(plan)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %+v", warnings)
+	}
+}
+
+func TestIndexWarnings_InvalidPlan(t *testing.T) {
+	if _, err := IndexWarnings(json.RawMessage(`not json`)); err == nil {
+		t.Error("Expected error for invalid plan")
+	}
+}
+
+func TestSearchOpportunitiesV2_ExplainWarnsOnSeqScan(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, place_of_performance) VALUES
+			('N1', 'Janitorial Services', '2025-03-14', true, '{"state": {"code": "VA"}}')
+	`)
+	repo := NewOpportunityRepository(pool)
+
+	// There is no index on place_of_performance, so the state filter always seq-scans
+	result, err := repo.SearchOpportunitiesV2(context.Background(), SearchParamsV2{State: "VA", IncludeArchived: true, Explain: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	warnings, ok := result.Debug["indexWarnings"].([]IndexWarning)
+	if !ok {
+		t.Fatalf("Expected indexWarnings in debug, got %v", result.Debug["indexWarnings"])
+	}
+	found := false
+	for _, w := range warnings {
+		for _, param := range w.Params {
+			if w.Relation == "opportunity" && param == "state" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected a state seq scan warning, got %+v", warnings)
+	}
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/models"
+)
+
+type JobRunRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewJobRunRepository(db *pgxpool.Pool) *JobRunRepository {
+	return &JobRunRepository{db: db}
+}
+
+// StartRun records a run of jobType as running and returns its ID
+// Requires migrations/009_job_run.sql
+func (r *JobRunRepository) StartRun(ctx context.Context, jobType string) (int64, error) {
+	var id int64
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO job_run (job_type, status) VALUES ($1, $2) RETURNING id
+	`, jobType, string(models.JobStatusRunning)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record job start: %w", err)
+	}
+	return id, nil
+}
+
+// FinishRun marks a run succeeded or failed, storing stats as JSON and errMsg (if non-empty)
+func (r *JobRunRepository) FinishRun(ctx context.Context, id int64, status models.JobStatus, stats interface{}, errMsg string) error {
+	var statsJSON []byte
+	if stats != nil {
+		var err error
+		if statsJSON, err = json.Marshal(stats); err != nil {
+			return fmt.Errorf("failed to marshal job stats: %w", err)
+		}
+	}
+	var errText *string
+	if errMsg != "" {
+		errText = &errMsg
+	}
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE job_run SET status = $2, finished_at = now(), stats = $3, error = $4
+		WHERE id = $1
+	`, id, string(status), statsJSON, errText)
+	if err != nil {
+		return fmt.Errorf("failed to record job finish: %w", err)
+	}
+	return nil
+}
+
+// ListRuns returns the most recent runs, newest first, optionally only those of jobType
+func (r *JobRunRepository) ListRuns(ctx context.Context, jobType string, limit int) ([]models.JobRun, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, job_type, status, started_at, finished_at,
+			(EXTRACT(EPOCH FROM finished_at - started_at) * 1000)::bigint, stats, error
+		FROM job_run
+		WHERE $1 = '' OR job_type = $1
+		ORDER BY started_at DESC, id DESC
+		LIMIT $2
+	`, jobType, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), `relation "job_run" does not exist`) {
+			return nil, fmt.Errorf("database migration required: %w. Run: pnpm --filter api db:migrate", err)
+		}
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.JobRun{}
+	for rows.Next() {
+		var run models.JobRun
+		var status string
+		var stats []byte
+		if err := rows.Scan(&run.ID, &run.JobType, &status, &run.StartedAt, &run.FinishedAt, &run.DurationMs, &stats, &run.Error); err != nil {
+			return nil, fmt.Errorf("failed to scan job run: %w", err)
+		}
+		run.Status = models.JobStatus(status)
+		if stats != nil {
+			run.Stats = stats
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/testdb"
+)
+
+func TestJobRunRepository_RecordsSuccessAndFailure(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+	testdb.Migrate(t, pool)
+	repo := NewJobRunRepository(pool)
+
+	okID, err := repo.StartRun(ctx, models.JobTypeIngest)
+	if err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	if err := repo.FinishRun(ctx, okID, models.JobStatusSucceeded, map[string]int{"new": 3}, ""); err != nil {
+		t.Fatalf("FinishRun failed: %v", err)
+	}
+
+	failedID, err := repo.StartRun(ctx, models.JobTypeBackfillDescriptions)
+	if err != nil {
+		t.Fatalf("StartRun failed: %v", err)
+	}
+	if err := repo.FinishRun(ctx, failedID, models.JobStatusFailed, nil, "SAM returned 500"); err != nil {
+		t.Fatalf("FinishRun failed: %v", err)
+	}
+
+	runs, err := repo.ListRuns(ctx, "", 10)
+	if err != nil {
+		t.Fatalf("ListRuns failed: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != failedID || runs[1].ID != okID {
+		t.Fatalf("Expected the failed run then the successful one, got %+v", runs)
+	}
+
+	failed := runs[0]
+	if failed.Status != models.JobStatusFailed || failed.Error == nil || *failed.Error != "SAM returned 500" || failed.FinishedAt == nil {
+		t.Errorf("Expected failed run with its error, got %+v", failed)
+	}
+	succeeded := runs[1]
+	if succeeded.Status != models.JobStatusSucceeded || succeeded.Error != nil || succeeded.DurationMs == nil {
+		t.Errorf("Expected succeeded run with a duration and no error, got %+v", succeeded)
+	}
+	var stats map[string]int
+	if err := json.Unmarshal(succeeded.Stats, &stats); err != nil || stats["new"] != 3 {
+		t.Errorf("Expected stats {new: 3}, got %s", succeeded.Stats)
+	}
+
+	ingestRuns, err := repo.ListRuns(ctx, models.JobTypeIngest, 10)
+	if err != nil {
+		t.Fatalf("ListRuns by type failed: %v", err)
+	}
+	if len(ingestRuns) != 1 || ingestRuns[0].ID != okID {
+		t.Errorf("Expected only the ingest run, got %+v", ingestRuns)
+	}
+}
@@ -4,16 +4,29 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"govcon/api/internal/models"
 )
 
 type OpportunityRepository struct {
 	db *pgxpool.Pool
+
+	// Whether opportunity.description_status exists (migration 011), looked up on first search
+	statusColumnMu      sync.Mutex
+	statusColumnChecked bool
+	statusColumn        bool
+	statusColumnHits    int64 // lookups answered from the cached result
+	statusColumnMisses  int64 // lookups that queried information_schema
 }
 
 func NewOpportunityRepository(db *pgxpool.Pool) *OpportunityRepository {
@@ -136,7 +149,7 @@ func (r *OpportunityRepository) SearchOpportunities(ctx context.Context, params
 			sub_tier, office, links
 		FROM opportunity
 		%s
-		ORDER BY posted_date DESC, notice_id
+		ORDER BY posted_date DESC NULLS LAST, notice_id ASC
 		LIMIT $%d OFFSET $%d
 	`, whereClause, argPos, argPos+1)
 
@@ -174,8 +187,8 @@ func (r *OpportunityRepository) SearchOpportunities(ctx context.Context, params
 		if len(contactJSON) > 0 {
 			json.Unmarshal(contactJSON, &opp.PointOfContact)
 		}
-		if len(placeJSON) > 0 {
-			json.Unmarshal(placeJSON, &opp.PlaceOfPerformance)
+		if err := models.DecodePlaceOfPerformance(placeJSON, &opp); err != nil {
+			fmt.Printf("Warning: Failed to decode place_of_performance for %s: %v\n", opp.NoticeID, err)
 		}
 		if len(linksJSON) > 0 {
 			json.Unmarshal(linksJSON, &opp.Links)
@@ -197,53 +210,103 @@ func (r *OpportunityRepository) SearchOpportunities(ctx context.Context, params
 	}, nil
 }
 
-// GetOpportunityByNoticeID retrieves a single opportunity by notice ID.
-func (r *OpportunityRepository) GetOpportunityByNoticeID(ctx context.Context, noticeID string) (*models.Opportunity, error) {
-	var opp models.Opportunity
-	var naicsJSON, contactJSON, placeJSON, linksJSON json.RawMessage
-	var activeBool bool
-	var rawDataJSON json.RawMessage
-
-	var solicitationNumber, agencyPathName *string
-	err := r.db.QueryRow(ctx, `
+// opportunityDetailQuery selects everything scanOpportunityDetail reads; callers append the WHERE clause
+const opportunityDetailQuery = `
 		SELECT 
 			o.notice_id, o.title, o.organization_type, o.posted_date, o.type, o.base_type,
 			o.archive_type, o.archive_date, o.type_of_set_aside, o.type_of_set_aside_desc,
 			o.response_deadline, o.naics, o.classification_code, o.active,
 			o.point_of_contact, o.place_of_performance, o.description, o.department,
 			o.sub_tier, o.office, o.links, o.solicitation_number, o.agency_path_name,
-			COALESCE(r.raw_data, '{}'::jsonb)
+			o.title_synthesized, o.sam_ui_url, COALESCE(r.raw_data, '{}'::jsonb)
 		FROM opportunity o
 		LEFT JOIN opportunity_raw r ON o.notice_id = r.notice_id
-		WHERE o.notice_id = $1
-	`, noticeID).Scan(
+`
+
+// rawString returns raw_data[key] when it is a string (including ""), or nil when missing, null or not a string
+func rawString(rawData map[string]interface{}, key string) *string {
+	if val, ok := rawData[key].(string); ok {
+		return &val
+	}
+	return nil
+}
+
+// opportunityQueryError wraps a detail query error, pointing at the migration when columns are missing
+func opportunityQueryError(err error) error {
+	errStr := err.Error()
+	if strings.Contains(errStr, "solicitation_number") || 
+	   strings.Contains(errStr, "agency_path_name") ||
+	   (strings.Contains(errStr, "column") && strings.Contains(errStr, "does not exist")) {
+		return fmt.Errorf("database migration required: %w. Run: pnpm --filter api db:migrate", err)
+	}
+	return fmt.Errorf("failed to get opportunity: %w", err)
+}
+
+// GetOpportunityByNoticeID retrieves a single opportunity by notice ID.
+// Returns ErrOpportunityNotFound if the notice ID does not exist
+func (r *OpportunityRepository) GetOpportunityByNoticeID(ctx context.Context, noticeID string) (*models.Opportunity, error) {
+	opp, err := scanOpportunityDetail(r.db.QueryRow(ctx, opportunityDetailQuery+"WHERE o.notice_id = $1", noticeID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrOpportunityNotFound
+	}
+	if err != nil {
+		return nil, opportunityQueryError(err)
+	}
+	return opp, nil
+}
+
+// GetOpportunitiesByNoticeIDs retrieves many opportunities in a single query, keyed by notice ID
+// IDs with no opportunity row are absent from the map
+func (r *OpportunityRepository) GetOpportunitiesByNoticeIDs(ctx context.Context, noticeIDs []string) (map[string]*models.Opportunity, error) {
+	opps := make(map[string]*models.Opportunity, len(noticeIDs))
+	if len(noticeIDs) == 0 {
+		return opps, nil
+	}
+
+	rows, err := r.db.Query(ctx, opportunityDetailQuery+"WHERE o.notice_id = ANY($1)", noticeIDs)
+	if err != nil {
+		return nil, opportunityQueryError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		opp, err := scanOpportunityDetail(rows)
+		if err != nil {
+			return nil, opportunityQueryError(err)
+		}
+		opps[opp.NoticeID] = opp
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating opportunities: %w", err)
+	}
+	return opps, nil
+}
+
+// scanOpportunityDetail scans one opportunityDetailQuery row, filling fields only present in raw_data
+func scanOpportunityDetail(row pgx.Row) (*models.Opportunity, error) {
+	var opp models.Opportunity
+	var naicsJSON, contactJSON, placeJSON, linksJSON json.RawMessage
+	var activeBool bool
+	var rawDataJSON json.RawMessage
+
+	var solicitationNumber, agencyPathName *string
+	err := row.Scan(
 		&opp.NoticeID, &opp.Title, &opp.OrganizationType, &opp.PostedDate, &opp.Type, &opp.BaseType,
 		&opp.ArchiveType, &opp.ArchiveDate, &opp.TypeOfSetAside, &opp.TypeOfSetAsideDesc,
 		&opp.ResponseDeadline, &naicsJSON, &opp.ClassificationCode, &activeBool,
 		&contactJSON, &placeJSON, &opp.Description, &opp.Department,
 		&opp.SubTier, &opp.Office, &linksJSON, &solicitationNumber, &agencyPathName,
-		&rawDataJSON,
+		&opp.TitleSynthesized, &opp.SAMUIURL, &rawDataJSON,
 	)
 	if err != nil {
-		// Check if error is due to missing columns (migration not run)
-		errStr := err.Error()
-		if strings.Contains(errStr, "solicitation_number") || 
-		   strings.Contains(errStr, "agency_path_name") ||
-		   (strings.Contains(errStr, "column") && strings.Contains(errStr, "does not exist")) {
-			return nil, fmt.Errorf("database migration required: %w. Run: pnpm --filter api db:migrate", err)
-		}
-		return nil, fmt.Errorf("failed to get opportunity: %w", err)
+		return nil, err
 	}
 
 	opp.Active = models.FlexibleBool(activeBool)
 
-	// Assign optional fields
-	if solicitationNumber != nil {
-		opp.SolicitationNumber = *solicitationNumber
-	}
-	if agencyPathName != nil {
-		opp.AgencyPathName = *agencyPathName
-	}
+	// Optional fields stay nil when the column is NULL
+	opp.SolicitationNumber = solicitationNumber
+	opp.AgencyPathName = agencyPathName
 
 	// Unmarshal JSON fields
 	if len(naicsJSON) > 0 {
@@ -252,37 +315,25 @@ func (r *OpportunityRepository) GetOpportunityByNoticeID(ctx context.Context, no
 	if len(contactJSON) > 0 {
 		json.Unmarshal(contactJSON, &opp.PointOfContact)
 	}
-	if len(placeJSON) > 0 {
-		json.Unmarshal(placeJSON, &opp.PlaceOfPerformance)
+	if err := models.DecodePlaceOfPerformance(placeJSON, &opp); err != nil {
+		fmt.Printf("Warning: Failed to decode place_of_performance for %s: %v\n", opp.NoticeID, err)
 	}
 	if len(linksJSON) > 0 {
 		json.Unmarshal(linksJSON, &opp.Links)
 	}
 
 	// Extract missing fields from raw_data
+	var rawData map[string]interface{}
 	if len(rawDataJSON) > 0 {
-		var rawData map[string]interface{}
-		if err := json.Unmarshal(rawDataJSON, &rawData); err == nil {
-			// Extract fullParentPathName and fullParentPathCode
-			if val, ok := rawData["fullParentPathName"].(string); ok && val != "" {
-				opp.FullParentPathName = val
-			}
-			if val, ok := rawData["fullParentPathCode"].(string); ok && val != "" {
-				opp.FullParentPathCode = val
-			}
-
-			// Extract typeOfSetAsideDescription
-			if val, ok := rawData["typeOfSetAsideDescription"].(string); ok && val != "" {
-				opp.TypeOfSetAsideDescription = val
-			} else if val, ok := rawData["typeOfSetAsideDescription"]; ok && val != nil {
-				// Handle null explicitly
-				opp.TypeOfSetAsideDescription = ""
-			}
+		if err := models.DecodeJSON(rawDataJSON, &rawData); err == nil {
+			// Optional strings: nil when the key is missing or null, "" when SAM sent an empty string
+			opp.FullParentPathName = rawString(rawData, "fullParentPathName")
+			opp.FullParentPathCode = rawString(rawData, "fullParentPathCode")
+			opp.TypeOfSetAsideDescription = rawString(rawData, "typeOfSetAsideDescription")
+			opp.UILink = rawString(rawData, "uiLink")
 
 			// Extract naicsCode and naicsCodes
-			if val, ok := rawData["naicsCode"].(string); ok && val != "" {
-				opp.NAICSCode = val
-			}
+			opp.NAICSCode = rawString(rawData, "naicsCode")
 			if val, ok := rawData["naicsCodes"].([]interface{}); ok {
 				naicsCodes := make([]string, 0, len(val))
 				for _, v := range val {
@@ -325,11 +376,6 @@ func (r *OpportunityRepository) GetOpportunityByNoticeID(ctx context.Context, no
 				}
 			}
 
-			// Extract uiLink
-			if val, ok := rawData["uiLink"].(string); ok && val != "" {
-				opp.UILink = val
-			}
-
 			// Extract resourceLinks
 			if val, exists := rawData["resourceLinks"]; exists && val != nil {
 				if arr, ok := val.([]interface{}); ok {
@@ -347,23 +393,80 @@ func (r *OpportunityRepository) GetOpportunityByNoticeID(ctx context.Context, no
 		}
 	}
 
+	opp.Provenance = detailProvenance(&opp, rawData)
 	return &opp, nil
 }
 
+// detailProvenance marks each enrichable field that has a value as SAM's or derived, comparing the stored
+// value with raw_data (nil when the raw row is missing, so everything with a value counts as derived):
+// a synthesized title, a date-only deadline stored as end of day, and an agency path that isn't SAM's
+// fullParentPathName
+func detailProvenance(opp *models.Opportunity, rawData map[string]interface{}) map[string]string {
+	provenance := make(map[string]string)
+	mark := func(field string, fromSAM bool) {
+		if fromSAM {
+			provenance[field] = models.ProvenanceSAM
+		} else {
+			provenance[field] = models.ProvenanceDerived
+		}
+	}
+
+	mark("title", !opp.TitleSynthesized && rawData != nil)
+
+	if opp.ResponseDeadline != "" {
+		// SAM spells the key responseDeadLine; file imports and re-serialized raw rows use responseDeadline
+		raw := rawString(rawData, "responseDeadLine")
+		if raw == nil {
+			raw = rawString(rawData, "responseDeadline")
+		}
+		// Compare in the format the API returns, so a reformatted offset isn't mistaken for a rewrite
+		mark("responseDeadline", raw != nil && models.FormatAPIDate(*raw) == models.FormatAPIDate(opp.ResponseDeadline))
+	}
+
+	if opp.AgencyPathName != nil && *opp.AgencyPathName != "" {
+		raw := rawString(rawData, "fullParentPathName")
+		mark("agencyPathName", raw != nil && *raw == *opp.AgencyPathName)
+	}
+	return provenance
+}
+
+// hasAttachmentsPredicate matches opportunity_raw rows whose SAM response lists attachments (resourceLinks). It is
+// the predicate of idx_opportunity_raw_has_attachments (migration 018) and must stay identical to it.
+const hasAttachmentsPredicate = "jsonb_typeof(r.raw_data->'resourceLinks') = 'array' AND r.raw_data->'resourceLinks' <> '[]'::jsonb"
+
 // SearchParamsV2 represents search parameters for the new search endpoint
 type SearchParamsV2 struct {
 	Q          string // keyword search
+	SearchFields []string // fields Q searches (IsValidSearchField names); empty searches all of them
 	NAICS      string // exact match in JSONB array
-	SetAside   string // exact match
+	SetAside   string // exact match; comma-separated for any of several codes
 	State      string // extract from place_of_performance JSONB
 	Agency     string // prefix/ILIKE match on agency_path_name
+	Classification       string // PSC/FSC classification_code; exact match unless ClassificationPrefix
+	ClassificationPrefix bool   // match classification_code by prefix (e.g. "R4" matches R408, R425)
 	PostedFrom string // date range
 	PostedTo   string
 	DueFrom    string
 	DueTo      string
+	DescriptionStatus string // none, ready, not_found, error, available_unfetched
+	Tag        string // opportunities carrying this tag (opportunity_tag)
+	ExcludeTypes []string // lower-cased notice types to leave out, matched against type and base_type (models.ParseNoticeTypeList)
+	NoticeTypes []string // lower-cased notice types to keep, matched against this notice's type only (models.ParseNoticeTypeList)
+	ProcurementTypes []string // SAM ptype codes to keep, matched against procurement_type (models.IsValidProcurementType)
+	HasAttachments *bool // true: only opportunities with SAM resourceLinks attachments; false: only those without; nil: either
+	IncludeArchived bool // include archived opportunities alongside open ones
+	ArchivedOnly    bool // only archived opportunities (takes precedence over IncludeArchived)
 	Sort       string // posted_desc, due_asc, relevance
+	RecencyBoost bool // relevance sort only: decay ts_rank by days since posted
 	Limit      int    // default 25, max 100
 	Cursor     string // base64 JSON cursor
+	Explain    bool   // also run the query under EXPLAIN ANALYZE and return SQL and plan in Debug
+	ExplainRelevance bool // relevance sort with Q only: return each item's score and matched terms per field
+	Highlight  bool   // with Q only: return a description snippet with the matched terms marked
+	HighlightMarkup string // html (default) or markdown: how Highlight marks matches
+	SeenSince  *time.Time // caller's last visit: set each item's Freshness against it; nil leaves Freshness empty
+
+	materializedStatus bool // set by the repository: read opportunity.description_status instead of joining
 }
 
 // SearchResultV2 represents the search result with cursor pagination
@@ -377,7 +480,9 @@ type SearchResultV2 struct {
 type Cursor struct {
 	PostedDate       string `json:"postedDate,omitempty"`
 	ResponseDeadline string `json:"responseDeadline,omitempty"`
-	NoticeID         string `json:"noticeId"`
+	NullDate         bool     `json:"nullDate,omitempty"` // the last row's sort date was NULL: paging is inside the NULLS LAST tail
+	Rank             *float64 `json:"rank,omitempty"`     // the last row's relevance rank, for sort=relevance with a query
+	NoticeID         string   `json:"noticeId"`
 }
 
 // encodeCursor encodes a cursor to base64 JSON string
@@ -402,9 +507,182 @@ func decodeCursor(encoded string) (*Cursor, error) {
 	return &cursor, nil
 }
 
-// SearchOpportunitiesV2 searches opportunities with filters, keyset pagination, and full-text search.
-func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, params SearchParamsV2) (*SearchResultV2, error) {
-	// Build WHERE clause dynamically
+// descriptionStatusExpr derives the UI description status from the opportunity_description LEFT JOIN (aliased od)
+const descriptionStatusExpr = `CASE
+				WHEN od.source_type = 'none' OR od.source_type IS NULL THEN 'none'
+				WHEN od.fetch_status = 'fetched' THEN 'ready'
+				WHEN od.fetch_status = 'not_found' THEN 'not_found'
+				WHEN od.fetch_status = 'error' THEN 'error'
+				WHEN od.fetch_status = 'not_requested' THEN 'available_unfetched'
+				ELSE 'available_unfetched'
+			END`
+
+// materializedDescriptionStatusExpr reads the status migration 011 keeps on opportunity, avoiding the join
+const materializedDescriptionStatusExpr = "o.description_status"
+
+// descriptionStatusSQL returns the status expression and the join it needs ("" for the materialized column)
+func descriptionStatusSQL(materialized bool) (expr string, join string) {
+	if materialized {
+		return materializedDescriptionStatusExpr, ""
+	}
+	return descriptionStatusExpr, "LEFT JOIN opportunity_description od ON o.notice_id = od.notice_id"
+}
+
+// hasDescriptionStatusColumn reports whether opportunity.description_status exists. A successful lookup is
+// cached for the repository's lifetime, so the API picks the column up on restart after migrating
+func (r *OpportunityRepository) hasDescriptionStatusColumn(ctx context.Context) bool {
+	r.statusColumnMu.Lock()
+	defer r.statusColumnMu.Unlock()
+	if r.statusColumnChecked {
+		r.statusColumnHits++
+		return r.statusColumn
+	}
+	r.statusColumnMisses++
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'opportunity' AND column_name = 'description_status'
+		)
+	`).Scan(&r.statusColumn)
+	if err != nil {
+		// Computed status still works; check again next time
+		return false
+	}
+	r.statusColumnChecked = true
+	return r.statusColumn
+}
+
+// CacheStats reports the description_status column lookup cache (size 1 once the result is cached)
+func (r *OpportunityRepository) CacheStats() models.CacheStats {
+	r.statusColumnMu.Lock()
+	defer r.statusColumnMu.Unlock()
+	stats := models.CacheStats{Hits: r.statusColumnHits, Misses: r.statusColumnMisses}
+	if r.statusColumnChecked {
+		stats.Size = 1
+	}
+	return stats
+}
+
+// PurgeCache forgets the description_status column lookup, so the next search checks the schema again
+// (e.g. after running migration 011 without restarting the API)
+func (r *OpportunityRepository) PurgeCache() {
+	r.statusColumnMu.Lock()
+	r.statusColumnChecked = false
+	r.statusColumn = false
+	r.statusColumnMu.Unlock()
+}
+
+// validDescriptionStatuses are the values descriptionStatusExpr can produce
+var validDescriptionStatuses = map[string]bool{
+	"none":                true,
+	"ready":               true,
+	"not_found":           true,
+	"error":               true,
+	"available_unfetched": true,
+}
+
+// IsValidDescriptionStatus reports whether s is a descriptionStatus value search can filter on
+func IsValidDescriptionStatus(s string) bool {
+	return validDescriptionStatuses[s]
+}
+
+// searchFieldColumns whitelists the fields keyword search can be scoped to (searchFields), in the order
+// they are concatenated into the search vector. Only these columns are ever interpolated into SQL.
+var searchFieldColumns = []struct{ name, column string }{
+	{"title", "title"},
+	{"solicitationNumber", "solicitation_number"},
+	{"agency", "agency_path_name"},
+	{"description", "description"},
+}
+
+// IsValidSearchField reports whether s is a field keyword search can be scoped to
+func IsValidSearchField(s string) bool {
+	for _, field := range searchFieldColumns {
+		if field.name == s {
+			return true
+		}
+	}
+	return false
+}
+
+// scopedSearchFields returns the whitelisted fields selected by name, in whitelist order; no names (the
+// default) selects all of them. Unknown names are ignored.
+func scopedSearchFields(fields []string) []struct{ name, column string } {
+	if len(fields) == 0 {
+		return searchFieldColumns
+	}
+	selected := make(map[string]bool, len(fields))
+	for _, name := range fields {
+		selected[name] = true
+	}
+	var scoped []struct{ name, column string }
+	for _, field := range searchFieldColumns {
+		if selected[field.name] {
+			scoped = append(scoped, field)
+		}
+	}
+	return scoped
+}
+
+// searchVectorExpr returns the to_tsvector expression keyword search matches and ranks against, over the
+// selected fields; no fields (the default) searches all of them. Unknown names are ignored.
+func searchVectorExpr(fields []string) string {
+	var parts []string
+	for _, field := range scopedSearchFields(fields) {
+		parts = append(parts, fmt.Sprintf("COALESCE(%s, '')", field.column))
+	}
+	if len(parts) == 0 {
+		return "to_tsvector('english', '')"
+	}
+	return fmt.Sprintf("to_tsvector('english', %s)", strings.Join(parts, " || ' ' || "))
+}
+
+// placeStateExpr is the place of performance state code: state is stored as {"code", "name"}, but rows
+// from before migration 016 may hold the bare code. Indexed as written by idx_opportunity_pop_state_code.
+const placeStateExpr = "COALESCE(place_of_performance->'state'->>'code', place_of_performance->>'state')"
+
+// orderByV2 returns the ORDER BY for a sort type
+// Every order ends with o.notice_id ASC (the primary key) so ties are fully deterministic,
+// and NULLs always sort last - cursorConditionV2 relies on both
+func orderByV2(sortType string) string {
+	switch sortType {
+	case "due_asc":
+		return "o.response_deadline ASC NULLS LAST, o.notice_id ASC"
+	default: // posted_desc, and relevance without a query
+		return "o.posted_date DESC NULLS LAST, o.notice_id ASC"
+	}
+}
+
+// cursorConditionV2 returns the keyset predicate selecting rows strictly after cursor in orderByV2 order
+// (relevance without a query is posted_desc order; ranked relevance uses rankCursorConditionV2)
+// NULL dates sort last: a cursor on a dated row keeps the whole NULL tail ahead of it, and a cursor inside the
+// tail (NullDate) continues it by notice_id alone, so no row is returned twice
+func cursorConditionV2(sortType string, cursor Cursor, argPos int) (string, []interface{}) {
+	column, after, date := "o.posted_date", "<", cursor.PostedDate
+	if sortType == "due_asc" {
+		column, after, date = "o.response_deadline", ">", cursor.ResponseDeadline
+	}
+	if cursor.NullDate {
+		return fmt.Sprintf("(%s IS NULL AND o.notice_id > $%d)", column, argPos), []interface{}{cursor.NoticeID}
+	}
+	return fmt.Sprintf(
+		"(%s %s $%d OR (%s = $%d AND o.notice_id > $%d) OR %s IS NULL)",
+		column, after, argPos, column, argPos, argPos+1, column,
+	), []interface{}{date, cursor.NoticeID}
+}
+
+// rankCursorConditionV2 is the keyset predicate for relevance order (rankExpr DESC, then posted_desc order):
+// rows ranked below the cursor's row, or ranked the same and after it by posted date and notice ID.
+// rankExpr must be the ORDER BY expression, so the rank it computes matches the one stored in the cursor.
+func rankCursorConditionV2(rankExpr string, cursor Cursor, argPos int) (string, []interface{}) {
+	tieBreak, tieBreakArgs := cursorConditionV2("posted_desc", cursor, argPos+1)
+	return fmt.Sprintf("(%s < $%d OR (%s = $%d AND %s))", rankExpr, argPos, rankExpr, argPos, tieBreak),
+		append([]interface{}{*cursor.Rank}, tieBreakArgs...)
+}
+
+// buildSearchFiltersV2 builds the WHERE conditions and positional args for the V2 search filters
+// Returns the next free argument position so callers can keep appending (cursor, ORDER BY, LIMIT)
+func buildSearchFiltersV2(params SearchParamsV2) ([]string, []interface{}, int) {
 	conditions := []string{}
 	args := []interface{}{}
 	argPos := 1
@@ -412,16 +690,11 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 	// Keyword search - use computed tsvector (works with or without migration)
 	// If search_tsv column exists (after migration), it will be faster, but this works either way
 	if params.Q != "" {
-		// Use computed tsvector that includes all searchable fields
+		// Use computed tsvector over the searched fields (all of them unless SearchFields narrows it)
 		// This works whether or not the migration has been run
 		conditions = append(conditions, fmt.Sprintf(
-			`to_tsvector('english', 
-				COALESCE(title, '') || ' ' || 
-				COALESCE(solicitation_number, '') || ' ' || 
-				COALESCE(agency_path_name, '') || ' ' || 
-				COALESCE(description, '')
-			) @@ websearch_to_tsquery('english', $%d)`,
-			argPos))
+			"%s @@ websearch_to_tsquery('english', $%d)",
+			searchVectorExpr(params.SearchFields), argPos))
 		args = append(args, params.Q)
 		argPos++
 	}
@@ -434,16 +707,24 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 		argPos++
 	}
 
-	// Set-aside filter
-	if params.SetAside != "" {
+	// Set-aside filter - comma-separated list of codes, any of which may match
+	if setAsides := parseSetAsideList(params.SetAside); len(setAsides) == 1 {
 		conditions = append(conditions, fmt.Sprintf("type_of_set_aside = $%d", argPos))
-		args = append(args, params.SetAside)
+		args = append(args, setAsides[0])
 		argPos++
+	} else if len(setAsides) > 1 {
+		placeholders := make([]string, len(setAsides))
+		for i, code := range setAsides {
+			placeholders[i] = fmt.Sprintf("$%d", argPos)
+			args = append(args, code)
+			argPos++
+		}
+		conditions = append(conditions, fmt.Sprintf("type_of_set_aside IN (%s)", strings.Join(placeholders, ", ")))
 	}
 
 	// State filter - extract from place_of_performance JSONB
 	if params.State != "" {
-		conditions = append(conditions, fmt.Sprintf("place_of_performance->>'state' = $%d", argPos))
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", placeStateExpr, argPos))
 		args = append(args, params.State)
 		argPos++
 	}
@@ -455,6 +736,50 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 		argPos++
 	}
 
+	// Classification (PSC) filter - exact match, or prefix match for a whole PSC group
+	if params.Classification != "" {
+		if params.ClassificationPrefix {
+			conditions = append(conditions, fmt.Sprintf("classification_code LIKE $%d", argPos))
+			args = append(args, escapeLike(params.Classification)+"%")
+		} else {
+			conditions = append(conditions, fmt.Sprintf("classification_code = $%d", argPos))
+			args = append(args, params.Classification)
+		}
+		argPos++
+	}
+
+	// Notice type exclusion - drops boilerplate types (e.g. award notices) whether SAM sent them as type or base_type
+	if len(params.ExcludeTypes) > 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"NOT (LOWER(COALESCE(type, '')) = ANY($%d) OR LOWER(COALESCE(base_type, '')) = ANY($%d))", argPos, argPos))
+		args = append(args, params.ExcludeTypes)
+		argPos++
+	}
+
+	// Notice type filter - this notice's category, e.g. "combined synopsis/solicitation" but not its award notice
+	if len(params.NoticeTypes) > 0 {
+		conditions = append(conditions, fmt.Sprintf("LOWER(BTRIM(COALESCE(type, ''))) = ANY($%d)", argPos))
+		args = append(args, params.NoticeTypes)
+		argPos++
+	}
+
+	// Procurement type filter - SAM ptype of the procurement, kept across amendments and award notices
+	if len(params.ProcurementTypes) > 0 {
+		conditions = append(conditions, fmt.Sprintf("procurement_type = ANY($%d)", argPos))
+		args = append(args, params.ProcurementTypes)
+		argPos++
+	}
+
+	// Attachment filter - EXISTS on opportunity_raw, served by the partial index from
+	// migrations/018_opportunity_raw_resource_links_index.sql
+	if params.HasAttachments != nil {
+		exists := "EXISTS (SELECT 1 FROM opportunity_raw r WHERE r.notice_id = o.notice_id AND " + hasAttachmentsPredicate + ")"
+		if !*params.HasAttachments {
+			exists = "NOT " + exists
+		}
+		conditions = append(conditions, exists)
+	}
+
 	// Posted date range
 	if params.PostedFrom != "" {
 		postedFromDB, err := convertDateFormat(params.PostedFrom)
@@ -484,14 +809,136 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 		}
 	}
 
+	// Deadlines are stored as strings, with a time of day (e.g. end of day for date-only SAM deadlines),
+	// so dueTo covers the whole day by comparing against the start of the next one
 	if params.DueTo != "" {
 		dueToDB, err := convertDateFormat(params.DueTo)
 		if err == nil {
-			conditions = append(conditions, fmt.Sprintf("response_deadline <= $%d", argPos))
-			args = append(args, dueToDB)
-			argPos++
+			if day, err := time.Parse("2006-01-02", dueToDB); err == nil {
+				conditions = append(conditions, fmt.Sprintf("response_deadline < $%d", argPos))
+				args = append(args, day.AddDate(0, 0, 1).Format("2006-01-02"))
+				argPos++
+			}
+		}
+	}
+
+	// Description status filter - repeats the status expression since WHERE can't see the SELECT alias
+	if params.DescriptionStatus != "" {
+		statusExpr, _ := descriptionStatusSQL(params.materializedStatus)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", statusExpr, argPos))
+		args = append(args, params.DescriptionStatus)
+		argPos++
+	}
+
+	// Tag filter - requires migrations/007_opportunity_tag.sql
+	if params.Tag != "" {
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM opportunity_tag ot WHERE ot.notice_id = o.notice_id AND ot.tag = $%d)", argPos))
+		args = append(args, params.Tag)
+		argPos++
+	}
+
+	// Archive status - archivedOnly wins over includeArchived; by default archived opportunities are excluded
+	today := appNow().Format("2006-01-02")
+	switch {
+	case params.ArchivedOnly:
+		conditions = append(conditions, archivedCondition(argPos))
+		args = append(args, today)
+		argPos++
+	case params.IncludeArchived:
+		// No archive filter
+	default:
+		conditions = append(conditions, "NOT "+archivedCondition(argPos))
+		args = append(args, today)
+		argPos++
+	}
+
+	return conditions, args, argPos
+}
+
+// setAsideAliases maps common spellings to the SAM typeOfSetAside codes stored in type_of_set_aside
+var setAsideAliases = map[string]string{
+	"8(A)":          "8A",
+	"SDVOSB":        "SDVOSBC",
+	"HUBZONE":       "HZC",
+	"SMALLBUSINESS": "SBA",
+}
+
+// CanonicalSetAside normalizes a set-aside code for matching: trimmed, upper-cased, common aliases resolved
+func CanonicalSetAside(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if canonical, ok := setAsideAliases[strings.ReplaceAll(code, " ", "")]; ok {
+		return canonical
+	}
+	return code
+}
+
+// parseSetAsideList splits a comma-separated setAside param into canonical codes, dropping empties and duplicates
+func parseSetAsideList(value string) []string {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		code := CanonicalSetAside(part)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// defaultRecencyHalfLifeDays is how many days it takes the recency boost to halve a match's rank
+const defaultRecencyHalfLifeDays = 30
+
+// getRecencyHalfLifeDays returns the recency boost half-life (SEARCH_RECENCY_HALF_LIFE_DAYS or default)
+func getRecencyHalfLifeDays() int {
+	if daysStr := os.Getenv("SEARCH_RECENCY_HALF_LIFE_DAYS"); daysStr != "" {
+		if days, err := strconv.Atoi(daysStr); err == nil && days > 0 {
+			return days
 		}
 	}
+	return defaultRecencyHalfLifeDays
+}
+
+// recencyDecayExpr is a multiplier in (0, 1]: 0.5^(days since posted / halfLifeDays), with today at $argPos
+// posted_date is VARCHAR, so only values starting with a real calendar date are cast; undated rows and
+// impossible dates ("2025-13-45") decay to 0 and sort last instead of failing the search
+// Future-dated postings are not boosted above 1
+func recencyDecayExpr(argPos int, halfLifeDays int) string {
+	return fmt.Sprintf(
+		`(CASE WHEN %s THEN power(0.5, GREATEST($%d::date - LEFT(o.posted_date, 10)::date, 0)::float8 / %d) ELSE 0 END)`,
+		calendarDatePrefixExpr("o.posted_date"), argPos, halfLifeDays)
+}
+
+// calendarDatePrefixExpr is true when col starts with a valid YYYY-MM-DD date, so LEFT(col, 10)::date can't
+// raise an error. The pattern bounds month and day; the day is then checked against the month's length
+// (leap years included). Nested CASE, not AND, because Postgres doesn't promise to short-circuit AND.
+func calendarDatePrefixExpr(col string) string {
+	return fmt.Sprintf(
+		`(CASE WHEN %[1]s ~ '^[1-9]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])' `+
+			`THEN SUBSTRING(%[1]s, 9, 2)::int <= EXTRACT(DAY FROM make_date(LEFT(%[1]s, 4)::int, SUBSTRING(%[1]s, 6, 2)::int, 1) + interval '1 month - 1 day') `+
+			`ELSE false END)`,
+		col)
+}
+
+// escapeLike escapes LIKE wildcards so user input only matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// archivedCondition matches opportunities ingestion stored as archived (archived_at) or whose archive date has
+// passed since; archive_date is stored as VARCHAR (YYYY-MM-DD...), so the first 10 chars compare as a date string
+func archivedCondition(argPos int) string {
+	return fmt.Sprintf("(o.archived_at IS NOT NULL OR (COALESCE(o.archive_date, '') <> '' AND LEFT(o.archive_date, 10) < $%d))", argPos)
+}
+
+// SearchOpportunitiesV2 searches opportunities with filters, keyset pagination, and full-text search.
+func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, params SearchParamsV2) (*SearchResultV2, error) {
+	params.materializedStatus = r.hasDescriptionStatusColumn(ctx)
+	statusExpr, statusJoin := descriptionStatusSQL(params.materializedStatus)
+
+	// Build WHERE clause dynamically
+	conditions, args, argPos := buildSearchFiltersV2(params)
 
 	// Handle cursor for keyset pagination
 	var cursor *Cursor
@@ -504,41 +951,44 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 
 	// Add cursor conditions based on sort type
 	sortType := params.Sort
-	if sortType == "" {
+	if sortType != "due_asc" && sortType != "relevance" {
 		sortType = "posted_desc"
 	}
 
+	// Relevance with a query orders by ts_rank over the same fields the keyword filter matched (computed
+	// tsvector, works with or without migration). The rank leads the ORDER BY and the cursor, so it is built first.
+	ranked := sortType == "relevance" && params.Q != ""
+	rankExpr := ""
+	if ranked {
+		rankExpr = fmt.Sprintf(
+			"ts_rank(%s, websearch_to_tsquery('english', $%d))",
+			searchVectorExpr(params.SearchFields), argPos)
+		args = append(args, params.Q)
+		argPos++
+
+		// Optional recency boost: halve the rank every half-life since posting
+		if params.RecencyBoost {
+			rankExpr = fmt.Sprintf("%s * %s", rankExpr, recencyDecayExpr(argPos, getRecencyHalfLifeDays()))
+			args = append(args, appNow().Format("2006-01-02"))
+			argPos++
+		}
+	}
+
+	// A ranked cursor needs the rank of the row it stopped at; one without (e.g. from another sort) is ignored
+	if cursor != nil && ranked && cursor.Rank == nil {
+		cursor = nil
+	}
 	if cursor != nil {
-		switch sortType {
-		case "posted_desc":
-			if cursor.PostedDate != "" {
-				conditions = append(conditions, fmt.Sprintf(
-					"(posted_date < $%d OR (posted_date = $%d AND notice_id < $%d))",
-					argPos, argPos, argPos+1,
-				))
-				args = append(args, cursor.PostedDate, cursor.NoticeID)
-				argPos += 2
-			}
-		case "due_asc":
-			if cursor.ResponseDeadline != "" {
-				conditions = append(conditions, fmt.Sprintf(
-					"(response_deadline > $%d OR (response_deadline = $%d AND notice_id > $%d) OR (response_deadline IS NULL AND notice_id > $%d))",
-					argPos, argPos, argPos+1, argPos+1,
-				))
-				args = append(args, cursor.ResponseDeadline, cursor.NoticeID)
-				argPos += 2
-			}
-		case "relevance":
-			// Fall back to posted_desc cursor format
-			if cursor.PostedDate != "" {
-				conditions = append(conditions, fmt.Sprintf(
-					"(posted_date < $%d OR (posted_date = $%d AND notice_id < $%d))",
-					argPos, argPos, argPos+1,
-				))
-				args = append(args, cursor.PostedDate, cursor.NoticeID)
-				argPos += 2
-			}
+		var condition string
+		var cursorArgs []interface{}
+		if ranked {
+			condition, cursorArgs = rankCursorConditionV2(rankExpr, *cursor, argPos)
+		} else {
+			condition, cursorArgs = cursorConditionV2(sortType, *cursor, argPos)
 		}
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+		argPos += len(cursorArgs)
 	}
 
 	whereClause := ""
@@ -557,31 +1007,17 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 
 	// Build ORDER BY clause based on sort type
 	var orderBy string
-	switch sortType {
-	case "due_asc":
-		orderBy = "response_deadline ASC NULLS LAST, notice_id ASC"
-	case "relevance":
-		if params.Q != "" {
-			// Use ts_rank for relevance when searching (computed tsvector, works with or without migration)
-			orderBy = fmt.Sprintf(
-				`ts_rank(to_tsvector('english', 
-					COALESCE(title, '') || ' ' || 
-					COALESCE(solicitation_number, '') || ' ' || 
-					COALESCE(agency_path_name, '') || ' ' || 
-					COALESCE(description, '')
-				), websearch_to_tsquery('english', $%d)) DESC, posted_date DESC NULLS LAST, notice_id ASC`,
-				argPos)
-			args = append(args, params.Q)
-			argPos++
-		} else {
-			// Fall back to posted_desc if no search query
-			orderBy = "posted_date DESC NULLS LAST, notice_id ASC"
-		}
-	default: // posted_desc
-		orderBy = "posted_date DESC NULLS LAST, notice_id ASC"
+	explainRelevance := params.ExplainRelevance && ranked
+	scoreColumn := ""
+	if ranked {
+		orderBy = fmt.Sprintf("%s DESC, %s", rankExpr, orderByV2("posted_desc"))
+		// Same expression as the ORDER BY, so the returned scores (and the next cursor) match the result order
+		scoreColumn = fmt.Sprintf(",\n\t\t\t%s AS relevance_score", rankExpr)
+	} else {
+		orderBy = orderByV2(sortType)
 	}
 
-	// Build SELECT query with LEFT JOIN to opportunity_description for descriptionStatus
+	// Build SELECT query; descriptionStatus comes from the materialized column, or a LEFT JOIN to opportunity_description
 	// Note: If migration hasn't been run, solicitation_number and agency_path_name columns won't exist
 	// The query will fail with a clear error that should prompt running the migration
 	query := fmt.Sprintf(`
@@ -590,24 +1026,25 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 			o.archive_type, o.archive_date, o.type_of_set_aside, o.type_of_set_aside_desc,
 			o.response_deadline, o.naics, o.classification_code, o.active,
 			o.point_of_contact, o.place_of_performance, o.description, o.department,
-			o.sub_tier, o.office, o.links, o.solicitation_number, o.agency_path_name,
-			CASE
-				WHEN od.source_type = 'none' OR od.source_type IS NULL THEN 'none'
-				WHEN od.fetch_status = 'fetched' THEN 'ready'
-				WHEN od.fetch_status = 'not_found' THEN 'not_found'
-				WHEN od.fetch_status = 'error' THEN 'error'
-				WHEN od.fetch_status = 'not_requested' THEN 'available_unfetched'
-				ELSE 'available_unfetched'
-			END AS description_status
+			o.sub_tier, o.office, o.links, o.solicitation_number, o.agency_path_name, o.sam_ui_url,
+			o.first_seen, o.last_updated, %s AS description_status%s
 		FROM opportunity o
-		LEFT JOIN opportunity_description od ON o.notice_id = od.notice_id
+		%s
 		%s
 		ORDER BY %s
 		LIMIT $%d
-	`, whereClause, orderBy, argPos)
+	`, statusExpr, scoreColumn, statusJoin, whereClause, orderBy, argPos)
 
 	args = append(args, limit+1) // Fetch one extra to determine if there's a next page
 
+	// EXPLAIN ANALYZE executes the query, so this doubles its cost; callers gate it
+	var plan json.RawMessage
+	if params.Explain {
+		if err := r.db.QueryRow(ctx, "EXPLAIN (ANALYZE, FORMAT JSON) "+query, args...).Scan(&plan); err != nil {
+			return nil, fmt.Errorf("failed to explain search query: %w", err)
+		}
+	}
+
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		// Check if error is due to missing columns (migration not run)
@@ -622,35 +1059,59 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 	defer rows.Close()
 
 	var opportunities []models.Opportunity
+	var nullSortDates []bool // per row: the sort column was NULL, for the next cursor
+	var ranks []float64      // per row: the relevance rank, for the next cursor when ranked
 	for rows.Next() {
 		var opp models.Opportunity
 		var naicsJSON, contactJSON, placeJSON, linksJSON json.RawMessage
 		var activeBool bool
+		var postedDate, responseDeadline *string
 		var solicitationNumber, agencyPathName *string
 		var descriptionStatus *string
+		var firstSeen, lastUpdated time.Time
+		var score float64
 
-		err := rows.Scan(
-			&opp.NoticeID, &opp.Title, &opp.OrganizationType, &opp.PostedDate, &opp.Type, &opp.BaseType,
+		dest := []interface{}{
+			&opp.NoticeID, &opp.Title, &opp.OrganizationType, &postedDate, &opp.Type, &opp.BaseType,
 			&opp.ArchiveType, &opp.ArchiveDate, &opp.TypeOfSetAside, &opp.TypeOfSetAsideDesc,
-			&opp.ResponseDeadline, &naicsJSON, &opp.ClassificationCode, &activeBool,
+			&responseDeadline, &naicsJSON, &opp.ClassificationCode, &activeBool,
 			&contactJSON, &placeJSON, &opp.Description, &opp.Department,
-			&opp.SubTier, &opp.Office, &linksJSON, &solicitationNumber, &agencyPathName,
-			&descriptionStatus,
-		)
+			&opp.SubTier, &opp.Office, &linksJSON, &solicitationNumber, &agencyPathName, &opp.SAMUIURL,
+			&firstSeen, &lastUpdated, &descriptionStatus,
+		}
+		if ranked {
+			dest = append(dest, &score)
+		}
+		err := rows.Scan(dest...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan opportunity: %w", err)
 		}
 
-		// Assign optional fields
-		if solicitationNumber != nil {
-			opp.SolicitationNumber = *solicitationNumber
+		// Optional fields stay nil when the column is NULL
+		opp.SolicitationNumber = solicitationNumber
+		opp.AgencyPathName = agencyPathName
+		if postedDate != nil {
+			opp.PostedDate = *postedDate
+		}
+		if responseDeadline != nil {
+			opp.ResponseDeadline = *responseDeadline
 		}
-		if agencyPathName != nil {
-			opp.AgencyPathName = *agencyPathName
+		if sortType == "due_asc" {
+			nullSortDates = append(nullSortDates, responseDeadline == nil)
+		} else {
+			nullSortDates = append(nullSortDates, postedDate == nil)
 		}
+		ranks = append(ranks, score)
 		if descriptionStatus != nil {
 			opp.DescriptionStatus = *descriptionStatus
 		}
+		opp.FirstSeen, opp.LastUpdated = &firstSeen, &lastUpdated
+		if params.SeenSince != nil {
+			opp.Freshness = models.ClassifyFreshness(firstSeen, lastUpdated, *params.SeenSince)
+		}
+		if explainRelevance {
+			opp.Relevance = &models.RelevanceExplanation{Score: score, Matches: map[string][]string{}}
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan opportunity: %w", err)
 		}
@@ -664,8 +1125,8 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 		if len(contactJSON) > 0 {
 			json.Unmarshal(contactJSON, &opp.PointOfContact)
 		}
-		if len(placeJSON) > 0 {
-			json.Unmarshal(placeJSON, &opp.PlaceOfPerformance)
+		if err := models.DecodePlaceOfPerformance(placeJSON, &opp); err != nil {
+			fmt.Printf("Warning: Failed to decode place_of_performance for %s: %v\n", opp.NoticeID, err)
 		}
 		if len(linksJSON) > 0 {
 			json.Unmarshal(linksJSON, &opp.Links)
@@ -688,6 +1149,11 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 		// Create cursor based on sort type
 		var cursor Cursor
 		cursor.NoticeID = lastItem.NoticeID
+		cursor.NullDate = nullSortDates[limit-1]
+		if ranked {
+			rank := ranks[limit-1]
+			cursor.Rank = &rank
+		}
 		switch sortType {
 		case "posted_desc", "relevance":
 			cursor.PostedDate = lastItem.PostedDate
@@ -701,21 +1167,59 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 		}
 	}
 
+	// Term matches are computed for the returned page only
+	if explainRelevance && len(opportunities) > 0 {
+		if err := r.explainRelevanceMatches(ctx, params.Q, params.SearchFields, opportunities); err != nil {
+			return nil, err
+		}
+	}
+
+	// Snippets are also computed for the returned page only; ts_headline re-parses each description
+	if params.Highlight && params.Q != "" && len(opportunities) > 0 {
+		if err := r.addSnippets(ctx, params.Q, params.HighlightMarkup, opportunities); err != nil {
+			return nil, err
+		}
+	}
+
 	// Build debug info (dev only)
 	debug := map[string]interface{}{
 		"sort":          sortType,
 		"appliedFilters": map[string]interface{}{
 			"q":          params.Q,
+			"searchFields": params.SearchFields,
 			"naics":      params.NAICS,
 			"setAside":   params.SetAside,
 			"state":      params.State,
 			"agency":     params.Agency,
+			"classification":       params.Classification,
+			"classificationPrefix": params.ClassificationPrefix,
 			"postedFrom": params.PostedFrom,
 			"postedTo":   params.PostedTo,
 			"dueFrom":    params.DueFrom,
 			"dueTo":      params.DueTo,
+			"descriptionStatus": params.DescriptionStatus,
+			"tag":               params.Tag,
+			"excludeTypes":      params.ExcludeTypes,
+			"noticeTypes":       params.NoticeTypes,
+			"procurementTypes":  params.ProcurementTypes,
+			"hasAttachments":    params.HasAttachments,
+			"includeArchived":   params.IncludeArchived,
+			"archivedOnly":      params.ArchivedOnly,
+			"recencyBoost":      params.RecencyBoost,
+			"explainRelevance":  explainRelevance,
+			"highlight":         params.Highlight,
+			"highlightMarkup":   params.HighlightMarkup,
+			"seenSince":         params.SeenSince,
 		},
 	}
+	if params.Explain {
+		debug["sql"] = strings.TrimSpace(query)
+		debug["plan"] = plan
+		// Flag filters that fell back to a sequential scan; a malformed plan just skips the advice
+		if warnings, err := IndexWarnings(plan); err == nil {
+			debug["indexWarnings"] = warnings
+		}
+	}
 
 	return &SearchResultV2{
 		Items:      opportunities,
@@ -724,9 +1228,315 @@ func (r *OpportunityRepository) SearchOpportunitiesV2(ctx context.Context, param
 	}, nil
 }
 
+// defaultSnippetMaxWords caps each highlighted fragment; SEARCH_SNIPPET_MAX_WORDS overrides it
+const defaultSnippetMaxWords = 35
+
+// getSnippetMaxWords returns the longest snippet fragment in words (SEARCH_SNIPPET_MAX_WORDS or default)
+func getSnippetMaxWords() int {
+	if wordsStr := os.Getenv("SEARCH_SNIPPET_MAX_WORDS"); wordsStr != "" {
+		if words, err := strconv.Atoi(wordsStr); err == nil && words > 1 {
+			return words
+		}
+	}
+	return defaultSnippetMaxWords
+}
+
+// Highlight markup modes for SearchParamsV2.HighlightMarkup
+const (
+	HighlightMarkupHTML     = "html"     // <mark>term</mark>
+	HighlightMarkupMarkdown = "markdown" // **term**
+)
+
+// IsValidHighlightMarkup reports whether s is a highlight markup mode
+func IsValidHighlightMarkup(s string) bool {
+	return s == HighlightMarkupHTML || s == HighlightMarkupMarkdown
+}
+
+// ts_headline marks matches with these private-use characters rather than the final markup, so the description
+// text around them can be escaped without touching the markers. They are stripped from descriptions first.
+const (
+	snippetStartSel = "\uE000"
+	snippetStopSel  = "\uE001"
+)
+
+// snippetOptions returns ts_headline options: up to two fragments of at most maxWords words, matches between
+// the sentinel selectors. MinWords must stay below MaxWords, so it shrinks with short limits
+func snippetOptions(maxWords int) string {
+	minWords := 15
+	if minWords >= maxWords {
+		minWords = maxWords / 2
+	}
+	return fmt.Sprintf(`StartSel=%s, StopSel=%s, MaxWords=%d, MinWords=%d, MaxFragments=2, FragmentDelimiter=" ... "`,
+		snippetStartSel, snippetStopSel, maxWords, minWords)
+}
+
+// markdownEscaper backslash-escapes characters Markdown would read as formatting or links
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+
+// renderSnippet turns a sentinel-marked ts_headline into a snippet that is safe to render in markup mode:
+// the description text is HTML-escaped (and Markdown-escaped for markdown), then the sentinels become the markup
+func renderSnippet(headline, markup string) string {
+	text := html.EscapeString(headline)
+	start, stop := "<mark>", "</mark>"
+	if markup == HighlightMarkupMarkdown {
+		text = markdownEscaper.Replace(text)
+		start, stop = "**", "**"
+	}
+	return strings.NewReplacer(snippetStartSel, start, snippetStopSel, stop).Replace(text)
+}
+
+// addSnippets sets each item's Snippet to ts_headline of its description for q, rendered in markup mode
+func (r *OpportunityRepository) addSnippets(ctx context.Context, q, markup string, items []models.Opportunity) error {
+	noticeIDs := make([]string, len(items))
+	for i := range items {
+		noticeIDs[i] = items[i].NoticeID
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT notice_id, ts_headline('english', translate(COALESCE(description, ''), $4, ''), websearch_to_tsquery('english', $2), $3)
+		FROM opportunity
+		WHERE notice_id = ANY($1)
+	`, noticeIDs, q, snippetOptions(getSnippetMaxWords()), snippetStartSel+snippetStopSel)
+	if err != nil {
+		return fmt.Errorf("failed to build snippets: %w", err)
+	}
+	defer rows.Close()
+
+	snippets := make(map[string]string, len(items))
+	for rows.Next() {
+		var noticeID, headline string
+		if err := rows.Scan(&noticeID, &headline); err != nil {
+			return fmt.Errorf("failed to scan snippet: %w", err)
+		}
+		snippets[noticeID] = renderSnippet(headline, markup)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating snippets: %w", err)
+	}
+	for i := range items {
+		items[i].Snippet = snippets[items[i].NoticeID]
+	}
+	return nil
+}
+
+// explainRelevanceMatches fills each item's Relevance.Matches with the query terms each searched field contains
+// Only the fields the search was scoped to (searchFields) are reported, under the same names
+// Terms are compared as English lexemes, the way the search vector stems them
+func (r *OpportunityRepository) explainRelevanceMatches(ctx context.Context, q string, searchFields []string, items []models.Opportunity) error {
+	fields := scopedSearchFields(searchFields)
+	if len(fields) == 0 {
+		return nil
+	}
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = fmt.Sprintf("('%s', o.%s)", field.name, field.column)
+	}
+	query := fmt.Sprintf(`
+		WITH terms AS (
+			SELECT tsvector_to_array(to_tsvector('english', $2)) AS lexemes
+		)
+		SELECT o.notice_id, f.field, ARRAY(
+			SELECT lexeme FROM unnest(tsvector_to_array(to_tsvector('english', COALESCE(f.value, '')))) AS lexeme
+			WHERE lexeme = ANY(terms.lexemes)
+			ORDER BY lexeme
+		)
+		FROM opportunity o
+		CROSS JOIN terms
+		CROSS JOIN LATERAL (VALUES %s) AS f(field, value)
+		WHERE o.notice_id = ANY($1)
+	`, strings.Join(values, ", "))
+
+	byID := make(map[string]*models.RelevanceExplanation, len(items))
+	noticeIDs := make([]string, len(items))
+	for i := range items {
+		noticeIDs[i] = items[i].NoticeID
+		byID[items[i].NoticeID] = items[i].Relevance
+	}
+
+	rows, err := r.db.Query(ctx, query, noticeIDs, q)
+	if err != nil {
+		return fmt.Errorf("failed to explain relevance: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var noticeID, field string
+		var matched []string
+		if err := rows.Scan(&noticeID, &field, &matched); err != nil {
+			return fmt.Errorf("failed to scan relevance matches: %w", err)
+		}
+		if explanation := byID[noticeID]; explanation != nil && len(matched) > 0 {
+			explanation.Matches[field] = matched
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating relevance matches: %w", err)
+	}
+	return nil
+}
+
+// FacetCount is one value of a search facet with the number of matching opportunities
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// ClassificationFacets counts matching opportunities per classification code, most common first
+// All search filters apply except the classification filter itself, so clients can offer sibling codes
+func (r *OpportunityRepository) ClassificationFacets(ctx context.Context, params SearchParamsV2, limit int) ([]FacetCount, error) {
+	params.Classification = ""
+	params.ClassificationPrefix = false
+	params.materializedStatus = r.hasDescriptionStatusColumn(ctx)
+	_, statusJoin := descriptionStatusSQL(params.materializedStatus)
+	conditions, args, argPos := buildSearchFiltersV2(params)
+	conditions = append(conditions, "COALESCE(o.classification_code, '') <> ''")
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	query := fmt.Sprintf(`
+		SELECT o.classification_code, COUNT(*)
+		FROM opportunity o
+		%s
+		WHERE %s
+		GROUP BY o.classification_code
+		ORDER BY COUNT(*) DESC, o.classification_code ASC
+		LIMIT $%d
+	`, statusJoin, strings.Join(conditions, " AND "), argPos)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query classification facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := []FacetCount{}
+	for rows.Next() {
+		var facet FacetCount
+		if err := rows.Scan(&facet.Value, &facet.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan classification facet: %w", err)
+		}
+		facets = append(facets, facet)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating classification facets: %w", err)
+	}
+	return facets, nil
+}
+
+// agencyExpr is the agency an opportunity counts under: agency_path_name, or for rows ingested without it
+// the same dotted path synthesized from department, sub-tier and office ("" when none is set)
+const agencyExpr = `COALESCE(NULLIF(o.agency_path_name, ''),
+	CONCAT_WS('.', NULLIF(o.department, ''), NULLIF(o.sub_tier, ''), NULLIF(o.office, '')))`
+
+// AgencyCount is one agency with the number of matching opportunities
+type AgencyCount struct {
+	Agency string `json:"agency"`
+	Count  int    `json:"count"`
+}
+
+// AgencyCounts counts opportunities matching the V2 search filters per agency, most common first
+// Opportunities with no agency information are left out
+func (r *OpportunityRepository) AgencyCounts(ctx context.Context, params SearchParamsV2, limit int) ([]AgencyCount, error) {
+	params.materializedStatus = r.hasDescriptionStatusColumn(ctx)
+	_, statusJoin := descriptionStatusSQL(params.materializedStatus)
+	conditions, args, argPos := buildSearchFiltersV2(params)
+	conditions = append(conditions, agencyExpr+" <> ''")
+
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s AS agency, COUNT(*)
+		FROM opportunity o
+		%s
+		WHERE %s
+		GROUP BY 1
+		ORDER BY COUNT(*) DESC, agency ASC
+		LIMIT $%d
+	`, agencyExpr, statusJoin, strings.Join(conditions, " AND "), argPos)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agency counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []AgencyCount{}
+	for rows.Next() {
+		var count AgencyCount
+		if err := rows.Scan(&count.Agency, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan agency count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating agency counts: %w", err)
+	}
+	return counts, nil
+}
+
+// AcceptedDateFormats describes the date formats convertDateFormat understands, for error messages
+const AcceptedDateFormats = "YYYY-MM-DD, MM/DD/YYYY, RFC3339, or relative (today, now, -30d, +7d)"
+
+// ValidateDateParam checks that a date query parameter can be parsed
+// Empty values are valid (the filter is simply not applied)
+func ValidateDateParam(name, value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, err := convertDateFormat(value); err != nil {
+		return fmt.Errorf("invalid %s %q: expected %s", name, value, AcceptedDateFormats)
+	}
+	return nil
+}
+
+// nowFunc returns the current time; overridden in tests so relative dates are deterministic
+var nowFunc = time.Now
+
+// appLocation is the timezone "today" and relative dates resolve in (APP_TIMEZONE), read once at startup
+var appLocation = models.AppLocation()
+
+// appNow returns the current time in appLocation, so date filters don't depend on the host's TZ
+func appNow() time.Time {
+	return nowFunc().In(appLocation)
+}
+
+// parseRelativeDate resolves relative expressions against the current date
+// Supports "today", "now", and signed day offsets like "-30d" or "+7d"
+func parseRelativeDate(dateStr string) (time.Time, bool) {
+	expr := strings.ToLower(strings.TrimSpace(dateStr))
+	now := appNow()
+
+	switch expr {
+	case "today", "now":
+		return now, true
+	}
+
+	if len(expr) < 3 || !strings.HasSuffix(expr, "d") || (expr[0] != '-' && expr[0] != '+') {
+		return time.Time{}, false
+	}
+	days, err := strconv.Atoi(expr[1 : len(expr)-1])
+	if err != nil || days < 0 {
+		return time.Time{}, false
+	}
+	if expr[0] == '-' {
+		days = -days
+	}
+	return now.AddDate(0, 0, days), true
+}
+
 // convertDateFormat converts MM/DD/YYYY to YYYY-MM-DD format
 // If the input is already in YYYY-MM-DD format, it returns it as-is
+// Relative expressions (today, now, -30d, +7d) are resolved server-side so saved searches stay relative
 func convertDateFormat(dateStr string) (string, error) {
+	if t, ok := parseRelativeDate(dateStr); ok {
+		return t.Format("2006-01-02"), nil
+	}
 	// Try parsing as MM/DD/YYYY first
 	if t, err := time.Parse("01/02/2006", dateStr); err == nil {
 		return t.Format("2006-01-02"), nil
@@ -735,9 +1545,9 @@ func convertDateFormat(dateStr string) (string, error) {
 	if t, err := time.Parse("2006-01-02", dateStr); err == nil {
 		return t.Format("2006-01-02"), nil
 	}
-	// Try parsing as RFC3339 or ISO8601
+	// Try parsing as RFC3339 or ISO8601; timestamps take their calendar date in appLocation
 	if t, err := time.Parse(time.RFC3339, dateStr); err == nil {
-		return t.Format("2006-01-02"), nil
+		return t.In(appLocation).Format("2006-01-02"), nil
 	}
 	// Return original if we can't parse (let database handle it)
 	return dateStr, fmt.Errorf("unable to parse date: %s", dateStr)
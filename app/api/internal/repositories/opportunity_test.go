@@ -0,0 +1,1419 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/testdb"
+)
+
+func TestConvertDateFormat_MMDDYYYY(t *testing.T) {
+	got, err := convertDateFormat("03/15/2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != "2025-03-15" {
+		t.Errorf("Expected %q, got %q", "2025-03-15", got)
+	}
+}
+
+func TestConvertDateFormat_ISO(t *testing.T) {
+	got, err := convertDateFormat("2025-03-15")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != "2025-03-15" {
+		t.Errorf("Expected %q, got %q", "2025-03-15", got)
+	}
+}
+
+func TestValidateDateParam_Valid(t *testing.T) {
+	for _, value := range []string{"", "03/15/2025", "2025-03-15", "2025-03-15T10:00:00Z"} {
+		if err := ValidateDateParam("postedFrom", value); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", value, err)
+		}
+	}
+}
+
+func TestValidateDateParam_Invalid(t *testing.T) {
+	for _, value := range []string{"2025-13-40", "13/45/2025", "yesterday-ish", "2025/03/15"} {
+		err := ValidateDateParam("postedFrom", value)
+		if err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+			continue
+		}
+		if !strings.Contains(err.Error(), "postedFrom") {
+			t.Errorf("Expected error to name the parameter, got %q", err.Error())
+		}
+	}
+}
+
+// withFixedNow pins the clock; dates resolve in now's own zone unless the test also calls withAppLocation
+func withFixedNow(t *testing.T, now time.Time) {
+	t.Helper()
+	orig := nowFunc
+	nowFunc = func() time.Time { return now }
+	t.Cleanup(func() { nowFunc = orig })
+	withAppLocation(t, now.Location())
+}
+
+func withAppLocation(t *testing.T, loc *time.Location) {
+	t.Helper()
+	orig := appLocation
+	appLocation = loc
+	t.Cleanup(func() { appLocation = orig })
+}
+
+// TestDateFilters_AppTimezoneBoundary checks "today" and timestamp params resolve in the configured zone,
+// not the host's: 02:30 UTC on Mar 15 is still Mar 14 in New York but already Mar 15 in Tokyo
+func TestDateFilters_AppTimezoneBoundary(t *testing.T) {
+	withFixedNow(t, time.Date(2025, 3, 15, 2, 30, 0, 0, time.UTC))
+
+	for _, c := range []struct {
+		zone  string
+		today string
+	}{
+		{"America/New_York", "2025-03-14"},
+		{"Asia/Tokyo", "2025-03-15"},
+	} {
+		loc, err := time.LoadLocation(c.zone)
+		if err != nil {
+			t.Fatalf("Failed to load %s: %v", c.zone, err)
+		}
+		withAppLocation(t, loc)
+
+		if got, _ := convertDateFormat("today"); got != c.today {
+			t.Errorf("%s: expected today %q, got %q", c.zone, c.today, got)
+		}
+		if got, _ := convertDateFormat("2025-03-15T02:30:00Z"); got != c.today {
+			t.Errorf("%s: expected timestamp to fall on %q, got %q", c.zone, c.today, got)
+		}
+		_, args, _ := buildSearchFiltersV2(SearchParamsV2{})
+		if len(args) != 1 || args[0] != c.today {
+			t.Errorf("%s: expected archive cutoff [%s], got %v", c.zone, c.today, args)
+		}
+		_, args, _ = buildSearchFiltersV2(SearchParamsV2{DueTo: "today", IncludeArchived: true})
+		nextDay, _ := time.Parse("2006-01-02", c.today)
+		if len(args) != 1 || args[0] != nextDay.AddDate(0, 0, 1).Format("2006-01-02") {
+			t.Errorf("%s: expected dueTo=today to end before the next day, got %v", c.zone, args)
+		}
+	}
+}
+
+func TestConvertDateFormat_Relative(t *testing.T) {
+	withFixedNow(t, time.Date(2025, 3, 15, 14, 30, 0, 0, time.UTC))
+
+	cases := map[string]string{
+		"today": "2025-03-15",
+		"now":   "2025-03-15",
+		"TODAY": "2025-03-15",
+		"-30d":  "2025-02-13",
+		"+7d":   "2025-03-22",
+		"+14d":  "2025-03-29",
+		"-0d":   "2025-03-15",
+	}
+	for input, expected := range cases {
+		got, err := convertDateFormat(input)
+		if err != nil {
+			t.Errorf("convertDateFormat(%q): expected no error, got %v", input, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("convertDateFormat(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+}
+
+func TestConvertDateFormat_RelativeInvalid(t *testing.T) {
+	for _, input := range []string{"30d", "-d", "+7", "-7w", "+-7d", "tomorrow"} {
+		if _, err := convertDateFormat(input); err == nil {
+			t.Errorf("convertDateFormat(%q): expected error", input)
+		}
+	}
+}
+
+func TestValidateDateParam_RelativeWithAbsolute(t *testing.T) {
+	withFixedNow(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC))
+
+	// A relative lower bound combined with an absolute upper bound
+	from, err := convertDateFormat("-30d")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	to, err := convertDateFormat("04/01/2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if from != "2025-02-13" || to != "2025-04-01" {
+		t.Errorf("Expected range 2025-02-13..2025-04-01, got %s..%s", from, to)
+	}
+	if err := ValidateDateParam("postedFrom", "-30d"); err != nil {
+		t.Errorf("Expected -30d to be valid, got %v", err)
+	}
+}
+
+func TestBuildSearchFiltersV2_DescriptionStatusReady(t *testing.T) {
+	conditions, args, argPos := buildSearchFiltersV2(SearchParamsV2{DescriptionStatus: "ready", IncludeArchived: true})
+
+	if len(conditions) != 1 {
+		t.Fatalf("Expected 1 condition, got %d: %v", len(conditions), conditions)
+	}
+	if !strings.Contains(conditions[0], "WHEN od.fetch_status = 'fetched' THEN 'ready'") {
+		t.Errorf("Expected condition to repeat the description status CASE, got %q", conditions[0])
+	}
+	if !strings.HasSuffix(conditions[0], "= $1") {
+		t.Errorf("Expected condition to compare against $1, got %q", conditions[0])
+	}
+	if len(args) != 1 || args[0] != "ready" {
+		t.Errorf("Expected args [ready], got %v", args)
+	}
+	if argPos != 2 {
+		t.Errorf("Expected next argPos 2, got %d", argPos)
+	}
+}
+
+func TestBuildSearchFiltersV2_DescriptionStatusMaterialized(t *testing.T) {
+	conditions, _, _ := buildSearchFiltersV2(SearchParamsV2{DescriptionStatus: "ready", IncludeArchived: true, materializedStatus: true})
+
+	if len(conditions) != 1 || conditions[0] != "o.description_status = $1" {
+		t.Errorf("Expected the materialized column compared against $1, got %v", conditions)
+	}
+	if _, join := descriptionStatusSQL(true); join != "" {
+		t.Errorf("Expected no join for the materialized column, got %q", join)
+	}
+}
+
+func TestBuildSearchFiltersV2_DescriptionStatusWithOtherFilters(t *testing.T) {
+	conditions, args, _ := buildSearchFiltersV2(SearchParamsV2{SetAside: "SBA", DescriptionStatus: "ready", IncludeArchived: true})
+
+	if len(conditions) != 2 {
+		t.Fatalf("Expected 2 conditions, got %d: %v", len(conditions), conditions)
+	}
+	if !strings.HasSuffix(conditions[1], "= $2") {
+		t.Errorf("Expected description status condition to use $2, got %q", conditions[1])
+	}
+	if len(args) != 2 || args[1] != "ready" {
+		t.Errorf("Expected args [SBA ready], got %v", args)
+	}
+}
+
+func TestIsValidDescriptionStatus(t *testing.T) {
+	for _, s := range []string{"none", "ready", "not_found", "error", "available_unfetched"} {
+		if !IsValidDescriptionStatus(s) {
+			t.Errorf("Expected %q to be valid", s)
+		}
+	}
+	for _, s := range []string{"", "fetched", "READY"} {
+		if IsValidDescriptionStatus(s) {
+			t.Errorf("Expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestOrderByV2_UniqueTieBreaker(t *testing.T) {
+	for _, sortType := range []string{"posted_desc", "due_asc", "relevance"} {
+		orderBy := orderByV2(sortType)
+		if !strings.HasSuffix(orderBy, "o.notice_id ASC") {
+			t.Errorf("%s: expected ORDER BY to end with the primary key, got %q", sortType, orderBy)
+		}
+		if !strings.Contains(orderBy, "NULLS LAST") {
+			t.Errorf("%s: expected NULLS LAST, got %q", sortType, orderBy)
+		}
+		// Repeated builds must be identical so cursors from one request apply to the next
+		if again := orderByV2(sortType); again != orderBy {
+			t.Errorf("%s: expected stable ORDER BY, got %q then %q", sortType, orderBy, again)
+		}
+	}
+}
+
+func TestSearchOpportunitiesV2_CursorPagesEachRowOnce(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	// Colliding dates, and NULL dates that sort last, in both sort columns
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, response_deadline, active) VALUES
+			('A', 'a', '2025-03-15', '2025-04-01', true),
+			('B', 'b', '2025-03-15', '2025-04-01', true),
+			('C', 'c', '2025-03-15', NULL, true),
+			('D', 'd', '2025-03-10', '2025-04-01', true),
+			('E', 'e', NULL, '2025-04-02', true),
+			('F', 'f', NULL, NULL, true),
+			('G', 'g', NULL, NULL, true),
+			('H', 'h', '2025-03-01', NULL, true);
+	`)
+	repo := NewOpportunityRepository(pool)
+
+	for _, sortType := range []string{"posted_desc", "due_asc"} {
+		seen := map[string]int{}
+		var order []string
+		cursor := ""
+		for page := 0; ; page++ {
+			if page > 10 {
+				t.Fatalf("%s: paging did not end, got %v", sortType, order)
+			}
+			result, err := repo.SearchOpportunitiesV2(context.Background(), SearchParamsV2{
+				Sort: sortType, Limit: 2, Cursor: cursor, IncludeArchived: true,
+			})
+			if err != nil {
+				t.Fatalf("%s: SearchOpportunitiesV2 failed: %v", sortType, err)
+			}
+			for _, opp := range result.Items {
+				seen[opp.NoticeID]++
+				order = append(order, opp.NoticeID)
+			}
+			if result.NextCursor == "" {
+				break
+			}
+			cursor = result.NextCursor
+		}
+		if len(seen) != 8 {
+			t.Errorf("%s: Expected all 8 rows, got %v", sortType, order)
+		}
+		for id, n := range seen {
+			if n != 1 {
+				t.Errorf("%s: Expected %s once, got %d times (%v)", sortType, id, n, order)
+			}
+		}
+	}
+}
+
+func TestSearchOpportunitiesV2_RelevanceCursorPagesEachRowOnce(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	// Higher-ranked rows posted before lower-ranked ones, rank ties on the same date, and a NULL date
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active) VALUES
+			('A', 'janitorial janitorial janitorial', '2025-03-01', true),
+			('B', 'janitorial janitorial', '2025-03-02', true),
+			('C', 'janitorial janitorial', '2025-03-02', true),
+			('D', 'janitorial janitorial', NULL, true),
+			('E', 'janitorial services', '2025-03-15', true),
+			('F', 'janitorial services', '2025-03-14', true),
+			('G', 'janitorial', '2025-03-20', true),
+			('H', 'janitorial', NULL, true);
+	`)
+	repo := NewOpportunityRepository(pool)
+	ctx := context.Background()
+
+	for _, boost := range []bool{false, true} {
+		params := SearchParamsV2{Q: "janitorial", Sort: "relevance", RecencyBoost: boost, IncludeArchived: true}
+		params.Limit = 100
+		all, err := repo.SearchOpportunitiesV2(ctx, params)
+		if err != nil {
+			t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+		}
+		var expected []string
+		for _, opp := range all.Items {
+			expected = append(expected, opp.NoticeID)
+		}
+		if len(expected) != 8 {
+			t.Fatalf("boost=%v: Expected all 8 rows in one page, got %v", boost, expected)
+		}
+
+		var order []string
+		params.Limit = 2
+		for page := 0; ; page++ {
+			if page > 10 {
+				t.Fatalf("boost=%v: paging did not end, got %v", boost, order)
+			}
+			result, err := repo.SearchOpportunitiesV2(ctx, params)
+			if err != nil {
+				t.Fatalf("boost=%v: SearchOpportunitiesV2 failed: %v", boost, err)
+			}
+			for _, opp := range result.Items {
+				order = append(order, opp.NoticeID)
+			}
+			if result.NextCursor == "" {
+				break
+			}
+			params.Cursor = result.NextCursor
+		}
+		// Paging returns exactly the unpaged order: no row skipped or repeated across pages
+		if strings.Join(order, ",") != strings.Join(expected, ",") {
+			t.Errorf("boost=%v: Expected pages to follow %v, got %v", boost, expected, order)
+		}
+	}
+}
+
+func TestRankCursorConditionV2(t *testing.T) {
+	rank := 0.25
+	condition, args := rankCursorConditionV2("ts_rank(v, q)", Cursor{Rank: &rank, PostedDate: "2025-03-02", NoticeID: "C"}, 3)
+	expected := "(ts_rank(v, q) < $3 OR (ts_rank(v, q) = $3 AND " +
+		"(o.posted_date < $4 OR (o.posted_date = $4 AND o.notice_id > $5) OR o.posted_date IS NULL)))"
+	if condition != expected {
+		t.Errorf("Expected %s, got %s", expected, condition)
+	}
+	if len(args) != 3 || args[0] != 0.25 || args[1] != "2025-03-02" || args[2] != "C" {
+		t.Errorf("Expected args [0.25 2025-03-02 C], got %v", args)
+	}
+}
+
+func TestBuildSearchFiltersV2_ArchiveFlags(t *testing.T) {
+	withFixedNow(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC))
+	archived := archivedCondition(1)
+
+	cases := []struct {
+		name     string
+		params   SearchParamsV2
+		expected string // "" means no archive condition
+	}{
+		{"default excludes archived", SearchParamsV2{}, "NOT " + archived},
+		{"includeArchived", SearchParamsV2{IncludeArchived: true}, ""},
+		{"archivedOnly", SearchParamsV2{ArchivedOnly: true}, archived},
+		{"archivedOnly wins over includeArchived", SearchParamsV2{IncludeArchived: true, ArchivedOnly: true}, archived},
+	}
+	for _, c := range cases {
+		conditions, args, _ := buildSearchFiltersV2(c.params)
+		if c.expected == "" {
+			if len(conditions) != 0 {
+				t.Errorf("%s: expected no conditions, got %v", c.name, conditions)
+			}
+			continue
+		}
+		if len(conditions) != 1 || conditions[0] != c.expected {
+			t.Errorf("%s: expected [%s], got %v", c.name, c.expected, conditions)
+		}
+		if len(args) != 1 || args[0] != "2025-03-15" {
+			t.Errorf("%s: expected args [2025-03-15], got %v", c.name, args)
+		}
+	}
+}
+
+func TestBuildSearchFiltersV2_ClassificationExact(t *testing.T) {
+	conditions, args, argPos := buildSearchFiltersV2(SearchParamsV2{Classification: "R425", IncludeArchived: true})
+
+	if len(conditions) != 1 || conditions[0] != "classification_code = $1" {
+		t.Fatalf("Expected [classification_code = $1], got %v", conditions)
+	}
+	if len(args) != 1 || args[0] != "R425" {
+		t.Errorf("Expected args [R425], got %v", args)
+	}
+	if argPos != 2 {
+		t.Errorf("Expected next argPos 2, got %d", argPos)
+	}
+}
+
+func TestBuildSearchFiltersV2_ClassificationPrefix(t *testing.T) {
+	conditions, args, _ := buildSearchFiltersV2(SearchParamsV2{Classification: "R4", ClassificationPrefix: true, IncludeArchived: true})
+
+	if len(conditions) != 1 || conditions[0] != "classification_code LIKE $1" {
+		t.Fatalf("Expected [classification_code LIKE $1], got %v", conditions)
+	}
+	if len(args) != 1 || args[0] != "R4%" {
+		t.Errorf("Expected args [R4%%], got %v", args)
+	}
+
+	// Wildcards in the input match literally
+	_, args, _ = buildSearchFiltersV2(SearchParamsV2{Classification: "R_%", ClassificationPrefix: true, IncludeArchived: true})
+	if args[0] != `R\_\%%` {
+		t.Errorf("Expected escaped pattern %q, got %q", `R\_\%%`, args[0])
+	}
+}
+
+func TestBuildSearchFiltersV2_ClassificationPrefixIgnoredWithoutCode(t *testing.T) {
+	conditions, _, _ := buildSearchFiltersV2(SearchParamsV2{ClassificationPrefix: true, IncludeArchived: true})
+	if len(conditions) != 0 {
+		t.Errorf("Expected no conditions, got %v", conditions)
+	}
+}
+
+func TestBuildSearchFiltersV2_SetAsideSingleValue(t *testing.T) {
+	conditions, args, argPos := buildSearchFiltersV2(SearchParamsV2{SetAside: "SBA", IncludeArchived: true})
+
+	if len(conditions) != 1 || conditions[0] != "type_of_set_aside = $1" {
+		t.Fatalf("Expected [type_of_set_aside = $1], got %v", conditions)
+	}
+	if len(args) != 1 || args[0] != "SBA" {
+		t.Errorf("Expected args [SBA], got %v", args)
+	}
+	if argPos != 2 {
+		t.Errorf("Expected next argPos 2, got %d", argPos)
+	}
+}
+
+func TestBuildSearchFiltersV2_SetAsideList(t *testing.T) {
+	conditions, args, argPos := buildSearchFiltersV2(SearchParamsV2{SetAside: "SDVOSBC, 8(a),,", DescriptionStatus: "ready", IncludeArchived: true})
+
+	if len(conditions) != 2 || conditions[0] != "type_of_set_aside IN ($1, $2)" {
+		t.Fatalf("Expected set-aside IN condition first, got %v", conditions)
+	}
+	if len(args) != 3 || args[0] != "SDVOSBC" || args[1] != "8A" || args[2] != "ready" {
+		t.Errorf("Expected args [SDVOSBC 8A ready], got %v", args)
+	}
+	if !strings.HasSuffix(conditions[1], "= $3") {
+		t.Errorf("Expected following condition to use $3, got %q", conditions[1])
+	}
+	if argPos != 4 {
+		t.Errorf("Expected next argPos 4, got %d", argPos)
+	}
+}
+
+func TestParseSetAsideList(t *testing.T) {
+	codes := parseSetAsideList(" sdvosb ,SDVOSBC, , hubzone")
+	if len(codes) != 2 || codes[0] != "SDVOSBC" || codes[1] != "HZC" {
+		t.Errorf("Expected [SDVOSBC HZC], got %v", codes)
+	}
+	if codes := parseSetAsideList(" , "); len(codes) != 0 {
+		t.Errorf("Expected no codes, got %v", codes)
+	}
+}
+
+func TestRecencyDecayExpr(t *testing.T) {
+	expr := recencyDecayExpr(4, 30)
+	if !strings.Contains(expr, "$4::date") || !strings.Contains(expr, "/ 30") {
+		t.Errorf("Expected decay against $4 with half-life 30, got %q", expr)
+	}
+	if !strings.HasSuffix(expr, "ELSE 0 END)") {
+		t.Errorf("Expected undated rows to decay to 0, got %q", expr)
+	}
+	if !strings.Contains(expr, calendarDatePrefixExpr("o.posted_date")) {
+		t.Errorf("Expected the cast to be guarded by a calendar date check, got %q", expr)
+	}
+}
+
+func TestGetRecencyHalfLifeDays(t *testing.T) {
+	t.Setenv("SEARCH_RECENCY_HALF_LIFE_DAYS", "14")
+	if days := getRecencyHalfLifeDays(); days != 14 {
+		t.Errorf("Expected 14, got %d", days)
+	}
+	t.Setenv("SEARCH_RECENCY_HALF_LIFE_DAYS", "0")
+	if days := getRecencyHalfLifeDays(); days != defaultRecencyHalfLifeDays {
+		t.Errorf("Expected default %d, got %d", defaultRecencyHalfLifeDays, days)
+	}
+}
+
+func TestBuildSearchFiltersV2_ExcludeTypes(t *testing.T) {
+	conditions, args, argPos := buildSearchFiltersV2(SearchParamsV2{ExcludeTypes: []string{"award notice", "justification"}, IncludeArchived: true})
+
+	expected := "NOT (LOWER(COALESCE(type, '')) = ANY($1) OR LOWER(COALESCE(base_type, '')) = ANY($1))"
+	if len(conditions) != 1 || conditions[0] != expected {
+		t.Fatalf("Expected [%s], got %v", expected, conditions)
+	}
+	if types, ok := args[0].([]string); len(args) != 1 || !ok || len(types) != 2 {
+		t.Errorf("Expected the excluded types as one array arg, got %v", args)
+	}
+	if argPos != 2 {
+		t.Errorf("Expected next argPos 2, got %d", argPos)
+	}
+
+	if conditions, _, _ := buildSearchFiltersV2(SearchParamsV2{IncludeArchived: true}); len(conditions) != 0 {
+		t.Errorf("Expected no type condition by default, got %v", conditions)
+	}
+}
+
+func TestSearchOpportunitiesV2_ExcludeTypes(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, type, base_type) VALUES
+			('SOL', 'Solicitation', '2025-03-01', true, 'Solicitation', 'Solicitation'),
+			('AWARD', 'Award', '2025-03-02', true, 'Award Notice', 'Award Notice'),
+			('AMENDED', 'Amended justification', '2025-03-03', true, 'Special Notice', 'Justification'),
+			('UNTYPED', 'Untyped', '2025-03-04', true, NULL, NULL)
+	`)
+	repo := NewOpportunityRepository(pool)
+
+	result, err := repo.SearchOpportunitiesV2(context.Background(), SearchParamsV2{
+		ExcludeTypes:    []string{"award notice", "justification"},
+		IncludeArchived: true,
+	})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	var ids []string
+	for _, opp := range result.Items {
+		ids = append(ids, opp.NoticeID)
+	}
+	if len(ids) != 2 || ids[0] != "UNTYPED" || ids[1] != "SOL" {
+		t.Errorf("Expected [UNTYPED SOL] with award notices and justifications excluded, got %v", ids)
+	}
+}
+
+func TestSearchOpportunitiesV2_RecencyBoost(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	withFixedNow(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC))
+
+	// STALE matches the query far better, but was posted six months ago
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, description) VALUES
+			('STALE', 'Janitorial Services', '2024-09-01', true, 'Janitorial services. Janitorial services for all buildings.'),
+			('FRESH', 'Facility Support', '2025-03-14', true, 'Scope includes janitorial services.')
+	`)
+	repo := NewOpportunityRepository(pool)
+	ctx := context.Background()
+
+	search := func(boost bool) []string {
+		t.Helper()
+		result, err := repo.SearchOpportunitiesV2(ctx, SearchParamsV2{Q: "janitorial services", Sort: "relevance", RecencyBoost: boost, IncludeArchived: true})
+		if err != nil {
+			t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+		}
+		var ids []string
+		for _, opp := range result.Items {
+			ids = append(ids, opp.NoticeID)
+		}
+		return ids
+	}
+
+	if ids := search(false); len(ids) != 2 || ids[0] != "STALE" {
+		t.Errorf("Expected pure relevance to rank STALE first, got %v", ids)
+	}
+	if ids := search(true); len(ids) != 2 || ids[0] != "FRESH" {
+		t.Errorf("Expected recency boost to rank FRESH first, got %v", ids)
+	}
+}
+
+func TestSearchOpportunitiesV2_RecencyBoostSkipsInvalidDates(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	withFixedNow(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC))
+
+	// Date-shaped values that aren't dates must not fail the search with a cast error
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, description) VALUES
+			('FRESH', 'Janitorial Services', '2025-03-14', true, 'Janitorial services.'),
+			('MONTH', 'Janitorial Services', '2025-13-45', true, 'Janitorial services.'),
+			('FEB30', 'Janitorial Services', '2025-02-30', true, 'Janitorial services.'),
+			('NOLEAP', 'Janitorial Services', '2025-02-29T10:00:00', true, 'Janitorial services.'),
+			('YEAR0', 'Janitorial Services', '0000-01-01', true, 'Janitorial services.'),
+			('LEAP', 'Janitorial Services', '2024-02-29', true, 'Janitorial services.')
+	`)
+	repo := NewOpportunityRepository(pool)
+
+	result, err := repo.SearchOpportunitiesV2(context.Background(), SearchParamsV2{Q: "janitorial", Sort: "relevance", RecencyBoost: true, IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed on invalid dates: %v", err)
+	}
+	var ids []string
+	for _, opp := range result.Items {
+		ids = append(ids, opp.NoticeID)
+	}
+	// Equal text rank, so the boost orders the real dates first and the invalid ones (decay 0) last
+	if len(ids) != 6 || ids[0] != "FRESH" || ids[1] != "LEAP" {
+		t.Errorf("Expected FRESH then LEAP ahead of the invalid dates, got %v", ids)
+	}
+}
+
+func TestSearchOpportunitiesV2_Explain(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active) VALUES
+			('N1', 'Janitorial Services', '2025-03-14', true)
+	`)
+	repo := NewOpportunityRepository(pool)
+	ctx := context.Background()
+
+	result, err := repo.SearchOpportunitiesV2(ctx, SearchParamsV2{Q: "janitorial", Explain: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Errorf("Expected 1 item alongside the plan, got %d", len(result.Items))
+	}
+	plan, ok := result.Debug["plan"].(json.RawMessage)
+	if !ok || len(plan) == 0 {
+		t.Fatalf("Expected a plan in debug, got %v", result.Debug["plan"])
+	}
+	var parsed []map[string]interface{}
+	if err := json.Unmarshal(plan, &parsed); err != nil || len(parsed) == 0 || parsed[0]["Plan"] == nil {
+		t.Errorf("Expected EXPLAIN JSON with a Plan node, got %s", plan)
+	}
+	if sql, _ := result.Debug["sql"].(string); !strings.Contains(sql, "FROM opportunity o") {
+		t.Errorf("Expected executed SQL in debug, got %q", sql)
+	}
+
+	// Without explain, neither is returned
+	result, err = repo.SearchOpportunitiesV2(ctx, SearchParamsV2{Q: "janitorial"})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if _, ok := result.Debug["plan"]; ok {
+		t.Error("Expected no plan without explain")
+	}
+}
+
+func TestSearchOpportunitiesV2_ExplainRelevance(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, description) VALUES
+			('BEST', 'Janitorial Services', '2025-03-01', true, 'Janitorial services. Janitorial services for all buildings.'),
+			('MID', 'Facility Support', '2025-03-02', true, 'Scope includes janitorial services.'),
+			('LOW', 'Grounds Maintenance', '2025-03-03', true, 'Mowing, plus janitorial support as needed.')
+	`)
+	repo := NewOpportunityRepository(pool)
+	ctx := context.Background()
+
+	result, err := repo.SearchOpportunitiesV2(ctx, SearchParamsV2{Q: "janitorial services", Sort: "relevance", ExplainRelevance: true, IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(result.Items))
+	}
+	for i, opp := range result.Items {
+		if opp.Relevance == nil {
+			t.Fatalf("Expected a relevance explanation for %s", opp.NoticeID)
+		}
+		if opp.Relevance.Score <= 0 {
+			t.Errorf("Expected a positive score for %s, got %v", opp.NoticeID, opp.Relevance.Score)
+		}
+		if i > 0 && opp.Relevance.Score > result.Items[i-1].Relevance.Score {
+			t.Errorf("Expected scores in result order, got %s %v after %s %v",
+				opp.NoticeID, opp.Relevance.Score, result.Items[i-1].NoticeID, result.Items[i-1].Relevance.Score)
+		}
+	}
+	best := result.Items[0]
+	if best.NoticeID != "BEST" {
+		t.Fatalf("Expected BEST first, got %s", best.NoticeID)
+	}
+	if got := strings.Join(best.Relevance.Matches["title"], ","); got != "janitori,servic" {
+		t.Errorf("Expected title to match both terms, got %v", best.Relevance.Matches["title"])
+	}
+	if got := result.Items[1].Relevance.Matches["title"]; got != nil {
+		t.Errorf("Expected no title match for %s, got %v", result.Items[1].NoticeID, got)
+	}
+	if got := best.Relevance.Matches["description"]; len(got) == 0 {
+		t.Errorf("Expected description matches by default, got %v", best.Relevance.Matches)
+	}
+
+	// Scoped to the title, only title matches are explained
+	result, err = repo.SearchOpportunitiesV2(ctx, SearchParamsV2{Q: "janitorial services", Sort: "relevance", SearchFields: []string{"title"}, ExplainRelevance: true, IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].NoticeID != "BEST" {
+		t.Fatalf("Expected only BEST to match on title, got %d items", len(result.Items))
+	}
+	if matches := result.Items[0].Relevance.Matches; len(matches) != 1 || len(matches["title"]) == 0 {
+		t.Errorf("Expected title matches only, got %v", matches)
+	}
+
+	// Not requested: no explanation
+	result, err = repo.SearchOpportunitiesV2(ctx, SearchParamsV2{Q: "janitorial services", Sort: "relevance", IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	for _, opp := range result.Items {
+		if opp.Relevance != nil {
+			t.Errorf("Expected no relevance explanation for %s without explainRelevance", opp.NoticeID)
+		}
+	}
+}
+
+func TestGetSnippetMaxWords(t *testing.T) {
+	t.Setenv("SEARCH_SNIPPET_MAX_WORDS", "")
+	if words := getSnippetMaxWords(); words != defaultSnippetMaxWords {
+		t.Errorf("Expected default %d, got %d", defaultSnippetMaxWords, words)
+	}
+	t.Setenv("SEARCH_SNIPPET_MAX_WORDS", "20")
+	if words := getSnippetMaxWords(); words != 20 {
+		t.Errorf("Expected 20, got %d", words)
+	}
+	t.Setenv("SEARCH_SNIPPET_MAX_WORDS", "1")
+	if words := getSnippetMaxWords(); words != defaultSnippetMaxWords {
+		t.Errorf("Expected default %d, got %d", defaultSnippetMaxWords, words)
+	}
+}
+
+func TestSnippetOptions_MinWordsBelowMaxWords(t *testing.T) {
+	if opts := snippetOptions(35); !strings.Contains(opts, "MaxWords=35, MinWords=15") {
+		t.Errorf("Expected MaxWords=35, MinWords=15, got %q", opts)
+	}
+	if opts := snippetOptions(10); !strings.Contains(opts, "MaxWords=10, MinWords=5") {
+		t.Errorf("Expected MinWords to shrink below MaxWords, got %q", opts)
+	}
+}
+
+func TestRenderSnippet_MarkupModes(t *testing.T) {
+	headline := "provide " + snippetStartSel + "janitorial" + snippetStopSel + " services"
+	if got, want := renderSnippet(headline, HighlightMarkupHTML), "provide <mark>janitorial</mark> services"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if got, want := renderSnippet(headline, HighlightMarkupMarkdown), "provide **janitorial** services"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestRenderSnippet_NeutralizesInjectedMarkup(t *testing.T) {
+	headline := `<script>alert(1)</script> <b>bold</b> ` + snippetStartSel + "janitorial" + snippetStopSel + ` **fake** [link](http://evil.example)`
+
+	got := renderSnippet(headline, HighlightMarkupHTML)
+	want := `&lt;script&gt;alert(1)&lt;/script&gt; &lt;b&gt;bold&lt;/b&gt; <mark>janitorial</mark> **fake** [link](http://evil.example)`
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	got = renderSnippet(headline, HighlightMarkupMarkdown)
+	want = `&lt;script&gt;alert(1)&lt;/script&gt; &lt;b&gt;bold&lt;/b&gt; **janitorial** \*\*fake\*\* \[link\](http://evil.example)`
+	if got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestSearchOpportunitiesV2_Highlight(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, description) VALUES
+			('N1', 'Facility Support', '2025-03-14', true, 'The contractor shall provide janitorial services for the main office building.')
+	`)
+	repo := NewOpportunityRepository(pool)
+	ctx := context.Background()
+
+	result, err := repo.SearchOpportunitiesV2(ctx, SearchParamsV2{Q: "janitorial", Highlight: true, IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(result.Items))
+	}
+	if snippet := result.Items[0].Snippet; !strings.Contains(snippet, "<mark>janitorial</mark>") {
+		t.Errorf("Expected highlighted term in snippet, got %q", snippet)
+	}
+
+	// Markdown markup, and tags in the description come back escaped
+	execTestSQL(t, pool, `UPDATE opportunity SET description = '<script>x</script> janitorial services' WHERE notice_id = 'N1'`)
+	result, err = repo.SearchOpportunitiesV2(ctx, SearchParamsV2{Q: "janitorial", Highlight: true, HighlightMarkup: HighlightMarkupMarkdown, IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(result.Items))
+	}
+	snippet := result.Items[0].Snippet
+	if !strings.Contains(snippet, "**janitorial**") {
+		t.Errorf("Expected Markdown-highlighted term in snippet, got %q", snippet)
+	}
+	if strings.Contains(snippet, "<script>") || !strings.Contains(snippet, "&lt;script&gt;") {
+		t.Errorf("Expected injected tag to be escaped, got %q", snippet)
+	}
+
+	// Without highlight, no snippet
+	result, err = repo.SearchOpportunitiesV2(ctx, SearchParamsV2{Q: "janitorial", IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].Snippet != "" {
+		t.Errorf("Expected no snippet without highlight, got %+v", result.Items)
+	}
+}
+
+func TestBuildSearchFiltersV2_DueToCoversWholeDay(t *testing.T) {
+	conditions, args, _ := buildSearchFiltersV2(SearchParamsV2{DueFrom: "2025-03-15", DueTo: "2025-03-15", IncludeArchived: true})
+	if len(conditions) != 2 {
+		t.Fatalf("Expected 2 conditions, got %v", conditions)
+	}
+	if !strings.Contains(conditions[1], "response_deadline < $2") {
+		t.Errorf("Expected exclusive upper bound, got %q", conditions[1])
+	}
+	if args[0] != "2025-03-15" || args[1] != "2025-03-16" {
+		t.Errorf("Expected bounds 2025-03-15 and 2025-03-16, got %v", args)
+	}
+
+	// Stored deadlines compare as strings: end of day and date-only values both fall in range
+	for _, deadline := range []string{"2025-03-15", "2025-03-15T17:00:00-04:00", "2025-03-15T23:59:59-04:00"} {
+		if !(deadline >= args[0].(string) && deadline < args[1].(string)) {
+			t.Errorf("Expected deadline %q within due range", deadline)
+		}
+	}
+}
+
+func TestSearchOpportunitiesV2_DueTodayIncludesEndOfDayDeadline(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, response_deadline, active) VALUES
+			('EOD', 'Date-only deadline', '2025-03-01', '2025-03-15T23:59:59-04:00', true),
+			('TIMED', 'Afternoon deadline', '2025-03-01', '2025-03-15T17:00:00-04:00', true),
+			('LATER', 'Next day', '2025-03-01', '2025-03-16T23:59:59-04:00', true)
+	`)
+	repo := NewOpportunityRepository(pool)
+
+	result, err := repo.SearchOpportunitiesV2(context.Background(), SearchParamsV2{DueFrom: "2025-03-15", DueTo: "2025-03-15", Sort: "due_asc", IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	var ids []string
+	for _, opp := range result.Items {
+		ids = append(ids, opp.NoticeID)
+	}
+	if len(ids) != 2 || ids[0] != "TIMED" || ids[1] != "EOD" {
+		t.Errorf("Expected [TIMED EOD], got %v", ids)
+	}
+}
+
+func TestGetOpportunitiesByNoticeIDs_OneMissing(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+	testdb.Migrate(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, naics, content_hash) VALUES ('N1', 'Janitorial Services', '[{"code": "561720"}]', 'h');
+		INSERT INTO opportunity_raw (notice_id, raw_data) VALUES ('N1', '{"award": {"amount": "1000"}}');
+		INSERT INTO opportunity_description (notice_id, source_type, fetch_status, ai_meta) VALUES
+			('N1', 'inline', 'fetched', '{"key_requirements": ["Delivery: 30 calendar days ARO"]}');
+	`)
+
+	opps, err := NewOpportunityRepository(pool).GetOpportunitiesByNoticeIDs(ctx, []string{"N1", "MISSING"})
+	if err != nil {
+		t.Fatalf("GetOpportunitiesByNoticeIDs failed: %v", err)
+	}
+	if len(opps) != 1 || opps["N1"] == nil || opps["N1"].Title != "Janitorial Services" {
+		t.Fatalf("Expected only N1, got %v", opps)
+	}
+	if len(opps["N1"].NAICS) != 1 || opps["N1"].Award == nil {
+		t.Errorf("Expected NAICS and award from the detail scan, got %+v", opps["N1"])
+	}
+
+	metas, err := NewDescriptionRepository(pool).GetAIMetas(ctx, []string{"N1", "MISSING"})
+	if err != nil {
+		t.Fatalf("GetAIMetas failed: %v", err)
+	}
+	if len(metas) != 1 || metas["N1"] == nil || len(metas["N1"].KeyRequirements) != 1 {
+		t.Errorf("Expected N1's key requirements only, got %v", metas)
+	}
+}
+
+func TestGetOpportunityByNoticeID_OptionalFieldsNullVsEmpty(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+	testdb.Migrate(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, solicitation_number, agency_path_name, content_hash) VALUES ('N1', 'Janitorial Services', '', NULL, 'h');
+		INSERT INTO opportunity_raw (notice_id, raw_data) VALUES ('N1', '{"fullParentPathName": "", "naicsCode": null}');
+	`)
+
+	opp, err := NewOpportunityRepository(pool).GetOpportunityByNoticeID(ctx, "N1")
+	if err != nil {
+		t.Fatalf("GetOpportunityByNoticeID failed: %v", err)
+	}
+	if opp.SolicitationNumber == nil || *opp.SolicitationNumber != "" {
+		t.Errorf("Expected empty solicitation_number to be \"\", got %v", opp.SolicitationNumber)
+	}
+	if opp.AgencyPathName != nil {
+		t.Errorf("Expected NULL agency_path_name to be nil, got %q", *opp.AgencyPathName)
+	}
+	if opp.FullParentPathName == nil || *opp.FullParentPathName != "" {
+		t.Errorf("Expected empty fullParentPathName to be \"\", got %v", opp.FullParentPathName)
+	}
+	if opp.NAICSCode != nil || opp.UILink != nil {
+		t.Errorf("Expected null and missing raw fields to be nil, got %v %v", opp.NAICSCode, opp.UILink)
+	}
+}
+
+func TestDetailProvenance_SynthesizedTitleIsDerived(t *testing.T) {
+	agency := "DEPT OF DEFENSE.DEPT OF THE ARMY"
+	opp := &models.Opportunity{
+		NoticeID:         "N1",
+		Title:            "Untitled (notice N1)",
+		TitleSynthesized: true,
+		ResponseDeadline: "2025-02-01T23:59:59-05:00",
+		AgencyPathName:   &agency,
+	}
+	rawData := map[string]interface{}{
+		"title":              "",
+		"responseDeadLine":   "2025-02-01",
+		"fullParentPathName": agency,
+	}
+
+	got := detailProvenance(opp, rawData)
+	want := map[string]string{
+		"title":            models.ProvenanceDerived,
+		"responseDeadline": models.ProvenanceDerived,
+		"agencyPathName":   models.ProvenanceSAM,
+	}
+	for field, value := range want {
+		if got[field] != value {
+			t.Errorf("%s: Expected %q, got %q", field, value, got[field])
+		}
+	}
+}
+
+func TestDetailProvenance_SAMValues(t *testing.T) {
+	opp := &models.Opportunity{Title: "Janitorial Services", ResponseDeadline: "2025-02-01T17:00:00-0500"}
+	// A deadline with a time of day is stored as sent; only its offset format differs from the raw row
+	rawData := map[string]interface{}{"title": "Janitorial Services", "responseDeadLine": "2025-02-01T17:00:00-05:00"}
+
+	got := detailProvenance(opp, rawData)
+	if got["title"] != models.ProvenanceSAM || got["responseDeadline"] != models.ProvenanceSAM {
+		t.Errorf("Expected title and deadline from SAM, got %v", got)
+	}
+	if _, ok := got["agencyPathName"]; ok {
+		t.Errorf("Expected no entry for an absent agency path, got %v", got)
+	}
+
+	// Without the raw row nothing can be traced back to SAM
+	if got := detailProvenance(opp, nil); got["title"] != models.ProvenanceDerived {
+		t.Errorf("Expected derived without raw data, got %v", got)
+	}
+}
+
+func TestGetOpportunityByNoticeID_Provenance(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+	testdb.Migrate(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, title_synthesized, agency_path_name, content_hash) VALUES ('N1', 'Untitled (notice N1)', true, 'DOD.ARMY', 'h');
+		INSERT INTO opportunity_raw (notice_id, raw_data) VALUES ('N1', '{"title": "", "fullParentPathName": "DEPT OF DEFENSE.DEPT OF THE ARMY"}');
+	`)
+
+	opp, err := NewOpportunityRepository(pool).GetOpportunityByNoticeID(ctx, "N1")
+	if err != nil {
+		t.Fatalf("GetOpportunityByNoticeID failed: %v", err)
+	}
+	if opp.Provenance["title"] != models.ProvenanceDerived || opp.Provenance["agencyPathName"] != models.ProvenanceDerived {
+		t.Errorf("Expected synthesized title and rewritten agency path to be derived, got %v", opp.Provenance)
+	}
+}
+
+func TestAgencyCounts_NAICSFilter(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, naics, agency_path_name, department, sub_tier, office, archived_at) VALUES
+			('DLA1', 'Valves', '2025-03-01', true, '[{"code": "336413"}]', 'DEPT OF DEFENSE.DLA', NULL, NULL, NULL, NULL),
+			('DLA2', 'Seals', '2025-03-02', true, '[{"code": "336413"}]', 'DEPT OF DEFENSE.DLA', NULL, NULL, NULL, NULL),
+			('DLA3', 'Gaskets', '2025-03-03', true, '[{"code": "336413"}]', 'DEPT OF DEFENSE.DLA', NULL, NULL, NULL, NULL),
+			('NAVY1', 'Pumps', '2025-03-04', true, '[{"code": "336413"}]', 'DEPT OF DEFENSE.NAVY', NULL, NULL, NULL, NULL),
+			('SYN1', 'Hoses', '2025-03-05', true, '[{"code": "336413"}]', NULL, 'DEPT OF DEFENSE', 'NAVY', NULL, NULL),
+			('VA1', 'Beds', '2025-03-06', true, '[{"code": "336413"}]', '', 'VETERANS AFFAIRS', '', '', NULL),
+			('NONE1', 'Unknown', '2025-03-07', true, '[{"code": "336413"}]', NULL, NULL, NULL, NULL, NULL),
+			('OTHER1', 'Janitorial', '2025-03-08', true, '[{"code": "561720"}]', 'GSA', NULL, NULL, NULL, NULL),
+			('OLD1', 'Archived valves', '2025-03-09', false, '[{"code": "336413"}]', 'DEPT OF DEFENSE.DLA', NULL, NULL, NULL, now())
+	`)
+	repo := NewOpportunityRepository(pool)
+
+	counts, err := repo.AgencyCounts(context.Background(), SearchParamsV2{NAICS: "336413"}, 10)
+	if err != nil {
+		t.Fatalf("AgencyCounts failed: %v", err)
+	}
+	expected := []AgencyCount{
+		{Agency: "DEPT OF DEFENSE.DLA", Count: 3},
+		{Agency: "DEPT OF DEFENSE.NAVY", Count: 2},
+		{Agency: "VETERANS AFFAIRS", Count: 1},
+	}
+	if len(counts) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, counts)
+	}
+	for i := range expected {
+		if counts[i] != expected[i] {
+			t.Errorf("Expected %v at position %d, got %v", expected[i], i, counts[i])
+		}
+	}
+
+	top, err := repo.AgencyCounts(context.Background(), SearchParamsV2{NAICS: "336413"}, 1)
+	if err != nil {
+		t.Fatalf("AgencyCounts failed: %v", err)
+	}
+	if len(top) != 1 || top[0].Agency != "DEPT OF DEFENSE.DLA" {
+		t.Errorf("Expected only the top agency with limit 1, got %v", top)
+	}
+}
+
+func TestOpportunityRepository_PurgeCache(t *testing.T) {
+	repo := NewOpportunityRepository(nil)
+	repo.statusColumnChecked, repo.statusColumn = true, true
+	repo.statusColumnHits, repo.statusColumnMisses = 5, 1
+
+	if stats := repo.CacheStats(); stats.Size != 1 || stats.Hits != 5 || stats.Misses != 1 {
+		t.Errorf("Expected size 1 with 5 hits and 1 miss, got %+v", stats)
+	}
+	repo.PurgeCache()
+	if stats := repo.CacheStats(); stats.Size != 0 || stats.Hits != 5 {
+		t.Errorf("Expected an empty cache keeping its counters, got %+v", stats)
+	}
+	if repo.statusColumn {
+		t.Error("Expected the cached column result to be cleared")
+	}
+}
+
+func TestSearchVectorExpr_Scope(t *testing.T) {
+	all := searchVectorExpr(nil)
+	for _, column := range []string{"title", "solicitation_number", "agency_path_name", "description"} {
+		if !strings.Contains(all, "COALESCE("+column+", '')") {
+			t.Errorf("Expected the default vector to include %s, got %q", column, all)
+		}
+	}
+
+	titleOnly := searchVectorExpr([]string{"title"})
+	if titleOnly != "to_tsvector('english', COALESCE(title, ''))" {
+		t.Errorf("Expected a title-only vector, got %q", titleOnly)
+	}
+
+	// Whitelist order wins over request order, and unknown names never reach the SQL
+	scoped := searchVectorExpr([]string{"description", "agency", "o.title; DROP TABLE opportunity"})
+	if scoped != "to_tsvector('english', COALESCE(agency_path_name, '') || ' ' || COALESCE(description, ''))" {
+		t.Errorf("Expected agency and description only, got %q", scoped)
+	}
+}
+
+func TestScopedSearchFields(t *testing.T) {
+	var names []string
+	for _, field := range scopedSearchFields(nil) {
+		names = append(names, field.name)
+	}
+	if got := strings.Join(names, ","); got != "title,solicitationNumber,agency,description" {
+		t.Errorf("Expected every search field by default, got %s", got)
+	}
+
+	names = nil
+	for _, field := range scopedSearchFields([]string{"description", "agencyPathName", "title"}) {
+		names = append(names, field.name)
+	}
+	if got := strings.Join(names, ","); got != "title,description" {
+		t.Errorf("Expected title and description in whitelist order, got %s", got)
+	}
+}
+
+func TestBuildSearchFiltersV2_SearchFields(t *testing.T) {
+	conditions, args, argPos := buildSearchFiltersV2(SearchParamsV2{Q: "janitorial", SearchFields: []string{"title"}, IncludeArchived: true})
+	if len(conditions) != 1 || conditions[0] != "to_tsvector('english', COALESCE(title, '')) @@ websearch_to_tsquery('english', $1)" {
+		t.Errorf("Expected a title-only keyword condition, got %v", conditions)
+	}
+	if len(args) != 1 || args[0] != "janitorial" || argPos != 2 {
+		t.Errorf("Expected [janitorial] and next argPos 2, got %v and %d", args, argPos)
+	}
+}
+
+func TestSearchOpportunitiesV2_SearchFields(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	// BOILER only mentions janitorial services in its description boilerplate
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, agency_path_name, description) VALUES
+			('TITLE', 'Janitorial Services', '2025-03-01', true, 'DEPT OF THE ARMY', 'Clean the barracks.'),
+			('BOILER', 'Roof Repair', '2025-03-02', true, 'DEPT OF THE NAVY', 'Excludes janitorial services.')
+	`)
+	repo := NewOpportunityRepository(pool)
+	ctx := context.Background()
+
+	search := func(fields []string) []string {
+		t.Helper()
+		result, err := repo.SearchOpportunitiesV2(ctx, SearchParamsV2{Q: "janitorial", SearchFields: fields, Sort: "relevance", IncludeArchived: true})
+		if err != nil {
+			t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+		}
+		var ids []string
+		for _, opp := range result.Items {
+			ids = append(ids, opp.NoticeID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	if ids := search(nil); strings.Join(ids, ",") != "BOILER,TITLE" {
+		t.Errorf("Expected all-field search to match both, got %v", ids)
+	}
+	if ids := search([]string{"title"}); strings.Join(ids, ",") != "TITLE" {
+		t.Errorf("Expected title-only search to match TITLE, got %v", ids)
+	}
+	if ids := search([]string{"description"}); strings.Join(ids, ",") != "BOILER" {
+		t.Errorf("Expected description search to match BOILER, got %v", ids)
+	}
+}
+
+func TestSearchOpportunitiesV2_StateFilterPlaceShapes(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, place_of_performance) VALUES
+			('OBJECT', 'Object', '2025-03-01', true, '{"state": {"code": "VA", "name": "Virginia"}}'),
+			('BARE', 'Bare code', '2025-03-02', true, '{"state": "VA"}'),
+			('STRINGIFIED', 'Stringified', '2025-03-03', true, to_jsonb('{"state":{"code":"VA","name":"Virginia"}}'::text)),
+			('NESTED', 'Stringified state', '2025-03-04', true, '{"state": "{\"code\":\"VA\",\"name\":\"Virginia\"}"}'),
+			('JSONNULL', 'JSON null', '2025-03-05', true, 'null'),
+			('SQLNULL', 'SQL null', '2025-03-06', true, NULL),
+			('MD', 'Maryland', '2025-03-07', true, '{"state": {"code": "MD", "name": "Maryland"}}')
+	`)
+	repo := NewOpportunityRepository(pool)
+	ctx := context.Background()
+
+	search := func() []string {
+		t.Helper()
+		result, err := repo.SearchOpportunitiesV2(ctx, SearchParamsV2{State: "VA", IncludeArchived: true})
+		if err != nil {
+			t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+		}
+		var ids []string
+		for _, opp := range result.Items {
+			ids = append(ids, opp.NoticeID)
+			if state, _ := opp.PlaceOfPerformance.State.(map[string]interface{}); state["code"] != "VA" {
+				t.Errorf("%s: Expected state decoded as {code: VA}, got %v", opp.NoticeID, opp.PlaceOfPerformance.State)
+			}
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	// Object and bare-code states match as stored; the legacy stringified rows need migration 016
+	if ids := search(); strings.Join(ids, ",") != "BARE,OBJECT" {
+		t.Errorf("Expected BARE and OBJECT before the migration, got %v", ids)
+	}
+
+	execMigrationFile(t, pool, "016_opportunity_place_of_performance_canonical.sql")
+	if ids := search(); strings.Join(ids, ",") != "BARE,NESTED,OBJECT,STRINGIFIED" {
+		t.Errorf("Expected every Virginia row after the migration, got %v", ids)
+	}
+
+	var nullCount int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM opportunity WHERE place_of_performance IS NULL`).Scan(&nullCount); err != nil {
+		t.Fatalf("Failed to count NULL places: %v", err)
+	}
+	if nullCount != 2 {
+		t.Errorf("Expected the JSON null to become NULL (2 NULL rows), got %d", nullCount)
+	}
+
+	// Null places scan without error and serialize without a placeOfPerformance
+	result, err := repo.SearchOpportunitiesV2(ctx, SearchParamsV2{IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	for _, opp := range result.Items {
+		if opp.NoticeID != "JSONNULL" && opp.NoticeID != "SQLNULL" {
+			continue
+		}
+		data, _ := json.Marshal(opp)
+		if strings.Contains(string(data), "placeOfPerformance") {
+			t.Errorf("%s: Expected no placeOfPerformance, got %s", opp.NoticeID, data)
+		}
+	}
+}
+
+func TestBuildSearchFiltersV2_NoticeAndProcurementTypes(t *testing.T) {
+	conditions, args, argPos := buildSearchFiltersV2(SearchParamsV2{
+		NoticeTypes:      []string{"award notice"},
+		ProcurementTypes: []string{"k"},
+		IncludeArchived:  true,
+	})
+	expected := []string{
+		"LOWER(BTRIM(COALESCE(type, ''))) = ANY($1)",
+		"procurement_type = ANY($2)",
+	}
+	if strings.Join(conditions, " AND ") != strings.Join(expected, " AND ") {
+		t.Errorf("Expected %v, got %v", expected, conditions)
+	}
+	if len(args) != 2 || argPos != 3 {
+		t.Errorf("Expected 2 args and next argPos 3, got %v and %d", args, argPos)
+	}
+}
+
+func TestSearchOpportunitiesV2_NoticeTypeVsProcurementType(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, type, base_type) VALUES
+			('COMBINED', 'Combined', '2025-03-01', true, 'Combined Synopsis/Solicitation', 'Combined Synopsis/Solicitation'),
+			('AWARD', 'Award of combined', '2025-03-02', true, 'Award Notice', 'Combined Synopsis/Solicitation'),
+			('PRESOL', 'Presolicitation', '2025-03-03', true, 'Presolicitation', 'Presolicitation')
+	`)
+	execMigrationFile(t, pool, "017_opportunity_procurement_type.sql")
+	repo := NewOpportunityRepository(pool)
+
+	search := func(params SearchParamsV2) string {
+		t.Helper()
+		params.IncludeArchived = true
+		result, err := repo.SearchOpportunitiesV2(context.Background(), params)
+		if err != nil {
+			t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+		}
+		var ids []string
+		for _, opp := range result.Items {
+			ids = append(ids, opp.NoticeID)
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+
+	// The notice type is this notice's own category
+	if ids := search(SearchParamsV2{NoticeTypes: []string{"combined synopsis/solicitation"}}); ids != "COMBINED" {
+		t.Errorf("Expected only COMBINED for noticeType, got %s", ids)
+	}
+	// The procurement type follows the base notice, so the award notice is included
+	if ids := search(SearchParamsV2{ProcurementTypes: []string{"k"}}); ids != "AWARD,COMBINED" {
+		t.Errorf("Expected AWARD and COMBINED for ptype k, got %s", ids)
+	}
+	if ids := search(SearchParamsV2{ProcurementTypes: []string{"p"}}); ids != "PRESOL" {
+		t.Errorf("Expected only PRESOL for ptype p, got %s", ids)
+	}
+	if ids := search(SearchParamsV2{NoticeTypes: []string{"award notice"}, ProcurementTypes: []string{"k"}}); ids != "AWARD" {
+		t.Errorf("Expected only AWARD for both filters, got %s", ids)
+	}
+}
+
+func TestBuildSearchFiltersV2_HasAttachments(t *testing.T) {
+	with, without := true, false
+	conditions, args, _ := buildSearchFiltersV2(SearchParamsV2{HasAttachments: &with, IncludeArchived: true})
+	expected := "EXISTS (SELECT 1 FROM opportunity_raw r WHERE r.notice_id = o.notice_id AND " + hasAttachmentsPredicate + ")"
+	if len(conditions) != 1 || conditions[0] != expected || len(args) != 0 {
+		t.Errorf("Expected [%s] with no args, got %v and %v", expected, conditions, args)
+	}
+
+	conditions, _, _ = buildSearchFiltersV2(SearchParamsV2{HasAttachments: &without, IncludeArchived: true})
+	if len(conditions) != 1 || conditions[0] != "NOT "+expected {
+		t.Errorf("Expected the NOT EXISTS form, got %v", conditions)
+	}
+}
+
+func TestHasAttachmentsPredicate_MatchesMigrationIndex(t *testing.T) {
+	sql, err := os.ReadFile("../../migrations/018_opportunity_raw_resource_links_index.sql")
+	if err != nil {
+		t.Fatalf("Failed to read migration: %v", err)
+	}
+	// The index is on opportunity_raw itself, so its predicate has no r. alias
+	if want := strings.ReplaceAll(hasAttachmentsPredicate, "r.raw_data", "raw_data"); !strings.Contains(string(sql), "WHERE "+want+";") {
+		t.Errorf("Expected the migration's index predicate to be %q", want)
+	}
+}
+
+func TestSearchOpportunitiesV2_HasAttachments(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		CREATE TABLE opportunity_raw (
+			id SERIAL PRIMARY KEY,
+			notice_id VARCHAR NOT NULL UNIQUE,
+			raw_data JSONB NOT NULL,
+			fetched_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		INSERT INTO opportunity (notice_id, title, posted_date, active) VALUES
+			('DOCS', 'With attachments', '2025-03-01', true),
+			('EMPTY', 'Empty resourceLinks', '2025-03-02', true),
+			('NULL', 'Null resourceLinks', '2025-03-03', true),
+			('NONE', 'No resourceLinks', '2025-03-04', true),
+			('NORAW', 'No raw row', '2025-03-05', true);
+		INSERT INTO opportunity_raw (notice_id, raw_data) VALUES
+			('DOCS', '{"noticeId": "DOCS", "resourceLinks": ["https://sam.gov/api/prod/opps/v3/opportunities/resources/files/abc/download"]}'),
+			('EMPTY', '{"noticeId": "EMPTY", "resourceLinks": []}'),
+			('NULL', '{"noticeId": "NULL", "resourceLinks": null}'),
+			('NONE', '{"noticeId": "NONE"}');
+	`)
+	execMigrationFile(t, pool, "018_opportunity_raw_resource_links_index.sql")
+	repo := NewOpportunityRepository(pool)
+
+	search := func(hasAttachments bool) string {
+		t.Helper()
+		result, err := repo.SearchOpportunitiesV2(context.Background(), SearchParamsV2{HasAttachments: &hasAttachments, IncludeArchived: true})
+		if err != nil {
+			t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+		}
+		var ids []string
+		for _, opp := range result.Items {
+			ids = append(ids, opp.NoticeID)
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+
+	if ids := search(true); ids != "DOCS" {
+		t.Errorf("Expected only DOCS with hasAttachments=true, got %s", ids)
+	}
+	if ids := search(false); ids != "EMPTY,NONE,NORAW,NULL" {
+		t.Errorf("Expected every other opportunity with hasAttachments=false, got %s", ids)
+	}
+}
+
+func TestSearchOpportunitiesV2_SeenSinceFreshness(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active, first_seen, last_updated) VALUES
+			('NEW', 'Stored after the visit', '2025-03-01', true, '2026-01-05T09:00:01Z', '2026-01-05T09:00:01Z'),
+			('UPDATED', 'Changed after the visit', '2025-03-02', true, '2026-01-01T00:00:00Z', '2026-01-05T09:00:01Z'),
+			('BOUNDARY', 'Changed at the visit', '2025-03-03', true, '2026-01-01T00:00:00Z', '2026-01-05T09:00:00Z'),
+			('OLD', 'Unchanged', '2025-03-04', true, '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z');
+	`)
+	repo := NewOpportunityRepository(pool)
+
+	seenSince := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	result, err := repo.SearchOpportunitiesV2(context.Background(), SearchParamsV2{SeenSince: &seenSince, IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	want := map[string]string{
+		"NEW":      models.FreshnessNew,
+		"UPDATED":  models.FreshnessUpdated,
+		"BOUNDARY": models.FreshnessUnchanged,
+		"OLD":      models.FreshnessUnchanged,
+	}
+	if len(result.Items) != len(want) {
+		t.Fatalf("Expected %d items, got %d", len(want), len(result.Items))
+	}
+	for _, opp := range result.Items {
+		if opp.Freshness != want[opp.NoticeID] {
+			t.Errorf("%s: Expected freshness %q, got %q", opp.NoticeID, want[opp.NoticeID], opp.Freshness)
+		}
+		if opp.FirstSeen == nil || opp.LastUpdated == nil {
+			t.Errorf("%s: Expected firstSeen and lastUpdated, got %v %v", opp.NoticeID, opp.FirstSeen, opp.LastUpdated)
+		}
+	}
+
+	// Without seenSince the timestamps are still returned, but no badge
+	result, err = repo.SearchOpportunitiesV2(context.Background(), SearchParamsV2{IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	for _, opp := range result.Items {
+		if opp.Freshness != "" {
+			t.Errorf("%s: Expected no freshness without seenSince, got %q", opp.NoticeID, opp.Freshness)
+		}
+	}
+}
+
+func TestMigration019_BackfillMatchesSAMUIURL(t *testing.T) {
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execTestSQL(t, pool, `
+		CREATE TABLE opportunity_raw (
+			id SERIAL PRIMARY KEY,
+			notice_id VARCHAR NOT NULL UNIQUE,
+			raw_data JSONB NOT NULL,
+			fetched_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		INSERT INTO opportunity (notice_id, title, posted_date, active) VALUES
+			('abc123', 'Plain', '2025-03-01', true),
+			(' A/B C?d#e%f ', 'Reserved', '2025-03-02', true),
+			('a$&+:=@~._-b;c,d', 'Sub-delims', '2025-03-03', true),
+			('Négoce–2025', 'Non-ASCII', '2025-03-04', true),
+			('UI', 'SAM page', '2025-03-05', true),
+			('API', 'API link', '2025-03-06', true);
+		INSERT INTO opportunity_raw (notice_id, raw_data) VALUES
+			('UI', '{"noticeId": "UI", "uiLink": " https://sam.gov/opp/UI/view "}'),
+			('API', '{"noticeId": "API", "uiLink": "https://api.sam.gov/opportunities/v2/search?noticeid=API"}');
+	`)
+	execMigrationFile(t, pool, "019_opportunity_sam_ui_url.sql")
+
+	rows, err := pool.Query(context.Background(), `SELECT notice_id, r.raw_data->>'uiLink', o.sam_ui_url
+		FROM opportunity o LEFT JOIN opportunity_raw r USING (notice_id)`)
+	if err != nil {
+		t.Fatalf("Failed to read sam_ui_url: %v", err)
+	}
+	defer rows.Close()
+	checked := 0
+	for rows.Next() {
+		var noticeID string
+		var uiLink, samUIURL *string
+		if err := rows.Scan(&noticeID, &uiLink, &samUIURL); err != nil {
+			t.Fatalf("Failed to scan: %v", err)
+		}
+		// The backfill must store what ingestion would
+		want := models.SAMUIURL(models.Opportunity{NoticeID: noticeID, UILink: uiLink})
+		if samUIURL == nil || *samUIURL != want {
+			t.Errorf("%q: expected %q, got %v", noticeID, want, samUIURL)
+		}
+		checked++
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Error iterating rows: %v", err)
+	}
+	if checked != 6 {
+		t.Errorf("Expected 6 rows, got %d", checked)
+	}
+}
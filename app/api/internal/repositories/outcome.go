@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/models"
+)
+
+type OutcomeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOutcomeRepository(db *pgxpool.Pool) *OutcomeRepository {
+	return &OutcomeRepository{db: db}
+}
+
+// UpsertOutcome sets the outcome for an opportunity, replacing any previous outcome
+// Returns ErrOpportunityNotFound if the notice ID does not exist
+func (r *OutcomeRepository) UpsertOutcome(ctx context.Context, outcome *models.OpportunityOutcome) error {
+	// Insert only when the opportunity exists so unknown IDs surface as not found rather than an FK error
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO opportunity_outcome (notice_id, status, awardee, award_amount, note, updated_at)
+		SELECT $1, $2, $3, $4, $5, now()
+		WHERE EXISTS (SELECT 1 FROM opportunity WHERE notice_id = $1)
+		ON CONFLICT (notice_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			awardee = EXCLUDED.awardee,
+			award_amount = EXCLUDED.award_amount,
+			note = EXCLUDED.note,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`, outcome.NoticeID, string(outcome.Status), outcome.Awardee, outcome.AwardAmount, outcome.Note,
+	).Scan(&outcome.UpdatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrOpportunityNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upsert outcome: %w", err)
+	}
+	return nil
+}
+
+// GetOutcome returns the outcome for an opportunity, or nil if none has been recorded
+func (r *OutcomeRepository) GetOutcome(ctx context.Context, noticeID string) (*models.OpportunityOutcome, error) {
+	var outcome models.OpportunityOutcome
+	var status string
+
+	err := r.db.QueryRow(ctx, `
+		SELECT notice_id, status, awardee, award_amount::float8, note, updated_at
+		FROM opportunity_outcome
+		WHERE notice_id = $1
+	`, noticeID).Scan(&outcome.NoticeID, &status, &outcome.Awardee, &outcome.AwardAmount, &outcome.Note, &outcome.UpdatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outcome: %w", err)
+	}
+	outcome.Status = models.OutcomeStatus(status)
+	return &outcome, nil
+}
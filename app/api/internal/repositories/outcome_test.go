@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/testdb"
+)
+
+func newTestOutcomeRepository(t *testing.T) *OutcomeRepository {
+	t.Helper()
+	pool := testdb.Open(t)
+	createTestOpportunityTable(t, pool)
+	execMigrationFile(t, pool, "006_opportunity_outcome.sql")
+	execTestSQL(t, pool, `INSERT INTO opportunity (notice_id) VALUES ('N1')`)
+	return NewOutcomeRepository(pool)
+}
+
+func TestOutcomeRepository_SetUpdateRead(t *testing.T) {
+	repo := newTestOutcomeRepository(t)
+	ctx := context.Background()
+
+	awardee := "Acme Corp"
+	amount := 125000.50
+	if err := repo.UpsertOutcome(ctx, &models.OpportunityOutcome{
+		NoticeID: "N1", Status: models.OutcomeStatusLost, Awardee: &awardee, AwardAmount: &amount,
+	}); err != nil {
+		t.Fatalf("Expected no error setting outcome, got %v", err)
+	}
+
+	got, err := repo.GetOutcome(ctx, "N1")
+	if err != nil || got == nil {
+		t.Fatalf("Expected outcome, got %v (err %v)", got, err)
+	}
+	if got.Status != models.OutcomeStatusLost || got.Awardee == nil || *got.Awardee != "Acme Corp" {
+		t.Errorf("Expected lost/Acme Corp, got %s/%v", got.Status, got.Awardee)
+	}
+	if got.AwardAmount == nil || *got.AwardAmount != 125000.50 {
+		t.Errorf("Expected award amount 125000.50, got %v", got.AwardAmount)
+	}
+
+	// Update replaces the previous outcome
+	note := "Decided not to pursue"
+	if err := repo.UpsertOutcome(ctx, &models.OpportunityOutcome{
+		NoticeID: "N1", Status: models.OutcomeStatusNoBid, Note: &note,
+	}); err != nil {
+		t.Fatalf("Expected no error updating outcome, got %v", err)
+	}
+	got, err = repo.GetOutcome(ctx, "N1")
+	if err != nil || got == nil {
+		t.Fatalf("Expected outcome, got %v (err %v)", got, err)
+	}
+	if got.Status != models.OutcomeStatusNoBid || got.Awardee != nil || got.Note == nil {
+		t.Errorf("Expected no_bid with note and no awardee, got %+v", got)
+	}
+}
+
+func TestOutcomeRepository_UnknownNoticeID(t *testing.T) {
+	repo := newTestOutcomeRepository(t)
+
+	err := repo.UpsertOutcome(context.Background(), &models.OpportunityOutcome{NoticeID: "MISSING", Status: models.OutcomeStatusWon})
+	if !errors.Is(err, ErrOpportunityNotFound) {
+		t.Errorf("Expected ErrOpportunityNotFound, got %v", err)
+	}
+}
+
+func TestOutcomeRepository_ReadUnset(t *testing.T) {
+	repo := newTestOutcomeRepository(t)
+
+	got, err := repo.GetOutcome(context.Background(), "N1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got != nil {
+		t.Errorf("Expected nil outcome, got %+v", got)
+	}
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/testdb"
+)
+
+// TestMigratedSchema_SupportsQueries applies the same migrations setup-db runs to an empty schema,
+// then exercises the repository queries so a column they read but no migration creates fails here
+func TestMigratedSchema_SupportsQueries(t *testing.T) {
+	pool := testdb.Open(t)
+	ctx := context.Background()
+
+	testdb.Migrate(t, pool)
+
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity_raw (notice_id, raw_data) VALUES
+			('N1', '{"solicitationNumber": "SOL-1", "fullParentPathName": "DEPT OF DEFENSE.ARMY"}');
+		INSERT INTO opportunity (
+			notice_id, title, posted_date, response_deadline, type_of_set_aside, naics,
+			classification_code, active, place_of_performance, description, department,
+			solicitation_number, agency_path_name, content_hash
+		) VALUES (
+			'N1', 'Janitorial Services', '2025-03-14', '2025-04-01T23:59:59-04:00', 'SBA', '[{"code": "561720"}]',
+			'S201', true, '{"state": {"code": "VA"}}', 'Clean the building.', 'DEPT OF DEFENSE',
+			'SOL-1', 'DEPT OF DEFENSE.ARMY', 'hash-1'
+		);
+		INSERT INTO opportunity_tag (notice_id, tag) VALUES ('N1', 'strategic');
+	`)
+
+	descRepo := NewDescriptionRepository(pool)
+	inline := "Clean the building."
+	if err := descRepo.UpsertDescription(ctx, &models.OpportunityDescription{
+		NoticeID:     "N1",
+		SourceType:   models.SourceTypeInline,
+		SourceInline: &inline,
+		FetchStatus:  models.FetchStatusFetched,
+		RawText:      &inline,
+		AIMeta:       &models.AiMeta{},
+	}); err != nil {
+		t.Fatalf("UpsertDescription failed: %v", err)
+	}
+	if _, err := descRepo.GetDescription(ctx, "N1"); err != nil {
+		t.Errorf("GetDescription failed: %v", err)
+	}
+	if _, err := descRepo.GetDescriptionStatuses(ctx, []string{"N1"}); err != nil {
+		t.Errorf("GetDescriptionStatuses failed: %v", err)
+	}
+
+	repo := NewOpportunityRepository(pool)
+	result, err := repo.SearchOpportunitiesV2(ctx, SearchParamsV2{
+		Q:              "janitorial",
+		NAICS:          "561720",
+		SetAside:       "SBA",
+		Agency:         "DEPT OF DEFENSE",
+		Classification: "S201",
+		PostedFrom:     "2025-03-01",
+		PostedTo:       "2025-03-31",
+		DueTo:          "2025-04-01",
+		Tag:            "strategic",
+		Sort:           "relevance",
+		Explain:        true,
+	})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if len(result.Items) != 1 {
+		t.Errorf("Expected 1 item, got %d", len(result.Items))
+	}
+
+	if _, err := repo.SearchOpportunities(ctx, SearchParams{SearchText: "janitorial"}); err != nil {
+		t.Errorf("SearchOpportunities failed: %v", err)
+	}
+	if _, err := repo.GetOpportunityByNoticeID(ctx, "N1"); err != nil {
+		t.Errorf("GetOpportunityByNoticeID failed: %v", err)
+	}
+	if _, err := repo.ClassificationFacets(ctx, SearchParamsV2{Q: "janitorial"}, 10); err != nil {
+		t.Errorf("ClassificationFacets failed: %v", err)
+	}
+	if _, err := repo.AgencyCounts(ctx, SearchParamsV2{Q: "janitorial"}, 10); err != nil {
+		t.Errorf("AgencyCounts failed: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"govcon/api/internal/models"
+)
+
+type TagRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTagRepository(db *pgxpool.Pool) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+// AddTag tags an opportunity; adding a tag it already has is a no-op that returns the original row
+// Returns ErrOpportunityNotFound if the notice ID does not exist
+func (r *TagRepository) AddTag(ctx context.Context, noticeID, tag string) (*models.OpportunityTag, error) {
+	result := models.OpportunityTag{NoticeID: noticeID, Tag: tag}
+
+	// Insert only when the opportunity exists so unknown IDs surface as not found rather than an FK error
+	// The no-op update lets RETURNING report the existing row on conflict
+	err := r.db.QueryRow(ctx, `
+		INSERT INTO opportunity_tag (notice_id, tag)
+		SELECT $1, $2
+		WHERE EXISTS (SELECT 1 FROM opportunity WHERE notice_id = $1)
+		ON CONFLICT (notice_id, tag) DO UPDATE SET tag = EXCLUDED.tag
+		RETURNING created_at
+	`, noticeID, tag).Scan(&result.CreatedAt)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrOpportunityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to add tag: %w", err)
+	}
+	return &result, nil
+}
+
+// AddTagBulk applies tag to every notice in noticeIDs that exists, in one statement so the batch is atomic
+// Returns the notice IDs that now carry the tag; IDs with no opportunity are left out rather than failing the batch
+func (r *TagRepository) AddTagBulk(ctx context.Context, noticeIDs []string, tag string) (map[string]bool, error) {
+	rows, err := r.db.Query(ctx, `
+		INSERT INTO opportunity_tag (notice_id, tag)
+		SELECT notice_id, $2 FROM opportunity WHERE notice_id = ANY($1)
+		ON CONFLICT (notice_id, tag) DO UPDATE SET tag = EXCLUDED.tag
+		RETURNING notice_id
+	`, noticeIDs, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add tags: %w", err)
+	}
+	defer rows.Close()
+
+	tagged := make(map[string]bool, len(noticeIDs))
+	for rows.Next() {
+		var noticeID string
+		if err := rows.Scan(&noticeID); err != nil {
+			return nil, fmt.Errorf("failed to scan tagged notice: %w", err)
+		}
+		tagged[noticeID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to add tags: %w", err)
+	}
+	return tagged, nil
+}
+
+// RemoveTag removes a tag from an opportunity
+// Returns false if the opportunity did not have the tag
+func (r *TagRepository) RemoveTag(ctx context.Context, noticeID, tag string) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM opportunity_tag WHERE notice_id = $1 AND tag = $2`, noticeID, tag)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove tag: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// ListTags returns an opportunity's tags in alphabetical order (empty if it has none)
+func (r *TagRepository) ListTags(ctx context.Context, noticeID string) ([]string, error) {
+	rows, err := r.db.Query(ctx, `SELECT tag FROM opportunity_tag WHERE notice_id = $1 ORDER BY tag`, noticeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+	return tags, nil
+}
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"govcon/api/internal/testdb"
+)
+
+func newTestTagRepository(t *testing.T) (*TagRepository, *OpportunityRepository) {
+	t.Helper()
+	pool := testdb.Open(t)
+	createTestSearchTables(t, pool)
+	execMigrationFile(t, pool, "007_opportunity_tag.sql")
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id, title, posted_date, active) VALUES
+			('N1', 'First', '2025-03-01', true),
+			('N2', 'Second', '2025-03-02', true)
+	`)
+	return NewTagRepository(pool), NewOpportunityRepository(pool)
+}
+
+func TestTagRepository_AddListRemove(t *testing.T) {
+	repo, _ := newTestTagRepository(t)
+	ctx := context.Background()
+
+	for _, tag := range []string{"strategic", "rebid", "strategic"} {
+		if _, err := repo.AddTag(ctx, "N1", tag); err != nil {
+			t.Fatalf("Expected no error adding %s, got %v", tag, err)
+		}
+	}
+	tags, err := repo.ListTags(ctx, "N1")
+	if err != nil {
+		t.Fatalf("Expected no error listing tags, got %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "rebid" || tags[1] != "strategic" {
+		t.Errorf("Expected [rebid strategic], got %v", tags)
+	}
+
+	removed, err := repo.RemoveTag(ctx, "N1", "rebid")
+	if err != nil || !removed {
+		t.Fatalf("Expected rebid to be removed, got %v (err %v)", removed, err)
+	}
+	removed, err = repo.RemoveTag(ctx, "N1", "rebid")
+	if err != nil || removed {
+		t.Errorf("Expected second removal to report false, got %v (err %v)", removed, err)
+	}
+	tags, _ = repo.ListTags(ctx, "N1")
+	if len(tags) != 1 || tags[0] != "strategic" {
+		t.Errorf("Expected [strategic] after removal, got %v", tags)
+	}
+}
+
+func TestTagRepository_UnknownNoticeID(t *testing.T) {
+	repo, _ := newTestTagRepository(t)
+
+	_, err := repo.AddTag(context.Background(), "MISSING", "rebid")
+	if !errors.Is(err, ErrOpportunityNotFound) {
+		t.Errorf("Expected ErrOpportunityNotFound, got %v", err)
+	}
+}
+
+func TestTagRepository_AddTagBulk(t *testing.T) {
+	repo, _ := newTestTagRepository(t)
+	ctx := context.Background()
+
+	if _, err := repo.AddTag(ctx, "N2", "rebid"); err != nil {
+		t.Fatalf("Expected no error adding rebid, got %v", err)
+	}
+
+	// A nonexistent ID is left out without failing the batch; an existing tag is kept
+	tagged, err := repo.AddTagBulk(ctx, []string{"N1", "MISSING", "N2"}, "rebid")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tagged) != 2 || !tagged["N1"] || !tagged["N2"] || tagged["MISSING"] {
+		t.Errorf("Expected N1 and N2 tagged, got %v", tagged)
+	}
+	for _, noticeID := range []string{"N1", "N2"} {
+		tags, _ := repo.ListTags(ctx, noticeID)
+		if len(tags) != 1 || tags[0] != "rebid" {
+			t.Errorf("%s: Expected [rebid], got %v", noticeID, tags)
+		}
+	}
+}
+
+func TestSearchOpportunitiesV2_FilterByTag(t *testing.T) {
+	repo, oppRepo := newTestTagRepository(t)
+	ctx := context.Background()
+
+	if _, err := repo.AddTag(ctx, "N2", "incumbent-known"); err != nil {
+		t.Fatalf("Expected no error adding tag, got %v", err)
+	}
+
+	result, err := oppRepo.SearchOpportunitiesV2(ctx, SearchParamsV2{Tag: "incumbent-known", IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].NoticeID != "N2" {
+		t.Errorf("Expected only N2, got %v", result.Items)
+	}
+
+	repo.RemoveTag(ctx, "N2", "incumbent-known")
+	result, err = oppRepo.SearchOpportunitiesV2(ctx, SearchParamsV2{Tag: "incumbent-known", IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("SearchOpportunitiesV2 failed: %v", err)
+	}
+	if len(result.Items) != 0 {
+		t.Errorf("Expected no results after removing the tag, got %v", result.Items)
+	}
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// execTestSQL runs SQL (inline or a migration file) against the test schema
+func execTestSQL(t *testing.T, pool *pgxpool.Pool, sql string) {
+	t.Helper()
+	if _, err := pool.Exec(context.Background(), sql); err != nil {
+		t.Fatalf("Failed to execute test SQL: %v", err)
+	}
+}
+
+// execMigrationFile runs a migration from the migrations directory against the test schema
+func execMigrationFile(t *testing.T, pool *pgxpool.Pool, name string) {
+	t.Helper()
+	sql, err := os.ReadFile("../../migrations/" + name)
+	if err != nil {
+		t.Fatalf("Failed to read migration %s: %v", name, err)
+	}
+	execTestSQL(t, pool, string(sql))
+}
+
+// createTestOpportunityTable creates a minimal opportunity table for tables that reference it
+func createTestOpportunityTable(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+	execTestSQL(t, pool, `
+		CREATE TABLE opportunity (
+			notice_id VARCHAR PRIMARY KEY,
+			title TEXT NOT NULL DEFAULT '',
+			content_hash VARCHAR NOT NULL DEFAULT ''
+		)
+	`)
+}
+
+// createTestSearchTables creates the full opportunity table (setup-db plus migration 002, 019 and 020 columns)
+// and opportunity_description, as SearchOpportunitiesV2 expects
+func createTestSearchTables(t *testing.T, pool *pgxpool.Pool) {
+	t.Helper()
+	execTestSQL(t, pool, `
+		CREATE TABLE opportunity (
+			notice_id VARCHAR PRIMARY KEY,
+			title TEXT NOT NULL,
+			organization_type VARCHAR,
+			posted_date VARCHAR,
+			type VARCHAR,
+			base_type VARCHAR,
+			archive_type VARCHAR,
+			archive_date VARCHAR,
+			type_of_set_aside VARCHAR,
+			type_of_set_aside_desc VARCHAR,
+			response_deadline VARCHAR,
+			naics JSONB,
+			classification_code VARCHAR,
+			active BOOLEAN NOT NULL DEFAULT false,
+			point_of_contact JSONB,
+			place_of_performance JSONB,
+			description TEXT,
+			department VARCHAR,
+			sub_tier VARCHAR,
+			office VARCHAR,
+			links JSONB,
+			content_hash VARCHAR NOT NULL DEFAULT '',
+			last_updated TIMESTAMPTZ NOT NULL DEFAULT now(),
+			first_seen TIMESTAMPTZ NOT NULL DEFAULT now(),
+			solicitation_number VARCHAR,
+			agency_path_name VARCHAR,
+			sam_ui_url VARCHAR,
+			archived_at TIMESTAMPTZ
+		)
+	`)
+	execMigrationFile(t, pool, "003_opportunity_description.sql")
+}
+
+// unreachableTestPool returns a pool whose connections always fail (nothing listens on port 1), for tests of
+// how database errors surface; it needs no TESTDB_URL
+func unreachableTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), "postgres://govcon@127.0.0.1:1/govcon?connect_timeout=1&sslmode=disable")
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"govcon/api/internal/models"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor can't be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListVersionsParams selects one page of an opportunity's version history
+type ListVersionsParams struct {
+	NoticeID string
+	Limit    int    // default 25, max 100
+	Cursor   string // base64 JSON cursor from a previous page's NextCursor
+}
+
+// ListVersionsResult is one page of version history, newest first
+type ListVersionsResult struct {
+	Items      []models.OpportunityVersion
+	NextCursor string
+}
+
+// VersionCursor is the keyset position after the last version of a page
+type VersionCursor struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	ID        int       `json:"id"`
+}
+
+// encodeVersionCursor encodes a version cursor to base64 JSON string
+func encodeVersionCursor(cursor VersionCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(data), nil
+}
+
+// decodeVersionCursor decodes a base64 JSON string to a version cursor
+func decodeVersionCursor(encoded string) (*VersionCursor, error) {
+	data, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	var cursor VersionCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// ListVersions returns an opportunity's versions ordered by (fetched_at, id) descending, paged by keyset
+// so deep history costs the same per page as the first one. id breaks ties between versions fetched in
+// the same ingestion run. Returns ErrOpportunityNotFound if the notice ID does not exist and
+// ErrInvalidCursor if params.Cursor doesn't decode.
+func (r *OpportunityRepository) ListVersions(ctx context.Context, params ListVersionsParams) (*ListVersionsResult, error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = 25
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	conditions := "v.notice_id = $1"
+	args := []interface{}{params.NoticeID}
+	if params.Cursor != "" {
+		cursor, err := decodeVersionCursor(params.Cursor)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		conditions += " AND (v.fetched_at, v.id) < ($2, $3)"
+		args = append(args, cursor.FetchedAt, cursor.ID)
+	}
+	args = append(args, limit+1) // Fetch one extra to determine if there's a next page
+
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT v.id, v.notice_id, v.content_hash, v.fetched_at, v.changed_fields
+		FROM opportunity_version v
+		WHERE %s
+		ORDER BY v.fetched_at DESC, v.id DESC
+		LIMIT $%d
+	`, conditions, len(args)), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query versions: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.OpportunityVersion{}
+	for rows.Next() {
+		var version models.OpportunityVersion
+		var changedFields []byte
+		if err := rows.Scan(&version.ID, &version.NoticeID, &version.ContentHash, &version.FetchedAt, &changedFields); err != nil {
+			return nil, fmt.Errorf("failed to scan version: %w", err)
+		}
+		if len(changedFields) > 0 {
+			version.ChangedFields = changedFields
+		}
+		items = append(items, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating versions: %w", err)
+	}
+
+	// An empty first page is either an opportunity that never changed or an unknown ID
+	if len(items) == 0 && params.Cursor == "" {
+		var exists bool
+		if err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM opportunity WHERE notice_id = $1)", params.NoticeID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check opportunity: %w", err)
+		}
+		if !exists {
+			return nil, ErrOpportunityNotFound
+		}
+	}
+
+	result := &ListVersionsResult{Items: items}
+	if len(items) > limit {
+		// We fetched one extra, remove it
+		result.Items = items[:limit]
+		last := result.Items[limit-1]
+		encoded, err := encodeVersionCursor(VersionCursor{FetchedAt: last.FetchedAt, ID: last.ID})
+		if err == nil {
+			result.NextCursor = encoded
+		}
+	}
+	return result, nil
+}
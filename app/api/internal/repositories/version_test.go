@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"govcon/api/internal/testdb"
+)
+
+func TestVersionCursor_RoundTrip(t *testing.T) {
+	fetchedAt := time.Date(2025, 3, 1, 12, 30, 45, 123456000, time.UTC)
+	encoded, err := encodeVersionCursor(VersionCursor{FetchedAt: fetchedAt, ID: 42})
+	if err != nil {
+		t.Fatalf("Expected no error encoding cursor, got %v", err)
+	}
+	decoded, err := decodeVersionCursor(encoded)
+	if err != nil {
+		t.Fatalf("Expected no error decoding cursor, got %v", err)
+	}
+	// Microseconds must survive, or versions fetched within the same second could be skipped
+	if !decoded.FetchedAt.Equal(fetchedAt) || decoded.ID != 42 {
+		t.Errorf("Expected %v/42, got %v/%d", fetchedAt, decoded.FetchedAt, decoded.ID)
+	}
+}
+
+func TestDecodeVersionCursor_Invalid(t *testing.T) {
+	for _, encoded := range []string{"not base64!", "bm90IGpzb24="} {
+		if _, err := decodeVersionCursor(encoded); err == nil {
+			t.Errorf("Expected error decoding %q", encoded)
+		}
+	}
+}
+
+func newTestVersionRepository(t *testing.T) *OpportunityRepository {
+	t.Helper()
+	pool := testdb.Open(t)
+	createTestOpportunityTable(t, pool)
+	execTestSQL(t, pool, `
+		CREATE TABLE opportunity_version (
+			id SERIAL PRIMARY KEY,
+			notice_id VARCHAR NOT NULL REFERENCES opportunity(notice_id) ON DELETE CASCADE,
+			content_hash VARCHAR NOT NULL,
+			raw_snapshot JSONB NOT NULL,
+			fetched_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			changed_fields JSONB
+		)
+	`)
+	execMigrationFile(t, pool, "013_opportunity_version_history_index.sql")
+	// 23 versions for N1, three per fetched_at so pages split ties; N2's versions must never leak in
+	execTestSQL(t, pool, `
+		INSERT INTO opportunity (notice_id) VALUES ('N1'), ('N2'), ('N3');
+		INSERT INTO opportunity_version (notice_id, content_hash, raw_snapshot, fetched_at)
+		SELECT 'N1', 'h' || i, '{}', '2025-03-01T00:00:00Z'::timestamptz + (i / 3) * interval '1 hour'
+		FROM generate_series(1, 23) AS i;
+		INSERT INTO opportunity_version (notice_id, content_hash, raw_snapshot, fetched_at)
+		SELECT 'N2', 'other' || i, '{}', '2025-03-01T00:00:00Z'::timestamptz + i * interval '1 hour'
+		FROM generate_series(1, 5) AS i;
+	`)
+	return NewOpportunityRepository(pool)
+}
+
+func TestListVersions_PagesWithoutGapsOrDuplicates(t *testing.T) {
+	repo := newTestVersionRepository(t)
+	ctx := context.Background()
+
+	seen := map[int]bool{}
+	var prevFetchedAt time.Time
+	prevID := 0
+	cursor := ""
+	pages := 0
+	for {
+		result, err := repo.ListVersions(ctx, ListVersionsParams{NoticeID: "N1", Limit: 5, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("Expected no error on page %d, got %v", pages+1, err)
+		}
+		pages++
+		for _, v := range result.Items {
+			if v.NoticeID != "N1" {
+				t.Errorf("Expected only N1 versions, got %s", v.NoticeID)
+			}
+			if seen[v.ID] {
+				t.Errorf("Expected each version once, got %d again", v.ID)
+			}
+			seen[v.ID] = true
+			if prevID != 0 && (v.FetchedAt.After(prevFetchedAt) || (v.FetchedAt.Equal(prevFetchedAt) && v.ID >= prevID)) {
+				t.Errorf("Expected (fetched_at, id) descending, got %v/%d after %v/%d", v.FetchedAt, v.ID, prevFetchedAt, prevID)
+			}
+			prevFetchedAt, prevID = v.FetchedAt, v.ID
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		if pages > 10 {
+			t.Fatal("Expected paging to end")
+		}
+		cursor = result.NextCursor
+	}
+
+	if len(seen) != 23 {
+		t.Errorf("Expected 23 versions across pages, got %d", len(seen))
+	}
+	if pages != 5 {
+		t.Errorf("Expected 5 pages of up to 5, got %d", pages)
+	}
+}
+
+func TestListVersions_EmptyAndUnknown(t *testing.T) {
+	repo := newTestVersionRepository(t)
+	ctx := context.Background()
+
+	result, err := repo.ListVersions(ctx, ListVersionsParams{NoticeID: "N3"})
+	if err != nil {
+		t.Fatalf("Expected no error for an opportunity without versions, got %v", err)
+	}
+	if len(result.Items) != 0 || result.NextCursor != "" {
+		t.Errorf("Expected an empty last page, got %d items and cursor %q", len(result.Items), result.NextCursor)
+	}
+
+	if _, err := repo.ListVersions(ctx, ListVersionsParams{NoticeID: "MISSING"}); !errors.Is(err, ErrOpportunityNotFound) {
+		t.Errorf("Expected ErrOpportunityNotFound, got %v", err)
+	}
+	if _, err := repo.ListVersions(ctx, ListVersionsParams{NoticeID: "N1", Cursor: "garbage!"}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+// Package samtest is a controllable fake of the SAM.gov APIs for tests: an httptest.Server answering the
+// opportunities search from fixture records (honoring limit and offset) and the noticedesc endpoint from
+// per-notice responses. Either endpoint can be given a sequence of canned responses first, e.g. a 429 then
+// a 503 then success, to exercise retries, and every request is recorded for assertions.
+//
+// Point a SAMService at SearchURL and give fixtures a DescriptionURL as their description:
+//
+//	sam := samtest.NewServer(t)
+//	sam.AddOpportunities(samtest.Opportunity("N1", "2025-01-15"))
+//	svc := &services.SAMService{APIKey: "test", BaseURL: sam.SearchURL()}
+package samtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Paths served, matching SAM's own so URLs read naturally in failures
+const (
+	SearchPath      = "/opportunities/v2/search"
+	DescriptionPath = "/prod/opportunities/v1/noticedesc"
+)
+
+// Response is one canned HTTP response
+type Response struct {
+	Status      int    // defaults to 200
+	ContentType string // defaults to application/json
+	Header      http.Header
+	Body        []byte
+}
+
+// JSON returns a 200 response with v encoded as the body
+func JSON(v any) Response {
+	body, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("samtest: failed to encode response: %v", err))
+	}
+	return Response{Body: body}
+}
+
+// Description returns a noticedesc response carrying text, as SAM sends it: {"description": text}
+func Description(text string) Response {
+	return JSON(map[string]string{"description": text})
+}
+
+// MalformedDescription returns a noticedesc body with text placed verbatim inside the JSON string, so its
+// newlines stay raw: invalid JSON, but what SAM sends for some notices
+func MalformedDescription(text string) Response {
+	return Response{Body: []byte(`{"description":"` + text + `"}`)}
+}
+
+// ErrorEnvelope returns status with SAM's JSON error body, e.g. {"error": "Description not found"}
+func ErrorEnvelope(status int, message string) Response {
+	resp := JSON(map[string]string{"error": message})
+	resp.Status = status
+	return resp
+}
+
+// Status returns status with its status text as a plain-text body, e.g. 503 "Service Unavailable"
+func Status(status int) Response {
+	return Response{Status: status, ContentType: "text/plain", Body: []byte(http.StatusText(status))}
+}
+
+// RateLimited returns a 429 with Retry-After set to retryAfter (seconds or an HTTP date); empty omits it
+func RateLimited(retryAfter string) Response {
+	resp := Status(http.StatusTooManyRequests)
+	if retryAfter != "" {
+		resp.Header = http.Header{"Retry-After": {retryAfter}}
+	}
+	return resp
+}
+
+// PDF returns a 200 application/pdf response, as SAM serves for some description links
+func PDF() Response {
+	return Response{
+		ContentType: "application/pdf",
+		Body:        []byte("%PDF-1.4\n1 0 obj << /Type /Catalog >> endobj\ntrailer << /Root 1 0 R >>\n%%EOF\n"),
+	}
+}
+
+// Opportunity returns a minimal active solicitation record in SAM's search format; callers set any other fields
+func Opportunity(noticeID, postedDate string) map[string]any {
+	return map[string]any{
+		"noticeId":   noticeID,
+		"title":      "Opportunity " + noticeID,
+		"postedDate": postedDate,
+		"type":       "Solicitation",
+		"baseType":   "Solicitation",
+		"active":     "Yes",
+	}
+}
+
+// Request is a request the server received
+type Request struct {
+	Path  string
+	Query url.Values
+}
+
+// Server is the fake SAM. Its methods are safe to call while requests are in flight.
+type Server struct {
+	*httptest.Server
+
+	mu             sync.Mutex
+	opportunities  []map[string]any
+	totalRecords   *int
+	searchQueue    []Response
+	descriptions   map[string][]Response
+	descDefault    *Response
+	requests       []Request
+	descFetchCount map[string]int
+}
+
+// NewServer starts a Server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		descriptions:   make(map[string][]Response),
+		descFetchCount: make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// SearchURL is the base URL to give SAMService
+func (s *Server) SearchURL() string {
+	return s.URL + SearchPath
+}
+
+// DescriptionURL is noticeID's noticedesc link, for a fixture's description field
+func (s *Server) DescriptionURL(noticeID string) string {
+	return s.URL + DescriptionPath + "?noticeid=" + url.QueryEscape(noticeID)
+}
+
+// AddOpportunities appends records to the search results, in the order SAM will page through them
+func (s *Server) AddOpportunities(opps ...map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opportunities = append(s.opportunities, opps...)
+}
+
+// SetTotalRecords makes every page report n as totalRecords instead of the number of records added
+func (s *Server) SetTotalRecords(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.totalRecords = &n
+}
+
+// QueueSearch makes the next search requests get responses, one each, before pages are served again
+func (s *Server) QueueSearch(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.searchQueue = append(s.searchQueue, responses...)
+}
+
+// SetDescription makes noticeID's noticedesc requests get responses in order; the last one repeats
+func (s *Server) SetDescription(noticeID string, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.descriptions[noticeID] = responses
+}
+
+// SetDefaultDescription answers noticedesc requests for notices without a SetDescription, which
+// otherwise get SAM's "Description not found" envelope
+func (s *Server) SetDefaultDescription(resp Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.descDefault = &resp
+}
+
+// Requests returns every request received so far, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// SearchRequests returns the query of every search request received so far, in order
+func (s *Server) SearchRequests() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var queries []url.Values
+	for _, req := range s.requests {
+		if req.Path == SearchPath {
+			queries = append(queries, req.Query)
+		}
+	}
+	return queries
+}
+
+// DescriptionFetches returns how many noticedesc requests noticeID has had
+func (s *Server) DescriptionFetches(noticeID string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.descFetchCount[noticeID]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Path: r.URL.Path, Query: r.URL.Query()})
+	var resp Response
+	switch r.URL.Path {
+	case SearchPath:
+		resp = s.searchResponse(r.URL.Query())
+	case DescriptionPath:
+		resp = s.descriptionResponse(r.URL.Query().Get("noticeid"))
+	default:
+		resp = Status(http.StatusNotFound)
+	}
+	s.mu.Unlock()
+
+	for key, values := range resp.Header {
+		w.Header()[key] = values
+	}
+	contentType := resp.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(resp.Body)
+}
+
+// searchResponse returns the next queued response, or the page at the request's limit and offset
+// Callers must hold s.mu
+func (s *Server) searchResponse(query url.Values) Response {
+	if len(s.searchQueue) > 0 {
+		resp := s.searchQueue[0]
+		s.searchQueue = s.searchQueue[1:]
+		return resp
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	start := min(max(offset, 0), len(s.opportunities))
+	end := min(start+max(limit, 0), len(s.opportunities))
+	total := len(s.opportunities)
+	if s.totalRecords != nil {
+		total = *s.totalRecords
+	}
+	return JSON(map[string]any{
+		"totalRecords":      total,
+		"limit":             limit,
+		"offset":            offset,
+		"opportunitiesData": s.opportunities[start:end],
+	})
+}
+
+// descriptionResponse returns noticeID's next description response, repeating the last
+// Callers must hold s.mu
+func (s *Server) descriptionResponse(noticeID string) Response {
+	s.descFetchCount[noticeID]++
+	queue, ok := s.descriptions[noticeID]
+	if !ok || len(queue) == 0 {
+		if s.descDefault != nil {
+			return *s.descDefault
+		}
+		return ErrorEnvelope(http.StatusNotFound, "Description not found")
+	}
+	if len(queue) > 1 {
+		s.descriptions[noticeID] = queue[1:]
+	}
+	return queue[0]
+}
@@ -0,0 +1,108 @@
+package samtest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/samtest"
+	"govcon/api/internal/services"
+)
+
+func TestServer_SearchRetriesThroughRateLimitAndOutage(t *testing.T) {
+	sam := samtest.NewServer(t)
+	sam.AddOpportunities(
+		samtest.Opportunity("N1", "2025-01-15"),
+		samtest.Opportunity("N2", "2025-01-14"),
+		samtest.Opportunity("N3", "2025-01-13"),
+	)
+	sam.QueueSearch(samtest.RateLimited(""), samtest.Status(http.StatusServiceUnavailable))
+
+	svc := &services.SAMService{APIKey: "test", BaseURL: sam.SearchURL()}
+	req := models.OpportunitiesRequest{PostedFrom: "01/01/2025", PostedTo: "01/31/2025", Limit: 2, Offset: 0, PType: "o"}
+
+	var resp *models.OpportunitiesResponse
+	err := services.Retry(context.Background(), services.RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond}, func() error {
+		var err error
+		resp, err = svc.SearchOpportunities(req)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.TotalRecords != 3 || len(resp.OpportunitiesData) != 2 || resp.OpportunitiesData[0].NoticeID != "N1" {
+		t.Errorf("Expected the first page of 2 of 3, got total=%d items=%d", resp.TotalRecords, len(resp.OpportunitiesData))
+	}
+	if n := len(sam.SearchRequests()); n != 3 {
+		t.Errorf("Expected 3 search requests (429, 503, success), got %d", n)
+	}
+
+	// Without retries the first queued failure surfaces as a status error
+	sam.QueueSearch(samtest.Status(http.StatusBadGateway))
+	_, err = svc.SearchOpportunities(req)
+	var statusErr *services.HTTPStatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected a 502 HTTPStatusError, got %v", err)
+	}
+}
+
+func TestServer_StreamPagesHonorLimitAndOffset(t *testing.T) {
+	sam := samtest.NewServer(t)
+	sam.AddOpportunities(samtest.Opportunity("N1", "2025-01-15"), samtest.Opportunity("N2", "2025-01-14"))
+	sam.SetTotalRecords(5) // SAM sometimes over-reports
+
+	svc := &services.SAMService{APIKey: "test", BaseURL: sam.SearchURL()}
+	var got []string
+	total, count, err := svc.StreamOpportunities(models.OpportunitiesRequest{Limit: 10, Offset: 1}, func(opp models.Opportunity) error {
+		got = append(got, opp.NoticeID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if total != 5 || count != 1 || strings.Join(got, ",") != "N2" {
+		t.Errorf("Expected total 5 and only N2, got total=%d count=%d ids=%v", total, count, got)
+	}
+	if key := sam.SearchRequests()[0].Get("api_key"); key != "test" {
+		t.Errorf("Expected api_key test, got %q", key)
+	}
+}
+
+func TestServer_DescriptionOutcomes(t *testing.T) {
+	sam := samtest.NewServer(t)
+	sam.SetDescription("RAW", samtest.MalformedDescription("Line one\nLine two"))
+	sam.SetDescription("FLAKY", samtest.Status(http.StatusServiceUnavailable), samtest.Description("<p>Recovered</p>"))
+	sam.SetDescription("PDF", samtest.PDF())
+
+	text, _, status, _, err := services.FetchDescription(sam.DescriptionURL("RAW"), "test")
+	if err != nil || status != http.StatusOK || text != "Line one\nLine two" {
+		t.Errorf("Expected raw newlines to be tolerated, got %q (status %d, err %v)", text, status, err)
+	}
+
+	_, _, status, _, err = services.FetchDescription(sam.DescriptionURL("FLAKY"), "test")
+	if !services.IsRetryableError(err) || status != http.StatusServiceUnavailable {
+		t.Errorf("Expected a retryable 503, got status %d, err %v", status, err)
+	}
+	text, _, _, _, err = services.FetchDescription(sam.DescriptionURL("FLAKY"), "test")
+	if err != nil || text != "<p>Recovered</p>" {
+		t.Errorf("Expected the second fetch to succeed, got %q, err %v", text, err)
+	}
+	if n := sam.DescriptionFetches("FLAKY"); n != 2 {
+		t.Errorf("Expected 2 fetches, got %d", n)
+	}
+
+	_, _, status, contentType, _ := services.FetchDescription(sam.DescriptionURL("PDF"), "test")
+	if status != http.StatusOK || contentType != "application/pdf" {
+		t.Errorf("Expected a 200 PDF, got status %d content type %q", status, contentType)
+	}
+
+	// Notices without a configured response get SAM's not-found envelope
+	_, _, status, _, err = services.FetchDescription(sam.DescriptionURL("MISSING"), "test")
+	if err != nil || status != http.StatusNotFound {
+		t.Errorf("Expected not found, got status %d, err %v", status, err)
+	}
+}
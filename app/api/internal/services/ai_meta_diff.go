@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"govcon/api/internal/models"
+)
+
+// AiMetaFieldDelta counts how one AiMeta field changed across a set of descriptions
+type AiMetaFieldDelta struct {
+	Gained  int // not detected before, detected now
+	Lost    int // detected before, not detected now
+	Changed int // detected both times with a different value
+}
+
+// AiMetaDiff aggregates per-field changes between stored and re-extracted AiMeta
+type AiMetaDiff struct {
+	Compared int
+	Fields   map[string]*AiMetaFieldDelta // keyed by ai_meta JSON name
+}
+
+// NewAiMetaDiff returns an empty diff
+func NewAiMetaDiff() *AiMetaDiff {
+	return &AiMetaDiff{Fields: make(map[string]*AiMetaFieldDelta)}
+}
+
+// Add records the changes from stored to current for one description; a nil meta counts as nothing detected
+func (d *AiMetaDiff) Add(stored, current *models.AiMeta) {
+	d.Compared++
+	var before, after models.AiMeta
+	if stored != nil {
+		before = *stored
+	}
+	if current != nil {
+		after = *current
+	}
+	// Compare content, not discovery order
+	before.Canonicalize()
+	after.Canonicalize()
+
+	bv, av := reflect.ValueOf(before), reflect.ValueOf(after)
+	for i := 0; i < bv.NumField(); i++ {
+		name := aiMetaFieldName(bv.Type().Field(i))
+		old, cur := bv.Field(i), av.Field(i)
+		hadIt, hasIt := aiMetaDetected(old), aiMetaDetected(cur)
+
+		delta := d.Fields[name]
+		if delta == nil {
+			delta = &AiMetaFieldDelta{}
+			d.Fields[name] = delta
+		}
+		switch {
+		case !hadIt && hasIt:
+			delta.Gained++
+		case hadIt && !hasIt:
+			delta.Lost++
+		case hadIt && hasIt && !reflect.DeepEqual(old.Interface(), cur.Interface()):
+			delta.Changed++
+		}
+	}
+}
+
+// Lines reports each field that changed, sorted by name, e.g. "wawf_required: +312 -5 ~0"
+func (d *AiMetaDiff) Lines() []string {
+	names := make([]string, 0, len(d.Fields))
+	for name, delta := range d.Fields {
+		if delta.Gained != 0 || delta.Lost != 0 || delta.Changed != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		delta := d.Fields[name]
+		lines = append(lines, fmt.Sprintf("%s: +%d -%d ~%d", name, delta.Gained, delta.Lost, delta.Changed))
+	}
+	return lines
+}
+
+// aiMetaFieldName returns the field's ai_meta JSON key
+func aiMetaFieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// aiMetaDetected reports whether an AiMeta field holds a finding:
+// a non-empty list, a true flag, or any other set value
+func aiMetaDetected(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice:
+		return v.Len() > 0
+	case reflect.Ptr:
+		if v.IsNil() {
+			return false
+		}
+		if v.Elem().Kind() == reflect.Bool {
+			return v.Elem().Bool()
+		}
+		return true
+	}
+	return !v.IsZero()
+}
+
+// ReextractAiMeta runs a stored description's raw text through the current unwrap, normalization
+// and extraction, returning the AiMeta a reprocess would store
+func ReextractAiMeta(rawText string) (models.AiMeta, error) {
+	rawTextNormalized := NormalizeRaw(UnwrapDescriptionText(rawText))
+	_, _, aiMeta, _, err := OptimizeForAI(rawTextNormalized)
+	return aiMeta, err
+}
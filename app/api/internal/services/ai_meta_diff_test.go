@@ -0,0 +1,64 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"govcon/api/internal/models"
+)
+
+func TestAiMetaDiff_CountsPerField(t *testing.T) {
+	yes, no := true, false
+	days30, days45 := 30, 45
+	setAside := "Total Small Business"
+
+	diff := NewAiMetaDiff()
+	diff.Add(
+		&models.AiMeta{SetAsideDetected: &setAside, DeliveryDaysARO: &days30, POCEmails: []string{"b@x.gov", "a@x.gov"}},
+		&models.AiMeta{WAWFRequired: &yes, DeliveryDaysARO: &days45, POCEmails: []string{"a@x.gov", "b@x.gov"}},
+	)
+	diff.Add(&models.AiMeta{WAWFRequired: &no}, &models.AiMeta{WAWFRequired: &yes})
+	diff.Add(nil, &models.AiMeta{})
+
+	if diff.Compared != 3 {
+		t.Errorf("Expected 3 compared, got %d", diff.Compared)
+	}
+	want := []string{
+		"delivery_days_aro: +0 -0 ~1",
+		"set_aside_detected: +0 -1 ~0",
+		"wawf_required: +2 -0 ~0",
+	}
+	if got := diff.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v (reordered emails must not count as a change)", want, got)
+	}
+}
+
+func TestAiMetaDiff_ReextractedFixture(t *testing.T) {
+	setAside := "Total Small Business"
+	fixture := []struct {
+		rawText string
+		stored  models.AiMeta
+	}{
+		// Stored before WAWF detection existed
+		{"Invoices shall be submitted through WAWF within 10 days of delivery.", models.AiMeta{}},
+		// Stored set-aside no longer present in the text
+		{"The contractor shall provide janitorial services for the building.", models.AiMeta{SetAsideDetected: &setAside}},
+	}
+
+	diff := NewAiMetaDiff()
+	for _, f := range fixture {
+		current, err := ReextractAiMeta(f.rawText)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		stored := f.stored
+		diff.Add(&stored, &current)
+	}
+
+	if got := diff.Fields["wawf_required"]; got == nil || *got != (AiMetaFieldDelta{Gained: 1}) {
+		t.Errorf("Expected wawf_required +1, got %+v", got)
+	}
+	if got := diff.Fields["set_aside_detected"]; got == nil || *got != (AiMetaFieldDelta{Lost: 1}) {
+		t.Errorf("Expected set_aside_detected -1, got %+v", got)
+	}
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"govcon/api/internal/models"
+)
+
+// dateOnlyDeadlineLayouts are the deadline formats without a time of day (SAM ISO dates, file imports)
+var dateOnlyDeadlineLayouts = []string{"2006-01-02", "01/02/2006"}
+
+// getDeadlineLocation returns the timezone date-only deadlines close in: the app timezone
+// (APP_TIMEZONE, or DEADLINE_TIMEZONE for older configs; see models.AppLocation)
+func getDeadlineLocation() *time.Location {
+	return models.AppLocation()
+}
+
+// NormalizeResponseDeadline turns a date-only deadline into end of that day (23:59:59) in loc, as RFC3339,
+// so the opportunity counts as open through the whole day. Deadlines with a time of day, and values
+// that don't parse, are returned unchanged.
+func NormalizeResponseDeadline(deadline string, loc *time.Location) string {
+	trimmed := strings.TrimSpace(deadline)
+	for _, layout := range dateOnlyDeadlineLayouts {
+		day, err := time.Parse(layout, trimmed)
+		if err != nil {
+			continue
+		}
+		return time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, loc).Format(time.RFC3339)
+	}
+	return deadline
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeResponseDeadline(t *testing.T) {
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"2025-03-15", "2025-03-15T23:59:59-04:00"}, // EDT
+		{"2025-01-15", "2025-01-15T23:59:59-05:00"}, // EST
+		{"01/15/2025", "2025-01-15T23:59:59-05:00"}, // file import format
+		{"2025-03-15T17:00:00-04:00", "2025-03-15T17:00:00-04:00"},
+		{"2025-03-15T17:00:00", "2025-03-15T17:00:00"},
+		{"", ""},
+		{"TBD", "TBD"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeResponseDeadline(tt.in, eastern); got != tt.want {
+			t.Errorf("NormalizeResponseDeadline(%q): Expected %q, got %q", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestNormalizeResponseDeadline_OpenThroughWholeDay(t *testing.T) {
+	loc := time.FixedZone("EST", -5*3600)
+	deadline, err := time.Parse(time.RFC3339, NormalizeResponseDeadline("2025-01-15", loc))
+	if err != nil {
+		t.Fatalf("Expected RFC3339 deadline: %v", err)
+	}
+
+	for _, now := range []time.Time{
+		time.Date(2025, 1, 15, 0, 0, 1, 0, loc),
+		time.Date(2025, 1, 15, 17, 0, 0, 0, loc),
+		time.Date(2025, 1, 15, 23, 59, 0, 0, loc),
+	} {
+		if !now.Before(deadline) {
+			t.Errorf("Expected opportunity still open at %s (deadline %s)", now, deadline)
+		}
+	}
+	if next := time.Date(2025, 1, 16, 0, 0, 0, 0, loc); next.Before(deadline) {
+		t.Errorf("Expected deadline passed by %s", next)
+	}
+}
+
+func TestGetDeadlineLocation(t *testing.T) {
+	t.Setenv("APP_TIMEZONE", "")
+	t.Setenv("DEADLINE_TIMEZONE", "UTC")
+	if loc := getDeadlineLocation(); loc.String() != "UTC" {
+		t.Errorf("Expected UTC, got %s", loc)
+	}
+
+	t.Setenv("DEADLINE_TIMEZONE", "Not/AZone")
+	if loc := getDeadlineLocation(); loc != time.UTC {
+		t.Errorf("Expected UTC fallback for unknown zone, got %s", loc)
+	}
+}
+
+// A date-only deadline closes at the end of the day in APP_TIMEZONE, so the same instant can be
+// before the deadline in one zone and after it in another
+func TestNormalizeResponseDeadline_ConfiguredTimezone(t *testing.T) {
+	instant := time.Date(2025, 3, 15, 16, 0, 0, 0, time.UTC) // noon in New York, 01:00 Mar 16 in Tokyo
+
+	for _, c := range []struct {
+		zone string
+		want string
+		open bool
+	}{
+		{"America/New_York", "2025-03-15T23:59:59-04:00", true},
+		{"Asia/Tokyo", "2025-03-15T23:59:59+09:00", false},
+	} {
+		t.Setenv("APP_TIMEZONE", c.zone)
+		got := NormalizeResponseDeadline("2025-03-15", getDeadlineLocation())
+		if got != c.want {
+			t.Errorf("%s: Expected %q, got %q", c.zone, c.want, got)
+			continue
+		}
+		deadline, _ := time.Parse(time.RFC3339, got)
+		if open := instant.Before(deadline); open != c.open {
+			t.Errorf("%s: Expected open=%v at %s, got %v", c.zone, c.open, instant, open)
+		}
+	}
+}
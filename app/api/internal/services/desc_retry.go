@@ -0,0 +1,61 @@
+package services
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultDescRetryCooldown    = time.Hour
+	defaultDescRetryMaxAttempts = 5
+	maxDescRetryDelay           = 7 * 24 * time.Hour // Backoff stops doubling here
+)
+
+// DescriptionRetryPolicy decides when cmd/retry-desc-errors re-fetches a description stuck in fetch_status error
+type DescriptionRetryPolicy struct {
+	Cooldown    time.Duration // Wait after the first failure; doubles with each further failure
+	MaxAttempts int           // Give up once this many consecutive fetches have failed
+}
+
+// GetDescriptionRetryPolicy reads DESC_RETRY_COOLDOWN (Go duration, default 1h) and
+// DESC_RETRY_MAX_ATTEMPTS (default 5)
+func GetDescriptionRetryPolicy() DescriptionRetryPolicy {
+	policy := DescriptionRetryPolicy{Cooldown: defaultDescRetryCooldown, MaxAttempts: defaultDescRetryMaxAttempts}
+	if cooldownStr := os.Getenv("DESC_RETRY_COOLDOWN"); cooldownStr != "" {
+		if cooldown, err := time.ParseDuration(cooldownStr); err == nil && cooldown > 0 {
+			policy.Cooldown = cooldown
+		}
+	}
+	if attemptsStr := os.Getenv("DESC_RETRY_MAX_ATTEMPTS"); attemptsStr != "" {
+		if attempts, err := strconv.Atoi(attemptsStr); err == nil && attempts > 0 {
+			policy.MaxAttempts = attempts
+		}
+	}
+	return policy
+}
+
+// Delay returns how long after the last failed fetch the next retry waits, given attempts failures so far:
+// Cooldown after one, 2x after two, 4x after three, and so on, capped at maxDescRetryDelay
+func (p DescriptionRetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.Cooldown
+	for i := 1; i < attempts && delay < maxDescRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDescRetryDelay {
+		return maxDescRetryDelay
+	}
+	return delay
+}
+
+// Due reports whether a description with attempts consecutive failures, last tried at lastAttempt, should be
+// retried at now. Rows at MaxAttempts have been given up on; a row with no recorded attempt time is due.
+func (p DescriptionRetryPolicy) Due(attempts int, lastAttempt *time.Time, now time.Time) bool {
+	if attempts >= p.MaxAttempts {
+		return false
+	}
+	if lastAttempt == nil {
+		return true
+	}
+	return !now.Before(lastAttempt.Add(p.Delay(attempts)))
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDescriptionRetryPolicy_DelayDoublesAndCaps(t *testing.T) {
+	policy := DescriptionRetryPolicy{Cooldown: time.Hour, MaxAttempts: 100}
+	cases := map[int]time.Duration{
+		0:  time.Hour,
+		1:  time.Hour,
+		2:  2 * time.Hour,
+		4:  8 * time.Hour,
+		50: maxDescRetryDelay,
+	}
+	for attempts, expected := range cases {
+		if got := policy.Delay(attempts); got != expected {
+			t.Errorf("attempts %d: expected %v, got %v", attempts, expected, got)
+		}
+	}
+}
+
+func TestDescriptionRetryPolicy_Due(t *testing.T) {
+	policy := DescriptionRetryPolicy{Cooldown: time.Hour, MaxAttempts: 3}
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+	at := func(ago time.Duration) *time.Time {
+		tried := now.Add(-ago)
+		return &tried
+	}
+	cases := []struct {
+		name        string
+		attempts    int
+		lastAttempt *time.Time
+		expected    bool
+	}{
+		{"within cooldown", 1, at(30 * time.Minute), false},
+		{"cooldown elapsed", 1, at(time.Hour), true},
+		{"second failure waits twice as long", 2, at(90 * time.Minute), false},
+		{"second failure after doubled cooldown", 2, at(2 * time.Hour), true},
+		{"max attempts reached", 3, at(24 * time.Hour), false},
+		{"never tried", 1, nil, true},
+	}
+	for _, c := range cases {
+		if got := policy.Due(c.attempts, c.lastAttempt, now); got != c.expected {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+		}
+	}
+}
+
+func TestGetDescriptionRetryPolicy(t *testing.T) {
+	t.Setenv("DESC_RETRY_COOLDOWN", "")
+	t.Setenv("DESC_RETRY_MAX_ATTEMPTS", "")
+	if policy := GetDescriptionRetryPolicy(); policy.Cooldown != defaultDescRetryCooldown || policy.MaxAttempts != defaultDescRetryMaxAttempts {
+		t.Errorf("Expected defaults, got %+v", policy)
+	}
+
+	t.Setenv("DESC_RETRY_COOLDOWN", "15m")
+	t.Setenv("DESC_RETRY_MAX_ATTEMPTS", "8")
+	if policy := GetDescriptionRetryPolicy(); policy.Cooldown != 15*time.Minute || policy.MaxAttempts != 8 {
+		t.Errorf("Expected 15m/8, got %+v", policy)
+	}
+
+	t.Setenv("DESC_RETRY_COOLDOWN", "soon")
+	t.Setenv("DESC_RETRY_MAX_ATTEMPTS", "0")
+	if policy := GetDescriptionRetryPolicy(); policy.Cooldown != defaultDescRetryCooldown || policy.MaxAttempts != defaultDescRetryMaxAttempts {
+		t.Errorf("Expected invalid values to fall back to defaults, got %+v", policy)
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"time"
+
+	"govcon/api/internal/models"
+)
+
+// DescriptionFetch is the outcome of one FetchDescription call
+type DescriptionFetch struct {
+	RawText     string
+	RawJSON     string
+	HTTPStatus  int
+	ContentType string
+	Err         error
+}
+
+// FetchedDescription builds the opportunity_description row to store after fetching noticeID's description
+// from sourceURL: fetch_status from ClassifyFetchResult, then the error (flagging oversize), the not-found body,
+// or the unwrapped and normalized text with AI fields (reused from prev or lookup when the content matches)
+// Shared by the on-demand endpoint and cmd/retry-desc-errors so both store fetches the same way
+func FetchedDescription(noticeID, sourceURL string, fetch DescriptionFetch, prev *models.OpportunityDescription, now time.Time, lookup AIFieldLookup) *models.OpportunityDescription {
+	desc := &models.OpportunityDescription{
+		NoticeID:    noticeID,
+		SourceType:  models.SourceTypeURL,
+		SourceURL:   &sourceURL,
+		HTTPStatus:  &fetch.HTTPStatus,
+		FetchedAt:   &now,
+		ContentType: &fetch.ContentType,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	switch ClassifyFetchResult(fetch.HTTPStatus, fetch.RawText, fetch.Err) {
+	case models.FetchStatusError:
+		errorMsg := fetch.Err.Error()
+		desc.FetchStatus = models.FetchStatusError
+		desc.LastError = &errorMsg
+		desc.Oversize = IsDescriptionTooLarge(fetch.Err)
+	case models.FetchStatusNotFound:
+		desc.FetchStatus = models.FetchStatusNotFound
+		desc.RawText = &fetch.RawText
+		if fetch.RawJSON != "" {
+			desc.RawJsonResponse = &fetch.RawJSON
+		}
+	default:
+		// Success - store raw JSON response, then unwrap and normalize
+		if fetch.RawJSON != "" {
+			desc.RawJsonResponse = &fetch.RawJSON
+		}
+		desc.FetchStatus = models.FetchStatusFetched
+
+		// AI optimization only runs when no stored copy has output for this content hash
+		ApplyNormalizationWithLookup(desc, fetch.RawText, prev, now, lookup)
+	}
+	return desc
+}
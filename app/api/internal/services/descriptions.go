@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"io"
@@ -12,9 +13,11 @@ import (
 	"net/url"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"govcon/api/internal/models"
 )
@@ -56,21 +59,114 @@ func (s *DescriptionService) FetchDescriptionWithKey(descURL string) (string, st
 }
 
 const (
-	maxBodySize = 5 * 1024 * 1024 // 5MB
 	fetchTimeout = 10 * time.Second
-	maxExtractScanLength = 10 * 1024 * 1024 // 10MB max scan length
-	maxExtractedLength = 5 * 1024 * 1024    // 5MB max extracted description length
-	maxUnwrapRecursion = 2                   // Max recursion depth for UnwrapDescriptionText
-	NORMALIZATION_VERSION = 4                // Version of normalization logic - increment when NormalizeRaw, Normalize, or UnwrapDescriptionText changes
+	NORMALIZATION_VERSION = 6                // Version of normalization logic - increment when NormalizeRaw, Normalize, or UnwrapDescriptionText changes
+	AI_INPUT_VERSION = 2                     // Version of the AI fields - increment when OptimizeForAI's output, its config defaults, or AiMeta changes
 )
 
+// Description size and depth guardrails; each can be overridden per deployment (see getDescriptionLimit)
+const (
+	defaultMaxBodySize = 5 * 1024 * 1024           // 5MB max fetched description body (DESC_MAX_BODY_BYTES)
+	defaultMaxExtractScanLength = 10 * 1024 * 1024 // 10MB max scan length (DESC_MAX_SCAN_BYTES)
+	defaultMaxExtractedLength = 5 * 1024 * 1024    // 5MB max extracted description length (DESC_MAX_EXTRACTED_BYTES)
+	defaultMaxUnwrapRecursion = 16                 // Max unwrap depth; unwrapping also stops once a step changes nothing (DESC_MAX_UNWRAP_DEPTH)
+)
+
+// getDescriptionLimit returns the positive integer in env var name, or def when unset or invalid
+func getDescriptionLimit(name string, def int) int {
+	if limitStr := os.Getenv(name); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			return limit
+		}
+	}
+	return def
+}
+
+// getMaxBodySize returns the largest description body accepted, in bytes
+func getMaxBodySize() int { return getDescriptionLimit("DESC_MAX_BODY_BYTES", defaultMaxBodySize) }
+
+// getMaxExtractScanLength returns the longest payload ExtractDescriptionJSONLike will scan, in bytes
+func getMaxExtractScanLength() int {
+	return getDescriptionLimit("DESC_MAX_SCAN_BYTES", defaultMaxExtractScanLength)
+}
+
+// getMaxExtractedLength returns the longest description ExtractDescriptionJSONLike will return, in bytes
+func getMaxExtractedLength() int {
+	return getDescriptionLimit("DESC_MAX_EXTRACTED_BYTES", defaultMaxExtractedLength)
+}
+
+// getMaxUnwrapRecursion returns how many wrappers UnwrapDescriptionText will remove
+func getMaxUnwrapRecursion() int {
+	return getDescriptionLimit("DESC_MAX_UNWRAP_DEPTH", defaultMaxUnwrapRecursion)
+}
+
+// logDescriptionLimit notes that a guardrail cut processing short, so truncation and rejections are visible
+func logDescriptionLimit(setting string, limit, size int) {
+	log.Printf("[debug] description limit hit: %s=%d (got %d); raise %s if this is a legitimate description", setting, limit, size, setting)
+}
+
+// DescriptionTooLargeError reports a description rejected by a size guardrail, so callers can tell users it is
+// too large to display here instead of silently storing nothing
+type DescriptionTooLargeError struct {
+	What    string // what was too large, e.g. "response body"
+	Setting string // env var that sets the limit
+	Limit   int    // bytes
+}
+
+func (e *DescriptionTooLargeError) Error() string {
+	return fmt.Sprintf("%s exceeds maximum size of %d bytes (%s)", e.What, e.Limit, e.Setting)
+}
+
+// IsDescriptionTooLarge reports whether err came from a description size guardrail
+func IsDescriptionTooLarge(err error) bool {
+	var tooLarge *DescriptionTooLargeError
+	return errors.As(err, &tooLarge)
+}
+
+// MaxDescriptionBodySize returns the largest description payload accepted (DESC_MAX_BODY_BYTES), shared with
+// handlers taking ad-hoc text
+func MaxDescriptionBodySize() int64 {
+	return int64(getMaxBodySize())
+}
+
+// getDescAttachmentSource reports whether an opportunity with no description but with attachment links is
+// classified as an attachment source rather than none (DESC_ATTACHMENT_SOURCE, default true)
+func getDescAttachmentSource() bool {
+	if enabled, err := strconv.ParseBool(os.Getenv("DESC_ATTACHMENT_SOURCE")); err == nil {
+		return enabled
+	}
+	return true
+}
+
+// attachmentLink returns the first place an opportunity's content may live when it has no description:
+// a resourceLinks attachment, or failing that a links entry other than SAM's API self link ("" if neither)
+func attachmentLink(opportunity models.Opportunity) string {
+	for _, link := range opportunity.ResourceLinks {
+		if link = strings.TrimSpace(link); strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") {
+			return link
+		}
+	}
+	for _, link := range opportunity.Links {
+		href := strings.TrimSpace(link.Href)
+		if !strings.EqualFold(link.Rel, "self") && (strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://")) {
+			return href
+		}
+	}
+	return ""
+}
+
 // DetectSource analyzes the description field and determines the source type
-// Returns: sourceType, url (if url), inline (if inline)
+// Returns: sourceType, url (if url or attachment), inline (if inline)
+// An empty description is an attachment source when the opportunity links attachments (see attachmentLink)
+// and DESC_ATTACHMENT_SOURCE allows it, otherwise none
 func DetectSource(opportunity models.Opportunity) (sourceType models.DescriptionSourceType, urlStr string, inline string) {
 	desc := strings.TrimSpace(opportunity.Description)
 	
-	// If empty or null, return none
+	// If empty or null, the content may still be in the attachments
 	if desc == "" {
+		if link := attachmentLink(opportunity); link != "" && getDescAttachmentSource() {
+			return models.SourceTypeAttachment, link, ""
+		}
 		return models.SourceTypeNone, "", ""
 	}
 	
@@ -181,9 +277,17 @@ func parseLenientJSONString(s string, startQuote int) (string, int, bool) {
 // inside strings). Returns (desc, true) on success.
 // Only matches the top-level "description" key to avoid nested or string-literal matches.
 func ExtractDescriptionJSONLike(s string) (string, bool) {
+	desc, ok, _ := extractDescriptionJSONLike(s)
+	return desc, ok
+}
+
+// extractDescriptionJSONLike is ExtractDescriptionJSONLike, also returning a *DescriptionTooLargeError when
+// a size guardrail is what stopped the extraction
+func extractDescriptionJSONLike(s string) (string, bool, error) {
 	// Guardrails: limit scan length
-	if len(s) > maxExtractScanLength {
-		return "", false
+	if maxScan := getMaxExtractScanLength(); len(s) > maxScan {
+		logDescriptionLimit("DESC_MAX_SCAN_BYTES", maxScan, len(s))
+		return "", false, &DescriptionTooLargeError{What: "description payload", Setting: "DESC_MAX_SCAN_BYTES", Limit: maxScan}
 	}
 
 	key := `"description"`
@@ -198,7 +302,7 @@ func ExtractDescriptionJSONLike(s string) (string, bool) {
 		i++
 	}
 	if i >= len(s) {
-		return "", false
+		return "", false, nil
 	}
 	depth = 1
 	i++ // Move past '{'
@@ -232,7 +336,7 @@ func ExtractDescriptionJSONLike(s string) (string, bool) {
 						i++
 					}
 					if i >= len(s) || s[i] != ':' {
-						return "", false
+						return "", false, nil
 					}
 					i++ // past ':'
 
@@ -241,26 +345,27 @@ func ExtractDescriptionJSONLike(s string) (string, bool) {
 						i++
 					}
 					if i >= len(s) {
-						return "", false
+						return "", false, nil
 					}
 
 					// We only handle string values here: "...."
 					if s[i] != '"' {
-						return "", false
+						return "", false, nil
 					}
 
 					// Parse the string value (lenient)
 					val, _, ok := parseLenientJSONString(s, i)
 					if !ok {
-						return "", false
+						return "", false, nil
 					}
 
 					// Guardrail: limit extracted length
-					if len(val) > maxExtractedLength {
-						return "", false
+					if maxExtracted := getMaxExtractedLength(); len(val) > maxExtracted {
+						logDescriptionLimit("DESC_MAX_EXTRACTED_BYTES", maxExtracted, len(val))
+						return "", false, &DescriptionTooLargeError{What: "extracted description", Setting: "DESC_MAX_EXTRACTED_BYTES", Limit: maxExtracted}
 					}
 
-					return val, true
+					return val, true, nil
 				}
 			}
 			inString = !inString
@@ -277,7 +382,7 @@ func ExtractDescriptionJSONLike(s string) (string, bool) {
 			if ch == '}' || ch == ']' {
 				depth--
 				if depth < 0 {
-					return "", false
+					return "", false, nil
 				}
 				i++
 				continue
@@ -287,19 +392,21 @@ func ExtractDescriptionJSONLike(s string) (string, bool) {
 		i++
 	}
 
-	return "", false
+	return "", false, nil
 }
 
 // UnwrapDescriptionText tries to extract the real description text from common SAM formats.
-// Handles: plain text, {"description":"..."}, and double-encoded JSON strings.
-// Uses recursion limit to avoid pathological inputs.
+// Handles: plain text, {"description":"..."}, {"description":["...","..."]} (joined with newlines),
+// and double-encoded JSON strings, however many times they are wrapped.
+// Stops when a step no longer changes the text, with a depth limit as a backstop for pathological inputs.
 func UnwrapDescriptionText(input string) string {
 	return unwrapDescriptionTextRecursive(input, 0)
 }
 
 // unwrapDescriptionTextRecursive is the recursive implementation with depth tracking.
 func unwrapDescriptionTextRecursive(input string, depth int) string {
-	if depth >= maxUnwrapRecursion {
+	if maxDepth := getMaxUnwrapRecursion(); depth >= maxDepth {
+		logDescriptionLimit("DESC_MAX_UNWRAP_DEPTH", maxDepth, depth)
 		return input
 	}
 
@@ -308,34 +415,46 @@ func unwrapDescriptionTextRecursive(input string, depth int) string {
 		return input
 	}
 
+	// Loop guard: only go deeper when unwrapping produced different text
+	unwrap := func(next string) string {
+		if strings.TrimSpace(next) == s {
+			return input
+		}
+		return unwrapDescriptionTextRecursive(next, depth+1)
+	}
+
 	// Case A: input is a JSON object with "description"
 	if strings.HasPrefix(s, "{") && strings.Contains(s, "\"description\"") {
 		var obj struct {
 			Description any `json:"description"`
 		}
-		if err := json.Unmarshal([]byte(s), &obj); err == nil {
+		if err := models.DecodeJSON([]byte(s), &obj); err == nil {
 			switch v := obj.Description.(type) {
 			case string:
 				if strings.TrimSpace(v) != "" {
 					// Recurse: some SAM payloads contain another JSON wrapper in the value.
-					return unwrapDescriptionTextRecursive(v, depth+1)
+					return unwrap(v)
 				}
 			case map[string]any:
 				// Handle map by marshaling and recursing
 				if marshaled, err := json.Marshal(v); err == nil {
-					return unwrapDescriptionTextRecursive(string(marshaled), depth+1)
+					return unwrap(string(marshaled))
 				}
 			case []any:
-				// Handle slice by marshaling and recursing
+				// An array of text fragments reads as one description, a fragment per line
+				if joined, ok := joinDescriptionParts(v, depth+1); ok {
+					return joined
+				}
+				// Any other slice: marshal and recurse
 				if marshaled, err := json.Marshal(v); err == nil {
-					return unwrapDescriptionTextRecursive(string(marshaled), depth+1)
+					return unwrap(string(marshaled))
 				}
 			}
 		} else {
 			// Fallback for malformed JSON
 			if v, ok := ExtractDescriptionJSONLike(s); ok && strings.TrimSpace(v) != "" {
-				// Recurse once in case it was double-wrapped
-				return unwrapDescriptionTextRecursive(v, depth+1)
+				// Recurse in case it was wrapped again
+				return unwrap(v)
 			}
 		}
 	}
@@ -345,11 +464,11 @@ func unwrapDescriptionTextRecursive(input string, depth int) string {
 		var inner string
 		if err := json.Unmarshal([]byte(s), &inner); err == nil {
 			// recurse: inner could be {"description":"..."} or plain text
-			return unwrapDescriptionTextRecursive(inner, depth+1)
+			return unwrap(inner)
 		} else {
 			// Inner unmarshal failed - try lenient extraction as fallback
 			if v, ok := ExtractDescriptionJSONLike(s); ok && strings.TrimSpace(v) != "" {
-				return unwrapDescriptionTextRecursive(v, depth+1)
+				return unwrap(v)
 			}
 		}
 	}
@@ -357,6 +476,25 @@ func unwrapDescriptionTextRecursive(input string, depth int) string {
 	return input
 }
 
+// joinDescriptionParts joins a description sent as an array of strings with newlines, unwrapping each part
+// and skipping blank ones. Returns false if any element isn't a string or no part has text.
+func joinDescriptionParts(parts []any, depth int) (string, bool) {
+	var lines []string
+	for _, part := range parts {
+		text, ok := part.(string)
+		if !ok {
+			return "", false
+		}
+		if text = strings.TrimSpace(unwrapDescriptionTextRecursive(text, depth)); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	if len(lines) == 0 {
+		return "", false
+	}
+	return strings.Join(lines, "\n"), true
+}
+
 // FetchDescription fetches a description from a SAM API URL
 // Returns: rawText, rawJsonResponse, httpStatus, contentType, error
 func FetchDescription(descURL string, apiKey string) (string, string, int, string, error) {
@@ -382,7 +520,7 @@ func FetchDescription(descURL string, apiKey string) (string, string, int, strin
 		return "", "", 0, "", fmt.Errorf("failed to create request: %w", err)
 	}
 	
-	httpReq.Header.Set("Accept", "application/json")
+	setOutboundHeaders(httpReq)
 	
 	// Create HTTP client with timeout
 	client := &http.Client{
@@ -400,7 +538,8 @@ func FetchDescription(descURL string, apiKey string) (string, string, int, strin
 	contentType := resp.Header.Get("Content-Type")
 	
 	// Limit body size using LimitReader
-	limitedReader := io.LimitReader(resp.Body, maxBodySize)
+	maxBodySize := getMaxBodySize()
+	limitedReader := io.LimitReader(resp.Body, int64(maxBodySize))
 	bodyBytes, err := io.ReadAll(limitedReader)
 	if err != nil {
 		return "", "", resp.StatusCode, contentType, fmt.Errorf("failed to read response body: %w", err)
@@ -408,7 +547,8 @@ func FetchDescription(descURL string, apiKey string) (string, string, int, strin
 	
 	// Check if we hit the limit
 	if len(bodyBytes) >= maxBodySize {
-		return "", "", resp.StatusCode, contentType, fmt.Errorf("response body exceeds maximum size of %d bytes", maxBodySize)
+		logDescriptionLimit("DESC_MAX_BODY_BYTES", maxBodySize, len(bodyBytes))
+		return "", "", resp.StatusCode, contentType, &DescriptionTooLargeError{What: "response body", Setting: "DESC_MAX_BODY_BYTES", Limit: maxBodySize}
 	}
 	
 	// Store raw JSON response before any processing
@@ -416,7 +556,7 @@ func FetchDescription(descURL string, apiKey string) (string, string, int, strin
 	
 	// Try to parse as JSON and extract description field
 	var jsonResponse map[string]interface{}
-	if err := json.Unmarshal(bodyBytes, &jsonResponse); err == nil {
+	if err := models.DecodeJSON(bodyBytes, &jsonResponse); err == nil {
 		// Successfully parsed as JSON, try to extract description field
 		if descValue, ok := jsonResponse["description"]; ok {
 			// Handle string description
@@ -424,6 +564,12 @@ func FetchDescription(descURL string, apiKey string) (string, string, int, strin
 				// Unwrap any JSON wrapper before returning
 				return finalize(desc), rawJsonResponse, resp.StatusCode, contentType, nil
 			}
+			// Handle a description split into an array of text fragments
+			if parts, ok := descValue.([]interface{}); ok {
+				if desc, ok := joinDescriptionParts(parts, 0); ok {
+					return finalize(desc), rawJsonResponse, resp.StatusCode, contentType, nil
+				}
+			}
 		}
 		// If description field doesn't exist or is empty, check for error messages
 		if errorMsg, ok := jsonResponse["error"].(string); ok {
@@ -435,7 +581,7 @@ func FetchDescription(descURL string, apiKey string) (string, string, int, strin
 		rawText := string(bodyBytes)
 		rawText = finalize(rawText)
 		if resp.StatusCode != http.StatusOK {
-			return rawText, rawJsonResponse, resp.StatusCode, contentType, fmt.Errorf("SAM API returned status %d", resp.StatusCode)
+			return rawText, rawJsonResponse, resp.StatusCode, contentType, &HTTPStatusError{StatusCode: resp.StatusCode}
 		}
 		return rawText, rawJsonResponse, resp.StatusCode, contentType, nil
 	} else {
@@ -454,9 +600,14 @@ func FetchDescription(descURL string, apiKey string) (string, string, int, strin
 		}
 
 		// Fallback: tolerate malformed JSON by extracting "description" manually
-		if desc, ok := ExtractDescriptionJSONLike(string(bodyBytes)); ok && strings.TrimSpace(desc) != "" {
+		desc, ok, tooLarge := extractDescriptionJSONLike(string(bodyBytes))
+		if ok && strings.TrimSpace(desc) != "" {
 			return finalize(desc), rawJsonResponse, resp.StatusCode, contentType, nil
 		}
+		// Don't fall through to storing the whole payload as if it were the description
+		if tooLarge != nil {
+			return "", rawJsonResponse, resp.StatusCode, contentType, tooLarge
+		}
 	}
 	
 	// Not JSON or failed to parse, treat as plain text
@@ -470,7 +621,7 @@ func FetchDescription(descURL string, apiKey string) (string, string, int, strin
 	
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		return rawText, rawJsonResponse, resp.StatusCode, contentType, fmt.Errorf("SAM API returned status %d", resp.StatusCode)
+		return rawText, rawJsonResponse, resp.StatusCode, contentType, &HTTPStatusError{StatusCode: resp.StatusCode}
 	}
 	
 	return rawText, rawJsonResponse, resp.StatusCode, contentType, nil
@@ -560,6 +711,7 @@ func Normalize(rawText string) string {
 	lines := strings.Split(normalized, "\n")
 	var processedLines []string
 	blankLineCount := 0
+	fillMaxRun := getFillMaxRun()
 	
 	// Patterns for cleaning up pipe-related artifacts
 	// Match patterns like |1|, |2|, |3|, etc. (pipe, number, pipe)
@@ -588,6 +740,8 @@ func Normalize(rawText string) string {
 		cleaned = leadingPipePattern.ReplaceAllString(cleaned, "")
 		// Remove trailing pipes and whitespace
 		cleaned = trailingPipePattern.ReplaceAllString(cleaned, "")
+		// Tabs become spaces and form-field/leader fill is shortened, before spaces are collapsed
+		cleaned = collapseFill(cleaned, fillMaxRun)
 		// Clean up multiple spaces (using pre-compiled pattern)
 		cleaned = spacePattern.ReplaceAllString(cleaned, " ")
 		// Trim whitespace
@@ -609,6 +763,48 @@ func Normalize(rawText string) string {
 	return strings.Join(processedLines, "\n")
 }
 
+// defaultFillMaxRun is the longest run of one fill character Normalize keeps (NORMALIZE_FILL_MAX_RUN)
+const defaultFillMaxRun = 3
+
+// getFillMaxRun returns how many repeats of a fill character Normalize keeps: NORMALIZE_FILL_MAX_RUN, or
+// defaultFillMaxRun when unset or invalid; 0 leaves fill runs alone
+func getFillMaxRun() int {
+	if n, err := strconv.Atoi(os.Getenv("NORMALIZE_FILL_MAX_RUN")); err == nil && n >= 0 {
+		return n
+	}
+	return defaultFillMaxRun
+}
+
+// collapseFill turns tabs into spaces and shortens fill runs ("Name: ________", "Section 1 ........ 5")
+// in one line, for Normalize and the AI input
+func collapseFill(line string, maxRun int) string {
+	return collapseFillRuns(strings.ReplaceAll(line, "\t", " "), maxRun)
+}
+
+// collapseFillRuns shortens each run of a fill character ('_', '.' or '-') to at most maxRun repeats,
+// so blank form fields and dot leaders don't bloat the text; maxRun <= 0 returns s unchanged
+func collapseFillRuns(s string, maxRun int) string {
+	if maxRun <= 0 {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	var prev rune
+	run := 0
+	for _, r := range s {
+		if r == prev && (r == '_' || r == '.' || r == '-') {
+			run++
+			if run > maxRun {
+				continue
+			}
+		} else {
+			prev, run = r, 1
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 // ComputeContentHash computes SHA256 hash of text for change detection
 func ComputeContentHash(text string) string {
 	hash := sha256.Sum256([]byte(text))
@@ -617,8 +813,10 @@ func ComputeContentHash(text string) string {
 
 // AI processing configuration constants
 const (
-	defaultAIMaxChars = 8000
-	defaultAIMaxParas = 40
+	defaultAIMaxChars      = 8000
+	defaultAIMaxParas      = 40
+	defaultAIFallbackChars = 1500
+	defaultAIMinScore      = 0
 )
 
 // getAIMaxChars returns the maximum characters for AI input text (from env or default)
@@ -641,59 +839,252 @@ func getAIMaxParas() int {
 	return defaultAIMaxParas
 }
 
-// isTableRow detects if a line is table-ish (contains | and has a first field that looks like a clause title)
-func isTableRow(line string) bool {
-	if !strings.Contains(line, "|") {
-		return false
+// getAIMinScore returns the score a paragraph must exceed to be selected for AI input text (AI_DESC_MIN_SCORE,
+// default 0: only paragraphs with a positive score). A negative value also takes neutral paragraphs; a higher one
+// is stricter. Negative values are accepted, so anything that parses as an integer is used.
+func getAIMinScore() int {
+	if scoreStr := os.Getenv("AI_DESC_MIN_SCORE"); scoreStr != "" {
+		if score, err := strconv.Atoi(strings.TrimSpace(scoreStr)); err == nil {
+			return score
+		}
 	}
-	
-	// Extract first field (everything before the first pipe)
-	first := strings.TrimSpace(strings.SplitN(line, "|", 2)[0])
-	
-	// First field should be at least 8 characters to avoid junk
-	if len(first) < 8 {
-		return false
+	return defaultAIMinScore
+}
+
+// getAIFallbackChars returns how much leading text OptimizeForAI uses when no paragraph scores above the
+// selection threshold (AI_DESC_FALLBACK_CHARS, default 1500; 0 disables the fallback)
+func getAIFallbackChars() int {
+	if charsStr := os.Getenv("AI_DESC_FALLBACK_CHARS"); charsStr != "" {
+		if chars, err := strconv.Atoi(charsStr); err == nil && chars >= 0 {
+			return chars
+		}
 	}
-	
-	// First field should not be too long (likely not a clause title if > 100 chars)
-	if len(first) > 100 {
-		return false
+	return defaultAIFallbackChars
+}
+
+// leadingText returns about the first maxChars bytes of text, cut at a word boundary when there is one in the
+// second half and marked with "...", so a fallback excerpt doesn't end mid-word
+func leadingText(text string, maxChars int) string {
+	text = strings.TrimSpace(text)
+	if len(text) <= maxChars {
+		return text
+	}
+	if maxChars <= 3 {
+		return ""
+	}
+	cut := maxChars - 3
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	if space := strings.LastIndexAny(text[:cut], " \n\t"); space > cut/2 {
+		cut = space
+	}
+	return strings.TrimSpace(text[:cut]) + "..."
+}
+
+// getAIDedupSections reports whether OptimizeForAI collapses repeated headings and sections (AI_DEDUP_SECTIONS, default true)
+func getAIDedupSections() bool {
+	if enabled, err := strconv.ParseBool(os.Getenv("AI_DEDUP_SECTIONS")); err == nil {
+		return enabled
 	}
-	
 	return true
 }
 
-// parseClauseLine extracts clause titles and filters for relevance
-// Extracts title as everything before the first pipe, optionally handling date patterns
-func parseClauseLine(line string) (title string, isRelevant bool) {
+// sectionKey identifies a heading or section for repeat detection, ignoring case and whitespace
+func sectionKey(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// collapseRepeatedSections keeps only the first instance of repeated sections, comparing by sectionKey(key(...)):
+// a paragraph repeating an earlier one is dropped, and a repeated heading (headings[i], "" if none)
+// over new text is removed so only that text remains
+func collapseRepeatedSections(paragraphs, headings []string, key func(string) string) []string {
+	seen := make(map[string]bool, len(paragraphs))
+	seenHeadings := make(map[string]bool)
+	var kept []string
+	for i, para := range paragraphs {
+		paraKey := sectionKey(key(para))
+		if seen[paraKey] {
+			continue
+		}
+		seen[paraKey] = true
+		
+		if heading := headings[i]; heading != "" {
+			headingKey := sectionKey(key(heading))
+			body := strings.TrimSpace(strings.TrimPrefix(para, heading))
+			bodyKey := sectionKey(key(body))
+			if seenHeadings[headingKey] {
+				if body == "" || seen[bodyKey] {
+					continue
+				}
+				para = body
+			}
+			seenHeadings[headingKey] = true
+			seen[bodyKey] = true
+		}
+		kept = append(kept, para)
+	}
+	return kept
+}
+
+// Punctuation modes for AI_ASCII_PUNCTUATION
+const (
+	punctuationModeMatch = "match" // fold to ASCII for keyword matching only; AI input/excerpt keep the originals
+	punctuationModeAll   = "all"   // fold the whole AI input, so excerpts are ASCII too
+	punctuationModeOff   = "off"   // leave punctuation untouched
+)
+
+// getAIPunctuationMode returns how OptimizeForAI folds smart punctuation (from env or default "match")
+func getAIPunctuationMode() string {
+	switch mode := strings.ToLower(strings.TrimSpace(os.Getenv("AI_ASCII_PUNCTUATION"))); mode {
+	case punctuationModeAll, punctuationModeOff:
+		return mode
+	}
+	return punctuationModeMatch
+}
+
+// asciiPunctuationReplacer maps Unicode "smart" punctuation to ASCII equivalents
+var asciiPunctuationReplacer = strings.NewReplacer(
+	"\u2018", "'", "\u2019", "'", "\u201A", "'", "\u201B", "'", "\u2032", "'", // single quotes, prime
+	"\u201C", `"`, "\u201D", `"`, "\u201E", `"`, "\u201F", `"`, "\u2033", `"`, // double quotes, double prime
+	"\u2010", "-", "\u2011", "-", "\u2012", "-", "\u2013", "-", "\u2014", "-", "\u2015", "-", "\u2212", "-", // hyphens, dashes, minus
+	"\u00A0", " ", "\u2007", " ", "\u202F", " ", // non-breaking spaces
+	"\u2026", "...", // ellipsis
+)
+
+// NormalizePunctuation replaces curly quotes, dashes, non-breaking hyphens/spaces and ellipses with ASCII,
+// so keywords like "set-aside" match however the source typed them
+func NormalizePunctuation(text string) string {
+	return asciiPunctuationReplacer.Replace(text)
+}
+
+// Clause table row thresholds; the first field (before the first pipe) is the clause title
+const (
+	defaultClauseTitleMinLen = 8   // shorter first fields are usually junk
+	defaultClauseTitleMaxLen = 100 // longer first fields are usually prose, not a clause title
+)
+
+// clauseRowRules decides which pipe-delimited lines count as clause table rows
+type clauseRowRules struct {
+	MinTitleLen int
+	MaxTitleLen int
+	// AllowShortWithID accepts a title shorter than MinTitleLen when the row has a clause date or number
+	// (e.g. "Payment | 52.232-1 |")
+	AllowShortWithID bool
+	// RequireID rejects rows with no clause date or number anywhere, dropping pipe-delimited prose
+	RequireID bool
+}
+
+// getClauseRowRules reads the clause row thresholds: CLAUSE_TITLE_MIN_LEN, CLAUSE_TITLE_MAX_LEN,
+// CLAUSE_ROW_ALLOW_SHORT_WITH_ID and CLAUSE_ROW_REQUIRE_ID. The defaults match the original fixed rules.
+func getClauseRowRules() clauseRowRules {
+	rules := clauseRowRules{MinTitleLen: defaultClauseTitleMinLen, MaxTitleLen: defaultClauseTitleMaxLen}
+	if n, err := strconv.Atoi(os.Getenv("CLAUSE_TITLE_MIN_LEN")); err == nil && n >= 0 {
+		rules.MinTitleLen = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("CLAUSE_TITLE_MAX_LEN")); err == nil && n > 0 {
+		rules.MaxTitleLen = n
+	}
+	if enabled, err := strconv.ParseBool(os.Getenv("CLAUSE_ROW_ALLOW_SHORT_WITH_ID")); err == nil {
+		rules.AllowShortWithID = enabled
+	}
+	if enabled, err := strconv.ParseBool(os.Getenv("CLAUSE_ROW_REQUIRE_ID")); err == nil {
+		rules.RequireID = enabled
+	}
+	return rules
+}
+
+// clauseDatePattern matches a clause edition date such as "(JAN 2023)" or "DEC 2019"
+var clauseDatePattern = regexp.MustCompile(`(?i)\b(?:JAN|FEB|MAR|APR|MAY|JUN|JUL|AUG|SEP|SEPT|OCT|NOV|DEC)\s+(?:19|20)\d{2}\b`)
+
+// clauseNumberPattern matches a FAR (52.xxx-x) or DFARS (252.xxx-xxxx) clause number
+var clauseNumberPattern = regexp.MustCompile(`\b(?:52|252)\.\d{3}-\d+\b`)
+
+// hasClauseID reports whether text carries a clause date or number
+func hasClauseID(text string) bool {
+	text = NormalizePunctuation(text)
+	return clauseDatePattern.MatchString(text) || clauseNumberPattern.MatchString(text)
+}
+
+// clauseRowTitle returns the first field of a pipe-delimited line if rules accept the line as a clause row
+func clauseRowTitle(line string, rules clauseRowRules) (string, bool) {
 	if !strings.Contains(line, "|") {
 		return "", false
 	}
-	
+
 	// Extract first field (everything before the first pipe)
 	first := strings.TrimSpace(strings.SplitN(line, "|", 2)[0])
-	
-	// Avoid junk - first field should be at least 8 characters
-	if len(first) < 8 {
+	if len(first) > rules.MaxTitleLen || first == "" {
 		return "", false
 	}
-	
-	// Extract title - handle date patterns like "(JAN 2023)" / "(OCT 2020)" as part of title
-	// The date pattern is already part of the first field, so we just use it as-is
-	title = first
-	
-	titleLower := strings.ToLower(title)
-	
-	// Keywords for relevant clauses
-	relevantKeywords := []string{
-		"small business", "set-aside", "set aside", "cybersecurity", "cmmc",
-		"wawf", "wide area workflow", "priority rating", "payment", "certificate",
-		"compliance", "delivery", "submission", "quote", "validity", "irpod",
-		"do rated", "rated order", "certification", "certificate of compliance",
+	hasID := hasClauseID(line)
+	if rules.RequireID && !hasID {
+		return "", false
+	}
+	if len(first) < rules.MinTitleLen && !(rules.AllowShortWithID && hasID) {
+		return "", false
+	}
+	return first, true
+}
+
+// isTableRow detects if a line is table-ish (contains | and has a first field that looks like a clause title)
+func isTableRow(line string, rules clauseRowRules) bool {
+	_, ok := clauseRowTitle(line, rules)
+	return ok
+}
+
+// relevantClauseKeywords mark clause titles worth keeping; matched via clauseMatchKey,
+// so spacing, hyphenation and dash variants ("set aside", "set–aside", "setaside") all match
+var relevantClauseKeywords = []string{
+	"small business", "set-aside", "cybersecurity", "cmmc",
+	"wawf", "wide area workflow", "priority rating", "payment", "certificate",
+	"compliance", "delivery", "submission", "quote", "validity", "irpod",
+	"do rated", "rated order", "certification", "certificate of compliance",
+	"safeguarding covered defense information", "nist sp 800-171", "limitations on subcontracting",
+	"hubzone", "service-disabled veteran", "women-owned", "buy american", "berry amendment",
+}
+
+// relevantClausePattern matches common bid-relevant FAR/DFARS clause numbers in a clause title:
+// small business programs (52.219-x), safeguarding/CMMC (52.204-21, 252.204-7012/7019/7020/7021),
+// WAWF (252.232-7003/7006), priority ratings (52.211-14/15), inspection (52.246-x), Buy American (52.225-1..4)
+var relevantClausePattern = regexp.MustCompile(`\b(?:52\.219-\d+|52\.204-21|252\.204-70(?:12|19|20|21)|252\.232-700[36]|52\.211-1[45]|52\.246-\d+|52\.225-[1-4])\b`)
+
+// relevantClauseKeys is relevantClauseKeywords run through clauseMatchKey
+var relevantClauseKeys = func() []string {
+	keys := make([]string, 0, len(relevantClauseKeywords))
+	for _, keyword := range relevantClauseKeywords {
+		keys = append(keys, clauseMatchKey(keyword))
+	}
+	return deduplicateStrings(keys)
+}()
+
+// clauseMatchKey lowercases s, folds smart punctuation, and drops spaces, hyphens, underscores and slashes
+// so keyword matching ignores how words are joined
+func clauseMatchKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '_', '/':
+			return -1
+		}
+		return r
+	}, strings.ToLower(NormalizePunctuation(s)))
+}
+
+// parseClauseLine extracts clause titles and filters for relevance
+// The title is everything before the first pipe; date patterns like "(JAN 2023)" stay part of it
+func parseClauseLine(line string, rules clauseRowRules) (title string, isRelevant bool) {
+	title, ok := clauseRowTitle(line, rules)
+	if !ok {
+		return "", false
+	}
+
+	if relevantClausePattern.MatchString(NormalizePunctuation(title)) {
+		return title, true
 	}
 	
-	for _, keyword := range relevantKeywords {
-		if strings.Contains(titleLower, keyword) {
+	titleKey := clauseMatchKey(title)
+	for _, keyword := range relevantClauseKeys {
+		if strings.Contains(titleKey, keyword) {
 			return title, true
 		}
 	}
@@ -775,6 +1166,179 @@ func extractKeyFacts(text string) (facts []string) {
 	return deduplicateStrings(facts)
 }
 
+// Delivery and period-of-performance patterns, matched case-insensitively
+var (
+	// "90 days ARO", "thirty (30) calendar days after receipt of order", "10 business days after date of award"
+	deliveryAROPattern = regexp.MustCompile(`(?i)\(?(\d{1,4})\)?\s+(?:(calendar|business|working)\s+)?days?\s+(?:after\s+(?:the\s+)?(?:receipt|date)\s+of\s+(?:the\s+)?(?:order|award|contract)|a\.?r\.?o\b)`)
+	// "Period of Performance: 12 months", "PoP shall be twelve (12) months", "period of performance is 365 days"
+	popDurationPattern = regexp.MustCompile(`(?i)(?:period\s+of\s+performance|\bpop\b)(?:\s*\(pop\))?\s*(?::|is|shall\s+be|will\s+be)?\s*(?:[a-z]+\s+)?\(?(\d{1,3})\)?\s*(day|week|month|year)s?\b`)
+	// "Period of Performance: 10/01/2025 - 09/30/2026", "PoP: 2025-10-01 through 2026-09-30"
+	popRangePattern = regexp.MustCompile(`(?i)(?:period\s+of\s+performance|\bpop\b)(?:\s*\(pop\))?\s*(?::|is|shall\s+be|will\s+be)?\s*(?:from\s+)?(\d{1,2}/\d{1,2}/\d{4}|\d{4}-\d{2}-\d{2})\s*(?:-|to|through|thru)\s*(\d{1,2}/\d{1,2}/\d{4}|\d{4}-\d{2}-\d{2})`)
+)
+
+// extractDeliveryTerms finds the delivery lead time after receipt of order (days, and "calendar" or
+// "business") and the period of performance (a duration like "12 months" or a date range)
+func extractDeliveryTerms(text string) (deliveryDays *int, dayType *string, periodOfPerformance *string) {
+	if matches := deliveryAROPattern.FindStringSubmatch(text); len(matches) > 2 {
+		if days, err := strconv.Atoi(matches[1]); err == nil {
+			deliveryDays = &days
+			kind := "calendar" // Unqualified days are calendar days (FAR 2.101)
+			if unit := strings.ToLower(matches[2]); unit == "business" || unit == "working" {
+				kind = "business"
+			}
+			dayType = &kind
+		}
+	}
+
+	if matches := popRangePattern.FindStringSubmatch(text); len(matches) > 2 {
+		pop := matches[1] + " to " + matches[2]
+		periodOfPerformance = &pop
+	} else if matches := popDurationPattern.FindStringSubmatch(text); len(matches) > 2 {
+		if n, err := strconv.Atoi(matches[1]); err == nil && n > 0 {
+			unit := strings.ToLower(matches[2])
+			if n != 1 {
+				unit += "s"
+			}
+			pop := fmt.Sprintf("%d %s", n, unit)
+			periodOfPerformance = &pop
+		}
+	}
+	return deliveryDays, dayType, periodOfPerformance
+}
+
+// Questions-deadline patterns, matched case-insensitively
+const (
+	// Month names and abbreviations; "May" must be capitalized so the verb "may" isn't read as a month
+	monthName = `(?:jan(?:uary)?|feb(?:ruary)?|mar(?:ch)?|apr(?:il)?|(?-i:May|MAY)|june?|july?|aug(?:ust)?|sep(?:t(?:ember)?)?|oct(?:ober)?|nov(?:ember)?|dec(?:ember)?)\b`
+	// "Jan 5", "January 5, 2026", "Sept. 30 2026", "5 January 2026", "01/05/2026", "1/5/26", "2026-01-05",
+	// optionally followed by a time such as "at 2:00 PM EST"
+	questionsDueDate = `(?:` + monthName + `\.?\s+\d{1,2}(?:st|nd|rd|th)?(?:,?\s+\d{4})?` +
+		`|\d{1,2}\s+` + monthName + `\.?,?\s+\d{4}` +
+		`|\d{1,2}/\d{1,2}/(?:\d{4}|\d{2})\b|\d{4}-\d{2}-\d{2})` +
+		`(?:,?\s*(?:at|by|@)?\s*\d{1,2}(?::\d{2})?\s*[ap]\.?m\.?(?:\s*\(?(?:[ecmp][sd]?t|local(?:\s+time)?)\b\)?)?)?`
+)
+
+var (
+	// "Questions due by Jan 5", "All questions must be submitted no later than 01/05/2026",
+	// "Deadline for questions: January 5, 2026", "Last day to submit inquiries is 2026-01-05"
+	questionsDuePattern = regexp.MustCompile(`(?i)\b(?:questions?|inquiries|inquiry)\b([^;\n]{0,80}?)(` + questionsDueDate + `)`)
+	// A date after one of these belongs to the response deadline or the answers, not the questions deadline
+	questionsDueStopPattern = regexp.MustCompile(`(?i)\b(?:quotes?|quotations?|offers?|proposals?|responses?|bids?|closes?|closing|answers?|answered|posted)\b`)
+	// A sentence break between "questions" and the date, e.g. "Questions to the CO. Award by Jan 5"
+	sentenceBreakPattern = regexp.MustCompile(`[.!?]\s+[A-Z][a-z]`)
+)
+
+// extractQuestionsDue finds the deadline for submitting questions, as written (e.g. "Jan 5" or
+// "01/05/2026 at 2:00 PM EST"). The date must follow a mention of questions or inquiries in the same
+// clause without a response-deadline word in between, so "Questions due Jan 5; quotes due Jan 15" gives
+// "Jan 5" and "Questions about the quotes due Jan 15" gives nothing.
+func extractQuestionsDue(text string) *string {
+	for start := 0; start < len(text); {
+		m := questionsDuePattern.FindStringSubmatchIndex(text[start:])
+		if m == nil {
+			return nil
+		}
+		gap := text[start+m[2] : start+m[3]]
+		if !questionsDueStopPattern.MatchString(gap) && !sentenceBreakPattern.MatchString(gap) {
+			due := spacePattern.ReplaceAllString(strings.TrimSpace(text[start+m[4]:start+m[5]]), " ")
+			return &due
+		}
+		// Retry from just past this mention, so a later "questions" in the skipped span still counts
+		start += m[2]
+	}
+	return nil
+}
+
+// amendmentPreambleChars bounds where an amendment is looked for: amendments say so up front ("Amendment 0002
+// is issued to..."), while later text talks about amendments in general ("acknowledge all amendments")
+const amendmentPreambleChars = 500
+
+var (
+	// "Amendment 0002", "AMENDMENT NO. 3", "Amendment #02", "Modification P00001", "Modification Number: A00002"
+	amendmentNumberPattern = regexp.MustCompile(`(?i)\b(?:amendment|modification)\s*(?:(?:no|nbr|number)\b\.?|#)?\s*:?\s*([A-Z]?\d{1,5}(?:[/.-]\d+)*)\b`)
+	// Unnumbered amendment preambles: "This amendment...", "The purpose of this modification is...",
+	// "The solicitation is hereby amended", and the SF 30 form title
+	amendmentStatementPattern = regexp.MustCompile(`(?i)\bthis\s+(?:amendment|modification)\b|\bhereby\s+amended\b|\bamendment\s+of\s+solicitation\b|\b(?:sf|standard\s+form)[\s-]?30\b`)
+)
+
+// extractAmendment reports whether the description opens as an amendment or modification and, when the
+// preamble gives one, its number as written (upper-cased, e.g. "0002" or "P00001"). Both are nil for an
+// ordinary description. A "number" with a date or clause shape ("Amendment 12/05/2025", "FAR 52.212-1
+// modification") is not taken as an amendment number.
+func extractAmendment(text string) (isAmendment *bool, number *string) {
+	preamble := text
+	if len(preamble) > amendmentPreambleChars {
+		preamble = preamble[:amendmentPreambleChars]
+	}
+	for _, m := range amendmentNumberPattern.FindAllStringSubmatch(preamble, -1) {
+		if strings.ContainsAny(m[1], "/.-") {
+			continue
+		}
+		amended := true
+		n := strings.ToUpper(m[1])
+		return &amended, &n
+	}
+	if amendmentStatementPattern.MatchString(preamble) {
+		amended := true
+		return &amended, nil
+	}
+	return nil, nil
+}
+
+// clauseRefPattern matches a DFARS clause number (252.xxx-xxxx) or a FAR reference (xx.xxx, optionally -x);
+// the DFARS branch comes first so "252.204-7012" isn't read as FAR "52.204-7012"
+var clauseRefPattern = regexp.MustCompile(`252\.\d{3}-\d{4}|(\d{2})\.\d{3}(?:-\d+)?`)
+
+// farPrefixPattern matches a "FAR" label immediately before a reference, e.g. "FAR 19.502"
+var farPrefixPattern = regexp.MustCompile(`(?i)\bFAR\s*$`)
+
+// farMaxPart is the highest FAR part number (Part 53, Forms)
+const farMaxPart = 53
+
+// extractClauseNumbers finds FAR and DFARS clause numbers such as "52.212-1" and "252.204-7012",
+// returned sorted and de-duplicated. Lookalikes are skipped: a number glued to other digits, letters, "$"
+// or dots ("v10.123.4", "$15.250", "1.10.125"), a FAR part above 53, and a bare decimal with no dash
+// ("10.125 inches") unless it is labeled "FAR".
+func extractClauseNumbers(text string) []string {
+	text = NormalizePunctuation(text) // "52.212\u20131" is still a clause number
+	var numbers []string
+	for _, loc := range clauseRefPattern.FindAllStringSubmatchIndex(text, -1) {
+		start, end := loc[0], loc[1]
+		if start > 0 {
+			if prev := text[start-1]; isClauseRefChar(prev) || prev == '.' || prev == '$' {
+				continue
+			}
+		}
+		if end < len(text) {
+			next := text[end]
+			if isClauseRefChar(next) || (next == '.' && end+1 < len(text) && text[end+1] >= '0' && text[end+1] <= '9') {
+				continue
+			}
+		}
+		number := text[start:end]
+		if loc[2] >= 0 { // FAR branch
+			part, _ := strconv.Atoi(text[loc[2]:loc[3]])
+			if part < 1 || part > farMaxPart {
+				continue
+			}
+			if !strings.Contains(number, "-") && !farPrefixPattern.MatchString(text[:start]) {
+				continue
+			}
+		}
+		numbers = append(numbers, number)
+	}
+	if len(numbers) == 0 {
+		return nil
+	}
+	sort.Strings(numbers)
+	return deduplicateStrings(numbers)
+}
+
+// isClauseRefChar reports whether c would make an adjacent match part of a longer token
+func isClauseRefChar(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
 // deduplicateStrings removes duplicates while preserving order
 func deduplicateStrings(slice []string) []string {
 	seen := make(map[string]bool)
@@ -856,12 +1420,54 @@ func isBoilerplateParagraph(para string) bool {
 	return false
 }
 
+// scoredParagraph is a candidate paragraph for the AI excerpt with its keyword score
+type scoredParagraph struct {
+	text  string
+	score int
+}
+
+// selectParagraphs takes paragraphs in order (best first) until the first score at or below minScore or
+// whichever cap is hit first: maxParas paragraphs, or maxChars characters including "\n\n" separators.
+// Both caps are hard limits; neither is relaxed because the other has room left.
+func selectParagraphs(scored []scoredParagraph, maxChars, maxParas, minScore int) []string {
+	var selected []string
+	totalChars := 0
+	for _, sp := range scored {
+		if len(selected) >= maxParas {
+			break
+		}
+		if sp.score <= minScore {
+			break // Stop at the threshold (zero by default: negative and neutral paragraphs are left out)
+		}
+		paraLen := len(sp.text)
+		if len(selected) > 0 {
+			paraLen += 2 // \n\n separator
+		}
+		if totalChars+paraLen > maxChars {
+			break
+		}
+		selected = append(selected, sp.text)
+		totalChars += paraLen
+	}
+	return selected
+}
+
 // OptimizeForAI processes raw normalized text to create AI-ready input with structured metadata
 func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string, aiMeta models.AiMeta, pocEmailPrimary *string, err error) {
 	if rawPostParse == "" {
 		return "", "", models.AiMeta{}, nil, nil
 	}
 	
+	// Keyword matching and fact extraction run on matchText(...); output keeps the text as given unless mode is "all"
+	matchText := func(s string) string { return s }
+	switch getAIPunctuationMode() {
+	case punctuationModeAll:
+		rawPostParse = NormalizePunctuation(rawPostParse)
+	case punctuationModeMatch:
+		matchText = NormalizePunctuation
+	}
+	matchPostParse := matchText(rawPostParse)
+	
 	// Extract structured data from raw_post_parse (before Normalize destroys table structure)
 	lines := strings.Split(rawPostParse, "\n")
 	var clauseTitles []string
@@ -870,8 +1476,10 @@ func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string,
 	var allURLs []string
 	
 	// Parse clause table lines
+	clauseRules := getClauseRowRules()
 	for _, line := range lines {
-		if title, isRelevant := parseClauseLine(line); isRelevant {
+		if _, isRelevant := parseClauseLine(matchText(line), clauseRules); isRelevant {
+			title, _ := parseClauseLine(line, clauseRules)
 			clauseTitles = append(clauseTitles, title)
 		}
 	}
@@ -885,12 +1493,32 @@ func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string,
 	}
 	
 	// Extract key facts
-	keyFacts := extractKeyFacts(rawPostParse)
+	keyFacts := extractKeyFacts(matchPostParse)
+	
+	// Delivery schedule and period of performance
+	deliveryDays, deliveryDayType, periodOfPerformance := extractDeliveryTerms(matchPostParse)
+	if deliveryDays != nil {
+		keyFacts = append(keyFacts, fmt.Sprintf("Delivery: %d %s days ARO", *deliveryDays, *deliveryDayType))
+	}
+	if periodOfPerformance != nil {
+		keyFacts = append(keyFacts, "Period of performance: "+*periodOfPerformance)
+	}
+	isAmendment, amendmentNumber := extractAmendment(matchPostParse)
+	if amendmentNumber != nil {
+		keyFacts = append(keyFacts, "Amendment: "+*amendmentNumber)
+	} else if isAmendment != nil {
+		keyFacts = append(keyFacts, "Amendment: yes (number not stated)")
+	}
+	questionsDue := extractQuestionsDue(matchPostParse)
+	if questionsDue != nil {
+		keyFacts = append(keyFacts, "Questions due: "+*questionsDue)
+	}
 	
 	// Build boilerplate-stripped text using state machine
 	// Also extract useful signals from boilerplate section before dropping
 	var cleanedLines []string
 	var boilerplateSection []string // Collect boilerplate lines for signal extraction
+	fillMaxRun := getFillMaxRun()
 	inBoilerplate := false
 	boilerplateEnterPattern := regexp.MustCompile(`(?i)information regarding abbreviations.*dd form 1423`)
 	boilerplateExitPatterns := []*regexp.Regexp{
@@ -901,7 +1529,7 @@ func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string,
 	
 	for _, line := range lines {
 		// Check for boilerplate entry
-		if boilerplateEnterPattern.MatchString(line) {
+		if boilerplateEnterPattern.MatchString(matchText(line)) {
 			inBoilerplate = true
 			boilerplateSection = []string{} // Reset boilerplate section
 			continue
@@ -911,14 +1539,14 @@ func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string,
 		if inBoilerplate {
 			shouldExit := false
 			for _, exitPattern := range boilerplateExitPatterns {
-				if exitPattern.MatchString(line) {
+				if exitPattern.MatchString(matchText(line)) {
 					shouldExit = true
 					break
 				}
 			}
 			if shouldExit {
 				// Extract useful signals from boilerplate section before exiting
-				boilerplateText := strings.Join(boilerplateSection, "\n")
+				boilerplateText := matchText(strings.Join(boilerplateSection, "\n"))
 				boilerplateTextLower := strings.ToLower(boilerplateText)
 				
 				// Extract NOFORN / Need-to-know / foreign nationals restrictions
@@ -943,15 +1571,17 @@ func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string,
 			continue // Skip ALL lines while in boilerplate mode
 		}
 		
-		// Not in boilerplate mode, keep the line
-		cleanedLines = append(cleanedLines, line)
+		// Not in boilerplate mode, keep the line (without its fill, which only costs AI input)
+		cleanedLines = append(cleanedLines, collapseFill(line, fillMaxRun))
 	}
 	
 	// Build paragraphs from lines (handles single-newline format)
 	// Accumulate lines until a blank line or heading marker
 	headingPattern := regexp.MustCompile(`^\d+\.\s+`) // Lines starting with "1. ", "2. ", etc.
 	var paragraphs []string
+	var paragraphHeadings []string // heading line that opened each paragraph, "" if none
 	var currentPara []string
+	currentHeading := ""
 	
 	for _, line := range cleanedLines {
 		lineTrimmed := strings.TrimSpace(line)
@@ -982,12 +1612,15 @@ func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string,
 				paraText := strings.Join(currentPara, "\n")
 				if strings.TrimSpace(paraText) != "" {
 					paragraphs = append(paragraphs, paraText)
+					paragraphHeadings = append(paragraphHeadings, currentHeading)
 				}
 				currentPara = []string{}
 			}
+			currentHeading = ""
 			// If it's a heading, start a new paragraph with it
 			if isHeading && lineTrimmed != "" {
 				currentPara = append(currentPara, lineTrimmed)
+				currentHeading = lineTrimmed
 			}
 		} else {
 			// Add line to current paragraph
@@ -1000,23 +1633,25 @@ func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string,
 		paraText := strings.Join(currentPara, "\n")
 		if strings.TrimSpace(paraText) != "" {
 			paragraphs = append(paragraphs, paraText)
+			paragraphHeadings = append(paragraphHeadings, currentHeading)
 		}
 	}
 	
-	// Score paragraphs
-	type scoredPara struct {
-		text  string
-		score int
+	// Long DoD descriptions repeat clause headers (e.g. "INSPECTION AND ACCEPTANCE") many times; keep only the first
+	if getAIDedupSections() {
+		paragraphs = collapseRepeatedSections(paragraphs, paragraphHeadings, matchText)
 	}
-	var scoredParagraphs []scoredPara
+	
+	// Score paragraphs
+	var scoredParagraphs []scoredParagraph
 	
 	for _, para := range paragraphs {
 		para = strings.TrimSpace(para)
 		if para == "" {
 			continue
 		}
-		score := scoreParagraph(para)
-		scoredParagraphs = append(scoredParagraphs, scoredPara{text: para, score: score})
+		score := scoreParagraph(matchText(para))
+		scoredParagraphs = append(scoredParagraphs, scoredParagraph{text: para, score: score})
 	}
 	
 	// Sort by score (descending) and take top paragraphs
@@ -1033,27 +1668,27 @@ func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string,
 	maxChars := getAIMaxChars()
 	maxParas := getAIMaxParas()
 	
-	var selectedParagraphs []string
-	totalChars := 0
 	headerText := "KEY FACTS:\n" + strings.Join(keyFacts, "\n") + "\n\nRELEVANT EXCERPT:\n"
-	headerChars := len(headerText)
 	
 	// Reserve space for header
-	availableChars := maxChars - headerChars
-	
-	for i, sp := range scoredParagraphs {
-		if i >= maxParas {
-			break
+	selectedParagraphs := selectParagraphs(scoredParagraphs, maxChars-len(headerText), maxParas, getAIMinScore())
+	
+	// Short or unusual descriptions can score nothing above the threshold, which would leave only the header;
+	// fall back to the leading text (boilerplate stripped when anything else remains) so summarization has a body
+	if len(selectedParagraphs) == 0 {
+		fallbackChars := getAIFallbackChars()
+		if budget := maxChars - len(headerText); fallbackChars > budget {
+			fallbackChars = budget
 		}
-		if sp.score <= 0 {
-			break // Stop at negative or zero scores
+		body := strings.Join(cleanedLines, "\n")
+		if strings.TrimSpace(body) == "" {
+			body = rawPostParse
 		}
-		paraLen := len(sp.text)
-		if totalChars+paraLen > availableChars {
-			break
+		if fallbackChars > 0 {
+			if lead := leadingText(body, fallbackChars); lead != "" {
+				selectedParagraphs = []string{lead}
+			}
 		}
-		selectedParagraphs = append(selectedParagraphs, sp.text)
-		totalChars += paraLen + 2 // +2 for \n\n
 	}
 	
 	// Build final AI input text
@@ -1085,7 +1720,7 @@ func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string,
 	// Extract actual certificate requirements from text
 	var certsRequired []string
 	certPattern := regexp.MustCompile(`(?i)(?:certificate|certification|cert)\s+(?:of\s+)?(?:compliance|conformance|origin|insurance|quality)`)
-	certMatches := certPattern.FindAllString(rawPostParse, -1)
+	certMatches := certPattern.FindAllString(matchPostParse, -1)
 	for _, match := range certMatches {
 		// Normalize and deduplicate
 		matchLower := strings.ToLower(strings.TrimSpace(match))
@@ -1113,37 +1748,48 @@ func OptimizeForAI(rawPostParse string) (aiInputText string, excerptText string,
 	
 	// Detect set-aside
 	setAsidePattern := regexp.MustCompile(`(?i)(?:set[-\s]?aside|small\s+business)\s*:?\s*([^\n]+)`)
-	if matches := setAsidePattern.FindStringSubmatch(rawPostParse); len(matches) > 1 {
+	if matches := setAsidePattern.FindStringSubmatch(matchPostParse); len(matches) > 1 {
 		setAside := strings.TrimSpace(matches[1])
 		aiMeta.SetAsideDetected = &setAside
 	}
 	
 	// Detect WAWF requirement
-	if strings.Contains(strings.ToLower(rawPostParse), "wawf") || strings.Contains(strings.ToLower(rawPostParse), "wide area workflow") {
+	if strings.Contains(strings.ToLower(matchPostParse), "wawf") || strings.Contains(strings.ToLower(matchPostParse), "wide area workflow") {
 		wawfRequired := true
 		aiMeta.WAWFRequired = &wawfRequired
 	}
 	
 	// Detect DO-rated
-	if strings.Contains(strings.ToLower(rawPostParse), "do rated") || strings.Contains(strings.ToLower(rawPostParse), "rated order") {
+	if strings.Contains(strings.ToLower(matchPostParse), "do rated") || strings.Contains(strings.ToLower(matchPostParse), "rated order") {
 		doRated := true
 		aiMeta.DORated = &doRated
 	}
 	
 	// Detect IRPOD requirement
-	if strings.Contains(strings.ToLower(rawPostParse), "irpod") {
+	if strings.Contains(strings.ToLower(matchPostParse), "irpod") {
 		irpodRequired := true
 		aiMeta.RequiresIRPODReview = &irpodRequired
 	}
 	
+	aiMeta.DeliveryDaysARO = deliveryDays
+	aiMeta.DeliveryDayType = deliveryDayType
+	aiMeta.PeriodOfPerformance = periodOfPerformance
+	aiMeta.QuestionsDueDetected = questionsDue
+	aiMeta.IsAmendment = isAmendment
+	aiMeta.AmendmentNumber = amendmentNumber
+	aiMeta.ClauseNumbers = extractClauseNumbers(matchPostParse)
+	
 	// Extract quote validity days - handle patterns like "pricing for this quotation is valid for 60 days"
 	quoteValPattern := regexp.MustCompile(`(?i)(?:pricing\s+for\s+this\s+)?(?:quote|quotation|offer)\s+(?:is\s+)?(?:valid|validity|good)\s+(?:for\s+)?(\d+)\s*days?`)
-	if matches := quoteValPattern.FindStringSubmatch(rawPostParse); len(matches) > 1 {
+	if matches := quoteValPattern.FindStringSubmatch(matchPostParse); len(matches) > 1 {
 		if days, err := strconv.Atoi(matches[1]); err == nil {
 			aiMeta.QuoteValidityDays = &days
 		}
 	}
 	
+	// Stable slice order so identical text always stores identical ai_meta
+	aiMeta.Canonicalize()
+	
 	return aiInputText, excerptText, aiMeta, pocEmailPrimary, nil
 }
 
@@ -2,8 +2,14 @@ package services
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+
+	"govcon/api/internal/models"
 )
 
 func TestExtractDescriptionJSONLike_ValidJSON(t *testing.T) {
@@ -169,8 +175,8 @@ func TestExtractDescriptionJSONLike_WhitespaceAroundKey(t *testing.T) {
 
 func TestExtractDescriptionJSONLike_MaxLengthGuardrail(t *testing.T) {
 	// Test that max length guardrail works
-	// Create a string that exceeds maxExtractedLength
-	largeValue := make([]byte, maxExtractedLength+1)
+	// Create a string that exceeds the default max extracted length
+	largeValue := make([]byte, defaultMaxExtractedLength+1)
 	for i := range largeValue {
 		largeValue[i] = 'A'
 	}
@@ -184,7 +190,7 @@ func TestExtractDescriptionJSONLike_MaxLengthGuardrail(t *testing.T) {
 
 func TestExtractDescriptionJSONLike_MaxScanLengthGuardrail(t *testing.T) {
 	// Test that max scan length guardrail works
-	largeInput := make([]byte, maxExtractScanLength+1)
+	largeInput := make([]byte, defaultMaxExtractScanLength+1)
 	for i := range largeInput {
 		largeInput[i] = 'A'
 	}
@@ -196,6 +202,93 @@ func TestExtractDescriptionJSONLike_MaxScanLengthGuardrail(t *testing.T) {
 	}
 }
 
+func TestExtractDescriptionJSONLike_RaisedExtractedLimit(t *testing.T) {
+	largeValue := strings.Repeat("A", defaultMaxExtractedLength+1)
+	input := `{"description":"` + largeValue + `"}`
+
+	t.Setenv("DESC_MAX_EXTRACTED_BYTES", strconv.Itoa(defaultMaxExtractedLength+1024))
+	desc, ok := ExtractDescriptionJSONLike(input)
+	if !ok || len(desc) != len(largeValue) {
+		t.Errorf("Expected the raised limit to let %d bytes through, got ok=%v len=%d", len(largeValue), ok, len(desc))
+	}
+}
+
+func TestGetDescriptionLimit(t *testing.T) {
+	for _, value := range []string{"", "abc", "0", "-5"} {
+		t.Setenv("DESC_MAX_UNWRAP_DEPTH", value)
+		if got := getMaxUnwrapRecursion(); got != defaultMaxUnwrapRecursion {
+			t.Errorf("DESC_MAX_UNWRAP_DEPTH=%q: Expected default %d, got %d", value, defaultMaxUnwrapRecursion, got)
+		}
+	}
+	t.Setenv("DESC_MAX_UNWRAP_DEPTH", "32")
+	if got := getMaxUnwrapRecursion(); got != 32 {
+		t.Errorf("Expected 32, got %d", got)
+	}
+}
+
+func TestFetchDescription_BodyLimitConfigurable(t *testing.T) {
+	body := `{"description":"` + strings.Repeat("A", 2048) + `"}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	t.Setenv("DESC_MAX_BODY_BYTES", "1024")
+	if _, _, _, _, err := FetchDescription(srv.URL, "key"); err == nil || !strings.Contains(err.Error(), "exceeds maximum size of 1024 bytes") {
+		t.Errorf("Expected the body to exceed a 1024-byte limit, got %v", err)
+	}
+
+	t.Setenv("DESC_MAX_BODY_BYTES", "4096")
+	rawText, _, _, _, err := FetchDescription(srv.URL, "key")
+	if err != nil {
+		t.Fatalf("Expected the raised limit to accept the body, got %v", err)
+	}
+	if len(rawText) != 2048 {
+		t.Errorf("Expected the full 2048-byte description, got %d bytes", len(rawText))
+	}
+}
+
+func TestFetchDescription_OversizeBodyFlagged(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"description":"`+strings.Repeat("A", 2048)+`"}`)
+	}))
+	defer srv.Close()
+	t.Setenv("DESC_MAX_BODY_BYTES", "1024")
+
+	rawText, _, _, _, err := FetchDescription(srv.URL, "key")
+	if !IsDescriptionTooLarge(err) {
+		t.Fatalf("Expected a too-large error, got %v", err)
+	}
+	if rawText != "" {
+		t.Errorf("Expected no text for an oversize body, got %d bytes", len(rawText))
+	}
+	if ClassifyFetchResult(http.StatusOK, rawText, err) != models.FetchStatusError {
+		t.Error("Expected an oversize body to be stored as a fetch error")
+	}
+}
+
+func TestFetchDescription_OversizeExtractedDescriptionFlagged(t *testing.T) {
+	// Malformed JSON (raw newline) goes through the lenient extractor, whose length limit rejects it
+	body := "{\"description\":\"" + strings.Repeat("A", 2048) + "\nmore\"}"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+	t.Setenv("DESC_MAX_EXTRACTED_BYTES", "1024")
+
+	rawText, rawJSON, _, _, err := FetchDescription(srv.URL, "key")
+	if !IsDescriptionTooLarge(err) || !strings.Contains(err.Error(), "DESC_MAX_EXTRACTED_BYTES") {
+		t.Fatalf("Expected a too-large error naming DESC_MAX_EXTRACTED_BYTES, got %v", err)
+	}
+	if rawText != "" {
+		t.Errorf("Expected the payload not to be stored as the description, got %d bytes", len(rawText))
+	}
+	if rawJSON != body {
+		t.Error("Expected the raw response to be returned")
+	}
+}
+
 func TestUnwrapDescriptionText_ValidJSON(t *testing.T) {
 	// Control case: valid JSON
 	input := `{"description":"ITEM UNIQUE IDENTIFICATION"}`
@@ -207,6 +300,16 @@ func TestUnwrapDescriptionText_ValidJSON(t *testing.T) {
 	}
 }
 
+func TestUnwrapDescriptionText_ObjectKeepsLargeInteger(t *testing.T) {
+	input := `{"description":{"contractId":12345678901234567890}}`
+	expected := `{"contractId":12345678901234567890}`
+
+	result := UnwrapDescriptionText(input)
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
 func TestUnwrapDescriptionText_MalformedJSON(t *testing.T) {
 	// Malformed JSON with raw newlines
 	input := `{"description":"ITEM UNIQUE IDENTIFICATION
@@ -238,11 +341,6 @@ func TestUnwrapDescriptionText_DoubleWrappedString(t *testing.T) {
 }
 
 func TestUnwrapDescriptionText_TripleWrapped(t *testing.T) {
-	// Triple-wrapped (should respect recursion limit of 2)
-	// With maxUnwrapRecursion = 2, we can unwrap twice:
-	// 1. Triple-wrapped -> double-wrapped (depth 0->1)
-	// 2. Double-wrapped -> inner JSON (depth 1->2)
-	// 3. Would unwrap inner JSON, but hit recursion limit, so returns inner JSON
 	inner := `{"description":"ITEM UNIQUE IDENTIFICATION"}`
 	// Double-wrap
 	doubleWrappedBytes, err := json.Marshal(inner)
@@ -255,20 +353,52 @@ func TestUnwrapDescriptionText_TripleWrapped(t *testing.T) {
 		t.Fatalf("Failed to marshal double-wrapped JSON: %v", err)
 	}
 	tripleWrapped := string(tripleWrappedBytes)
-	
+	expected := "ITEM UNIQUE IDENTIFICATION"
+
 	result := UnwrapDescriptionText(tripleWrapped)
-	// With recursion limit of 2, we can unwrap twice, so we should get the inner JSON
-	// which is still wrapped. The fallback extractor should handle it though.
-	// Actually, let's check: after 2 unwraps, we should have the inner JSON string.
-	// But the extractor should still work on it. Let me verify the actual behavior.
-	// The result should either be the final description OR the inner JSON (if limit hit).
-	// Since we have a fallback extractor, it should still extract even if JSON is malformed.
-	if result == tripleWrapped {
-		t.Error("Expected some unwrapping to occur")
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
 	}
-	// The result should contain "ITEM UNIQUE IDENTIFICATION" either directly or in JSON
-	if !strings.Contains(result, "ITEM UNIQUE IDENTIFICATION") {
-		t.Errorf("Expected result to contain 'ITEM UNIQUE IDENTIFICATION', got %q", result)
+}
+
+func TestUnwrapDescriptionText_QuadrupleWrapped(t *testing.T) {
+	// Alternate string encoding and object wrapping, four layers around the text
+	wrapped := "ITEM UNIQUE IDENTIFICATION"
+	for i := 0; i < 4; i++ {
+		obj, err := json.Marshal(map[string]string{"description": wrapped})
+		if err != nil {
+			t.Fatalf("Failed to wrap: %v", err)
+		}
+		encoded, err := json.Marshal(string(obj))
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		wrapped = string(encoded)
+	}
+	expected := "ITEM UNIQUE IDENTIFICATION"
+
+	result := UnwrapDescriptionText(wrapped)
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestUnwrapDescriptionText_PathologicalInputsTerminate(t *testing.T) {
+	// Nested far past the depth limit: stops partway, still carrying the text
+	deep := strings.Repeat(`{"description":`, 100) + `"value"` + strings.Repeat("}", 100)
+	if result := UnwrapDescriptionText(deep); result == deep || !strings.Contains(result, "value") {
+		t.Errorf("Expected partial unwrapping that keeps the text, got %d chars", len(result))
+	}
+
+	// Wrappers that open and never close, each looking like the next layer
+	selfReferential := strings.Repeat(`{"description":"`, 50)
+	if result := UnwrapDescriptionText(selfReferential); len(result) > len(selfReferential) {
+		t.Errorf("Expected unwrapping to only remove wrappers, got %q", result)
+	}
+
+	// A quote that decodes to nothing new
+	if result := UnwrapDescriptionText(`"\"`); result == "" {
+		t.Errorf("Expected malformed quoted input returned, got %q", result)
 	}
 }
 
@@ -464,3 +594,852 @@ func TestUnwrapDescriptionText_JSONDescriptionValueIsWrappedJSON(t *testing.T) {
 	}
 }
 
+func TestUnwrapDescriptionText_DescriptionArrayJoined(t *testing.T) {
+	input := `{"description":["Part 1","Part 2"]}`
+	expected := "Part 1\nPart 2"
+
+	result := UnwrapDescriptionText(input)
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestUnwrapDescriptionText_DescriptionArraySkipsBlankAndUnwrapsParts(t *testing.T) {
+	input := `{"description":["  ","{\"description\":\"Part 1\"}","Part 2 "]}`
+	expected := "Part 1\nPart 2"
+
+	result := UnwrapDescriptionText(input)
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestFetchDescription_DescriptionArrayJoined(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"description":["Part 1","Part 2"]}`)
+	}))
+	defer srv.Close()
+
+	rawText, rawJSON, status, _, err := FetchDescription(srv.URL+"/noticedesc?noticeid=N1", "key")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if status != http.StatusOK || rawText != "Part 1\nPart 2" {
+		t.Errorf("Expected status 200 and %q, got %d %q", "Part 1\nPart 2", status, rawText)
+	}
+	if rawJSON != `{"description":["Part 1","Part 2"]}` {
+		t.Errorf("Expected raw JSON preserved, got %q", rawJSON)
+	}
+}
+
+func TestUnwrapDescriptionText_DescriptionArrayOfObjectsNotJoined(t *testing.T) {
+	// Not an array of strings: keeps the previous marshal-and-recurse behavior
+	input := `{"description":[{"text":"Part 1"}]}`
+	expected := `[{"text":"Part 1"}]`
+
+	result := UnwrapDescriptionText(input)
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+
+// smartPunctuationDescription uses a non-breaking hyphen, non-breaking spaces and curly quotes
+const smartPunctuationDescription = "Small Business Set\u2011Aside (NOV 2020) | 52.219-6 | Applies\n" +
+	"The quotation is valid for 60\u00A0days.\n" +
+	"A \u201CCertificate\u00A0of Compliance\u201D is required with delivery."
+
+func TestNormalizePunctuation(t *testing.T) {
+	input := "\u201CSet\u2011aside\u201D \u2018small\u2019 business \u2014 2025\u20132026\u00A0only\u2026"
+	expected := `"Set-aside" 'small' business - 2025-2026 only...`
+	if result := NormalizePunctuation(input); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+// formFillDescription is a form-style description with blank underscore fields, a dot-leader table of contents
+// and tab-separated columns
+const formFillDescription = "OFFEROR INFORMATION\n" +
+	"Company Name: ____________________________\n" +
+	"CAGE Code:\t\t__________\tUEI:\t______________\n" +
+	"________________________________________\n" +
+	"Section L ........................ 12\n" +
+	"Section M ---------------------- 15\n" +
+	"See FAR 52.212-1... Offers are due on time."
+
+func TestNormalize_CollapsesFormFillLines(t *testing.T) {
+	t.Setenv("NORMALIZE_FILL_MAX_RUN", "")
+
+	expected := "OFFEROR INFORMATION\n" +
+		"Company Name: ___\n" +
+		"CAGE Code: ___ UEI: ___\n" +
+		"___\n" +
+		"Section L ... 12\n" +
+		"Section M --- 15\n" +
+		"See FAR 52.212-1... Offers are due on time."
+	if result := Normalize(formFillDescription); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestNormalize_FillCollapseDisabled(t *testing.T) {
+	t.Setenv("NORMALIZE_FILL_MAX_RUN", "0")
+
+	result := Normalize(formFillDescription)
+	if !strings.Contains(result, "Company Name: ____________________________") {
+		t.Errorf("Expected fill runs kept with NORMALIZE_FILL_MAX_RUN=0, got %q", result)
+	}
+	// Tabs are still normalized to spaces
+	if strings.Contains(result, "\t") || !strings.Contains(result, "CAGE Code: __________ UEI: ______________") {
+		t.Errorf("Expected tabs collapsed to single spaces, got %q", result)
+	}
+}
+
+func TestCollapseFillRuns(t *testing.T) {
+	tests := []struct {
+		input    string
+		maxRun   int
+		expected string
+	}{
+		{"Signature: __________ Date: ______", 3, "Signature: ___ Date: ___"},
+		{"Total ..........$500", 3, "Total ...$500"},
+		{"=====----=====", 2, "=====--====="},
+		{"Wait... what -- 52.212-4", 3, "Wait... what -- 52.212-4"},
+		{"_-_-_-_-", 1, "_-_-_-_-"},
+		{"__________", 0, "__________"},
+	}
+	for _, tt := range tests {
+		if result := collapseFillRuns(tt.input, tt.maxRun); result != tt.expected {
+			t.Errorf("collapseFillRuns(%q, %d): Expected %q, got %q", tt.input, tt.maxRun, tt.expected, result)
+		}
+	}
+}
+
+func TestGetFillMaxRun(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+	}{
+		{"", defaultFillMaxRun},
+		{"5", 5},
+		{"0", 0},
+		{"-1", defaultFillMaxRun},
+		{"many", defaultFillMaxRun},
+	}
+	for _, tt := range tests {
+		t.Setenv("NORMALIZE_FILL_MAX_RUN", tt.value)
+		if result := getFillMaxRun(); result != tt.expected {
+			t.Errorf("NORMALIZE_FILL_MAX_RUN=%q: Expected %d, got %d", tt.value, tt.expected, result)
+		}
+	}
+}
+
+func TestOptimizeForAI_CollapsesFormFill(t *testing.T) {
+	t.Setenv("NORMALIZE_FILL_MAX_RUN", "")
+
+	aiInputText, _, _, _, err := OptimizeForAI(formFillDescription)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(aiInputText, "____") || strings.Contains(aiInputText, "....") || strings.Contains(aiInputText, "\t") {
+		t.Errorf("Expected fill runs and tabs collapsed in AI input, got %q", aiInputText)
+	}
+}
+
+func TestOptimizeForAI_SmartPunctuationMatchesKeywords(t *testing.T) {
+	t.Setenv("AI_ASCII_PUNCTUATION", "")
+
+	aiInputText, _, aiMeta, _, err := OptimizeForAI(smartPunctuationDescription)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(aiMeta.ClausesKept) != 1 || aiMeta.ClausesKept[0] != "Small Business Set\u2011Aside (NOV 2020)" {
+		t.Errorf("Expected set-aside clause kept with original punctuation, got %q", aiMeta.ClausesKept)
+	}
+	if aiMeta.QuoteValidityDays == nil || *aiMeta.QuoteValidityDays != 60 {
+		t.Errorf("Expected quote validity of 60 days, got %v", aiMeta.QuoteValidityDays)
+	}
+	if len(aiMeta.CertsRequired) != 1 || aiMeta.CertsRequired[0] != "Certificate of Compliance" {
+		t.Errorf("Expected certificate of compliance, got %q", aiMeta.CertsRequired)
+	}
+	if !strings.Contains(aiInputText, "Quote validity: 60 days") {
+		t.Errorf("Expected quote validity key fact in AI input, got %q", aiInputText)
+	}
+	// Default mode folds for matching only; the excerpt keeps the original text
+	if !strings.Contains(aiInputText, "60\u00A0days") {
+		t.Errorf("Expected original punctuation preserved in AI input, got %q", aiInputText)
+	}
+}
+
+func TestOptimizeForAI_PunctuationModeAllFoldsOutput(t *testing.T) {
+	t.Setenv("AI_ASCII_PUNCTUATION", "all")
+
+	aiInputText, _, aiMeta, _, _ := OptimizeForAI(smartPunctuationDescription)
+	if strings.Contains(aiInputText, "\u00A0") || strings.Contains(aiInputText, "\u201C") {
+		t.Errorf("Expected ASCII-only punctuation in AI input, got %q", aiInputText)
+	}
+	if len(aiMeta.ClausesKept) != 1 || aiMeta.ClausesKept[0] != "Small Business Set-Aside (NOV 2020)" {
+		t.Errorf("Expected folded clause title, got %q", aiMeta.ClausesKept)
+	}
+}
+
+func TestOptimizeForAI_PunctuationModeOff(t *testing.T) {
+	t.Setenv("AI_ASCII_PUNCTUATION", "off")
+
+	_, _, aiMeta, _, _ := OptimizeForAI(smartPunctuationDescription)
+	if aiMeta.QuoteValidityDays != nil {
+		t.Errorf("Expected no quote validity match without folding, got %d", *aiMeta.QuoteValidityDays)
+	}
+}
+
+func TestParseClauseLine_KeywordVariants(t *testing.T) {
+	relevant := []string{
+		"Notice of Total Small Business Set Aside (NOV 2020) | 52.219-6 | ",
+		"Notice of Total Small Business Set\u2013Aside (NOV 2020) | 52.219-6 | ",
+		"Total SmallBusiness Setaside (NOV 2020) | 52.219-6 | ",
+		"Women-Owned Small Business Program (MAR 2020) | 52.219-30 | ",
+		"Wide-Area WorkFlow Payment Instructions (JAN 2023) | 252.232-7006 | ",
+		"Safeguarding Covered Defense Information and Cyber Incident Reporting (JAN 2023) | ",
+		"252.204-7012 Safeguarding Covered Defense Information | DEC 2019 | ",
+		"52.204-21 Basic Safeguarding of Covered Contractor Information Systems | ",
+		"252.204\u20137021 Contractor Compliance With the CMMC Level Requirement | ",
+		"52.225-1 Buy American\u2014Supplies (OCT 2022) | ",
+		"DO\u2011Rated Order Notice (APR 2008) | ",
+	}
+	for _, line := range relevant {
+		if _, ok := parseClauseLine(line, getClauseRowRules()); !ok {
+			t.Errorf("Expected clause to be relevant: %q", line)
+		}
+	}
+
+	irrelevant := []string{
+		"Definitions (JUN 2020) | 52.202-1 | ",
+		"Gratuities (APR 1984) | 52.203-3 | ",
+		"252.203-7000 Requirements Relating to Compensation of Former DoD Officials | ",
+	}
+	for _, line := range irrelevant {
+		if _, ok := parseClauseLine(line, getClauseRowRules()); ok {
+			t.Errorf("Expected clause not to be relevant: %q", line)
+		}
+	}
+}
+
+func TestParseClauseLine_KeepsOriginalTitle(t *testing.T) {
+	title, ok := parseClauseLine("Small Business Set\u2013Aside (NOV 2020) | 52.219-6 | ", getClauseRowRules())
+	if !ok {
+		t.Fatal("Expected clause to be relevant")
+	}
+	if title != "Small Business Set\u2013Aside (NOV 2020)" {
+		t.Errorf("Expected original title, got %q", title)
+	}
+}
+
+func TestGetClauseRowRules_DefaultsMatchFixedRules(t *testing.T) {
+	for _, key := range []string{"CLAUSE_TITLE_MIN_LEN", "CLAUSE_TITLE_MAX_LEN", "CLAUSE_ROW_ALLOW_SHORT_WITH_ID", "CLAUSE_ROW_REQUIRE_ID"} {
+		t.Setenv(key, "")
+	}
+	want := clauseRowRules{MinTitleLen: 8, MaxTitleLen: 100}
+	if got := getClauseRowRules(); got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestParseClauseLine_ShortButRealTitles(t *testing.T) {
+	short := []string{
+		"Payment | 52.232-1 | ",
+		"Payment | APR 1984 | ",
+		"Quote | 252.204\u20137012 | ",
+	}
+	defaults := getClauseRowRules()
+	for _, line := range short {
+		if _, ok := parseClauseLine(line, defaults); ok {
+			t.Errorf("Expected default rules to drop short title: %q", line)
+		}
+	}
+
+	t.Setenv("CLAUSE_ROW_ALLOW_SHORT_WITH_ID", "true")
+	rules := getClauseRowRules()
+	for _, line := range short {
+		if _, ok := parseClauseLine(line, rules); !ok {
+			t.Errorf("Expected short title with a clause date or number to be kept: %q", line)
+		}
+	}
+	if isTableRow("Payment | see below | ", rules) {
+		t.Error("Expected short title without a clause date or number to be dropped")
+	}
+
+	t.Setenv("CLAUSE_TITLE_MIN_LEN", "4")
+	if !isTableRow("Quote | see below | ", getClauseRowRules()) {
+		t.Error("Expected a lower CLAUSE_TITLE_MIN_LEN to keep a short title")
+	}
+}
+
+func TestParseClauseLine_LongJunkLines(t *testing.T) {
+	long := "Payment will be made after the contracting officer reviews the invoice, the receiving report and any other documents required under this order | see attachment | "
+	if _, ok := parseClauseLine(long, getClauseRowRules()); ok {
+		t.Errorf("Expected first field over %d chars to be dropped", defaultClauseTitleMaxLen)
+	}
+
+	t.Setenv("CLAUSE_TITLE_MAX_LEN", "200")
+	if _, ok := parseClauseLine(long, getClauseRowRules()); !ok {
+		t.Error("Expected a higher CLAUSE_TITLE_MAX_LEN to keep the line")
+	}
+
+	// Pipe-delimited prose with no clause date or number, dropped only under CLAUSE_ROW_REQUIRE_ID
+	junk := "Payment questions and answers | posted weekly | "
+	if _, ok := parseClauseLine(junk, getClauseRowRules()); !ok {
+		t.Error("Expected junk row to be kept without CLAUSE_ROW_REQUIRE_ID")
+	}
+	t.Setenv("CLAUSE_ROW_REQUIRE_ID", "true")
+	rules := getClauseRowRules()
+	if _, ok := parseClauseLine(junk, rules); ok {
+		t.Error("Expected row without a clause date or number to be dropped under CLAUSE_ROW_REQUIRE_ID")
+	}
+	if _, ok := parseClauseLine("Wide Area WorkFlow Payment Instructions (JAN 2023) | 252.232-7006 | ", rules); !ok {
+		t.Error("Expected a real clause row to be kept under CLAUSE_ROW_REQUIRE_ID")
+	}
+}
+
+func TestSelectParagraphs_ParagraphCapIsHardLimit(t *testing.T) {
+	var scored []scoredParagraph
+	for i := 0; i < 100; i++ {
+		scored = append(scored, scoredParagraph{text: "Delivery due.", score: 2})
+	}
+
+	selected := selectParagraphs(scored, 1000000, 5, 0)
+	if len(selected) != 5 {
+		t.Errorf("Expected 5 paragraphs, got %d", len(selected))
+	}
+}
+
+func TestSelectParagraphs_CharCapStopsFirst(t *testing.T) {
+	scored := []scoredParagraph{
+		{text: strings.Repeat("a", 40), score: 4},
+		{text: strings.Repeat("b", 40), score: 3},
+		{text: strings.Repeat("c", 40), score: 2},
+	}
+
+	// 40 + 2 + 40 = 82 fits; a third paragraph would need 124
+	selected := selectParagraphs(scored, 100, 10, 0)
+	if len(selected) != 2 {
+		t.Errorf("Expected 2 paragraphs, got %d", len(selected))
+	}
+	if total := len(strings.Join(selected, "\n\n")); total > 100 {
+		t.Errorf("Expected at most 100 chars, got %d", total)
+	}
+}
+
+func TestSelectParagraphs_StopsAtNonPositiveScore(t *testing.T) {
+	scored := []scoredParagraph{
+		{text: "Scope of work.", score: 2},
+		{text: "Block 1: boilerplate.", score: 0},
+		{text: "Delivery due.", score: 2},
+	}
+	if selected := selectParagraphs(scored, 1000, 10, 0); len(selected) != 1 {
+		t.Errorf("Expected 1 paragraph, got %d", len(selected))
+	}
+}
+
+func TestSelectParagraphs_MinScoreThreshold(t *testing.T) {
+	scored := []scoredParagraph{
+		{text: "Scope of work and delivery.", score: 4},
+		{text: "Barely relevant.", score: 1},
+		{text: "Parking is behind the building.", score: 0},
+		{text: "Block 1: boilerplate.", score: -10},
+	}
+	cases := []struct {
+		minScore int
+		expected int
+	}{
+		{1, 1},  // stricter: the barely-positive paragraph is left out
+		{0, 2},  // default: positive scores only
+		{-1, 3}, // looser: neutral context is included, boilerplate still isn't
+	}
+	for _, c := range cases {
+		if selected := selectParagraphs(scored, 1000, 10, c.minScore); len(selected) != c.expected {
+			t.Errorf("minScore %d: expected %d paragraphs, got %d: %q", c.minScore, c.expected, len(selected), selected)
+		}
+	}
+}
+
+func TestGetAIMinScore(t *testing.T) {
+	cases := map[string]int{"": 0, "-1": -1, " 3 ": 3, "abc": 0}
+	for value, expected := range cases {
+		t.Setenv("AI_DESC_MIN_SCORE", value)
+		if got := getAIMinScore(); got != expected {
+			t.Errorf("AI_DESC_MIN_SCORE=%q: expected %d, got %d", value, expected, got)
+		}
+	}
+}
+
+func TestOptimizeForAI_MinScoreIncludesNeutralParagraphs(t *testing.T) {
+	text := "Scope of work: mow the grounds weekly.\n\nParking is behind the building."
+
+	t.Setenv("AI_DESC_MIN_SCORE", "")
+	aiInputText, _, _, _, err := OptimizeForAI(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(aiInputText, "Scope of work") || strings.Contains(aiInputText, "Parking") {
+		t.Errorf("Expected only the scored paragraph by default, got %q", aiInputText)
+	}
+
+	t.Setenv("AI_DESC_MIN_SCORE", "-1")
+	aiInputText, _, _, _, err = OptimizeForAI(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(aiInputText, "Scope of work") || !strings.Contains(aiInputText, "Parking is behind the building.") {
+		t.Errorf("Expected the neutral paragraph included at -1, got %q", aiInputText)
+	}
+}
+
+func TestOptimizeForAI_ManySmallParagraphsRespectParaCap(t *testing.T) {
+	t.Setenv("AI_DESC_MAX_PARAS", "3")
+	t.Setenv("AI_DESC_MAX_CHARS", "")
+
+	var paras []string
+	for i := 0; i < 50; i++ {
+		paras = append(paras, fmt.Sprintf("Delivery %d due.", i))
+	}
+	aiInputText, _, _, _, err := OptimizeForAI(strings.Join(paras, "\n\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	excerpt := aiInputText[strings.Index(aiInputText, "RELEVANT EXCERPT:\n")+len("RELEVANT EXCERPT:\n"):]
+	if count := len(strings.Split(excerpt, "\n\n")); count != 3 {
+		t.Errorf("Expected 3 paragraphs in AI input, got %d: %q", count, excerpt)
+	}
+}
+
+func TestExtractDeliveryTerms_DeliveryARO(t *testing.T) {
+	tests := []struct {
+		text     string
+		wantDays int
+		wantType string
+	}{
+		{"Delivery within 90 days ARO.", 90, "calendar"},
+		{"Required delivery: 120 DAYS A.R.O.", 120, "calendar"},
+		{"Deliver no later than 45 calendar days after receipt of order.", 45, "calendar"},
+		{"Delivery is required within thirty (30) days after receipt of the order.", 30, "calendar"},
+		{"Items shall ship within 10 business days ARO.", 10, "business"},
+		{"Delivery: 5 working days after date of award.", 5, "business"},
+	}
+	for _, tt := range tests {
+		days, dayType, _ := extractDeliveryTerms(tt.text)
+		if days == nil || *days != tt.wantDays {
+			t.Errorf("%q: Expected %d days, got %v", tt.text, tt.wantDays, days)
+			continue
+		}
+		if dayType == nil || *dayType != tt.wantType {
+			t.Errorf("%q: Expected %s days, got %v", tt.text, tt.wantType, dayType)
+		}
+	}
+}
+
+func TestExtractDeliveryTerms_PeriodOfPerformance(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Period of Performance: 12 months", "12 months"},
+		{"The period of performance shall be twelve (12) months from date of award.", "12 months"},
+		{"Period of Performance (PoP) is 365 days.", "365 days"},
+		{"POP: 1 year base period.", "1 year"},
+		{"Period of Performance: 10/01/2025 - 09/30/2026", "10/01/2025 to 09/30/2026"},
+		{"PoP: 2025-10-01 through 2026-09-30", "2025-10-01 to 2026-09-30"},
+	}
+	for _, tt := range tests {
+		_, _, pop := extractDeliveryTerms(tt.text)
+		if pop == nil || *pop != tt.want {
+			t.Errorf("%q: Expected period of performance %q, got %v", tt.text, tt.want, pop)
+		}
+	}
+}
+
+func TestExtractDeliveryTerms_NoMatch(t *testing.T) {
+	days, dayType, pop := extractDeliveryTerms("Quotes are valid for 60 days. Questions are due 10 days before closing.")
+	if days != nil || dayType != nil || pop != nil {
+		t.Errorf("Expected no delivery terms, got %v %v %v", days, dayType, pop)
+	}
+}
+
+func TestExtractQuestionsDue(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Questions due by Jan 5; quotes due Jan 15.", "Jan 5"},
+		{"Quotes are due January 15, 2026. Questions are due no later than January 5, 2026.", "January 5, 2026"},
+		{"All questions must be submitted in writing to the contracting officer no later than 01/05/2026 at 2:00 PM EST.", "01/05/2026 at 2:00 PM EST"},
+		{"Deadline for questions: 2026-01-05", "2026-01-05"},
+		{"Inquiries will be accepted until 5 January 2026.", "5 January 2026"},
+		{"Technical questions due Sept. 30 2026, 10 a.m. ET", "Sept. 30 2026, 10 a.m. ET"},
+		{"Questions about the quotes due Jan 15 go to the buyer. Questions due Jan 5.", "Jan 5"},
+	}
+	for _, tt := range tests {
+		due := extractQuestionsDue(tt.text)
+		if due == nil || *due != tt.want {
+			t.Errorf("%q: Expected questions due %q, got %v", tt.text, tt.want, due)
+		}
+	}
+}
+
+func TestExtractQuestionsDue_NoMatch(t *testing.T) {
+	for _, text := range []string{
+		"Quotes due Jan 15, 2026.",
+		"Questions should be sent to the buyer. Offers are due 01/15/2026.",
+		"Questions must be submitted 5 days before the solicitation closes on Jan 15.",
+		"Questions and answers will be posted by Jan 10.",
+		"Questions may 10 percent of the parts be substituted?",
+	} {
+		if due := extractQuestionsDue(text); due != nil {
+			t.Errorf("%q: Expected no questions deadline, got %q", text, *due)
+		}
+	}
+}
+
+func TestOptimizeForAI_QuestionsDueSeparateFromResponse(t *testing.T) {
+	text := "Scope: furnish replacement valves.\nQuestions due by Jan 5; quotes due Jan 15."
+
+	aiInputText, _, aiMeta, _, err := OptimizeForAI(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if aiMeta.QuestionsDueDetected == nil || *aiMeta.QuestionsDueDetected != "Jan 5" {
+		t.Errorf("Expected questions due %q, got %v", "Jan 5", aiMeta.QuestionsDueDetected)
+	}
+	if !strings.Contains(aiInputText, "Questions due: Jan 5") {
+		t.Errorf("Expected questions due key fact in AI input, got %q", aiInputText)
+	}
+	if strings.Contains(aiInputText, "Questions due: Jan 15") {
+		t.Errorf("Expected the response date not to be taken as the questions date, got %q", aiInputText)
+	}
+}
+
+func TestOptimizeForAI_DeliveryTermsInAiMeta(t *testing.T) {
+	text := "Scope: furnish replacement valves.\nDelivery within 90 days ARO.\nPeriod of Performance: 12 months."
+
+	aiInputText, _, aiMeta, _, err := OptimizeForAI(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if aiMeta.DeliveryDaysARO == nil || *aiMeta.DeliveryDaysARO != 90 {
+		t.Errorf("Expected DeliveryDaysARO 90, got %v", aiMeta.DeliveryDaysARO)
+	}
+	if aiMeta.DeliveryDayType == nil || *aiMeta.DeliveryDayType != "calendar" {
+		t.Errorf("Expected calendar days, got %v", aiMeta.DeliveryDayType)
+	}
+	if aiMeta.PeriodOfPerformance == nil || *aiMeta.PeriodOfPerformance != "12 months" {
+		t.Errorf("Expected period of performance %q, got %v", "12 months", aiMeta.PeriodOfPerformance)
+	}
+	if !strings.Contains(aiInputText, "Delivery: 90 calendar days ARO") {
+		t.Errorf("Expected delivery key fact in AI input, got %q", aiInputText)
+	}
+}
+
+func TestExtractClauseNumbers_RealClauses(t *testing.T) {
+	text := "Offerors shall comply with FAR 52.212-1, Instructions to Offerors, and 52.212-4 (JAN 2023).\n" +
+		"DFARS 252.204-7012 Safeguarding Covered Defense Information | DEC 2019 |\n" +
+		"252.225-7001 Buy American and Balance of Payments Program\n" +
+		"This is a small business set-aside under FAR 19.502-2 and FAR 19.502.\n" +
+		"52.212\u20131 applies again, as does 52.204-7."
+
+	got := extractClauseNumbers(text)
+	want := []string{"19.502", "19.502-2", "252.204-7012", "252.225-7001", "52.204-7", "52.212-1", "52.212-4"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestExtractClauseNumbers_IgnoresLookalikes(t *testing.T) {
+	tests := []string{
+		"The bracket is 10.125 inches wide and 12.500 inches tall.",
+		"Unit price $15.250 per pound; total 1,234.567 lbs.",
+		"Requires firmware v10.123.4 or later (build 2.10.125-3).",
+		"Deliver by 12.31.2023 or 2023.12.31.",
+		"Server address 10.100.1.1, port 8443.",
+		"Part number 99.123-4 and drawing 60.001-2.",
+		"Serial AB52.212-1X and lot 152.212-1.",
+	}
+	for _, text := range tests {
+		if got := extractClauseNumbers(text); got != nil {
+			t.Errorf("%q: Expected no clause numbers, got %v", text, got)
+		}
+	}
+}
+
+func TestOptimizeForAI_ClauseNumbersInAiMeta(t *testing.T) {
+	text := "52.212-4 Contract Terms and Conditions - Commercial Products\n" +
+		"252.204-7012 Safeguarding Covered Defense Information\n" +
+		"The item weighs 10.125 lbs. See also 52.212-4."
+
+	_, _, aiMeta, _, err := OptimizeForAI(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []string{"252.204-7012", "52.212-4"}
+	if strings.Join(aiMeta.ClauseNumbers, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected clause numbers %v, got %v", want, aiMeta.ClauseNumbers)
+	}
+}
+
+func TestOptimizeForAI_AiMetaJSONIsByteStable(t *testing.T) {
+	text := "Contact bob@agency.gov or alice@agency.gov, phone (555) 222-3333 or (555) 111-2222.\n" +
+		"See https://sam.gov/b and https://sam.gov/a.\n" +
+		"52.212-4 Contract Terms and Conditions - Commercial Products\n" +
+		"52.204-7 System for Award Management\n" +
+		"Contractor must be ISO 9001 certified and hold a SECRET clearance.\n" +
+		"Delivery within 30 days ARO."
+
+	_, _, first, _, err := OptimizeForAI(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want, _ := json.Marshal(first)
+	for i := 0; i < 5; i++ {
+		_, _, aiMeta, _, _ := OptimizeForAI(text)
+		got, _ := json.Marshal(aiMeta)
+		if string(got) != string(want) {
+			t.Fatalf("Expected identical ai_meta JSON on run %d:\n%s\ngot:\n%s", i, want, got)
+		}
+	}
+}
+
+func TestOptimizeForAI_AiMetaSlicesSortedRegardlessOfOrder(t *testing.T) {
+	_, _, a, primaryA, _ := OptimizeForAI("Contact bob@agency.gov or alice@agency.gov.\nSee https://sam.gov/b and https://sam.gov/a for details.")
+	_, _, b, _, _ := OptimizeForAI("See https://sam.gov/a and https://sam.gov/b for details.\nContact alice@agency.gov or bob@agency.gov.")
+
+	gotA, _ := json.Marshal(a)
+	gotB, _ := json.Marshal(b)
+	if string(gotA) != string(gotB) {
+		t.Errorf("Expected reordered input to give identical ai_meta JSON:\n%s\ngot:\n%s", gotA, gotB)
+	}
+	if len(a.POCEmails) != 2 || a.POCEmails[0] != "alice@agency.gov" {
+		t.Errorf("Expected sorted POC emails, got %v", a.POCEmails)
+	}
+	// The primary email keeps discovery order
+	if primaryA == nil || *primaryA != "bob@agency.gov" {
+		t.Errorf("Expected primary email %q, got %v", "bob@agency.gov", primaryA)
+	}
+}
+
+func TestOptimizeForAI_RepeatedHeadingsCollapsed(t *testing.T) {
+	t.Setenv("AI_DEDUP_SECTIONS", "")
+	var b strings.Builder
+	b.WriteString("Contractor shall deliver 40 pallets of valves.\n\n")
+	for i := 0; i < 5; i++ {
+		heading := "INSPECTION AND ACCEPTANCE"
+		if i%2 == 1 {
+			heading = "  Inspection   and Acceptance "
+		}
+		b.WriteString(heading + "\nInspection and acceptance at destination by the contracting officer.\n\n")
+		b.WriteString(fmt.Sprintf("Line item %d delivery shall be packaged per ASTM D3951.\n\n", i))
+	}
+
+	aiInputText, _, _, _, err := OptimizeForAI(b.String())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n := strings.Count(strings.ToLower(aiInputText), "inspection and acceptance\n"); n != 1 {
+		t.Errorf("Expected a single retained heading, got %d in %q", n, aiInputText)
+	}
+	if n := strings.Count(aiInputText, "at destination by the contracting officer"); n != 1 {
+		t.Errorf("Expected a single retained section body, got %d", n)
+	}
+	// Distinct paragraphs between the repeats survive
+	for i := 0; i < 5; i++ {
+		if !strings.Contains(aiInputText, fmt.Sprintf("Line item %d delivery", i)) {
+			t.Errorf("Expected line item %d to be kept", i)
+		}
+	}
+}
+
+func TestOptimizeForAI_RepeatedHeadingsKeptWhenDisabled(t *testing.T) {
+	t.Setenv("AI_DEDUP_SECTIONS", "false")
+	text := strings.Repeat("INSPECTION AND ACCEPTANCE\nInspection at destination on delivery.\n\n", 3)
+
+	aiInputText, _, _, _, _ := OptimizeForAI(text)
+	if n := strings.Count(aiInputText, "INSPECTION AND ACCEPTANCE"); n != 3 {
+		t.Errorf("Expected all 3 headings with dedup off, got %d", n)
+	}
+}
+
+func TestCollapseRepeatedSections(t *testing.T) {
+	paragraphs := []string{
+		"INSPECTION AND ACCEPTANCE\nAt destination.",
+		"Packaging per\nASTM D3951",
+		"inspection  and acceptance\nat destination.", // same section, different case/whitespace
+		"PACKAGING  PER ASTM D3951",
+		"INSPECTION AND ACCEPTANCE\nBy the contracting officer.",
+		"INSPECTION AND ACCEPTANCE",
+	}
+	headings := []string{"INSPECTION AND ACCEPTANCE", "", "", "", "INSPECTION AND ACCEPTANCE", "INSPECTION AND ACCEPTANCE"}
+
+	got := collapseRepeatedSections(paragraphs, headings, func(s string) string { return s })
+	want := []string{"INSPECTION AND ACCEPTANCE\nAt destination.", "Packaging per\nASTM D3951", "By the contracting officer."}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestOptimizeForAI_FallbackWhenNothingScores(t *testing.T) {
+	t.Setenv("AI_DESC_FALLBACK_CHARS", "")
+	text := "Replacement hydraulic pump for the B-52 landing gear assembly.\nNSN 1650-01-234-5678, qty 4."
+
+	aiInputText, excerptText, _, _, err := OptimizeForAI(text)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(excerptText, "hydraulic pump") {
+		t.Errorf("Expected the leading text as excerpt, got %q", excerptText)
+	}
+	if !strings.HasSuffix(aiInputText, "RELEVANT EXCERPT:\n"+strings.TrimSpace(text)) {
+		t.Errorf("Expected the leading text under the header, got %q", aiInputText)
+	}
+}
+
+func TestOptimizeForAI_FallbackTruncatedAndDisableable(t *testing.T) {
+	text := "Replacement hydraulic pump for the B-52 landing gear assembly, with mounting hardware and seals."
+
+	t.Setenv("AI_DESC_FALLBACK_CHARS", "40")
+	_, excerptText, _, _, _ := OptimizeForAI(text)
+	if len(excerptText) > 40 || !strings.HasSuffix(excerptText, "...") || !strings.HasPrefix(excerptText, "Replacement hydraulic") {
+		t.Errorf("Expected at most 40 chars cut at a word and marked, got %q", excerptText)
+	}
+
+	t.Setenv("AI_DESC_FALLBACK_CHARS", "0")
+	if _, excerptText, _, _, _ := OptimizeForAI(text); excerptText != "" {
+		t.Errorf("Expected no excerpt with the fallback disabled, got %q", excerptText)
+	}
+}
+
+func TestLeadingText(t *testing.T) {
+	cases := []struct {
+		text     string
+		maxChars int
+		expected string
+	}{
+		{"  short  ", 100, "short"},
+		{"alpha beta gamma delta", 15, "alpha beta..."},
+		{"abcdefghijklmnop", 10, "abcdefg..."},
+		{"héllo wörld", 6, "hé..."},
+	}
+	for _, c := range cases {
+		if got := leadingText(c.text, c.maxChars); got != c.expected {
+			t.Errorf("leadingText(%q, %d): expected %q, got %q", c.text, c.maxChars, c.expected, got)
+		}
+	}
+}
+
+func TestDetectSource_ResourceLinkWithoutDescription(t *testing.T) {
+	t.Setenv("DESC_ATTACHMENT_SOURCE", "")
+	opp := models.Opportunity{
+		NoticeID:      "N1",
+		Description:   "  ",
+		ResourceLinks: []string{"https://sam.gov/api/prod/opps/v3/opportunities/resources/files/abc/download"},
+	}
+
+	sourceType, urlStr, inline := DetectSource(opp)
+	if sourceType != models.SourceTypeAttachment {
+		t.Errorf("Expected source type %q, got %q", models.SourceTypeAttachment, sourceType)
+	}
+	if urlStr != opp.ResourceLinks[0] || inline != "" {
+		t.Errorf("Expected the resource link as the source URL, got url=%q inline=%q", urlStr, inline)
+	}
+
+	// The toggle restores the old classification
+	t.Setenv("DESC_ATTACHMENT_SOURCE", "false")
+	if sourceType, urlStr, _ := DetectSource(opp); sourceType != models.SourceTypeNone || urlStr != "" {
+		t.Errorf("Expected none with DESC_ATTACHMENT_SOURCE=false, got %q %q", sourceType, urlStr)
+	}
+}
+
+func TestDetectSource_LinksFallback(t *testing.T) {
+	t.Setenv("DESC_ATTACHMENT_SOURCE", "")
+	opp := models.Opportunity{NoticeID: "N1"}
+	opp.Links = append(opp.Links, struct {
+		Rel  string `json:"rel"`
+		Href string `json:"href"`
+		Type string `json:"type"`
+	}{Rel: "self", Href: "https://api.sam.gov/prod/opportunities/v2/search?noticeid=N1"})
+
+	// SAM's self link is not content
+	if sourceType, _, _ := DetectSource(opp); sourceType != models.SourceTypeNone {
+		t.Errorf("Expected none with only a self link, got %q", sourceType)
+	}
+
+	opp.Links[0].Rel = "attachment"
+	opp.Links[0].Href = "https://example.gov/sow.pdf"
+	if sourceType, urlStr, _ := DetectSource(opp); sourceType != models.SourceTypeAttachment || urlStr != "https://example.gov/sow.pdf" {
+		t.Errorf("Expected the attachment link, got %q %q", sourceType, urlStr)
+	}
+
+	// A description always wins over links
+	opp.Description = "Furnish replacement valves."
+	if sourceType, _, inline := DetectSource(opp); sourceType != models.SourceTypeInline || inline != opp.Description {
+		t.Errorf("Expected inline text, got %q %q", sourceType, inline)
+	}
+}
+
+func TestExtractAmendment(t *testing.T) {
+	tests := []struct {
+		text string
+		want string // "" for an amendment without a stated number
+	}{
+		{"Amendment 0002 is issued to extend the response date to 01/15/2026.", "0002"},
+		{"AMENDMENT NO. 3\nThe purpose of this amendment is to answer vendor questions.", "3"},
+		{"Amendment #02: Revised SOW attached.", "02"},
+		{"Modification P00001 adds funding to CLIN 0001.", "P00001"},
+		{"Modification Number: a00002 - replace the drawing package.", "A00002"},
+		{"Amendment 12/05/2025\nAmendment 0001 answers questions received.", "0001"},
+		{"The purpose of this modification is to update the delivery schedule.", ""},
+		{"The solicitation is hereby amended as follows: the closing date is extended.", ""},
+		{"AMENDMENT OF SOLICITATION/MODIFICATION OF CONTRACT (SF 30)\nBlock 14 description follows.", ""},
+	}
+	for _, tt := range tests {
+		isAmendment, number := extractAmendment(tt.text)
+		if isAmendment == nil || !*isAmendment {
+			t.Errorf("%q: Expected an amendment, got %v", tt.text, isAmendment)
+			continue
+		}
+		if tt.want == "" {
+			if number != nil {
+				t.Errorf("%q: Expected no amendment number, got %q", tt.text, *number)
+			}
+		} else if number == nil || *number != tt.want {
+			t.Errorf("%q: Expected amendment number %q, got %v", tt.text, tt.want, number)
+		}
+	}
+}
+
+func TestExtractAmendment_NoMatch(t *testing.T) {
+	for _, text := range []string{
+		"The contractor shall furnish replacement valves. Offerors must acknowledge all amendments.",
+		"Clause 52.212-1 modification rights are reserved.",
+		"Scope: repair of hydraulic pumps.\n" + strings.Repeat("Inspection and acceptance at origin. ", 20) + "Amendment 0003 will be posted if needed.",
+	} {
+		if isAmendment, number := extractAmendment(text); isAmendment != nil || number != nil {
+			t.Errorf("%q: Expected no amendment, got %v %v", text, isAmendment, number)
+		}
+	}
+}
+
+func TestOptimizeForAI_AmendmentInAiMeta(t *testing.T) {
+	text := "Amendment 0002 is issued to extend the response date.\nScope: furnish replacement valves."
+
+	aiInputText, _, aiMeta, _, err := OptimizeForAI(text)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if aiMeta.IsAmendment == nil || !*aiMeta.IsAmendment {
+		t.Errorf("Expected is_amendment true, got %v", aiMeta.IsAmendment)
+	}
+	if aiMeta.AmendmentNumber == nil || *aiMeta.AmendmentNumber != "0002" {
+		t.Errorf("Expected amendment number %q, got %v", "0002", aiMeta.AmendmentNumber)
+	}
+	if !strings.Contains(aiInputText, "Amendment: 0002") {
+		t.Errorf("Expected amendment key fact in AI input, got %q", aiInputText)
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OpportunityEventsChannel is the Postgres NOTIFY channel ingestion publishes on
+const OpportunityEventsChannel = "opportunity_events"
+
+// defaultEventBufferSize is how many events a slow subscriber can fall behind before its oldest are dropped
+const defaultEventBufferSize = 64
+
+// defaultEventMaxOverflows is how many publishes in a row may find a subscriber's buffer full before it is disconnected
+const defaultEventMaxOverflows = 256
+
+// getEventBufferSize returns the per-subscriber buffer size (EVENT_BUFFER_SIZE, default 64)
+func getEventBufferSize() int {
+	if sizeStr := os.Getenv("EVENT_BUFFER_SIZE"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size > 0 {
+			return size
+		}
+	}
+	return defaultEventBufferSize
+}
+
+// getEventMaxOverflows returns how many consecutive overflows disconnect a subscriber (EVENT_MAX_OVERFLOWS, default 256)
+func getEventMaxOverflows() int {
+	if maxStr := os.Getenv("EVENT_MAX_OVERFLOWS"); maxStr != "" {
+		if max, err := strconv.Atoi(maxStr); err == nil && max > 0 {
+			return max
+		}
+	}
+	return defaultEventMaxOverflows
+}
+
+// OpportunityEvent describes an opportunity that ingestion just inserted or changed
+type OpportunityEvent struct {
+	NoticeID   string    `json:"noticeId"`
+	Title      string    `json:"title"`
+	PostedDate string    `json:"postedDate,omitempty"`
+	Action     string    `json:"action"` // new | updated
+	At         time.Time `json:"at"`
+}
+
+// EventMetrics counts events dropped for slow subscribers and subscribers disconnected for falling behind
+type EventMetrics struct {
+	dropped      atomic.Int64
+	disconnected atomic.Int64
+}
+
+// DefaultEventMetrics is the process-wide event counter set exposed on /metrics
+var DefaultEventMetrics = &EventMetrics{}
+
+// Dropped returns how many events were dropped because a subscriber's buffer was full
+func (m *EventMetrics) Dropped() int64 { return m.dropped.Load() }
+
+// Disconnected returns how many subscribers were disconnected for overflowing repeatedly
+func (m *EventMetrics) Disconnected() int64 { return m.disconnected.Load() }
+
+// WritePrometheus writes the counters in Prometheus text exposition format
+func (m *EventMetrics) WritePrometheus(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "# HELP govcon_stream_events_dropped_total Opportunity events dropped because a stream subscriber's buffer was full.\n"+
+		"# TYPE govcon_stream_events_dropped_total counter\n"+
+		"govcon_stream_events_dropped_total %d\n"+
+		"# HELP govcon_stream_subscribers_disconnected_total Stream subscribers disconnected for overflowing their buffer repeatedly.\n"+
+		"# TYPE govcon_stream_subscribers_disconnected_total counter\n"+
+		"govcon_stream_subscribers_disconnected_total %d\n",
+		m.Dropped(), m.Disconnected())
+	return err
+}
+
+// eventSubscriber is one subscriber's buffer and how many publishes in a row found it full
+type eventSubscriber struct {
+	ch        chan OpportunityEvent
+	overflows int
+}
+
+// EventBroker fans out opportunity events to in-process subscribers (e.g. SSE clients)
+// Publish never blocks: a subscriber whose buffer is full loses its oldest event to make room,
+// and one that stays full for maxOverflows publishes in a row is disconnected (its channel closed)
+type EventBroker struct {
+	mu           sync.Mutex
+	subscribers  map[*eventSubscriber]struct{}
+	bufferSize   int
+	maxOverflows int
+	metrics      *EventMetrics
+}
+
+// NewEventBroker creates a broker with bufferSize events per subscriber (0 for EVENT_BUFFER_SIZE or the default)
+func NewEventBroker(bufferSize int) *EventBroker {
+	if bufferSize <= 0 {
+		bufferSize = getEventBufferSize()
+	}
+	return &EventBroker{
+		subscribers:  make(map[*eventSubscriber]struct{}),
+		bufferSize:   bufferSize,
+		maxOverflows: getEventMaxOverflows(),
+		metrics:      DefaultEventMetrics,
+	}
+}
+
+// Subscribe registers a new subscriber; call the returned func to unsubscribe
+// The channel is closed on unsubscribe, or earlier if the broker disconnects a subscriber that fell too far behind
+func (b *EventBroker) Subscribe() (<-chan OpportunityEvent, func()) {
+	sub := &eventSubscriber{ch: make(chan OpportunityEvent, b.bufferSize)}
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.removeLocked(sub)
+	}
+	return sub.ch, unsubscribe
+}
+
+// removeLocked drops sub and closes its channel; safe to call again once removed. b.mu must be held
+func (b *EventBroker) removeLocked(sub *eventSubscriber) {
+	if _, ok := b.subscribers[sub]; !ok {
+		return
+	}
+	delete(b.subscribers, sub)
+	close(sub.ch)
+}
+
+// Publish delivers ev to every subscriber without blocking
+// A full buffer drops its oldest event to make room; a subscriber that overflows maxOverflows
+// publishes in a row is disconnected so it can't hold back the others
+func (b *EventBroker) Publish(ev OpportunityEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- ev:
+			sub.overflows = 0
+			continue
+		default:
+		}
+
+		// Slow consumer - drop the oldest buffered event rather than block ingestion
+		sub.overflows++
+		b.metrics.dropped.Add(1)
+		if sub.overflows >= b.maxOverflows {
+			b.metrics.disconnected.Add(1)
+			b.removeLocked(sub)
+			continue
+		}
+		select {
+		case <-sub.ch:
+		default: // The subscriber drained it meanwhile
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+		}
+	}
+}
+
+// SubscriberCount returns the number of connected subscribers
+func (b *EventBroker) SubscriberCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
+
+// NewNotifyEmitter returns an ingestion event emitter that publishes via Postgres NOTIFY,
+// so API processes listening with ListenOpportunityEvents receive events from a separate ingest process
+func NewNotifyEmitter(ctx context.Context, db *pgxpool.Pool) func(OpportunityEvent) {
+	return func(ev OpportunityEvent) {
+		payload, err := json.Marshal(ev)
+		if err != nil {
+			return
+		}
+		if _, err := db.Exec(ctx, "SELECT pg_notify($1, $2)", OpportunityEventsChannel, string(payload)); err != nil {
+			log.Printf("Warning: failed to publish opportunity event for %s: %v", ev.NoticeID, err)
+		}
+	}
+}
+
+// ListenOpportunityEvents relays Postgres NOTIFY payloads on OpportunityEventsChannel into broker
+// Blocks until ctx is cancelled or the listening connection fails
+func ListenOpportunityEvents(ctx context.Context, db *pgxpool.Pool, broker *EventBroker) error {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire listen connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+OpportunityEventsChannel); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", OpportunityEventsChannel, err)
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed waiting for notification: %w", err)
+		}
+
+		var ev OpportunityEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &ev); err != nil {
+			log.Printf("Warning: ignoring malformed opportunity event: %v", err)
+			continue
+		}
+		broker.Publish(ev)
+	}
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/samtest"
+)
+
+func TestEventBroker_SubscribeReceivesEvent(t *testing.T) {
+	b := NewEventBroker(4)
+	events, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+
+	b.Publish(OpportunityEvent{NoticeID: "N1", Action: "new"})
+
+	select {
+	case ev := <-events:
+		if ev.NoticeID != "N1" || ev.Action != "new" {
+			t.Errorf("Expected N1/new, got %s/%s", ev.NoticeID, ev.Action)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an event")
+	}
+}
+
+func TestEventBroker_SlowConsumerDoesNotBlock(t *testing.T) {
+	b := NewEventBroker(2)
+	events, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			b.Publish(OpportunityEvent{NoticeID: "N"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber")
+	}
+	if len(events) != 2 {
+		t.Errorf("Expected buffer of 2 events, got %d", len(events))
+	}
+}
+
+func TestEventBroker_SlowSubscriberDropsOldestOnlyForItself(t *testing.T) {
+	b := NewEventBroker(4)
+	b.metrics = &EventMetrics{}
+	slow, unsubscribeSlow := b.Subscribe() // never read until publishing is done
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := b.Subscribe()
+	defer unsubscribeFast()
+
+	// Each publish returns while the slow subscriber's buffer is full, and the fast one gets every event
+	const total = 20
+	for i := 0; i < total; i++ {
+		published := make(chan struct{})
+		go func() {
+			b.Publish(OpportunityEvent{NoticeID: fmt.Sprintf("N%02d", i)})
+			close(published)
+		}()
+		select {
+		case <-published:
+		case <-time.After(time.Second):
+			t.Fatalf("Publish %d blocked on a slow subscriber", i)
+		}
+		select {
+		case ev := <-fast:
+			if want := fmt.Sprintf("N%02d", i); ev.NoticeID != want {
+				t.Errorf("Expected fast subscriber to get %s, got %s", want, ev.NoticeID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Fast subscriber missed event %d", i)
+		}
+	}
+
+	// The slow subscriber kept the newest events; the oldest were dropped
+	var got []string
+	for len(slow) > 0 {
+		got = append(got, (<-slow).NoticeID)
+	}
+	if strings.Join(got, ",") != "N16,N17,N18,N19" {
+		t.Errorf("Expected the 4 newest events, got %v", got)
+	}
+	if dropped := b.metrics.Dropped(); dropped != total-4 {
+		t.Errorf("Expected %d dropped events, got %d", total-4, dropped)
+	}
+	if b.SubscriberCount() != 2 {
+		t.Errorf("Expected both subscribers still connected, got %d", b.SubscriberCount())
+	}
+}
+
+func TestEventBroker_DisconnectsAfterRepeatedOverflows(t *testing.T) {
+	b := NewEventBroker(2)
+	b.metrics = &EventMetrics{}
+	b.maxOverflows = 3
+	stalled, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < 2+3; i++ {
+		b.Publish(OpportunityEvent{NoticeID: "N"})
+	}
+
+	if b.SubscriberCount() != 0 {
+		t.Fatalf("Expected stalled subscriber to be disconnected, got %d subscribers", b.SubscriberCount())
+	}
+	if b.metrics.Disconnected() != 1 {
+		t.Errorf("Expected 1 disconnect, got %d", b.metrics.Disconnected())
+	}
+	// Buffered events are still readable, then the channel reports closed
+	for range stalled {
+	}
+	b.Publish(OpportunityEvent{NoticeID: "N"}) // Must not panic on the closed channel
+}
+
+func TestEventBroker_OverflowCountResetsWhenSubscriberCatchesUp(t *testing.T) {
+	b := NewEventBroker(1)
+	b.metrics = &EventMetrics{}
+	b.maxOverflows = 2
+	events, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < 5; i++ {
+		b.Publish(OpportunityEvent{NoticeID: "N"}) // fills the buffer
+		b.Publish(OpportunityEvent{NoticeID: "N"}) // one overflow
+		<-events                                   // catches up
+	}
+	if b.SubscriberCount() != 1 {
+		t.Errorf("Expected a subscriber that keeps catching up to stay connected")
+	}
+}
+
+func TestEventMetrics_WritePrometheus(t *testing.T) {
+	m := &EventMetrics{}
+	m.dropped.Add(3)
+	var sb strings.Builder
+	if err := m.WritePrometheus(&sb); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(sb.String(), "govcon_stream_events_dropped_total 3\n") {
+		t.Errorf("Expected dropped counter in output, got %q", sb.String())
+	}
+}
+
+func TestEventBroker_Unsubscribe(t *testing.T) {
+	b := NewEventBroker(1)
+	_, unsubscribe := b.Subscribe()
+	if b.SubscriberCount() != 1 {
+		t.Fatalf("Expected 1 subscriber, got %d", b.SubscriberCount())
+	}
+	unsubscribe()
+	unsubscribe() // Safe to call twice
+	if b.SubscriberCount() != 0 {
+		t.Errorf("Expected 0 subscribers, got %d", b.SubscriberCount())
+	}
+	b.Publish(OpportunityEvent{NoticeID: "N"}) // Must not panic on a closed channel
+}
+
+func TestIngestOpportunities_EmitsNewAndUpdated(t *testing.T) {
+	sam := samtest.NewServer(t)
+	addNumberedOpportunities(sam, 3)
+	svc, _ := newTestIngestionService(sam.SearchURL())
+	results := map[string]string{"N000": "new", "N001": "updated", "N002": "skipped"}
+	svc.processOpportunity = func(ctx context.Context, opp models.Opportunity) (string, error) {
+		return results[opp.NoticeID], nil
+	}
+
+	var emitted []OpportunityEvent
+	svc.SetEventEmitter(func(ev OpportunityEvent) { emitted = append(emitted, ev) })
+
+	if _, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(emitted) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(emitted))
+	}
+	if emitted[0].NoticeID != "N000" || emitted[0].Action != "new" {
+		t.Errorf("Expected N000/new, got %s/%s", emitted[0].NoticeID, emitted[0].Action)
+	}
+	if emitted[1].NoticeID != "N001" || emitted[1].Action != "updated" {
+		t.Errorf("Expected N001/updated, got %s/%s", emitted[1].NoticeID, emitted[1].Action)
+	}
+}
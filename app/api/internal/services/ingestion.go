@@ -5,30 +5,144 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"govcon/api/internal/models"
 )
 
 type IngestionStats struct {
-	New      int
-	Updated  int
-	Skipped  int
-	Errors   int
-	Total    int
+	New      int `json:"new"`
+	Updated  int `json:"updated"`
+	Skipped  int `json:"skipped"`
+	Excluded int `json:"excluded,omitempty"` // not stored because the notice type is excluded (INGEST_SKIP_EXCLUDED_TYPES)
+	Errors   int `json:"errors"`
+	Total    int `json:"total"`
+	SkippedPages []SkippedPage `json:"skippedPages,omitempty"` // SAM pages that still failed after retries
+	NoRecords    bool          `json:"noRecords,omitempty"`    // SAM reported no opportunities in the window: nothing to do, not a failure
+}
+
+// SkippedPage records a SAM page that could not be fetched so it can be re-run later
+type SkippedPage struct {
+	PostedFrom string `json:"postedFrom"`
+	PostedTo   string `json:"postedTo"`
+	Offset     int    `json:"offset"`
+	Limit      int    `json:"limit"`
+	Error      string `json:"error"`
 }
 
 type IngestionService struct {
 	db        *pgxpool.Pool
 	samService *SAMService
+	pageSize   int // Records requested per SAM page (SAM_PAGE_SIZE)
+	pageRetry  RetryConfig
+	deadlineLoc *time.Location // Timezone date-only response deadlines close in (DEADLINE_TIMEZONE)
+	skipTypes   []string       // Notice types not stored at all (EXCLUDED_NOTICE_TYPES when INGEST_SKIP_EXCLUDED_TYPES is set)
+	rawDumpDir  string         // Directory each raw SAM page is written to, gzipped, for audit (SAM_RAW_DUMP_DIR); "" for none
+
+	// processOpportunity overrides ProcessOpportunity when set (used by tests to avoid a database)
+	processOpportunity func(ctx context.Context, opp models.Opportunity) (string, error)
+
+	// OnProcessed, when set, is called with each opportunity that was stored and how: "new", "updated" or
+	// "skipped". Excluded notices and ones that failed to store are not reported. During IngestOpportunities it
+	// runs once the opportunity's page has committed, in page order, so a retried page doesn't report twice and
+	// the hook can read the stored rows; ProcessOpportunity calls it before returning. Downstream work (event
+	// streams, description prefetch, webhooks) registers here, chaining with AddProcessedHook, rather than in
+	// ProcessOpportunity. Hooks get a ProcessedOpportunity rather than the full record, so holding a page's
+	// results until it commits stays cheap; one that needs more reads the stored row by notice ID.
+	OnProcessed func(ctx context.Context, p ProcessedOpportunity)
+}
+
+// ProcessedOpportunity is what an OnProcessed hook is told about a stored opportunity
+type ProcessedOpportunity struct {
+	NoticeID   string
+	Title      string
+	PostedDate string // As SAM sent it
+	Result     string // "new", "updated" or "skipped"
+}
+
+// processedFrom summarizes opp, stored with result, for the OnProcessed hooks
+func processedFrom(opp models.Opportunity, result string) ProcessedOpportunity {
+	return ProcessedOpportunity{NoticeID: opp.NoticeID, Title: opp.Title, PostedDate: opp.PostedDate, Result: result}
+}
+
+// AddProcessedHook chains hook after any OnProcessed already set
+func (s *IngestionService) AddProcessedHook(hook func(ctx context.Context, p ProcessedOpportunity)) {
+	prev := s.OnProcessed
+	if prev == nil {
+		s.OnProcessed = hook
+		return
+	}
+	s.OnProcessed = func(ctx context.Context, p ProcessedOpportunity) {
+		prev(ctx, p)
+		hook(ctx, p)
+	}
+}
+
+// SetEventEmitter registers a callback invoked whenever an opportunity is new or updated
+func (s *IngestionService) SetEventEmitter(emitter func(OpportunityEvent)) {
+	s.AddProcessedHook(func(ctx context.Context, p ProcessedOpportunity) {
+		if p.Result != "new" && p.Result != "updated" {
+			return
+		}
+		emitter(OpportunityEvent{
+			NoticeID:   p.NoticeID,
+			Title:      p.Title,
+			PostedDate: models.FormatAPIDate(p.PostedDate),
+			Action:     p.Result,
+			At:         time.Now(),
+		})
+	})
+}
+
+const (
+	defaultSAMPageSize = 100  // Default records per SAM page
+	maxSAMPageSize     = 1000 // SAM API maximum page size
+)
+
+// getSAMPageSize returns the SAM page size (from env or default), clamped to SAM's maximum
+func getSAMPageSize() int {
+	if sizeStr := os.Getenv("SAM_PAGE_SIZE"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil && size > 0 {
+			if size > maxSAMPageSize {
+				return maxSAMPageSize
+			}
+			return size
+		}
+	}
+	return defaultSAMPageSize
+}
+
+// maxExtraPages is how many pages past the first page's TotalRecords we will follow
+// when SAM reports a growing total mid-run (new items posting while we paginate)
+const maxExtraPages = 2
+
+// getIngestSkippedTypes returns the notice types ingestion should not store: EXCLUDED_NOTICE_TYPES when
+// INGEST_SKIP_EXCLUDED_TYPES is true, otherwise none (excluded types are stored and only filtered at query time)
+func getIngestSkippedTypes() []string {
+	if skip, err := strconv.ParseBool(os.Getenv("INGEST_SKIP_EXCLUDED_TYPES")); err == nil && skip {
+		return models.ExcludedNoticeTypes()
+	}
+	return nil
 }
 
 func NewIngestionService(db *pgxpool.Pool, samService *SAMService) *IngestionService {
 	return &IngestionService{
 		db:        db,
 		samService: samService,
+		pageSize:   getSAMPageSize(),
+		pageRetry:  DefaultRetryConfig(),
+		deadlineLoc: getDeadlineLocation(),
+		skipTypes:   getIngestSkippedTypes(),
+		rawDumpDir:  getRawDumpDir(),
 	}
 }
 
@@ -36,10 +150,16 @@ func NewIngestionService(db *pgxpool.Pool, samService *SAMService) *IngestionSer
 // handles pagination, and stores them in the database with change detection.
 func (s *IngestionService) IngestOpportunities(ctx context.Context, postedFrom, postedTo string) (*IngestionStats, error) {
 	stats := &IngestionStats{}
-	limit := 100 // SAM API limit per page
+	limit := s.pageSize
+	if limit <= 0 {
+		limit = defaultSAMPageSize
+	}
 	offset := 0
+	firstTotal := 0 // TotalRecords reported by the first page
+	lastTotal := 0  // Most recent TotalRecords, used to decide when to stop after a skipped page
+	maxPages := 0
 
-	for {
+	for page := 0; ; page++ {
 		// Build request for current page
 		req := models.OpportunitiesRequest{
 			PostedFrom: postedFrom,
@@ -49,34 +169,75 @@ func (s *IngestionService) IngestOpportunities(ctx context.Context, postedFrom,
 			PType:      "o", // Default to opportunities
 		}
 
-		// Fetch page from SAM API
-		response, err := s.samService.SearchOpportunities(req)
+		// Stream the page from SAM into the database, retrying transient failures; a failed attempt
+		// rolls back, so a retry starts the page over
+		var result *pageResult
+		err := Retry(ctx, s.pageRetry, func() error {
+			var pageErr error
+			result, pageErr = s.ingestPage(ctx, req)
+			return pageErr
+		})
 		if err != nil {
-			return stats, fmt.Errorf("failed to fetch opportunities: %w", err)
-		}
+			// Without the first page we don't know how many pages there are
+			if page == 0 {
+				return stats, fmt.Errorf("failed to fetch opportunities: %w", err)
+			}
+			// A rejected key or an exhausted quota fails every remaining page the same way
+			if errors.Is(err, ErrSAMAuth) || errors.Is(err, ErrSAMQuota) {
+				return stats, fmt.Errorf("failed to fetch opportunities at offset %d: %w", offset, err)
+			}
 
-		// Process each opportunity
-		for _, opp := range response.OpportunitiesData {
-			stats.Total++
-			result, err := s.ProcessOpportunity(ctx, opp)
-			if err != nil {
-				stats.Errors++
-				// Log error but continue processing
-				fmt.Printf("Error processing opportunity %s: %v\n", opp.NoticeID, err)
-				continue
+			// Record the page and move on rather than discarding the pages already ingested
+			fmt.Printf("Warning: skipping page at offset %d after retries: %v\n", offset, err)
+			stats.SkippedPages = append(stats.SkippedPages, SkippedPage{
+				PostedFrom: postedFrom,
+				PostedTo:   postedTo,
+				Offset:     offset,
+				Limit:      limit,
+				Error:      err.Error(),
+			})
+			if offset+limit >= lastTotal || page+1 >= maxPages {
+				break
 			}
-			switch result {
-			case "new":
-				stats.New++
-			case "updated":
-				stats.Updated++
-			case "skipped":
-				stats.Skipped++
+			offset += limit
+			continue
+		}
+		lastTotal = result.totalRecords
+
+		// Cap iterations based on the first page so a fluctuating total can't spin forever
+		if page == 0 {
+			firstTotal = result.totalRecords
+			maxPages = (firstTotal+limit-1)/limit + maxExtraPages
+		}
+
+		// A window with nothing posted in it is a normal outcome, but its all-zero stats look like a run that
+		// broke, so it is flagged for the caller to report separately
+		if page == 0 && result.totalRecords == 0 && result.read == 0 {
+			fmt.Printf("No opportunities in window %s to %s (SAM reported 0 records)\n", postedFrom, postedTo)
+			stats.NoRecords = true
+			break
+		}
+
+		// An empty page means SAM has nothing more for us, whatever TotalRecords says
+		if result.read == 0 {
+			break
+		}
+
+		// The page is committed: count it and report what was stored to the hooks
+		stats.add(result.stats)
+		if s.OnProcessed != nil {
+			for _, p := range result.processed {
+				s.OnProcessed(ctx, p)
 			}
 		}
 
 		// Check if we've fetched all pages
-		if offset+limit >= response.TotalRecords {
+		if offset+limit >= result.totalRecords {
+			break
+		}
+		if page+1 >= maxPages {
+			fmt.Printf("Warning: stopping pagination after %d pages (first page reported %d records, latest %d)\n",
+				page+1, firstTotal, result.totalRecords)
 			break
 		}
 
@@ -86,18 +247,150 @@ func (s *IngestionService) IngestOpportunities(ctx context.Context, postedFrom,
 	return stats, nil
 }
 
+// SkipsNoticeType reports whether opp's notice type is excluded from storage (INGEST_SKIP_EXCLUDED_TYPES)
+func (s *IngestionService) SkipsNoticeType(opp models.Opportunity) bool {
+	return models.IsExcludedNoticeType(opp, s.skipTypes)
+}
+
+// pageResult is what one SAM page contributed to a run, applied once the page is committed
+type pageResult struct {
+	totalRecords int // SAM's totalRecords as of this page
+	read         int // opportunities in the page, whatever happened to them
+	stats        IngestionStats
+	processed    []ProcessedOpportunity // stored opportunities in page order, kept only when OnProcessed is set
+}
+
+// add folds a committed page's counts into the run's stats
+func (st *IngestionStats) add(page IngestionStats) {
+	st.New += page.New
+	st.Updated += page.Updated
+	st.Skipped += page.Skipped
+	st.Excluded += page.Excluded
+	st.Errors += page.Errors
+	st.Total += page.Total
+}
+
+// ingestPage streams one SAM page into the database: each opportunity is stored as soon as it is decoded, so
+// peak memory is one opportunity rather than the page. The page runs in one transaction, so a page that fails
+// part way (a dropped connection, a truncated body) leaves nothing behind and is retried from the start.
+// Each opportunity gets its own savepoint, so one that fails to store is counted as an error without losing
+// the rest of the page.
+func (s *IngestionService) ingestPage(ctx context.Context, req models.OpportunitiesRequest) (*pageResult, error) {
+	store := s.processOpportunity
+	var tx pgx.Tx
+	if store == nil {
+		var err error
+		tx, err = s.db.Begin(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to begin page transaction: %w", err)
+		}
+		defer tx.Rollback(ctx)
+		store = func(ctx context.Context, opp models.Opportunity) (string, error) {
+			return s.processInSavepoint(ctx, tx, opp)
+		}
+	}
+
+	// With SAM_RAW_DUMP_DIR set, the response is written to disk as it streams in, ahead of each opportunity
+	// being stored; a response that fails part way leaves no file, and the page's retry writes a new one
+	var raw io.Writer
+	streamed := false
+	if s.rawDumpDir != "" {
+		if dump := openRawPageDump(s.rawDumpDir, req); dump != nil {
+			raw = dump
+			defer func() { dump.finish(streamed) }()
+		}
+	}
+
+	result := &pageResult{}
+	total, read, err := s.samService.streamOpportunities(req, raw, func(opp models.Opportunity) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		result.stats.Total++
+		if s.SkipsNoticeType(opp) {
+			result.stats.Excluded++
+			return nil
+		}
+		action, err := store(ctx, opp)
+		if err != nil {
+			result.stats.Errors++
+			// Log error but continue processing
+			fmt.Printf("Error processing opportunity %s: %v\n", opp.NoticeID, err)
+			return nil
+		}
+		switch action {
+		case "new":
+			result.stats.New++
+		case "updated":
+			result.stats.Updated++
+		case "skipped":
+			result.stats.Skipped++
+		}
+		if s.OnProcessed != nil {
+			result.processed = append(result.processed, processedFrom(opp, action))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	streamed = true
+	result.totalRecords, result.read = total, read
+
+	if tx != nil {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("failed to commit page: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// processInSavepoint stores one opportunity inside the page transaction under a savepoint, rolling back
+// just that opportunity if it fails
+func (s *IngestionService) processInSavepoint(ctx context.Context, tx pgx.Tx, opp models.Opportunity) (string, error) {
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin savepoint: %w", err)
+	}
+	defer sp.Rollback(ctx)
+
+	action, err := s.processOpportunityIn(ctx, sp, opp)
+	if err != nil {
+		return "", err
+	}
+	if err := sp.Commit(ctx); err != nil {
+		return "", fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return action, nil
+}
+
+// ingestDB is what storing an opportunity needs: the pool, or a page transaction
+type ingestDB interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // ProcessOpportunity processes a single opportunity: computes hash, checks for changes,
-// and updates the database accordingly.
+// and updates the database accordingly, then reports the result to OnProcessed.
 // Returns "new", "updated", or "skipped" to indicate what action was taken.
 func (s *IngestionService) ProcessOpportunity(ctx context.Context, opp models.Opportunity) (string, error) {
+	action, err := s.processOpportunityIn(ctx, s.db, opp)
+	if err == nil && s.OnProcessed != nil {
+		s.OnProcessed(ctx, processedFrom(opp, action))
+	}
+	return action, err
+}
+
+// processOpportunityIn is ProcessOpportunity against q, the pool or a page transaction
+func (s *IngestionService) processOpportunityIn(ctx context.Context, q ingestDB, opp models.Opportunity) (string, error) {
 	// Compute content hash
 	hash, err := s.computeContentHash(opp)
 	if err != nil {
 		return "", fmt.Errorf("failed to compute hash: %w", err)
 	}
 
-	// Serialize raw data for storage
-	rawData, err := json.Marshal(opp)
+	// Serialize raw data for storage (as received; API date formatting is for responses only)
+	rawData, err := opp.MarshalRaw()
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal raw data: %w", err)
 	}
@@ -105,7 +398,7 @@ func (s *IngestionService) ProcessOpportunity(ctx context.Context, opp models.Op
 	// Check if opportunity exists
 	var existingHash string
 	var exists bool
-	err = s.db.QueryRow(ctx, 
+	err = q.QueryRow(ctx, 
 		"SELECT content_hash FROM opportunity WHERE notice_id = $1",
 		opp.NoticeID,
 	).Scan(&existingHash)
@@ -122,7 +415,7 @@ func (s *IngestionService) ProcessOpportunity(ctx context.Context, opp models.Op
 	if !exists {
 		// New opportunity - insert into both tables
 		// Insert into opportunity_raw
-		_, err = s.db.Exec(ctx, `
+		_, err = q.Exec(ctx, `
 			INSERT INTO opportunity_raw (notice_id, raw_data, fetched_at)
 			VALUES ($1, $2, $3)
 			ON CONFLICT (notice_id) DO UPDATE SET
@@ -134,7 +427,7 @@ func (s *IngestionService) ProcessOpportunity(ctx context.Context, opp models.Op
 		}
 
 		// Insert into opportunity
-		err = s.insertOpportunity(ctx, opp, hash, now, now)
+		err = s.insertOpportunity(ctx, q, opp, hash, now, now)
 		if err != nil {
 			return "", fmt.Errorf("failed to insert opportunity: %w", err)
 		}
@@ -142,7 +435,7 @@ func (s *IngestionService) ProcessOpportunity(ctx context.Context, opp models.Op
 	} else if existingHash != hash {
 		// Opportunity exists but hash changed - update
 		// Update opportunity_raw first
-		_, err = s.db.Exec(ctx, `
+		_, err = q.Exec(ctx, `
 			UPDATE opportunity_raw
 			SET raw_data = $1, fetched_at = $2
 			WHERE notice_id = $3
@@ -152,7 +445,7 @@ func (s *IngestionService) ProcessOpportunity(ctx context.Context, opp models.Op
 		}
 
 		// Insert version log with new hash and new raw snapshot (as per plan)
-		_, err = s.db.Exec(ctx, `
+		_, err = q.Exec(ctx, `
 			INSERT INTO opportunity_version (notice_id, content_hash, raw_snapshot, fetched_at)
 			VALUES ($1, $2, $3, $4)
 		`, opp.NoticeID, hash, rawData, now)
@@ -161,7 +454,7 @@ func (s *IngestionService) ProcessOpportunity(ctx context.Context, opp models.Op
 		}
 
 		// Update opportunity
-		err = s.updateOpportunity(ctx, opp, hash, now)
+		err = s.updateOpportunity(ctx, q, opp, hash, now)
 		if err != nil {
 			return "", fmt.Errorf("failed to update opportunity: %w", err)
 		}
@@ -173,6 +466,12 @@ func (s *IngestionService) ProcessOpportunity(ctx context.Context, opp models.Op
 
 // computeContentHash computes SHA256 hash of all normalized fields (excluding metadata fields).
 func (s *IngestionService) computeContentHash(opp models.Opportunity) (string, error) {
+	return ComputeOpportunityHash(opp)
+}
+
+// ComputeOpportunityHash is the change-detection hash stored in opportunity.content_hash
+// Exported so ops tools can re-hash stored raw data the same way ingestion does
+func ComputeOpportunityHash(opp models.Opportunity) (string, error) {
 	// Create a struct with only the fields we care about for change detection
 	hashData := struct {
 		NoticeID          string `json:"noticeId"`
@@ -231,55 +530,99 @@ func (s *IngestionService) computeContentHash(opp models.Opportunity) (string, e
 	return hex.EncodeToString(hash[:]), nil
 }
 
+// storedDeadline is the response_deadline column value: date-only deadlines become end of day
+// in deadlineLoc. The content hash and opportunity_raw keep SAM's original value.
+func (s *IngestionService) storedDeadline(deadline string) string {
+	loc := s.deadlineLoc
+	if loc == nil {
+		loc = time.UTC
+	}
+	return NormalizeResponseDeadline(deadline, loc)
+}
+
+// archivedAsOf reports whether opp counts as archived (models.IsArchived) on now's date in deadlineLoc, which
+// decides the archived_at column: set when the row is stored archived, kept while it stays archived, cleared if
+// SAM reactivates it
+func (s *IngestionService) archivedAsOf(opp models.Opportunity, now time.Time) bool {
+	loc := s.deadlineLoc
+	if loc == nil {
+		loc = time.UTC
+	}
+	return models.IsArchived(opp, now.In(loc).Format("2006-01-02"))
+}
+
 // insertOpportunity inserts a new opportunity into the database.
-func (s *IngestionService) insertOpportunity(ctx context.Context, opp models.Opportunity, hash string, firstSeen, lastUpdated time.Time) error {
+func (s *IngestionService) insertOpportunity(ctx context.Context, q ingestDB, opp models.Opportunity, hash string, firstSeen, lastUpdated time.Time) error {
 	naicsJSON, _ := json.Marshal(opp.NAICS)
 	contactJSON, _ := json.Marshal(opp.PointOfContact)
-	placeJSON, _ := json.Marshal(opp.PlaceOfPerformance)
+	placeJSON, _ := opp.PlaceOfPerformanceJSON()
 	linksJSON, _ := json.Marshal(opp.Links)
 
-	_, err := s.db.Exec(ctx, `
+	title, titleSynthesized := StoredTitle(opp)
+
+	_, err := q.Exec(ctx, `
 		INSERT INTO opportunity (
 			notice_id, title, organization_type, posted_date, type, base_type,
 			archive_type, archive_date, type_of_set_aside, type_of_set_aside_desc,
 			response_deadline, naics, classification_code, active,
 			point_of_contact, place_of_performance, description, department,
-			sub_tier, office, links, content_hash, first_seen, last_updated
+			sub_tier, office, links, content_hash, first_seen, last_updated, title_synthesized,
+			procurement_type, sam_ui_url, archived_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25,
+			NULLIF($26, ''), NULLIF($27, ''), CASE WHEN $28 THEN $24::timestamptz END
 		)
 	`,
-		opp.NoticeID, opp.Title, opp.OrganizationType, opp.PostedDate, opp.Type, opp.BaseType,
+		opp.NoticeID, title, opp.OrganizationType, opp.PostedDate, opp.Type, opp.BaseType,
 		opp.ArchiveType, opp.ArchiveDate, opp.TypeOfSetAside, opp.TypeOfSetAsideDesc,
-		opp.ResponseDeadline, naicsJSON, opp.ClassificationCode, opp.Active.Bool(),
+		s.storedDeadline(opp.ResponseDeadline), naicsJSON, opp.ClassificationCode, opp.Active.Bool(),
 		contactJSON, placeJSON, opp.Description, opp.Department,
-		opp.SubTier, opp.Office, linksJSON, hash, firstSeen, lastUpdated,
+		opp.SubTier, opp.Office, linksJSON, hash, firstSeen, lastUpdated, titleSynthesized,
+		models.ProcurementType(opp), models.SAMUIURL(opp), s.archivedAsOf(opp, lastUpdated),
 	)
 
 	return err
 }
 
+// StoredTitle returns the title to store for opp. SAM occasionally sends a notice with no title; rather than
+// store a blank one, it gets a placeholder built from the solicitation number (or notice ID), and the second
+// result reports that the title was synthesized. opportunity_raw and the content hash keep SAM's value.
+func StoredTitle(opp models.Opportunity) (string, bool) {
+	if strings.TrimSpace(opp.Title) != "" {
+		return opp.Title, false
+	}
+	if opp.SolicitationNumber != nil && strings.TrimSpace(*opp.SolicitationNumber) != "" {
+		return fmt.Sprintf("Untitled (solicitation %s)", strings.TrimSpace(*opp.SolicitationNumber)), true
+	}
+	return fmt.Sprintf("Untitled (notice %s)", opp.NoticeID), true
+}
+
 // updateOpportunity updates an existing opportunity in the database.
-func (s *IngestionService) updateOpportunity(ctx context.Context, opp models.Opportunity, hash string, lastUpdated time.Time) error {
+func (s *IngestionService) updateOpportunity(ctx context.Context, q ingestDB, opp models.Opportunity, hash string, lastUpdated time.Time) error {
 	naicsJSON, _ := json.Marshal(opp.NAICS)
 	contactJSON, _ := json.Marshal(opp.PointOfContact)
-	placeJSON, _ := json.Marshal(opp.PlaceOfPerformance)
+	placeJSON, _ := opp.PlaceOfPerformanceJSON()
 	linksJSON, _ := json.Marshal(opp.Links)
 
-	_, err := s.db.Exec(ctx, `
+	title, titleSynthesized := StoredTitle(opp)
+
+	_, err := q.Exec(ctx, `
 		UPDATE opportunity SET
 			title = $2, organization_type = $3, posted_date = $4, type = $5, base_type = $6,
 			archive_type = $7, archive_date = $8, type_of_set_aside = $9, type_of_set_aside_desc = $10,
 			response_deadline = $11, naics = $12, classification_code = $13, active = $14,
 			point_of_contact = $15, place_of_performance = $16, description = $17, department = $18,
-			sub_tier = $19, office = $20, links = $21, content_hash = $22, last_updated = $23
+			sub_tier = $19, office = $20, links = $21, content_hash = $22, last_updated = $23,
+			title_synthesized = $24, procurement_type = NULLIF($25, ''), sam_ui_url = NULLIF($26, ''),
+			archived_at = CASE WHEN $27 THEN COALESCE(archived_at, $23) END
 		WHERE notice_id = $1
 	`,
-		opp.NoticeID, opp.Title, opp.OrganizationType, opp.PostedDate, opp.Type, opp.BaseType,
+		opp.NoticeID, title, opp.OrganizationType, opp.PostedDate, opp.Type, opp.BaseType,
 		opp.ArchiveType, opp.ArchiveDate, opp.TypeOfSetAside, opp.TypeOfSetAsideDesc,
-		opp.ResponseDeadline, naicsJSON, opp.ClassificationCode, opp.Active.Bool(),
+		s.storedDeadline(opp.ResponseDeadline), naicsJSON, opp.ClassificationCode, opp.Active.Bool(),
 		contactJSON, placeJSON, opp.Description, opp.Department,
-		opp.SubTier, opp.Office, linksJSON, hash, lastUpdated,
+		opp.SubTier, opp.Office, linksJSON, hash, lastUpdated, titleSynthesized,
+		models.ProcurementType(opp), models.SAMUIURL(opp), s.archivedAsOf(opp, lastUpdated),
 	)
 
 	return err
@@ -0,0 +1,801 @@
+package services
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/samtest"
+	"govcon/api/internal/testdb"
+)
+
+// newTestIngestionService wires an IngestionService to the SAM search at baseURL and records processed notice IDs
+func newTestIngestionService(baseURL string) (*IngestionService, *[]string) {
+	var processed []string
+	svc := &IngestionService{
+		samService: &SAMService{APIKey: "test", BaseURL: baseURL},
+		pageRetry:  RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+		processOpportunity: func(ctx context.Context, opp models.Opportunity) (string, error) {
+			processed = append(processed, opp.NoticeID)
+			return "new", nil
+		},
+	}
+	return svc, &processed
+}
+
+// numberedOpportunity is the fixture record N000, N001, ... at index i
+func numberedOpportunity(i int) map[string]any {
+	return samtest.Opportunity(fmt.Sprintf("N%03d", i), "2025-01-15")
+}
+
+// addNumberedOpportunities adds n numbered records to sam's search results
+func addNumberedOpportunities(sam *samtest.Server, n int) {
+	for i := 0; i < n; i++ {
+		sam.AddOpportunities(numberedOpportunity(i))
+	}
+}
+
+// searchPage is a search response reporting total, carrying the numbered records in [offset, min(offset+limit, total))
+// Queue it with QueueSearch to script what a particular request sees
+func searchPage(limit, offset, total int) samtest.Response {
+	opps := []map[string]any{}
+	for i := offset; i < offset+limit && i < total; i++ {
+		opps = append(opps, numberedOpportunity(i))
+	}
+	return samtest.JSON(map[string]any{"totalRecords": total, "opportunitiesData": opps})
+}
+
+// truncated cuts resp's body off half way, as a connection dropped mid-page would
+func truncated(resp samtest.Response) samtest.Response {
+	resp.Body = resp.Body[:len(resp.Body)/2]
+	return resp
+}
+
+func TestIngestOpportunities_ShortFinalPage(t *testing.T) {
+	// 250 records: two full pages then a short page of 50
+	sam := samtest.NewServer(t)
+	addNumberedOpportunities(sam, 250)
+	svc, processed := newTestIngestionService(sam.SearchURL())
+
+	stats, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stats.Total != 250 || len(*processed) != 250 {
+		t.Errorf("Expected 250 processed, got stats.Total=%d processed=%d", stats.Total, len(*processed))
+	}
+	if n := len(sam.SearchRequests()); n != 3 {
+		t.Errorf("Expected 3 SAM calls, got %d", n)
+	}
+}
+
+func TestIngestOpportunities_EmptyPageStops(t *testing.T) {
+	// SAM claims 500 records but runs dry after the first page
+	sam := samtest.NewServer(t)
+	addNumberedOpportunities(sam, 100)
+	sam.SetTotalRecords(500)
+	svc, processed := newTestIngestionService(sam.SearchURL())
+
+	if _, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(*processed) != 100 {
+		t.Errorf("Expected 100 processed, got %d", len(*processed))
+	}
+	if n := len(sam.SearchRequests()); n != 2 {
+		t.Errorf("Expected 2 SAM calls, got %d", n)
+	}
+}
+
+func TestIngestOpportunities_ZeroRecordsIsNoRecords(t *testing.T) {
+	sam := samtest.NewServer(t)
+	svc, processed := newTestIngestionService(sam.SearchURL())
+
+	stats, err := svc.IngestOpportunities(context.Background(), "12/25/2025", "12/25/2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !stats.NoRecords {
+		t.Error("Expected a window with no records to be flagged NoRecords")
+	}
+	if stats.Total != 0 || len(*processed) != 0 || len(stats.SkippedPages) != 0 {
+		t.Errorf("Expected nothing processed, got stats=%+v processed=%d", stats, len(*processed))
+	}
+	if n := len(sam.SearchRequests()); n != 1 {
+		t.Errorf("Expected 1 SAM call, got %d", n)
+	}
+}
+
+func TestIngestOpportunities_NoRecordsOnlyWhenSAMReportsZero(t *testing.T) {
+	tests := []struct {
+		name    string
+		records int
+		total   int
+	}{
+		// Records were ingested before SAM ran dry
+		{"empty later page", 100, 500},
+		// SAM claims records but the first page is empty: suspicious, not "nothing to do"
+		{"empty first page with a total", 0, 40},
+		// A zero total with data is still data
+		{"zero total with data", 1, 0},
+	}
+	for _, tt := range tests {
+		sam := samtest.NewServer(t)
+		addNumberedOpportunities(sam, tt.records)
+		sam.SetTotalRecords(tt.total)
+		svc, _ := newTestIngestionService(sam.SearchURL())
+
+		stats, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025")
+		if err != nil {
+			t.Fatalf("%s: Expected no error, got %v", tt.name, err)
+		}
+		if stats.NoRecords {
+			t.Errorf("%s: Expected NoRecords false, got stats=%+v", tt.name, stats)
+		}
+	}
+}
+
+func TestIngestOpportunities_FirstPageFailureIsNotNoRecords(t *testing.T) {
+	sam := samtest.NewServer(t)
+	sam.QueueSearch(samtest.Status(http.StatusServiceUnavailable))
+	svc, _ := newTestIngestionService(sam.SearchURL())
+	svc.pageRetry = RetryConfig{MaxAttempts: 1, InitialBackoff: time.Millisecond}
+
+	stats, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025")
+	if err == nil {
+		t.Fatal("Expected an error when the first page fails")
+	}
+	if stats.NoRecords {
+		t.Error("Expected a failed run not to be flagged NoRecords")
+	}
+}
+
+func TestIngestOpportunities_ShiftingTotalIsCapped(t *testing.T) {
+	// Every call reports a larger total, as if new items keep posting; pagination must not run away
+	sam := samtest.NewServer(t)
+	for call := 0; call < 10; call++ {
+		sam.QueueSearch(searchPage(100, call*100, 200+call*1000))
+	}
+	svc, _ := newTestIngestionService(sam.SearchURL())
+
+	if _, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// First page reports 200 records (2 pages) plus maxExtraPages of slack
+	if expected, n := 2+maxExtraPages, len(sam.SearchRequests()); n != expected {
+		t.Errorf("Expected %d SAM calls, got %d", expected, n)
+	}
+}
+
+func TestIngestOpportunities_ShrinkingTotalStopsEarly(t *testing.T) {
+	// Total drops after the first page (items archived mid-run)
+	sam := samtest.NewServer(t)
+	sam.QueueSearch(searchPage(100, 0, 300))
+	addNumberedOpportunities(sam, 150)
+	svc, processed := newTestIngestionService(sam.SearchURL())
+
+	if _, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n := len(sam.SearchRequests()); n != 2 {
+		t.Errorf("Expected 2 SAM calls, got %d", n)
+	}
+	if len(*processed) != 150 {
+		t.Errorf("Expected 150 processed, got %d", len(*processed))
+	}
+}
+
+func TestIngestOpportunities_SmallPagesCoverAllRecordsOnce(t *testing.T) {
+	sam := samtest.NewServer(t)
+	addNumberedOpportunities(sam, 7)
+	svc, processed := newTestIngestionService(sam.SearchURL())
+	svc.pageSize = 3
+
+	if _, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	requests := sam.SearchRequests()
+	if len(requests) != 3 {
+		t.Errorf("Expected 3 SAM calls, got %d", len(requests))
+	}
+	for _, query := range requests {
+		if l := query.Get("limit"); l != "3" {
+			t.Errorf("Expected limit=3 on every request, got %s", l)
+		}
+	}
+
+	seen := make(map[string]int)
+	for _, id := range *processed {
+		seen[id]++
+	}
+	if len(seen) != 7 {
+		t.Errorf("Expected 7 distinct records, got %d", len(seen))
+	}
+	for id, n := range seen {
+		if n != 1 {
+			t.Errorf("Expected %s processed once, got %d", id, n)
+		}
+	}
+}
+
+func TestGetSAMPageSize(t *testing.T) {
+	cases := map[string]int{
+		"":     defaultSAMPageSize,
+		"250":  250,
+		"5000": maxSAMPageSize,
+		"0":    defaultSAMPageSize,
+		"abc":  defaultSAMPageSize,
+	}
+	for value, expected := range cases {
+		t.Setenv("SAM_PAGE_SIZE", value)
+		if got := getSAMPageSize(); got != expected {
+			t.Errorf("SAM_PAGE_SIZE=%q: expected %d, got %d", value, expected, got)
+		}
+	}
+}
+
+func TestIngestOpportunities_PersistentlyFailingPageIsSkipped(t *testing.T) {
+	// Page at offset 10 returns 503 on every attempt; every other page succeeds
+	sam := samtest.NewServer(t)
+	addNumberedOpportunities(sam, 30)
+	svc, processed := newTestIngestionService(sam.SearchURL())
+	svc.pageSize = 10
+	sam.QueueSearch(searchPage(10, 0, 30))
+	for i := 0; i < svc.pageRetry.MaxAttempts; i++ {
+		sam.QueueSearch(samtest.Status(http.StatusServiceUnavailable))
+	}
+
+	stats, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	failingCalls := 0
+	for _, query := range sam.SearchRequests() {
+		if query.Get("offset") == "10" {
+			failingCalls++
+		}
+	}
+	if failingCalls != svc.pageRetry.MaxAttempts {
+		t.Errorf("Expected %d attempts for the failing page, got %d", svc.pageRetry.MaxAttempts, failingCalls)
+	}
+	if len(*processed) != 20 {
+		t.Errorf("Expected 20 processed from the good pages, got %d", len(*processed))
+	}
+	if len(stats.SkippedPages) != 1 {
+		t.Fatalf("Expected 1 skipped page, got %d", len(stats.SkippedPages))
+	}
+	sp := stats.SkippedPages[0]
+	if sp.Offset != 10 || sp.Limit != 10 {
+		t.Errorf("Expected skipped page offset=10 limit=10, got offset=%d limit=%d", sp.Offset, sp.Limit)
+	}
+	if sp.PostedFrom != "01/01/2025" || sp.PostedTo != "01/31/2025" {
+		t.Errorf("Expected skipped page window 01/01/2025-01/31/2025, got %s-%s", sp.PostedFrom, sp.PostedTo)
+	}
+}
+
+func TestIngestOpportunities_FirstPageFailureAborts(t *testing.T) {
+	sam := samtest.NewServer(t)
+	svc, _ := newTestIngestionService(sam.SearchURL())
+	for i := 0; i < svc.pageRetry.MaxAttempts; i++ {
+		sam.QueueSearch(samtest.Status(http.StatusServiceUnavailable))
+	}
+
+	if _, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025"); err == nil {
+		t.Error("Expected error when the first page cannot be fetched")
+	}
+}
+
+func TestRetry_StopsOnNonRetryableError(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), RetryConfig{MaxAttempts: 5, InitialBackoff: time.Millisecond}, func() error {
+		calls++
+		return &HTTPStatusError{StatusCode: 400, Body: "bad request"}
+	})
+	if err == nil {
+		t.Error("Expected error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetry_SucceedsAfterTransientErrors(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond}, func() error {
+		calls++
+		if calls < 3 {
+			return &HTTPStatusError{StatusCode: 503, Body: "unavailable"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestIsRetryableError_RequestTimeoutStatus(t *testing.T) {
+	if !IsRetryableError(&HTTPStatusError{StatusCode: 408}) {
+		t.Error("Expected 408 to be retryable")
+	}
+}
+
+func TestIsRetryableError_MatchesStatusCodeNotBody(t *testing.T) {
+	err := &HTTPStatusError{StatusCode: 404, Body: "notice 500ABC123 not found"}
+	if IsRetryableError(err) {
+		t.Error("Expected 404 with \"500\" in the body to be non-retryable")
+	}
+	// Wrapped errors are matched on the code as well
+	if !IsRetryableError(fmt.Errorf("failed to fetch opportunities: %w", &HTTPStatusError{StatusCode: 522})) {
+		t.Error("Expected wrapped 522 to be retryable")
+	}
+	// An untyped error mentioning 500 is no longer treated as a 500 response
+	if IsRetryableError(fmt.Errorf("invalid notice ID 500XYZ")) {
+		t.Error("Expected untyped error containing \"500\" to be non-retryable")
+	}
+}
+
+func TestIsRetryableError_ConfiguredStatuses(t *testing.T) {
+	t.Setenv("RETRYABLE_HTTP_STATUSES", "418, 503")
+	if !IsRetryableError(&HTTPStatusError{StatusCode: 418}) {
+		t.Error("Expected configured 418 to be retryable")
+	}
+	if IsRetryableError(&HTTPStatusError{StatusCode: 500}) {
+		t.Error("Expected 500 to be non-retryable when not configured")
+	}
+
+	t.Setenv("RETRYABLE_HTTP_STATUSES", "bogus")
+	if !IsRetryableError(&HTTPStatusError{StatusCode: 429}) {
+		t.Error("Expected defaults when RETRYABLE_HTTP_STATUSES has no valid codes")
+	}
+}
+
+func TestStoredTitle(t *testing.T) {
+	sol := "W912-25-Q-0001"
+	blank := "  "
+	cases := []struct {
+		opp         models.Opportunity
+		title       string
+		synthesized bool
+	}{
+		{models.Opportunity{NoticeID: "N1", Title: "Janitorial Services"}, "Janitorial Services", false},
+		{models.Opportunity{NoticeID: "N1", Title: " ", SolicitationNumber: &sol}, "Untitled (solicitation W912-25-Q-0001)", true},
+		{models.Opportunity{NoticeID: "N1", SolicitationNumber: &blank}, "Untitled (notice N1)", true},
+		{models.Opportunity{NoticeID: "N1"}, "Untitled (notice N1)", true},
+	}
+	for _, c := range cases {
+		title, synthesized := StoredTitle(c.opp)
+		if title != c.title || synthesized != c.synthesized {
+			t.Errorf("Expected (%q, %v), got (%q, %v)", c.title, c.synthesized, title, synthesized)
+		}
+	}
+}
+
+func TestProcessOpportunity_TitlelessIsStoredWithPlaceholder(t *testing.T) {
+	pool := testdb.Open(t)
+	testdb.Migrate(t, pool)
+	ctx := context.Background()
+	svc := &IngestionService{db: pool}
+
+	action, err := svc.ProcessOpportunity(ctx, models.Opportunity{NoticeID: "NOTITLE", Type: "Solicitation"})
+	if err != nil || action != "new" {
+		t.Fatalf("Expected titleless opportunity to be inserted, got %q (%v)", action, err)
+	}
+
+	var title string
+	var synthesized bool
+	if err := pool.QueryRow(ctx, "SELECT title, title_synthesized FROM opportunity WHERE notice_id = 'NOTITLE'").Scan(&title, &synthesized); err != nil {
+		t.Fatalf("Failed to read opportunity: %v", err)
+	}
+	if title != "Untitled (notice NOTITLE)" || !synthesized {
+		t.Errorf("Expected flagged placeholder title, got %q (synthesized=%v)", title, synthesized)
+	}
+
+	// A later version with a real title clears the flag
+	if _, err := svc.ProcessOpportunity(ctx, models.Opportunity{NoticeID: "NOTITLE", Type: "Solicitation", Title: "Grounds Maintenance"}); err != nil {
+		t.Fatalf("Failed to update opportunity: %v", err)
+	}
+	pool.QueryRow(ctx, "SELECT title, title_synthesized FROM opportunity WHERE notice_id = 'NOTITLE'").Scan(&title, &synthesized)
+	if title != "Grounds Maintenance" || synthesized {
+		t.Errorf("Expected real title without flag, got %q (synthesized=%v)", title, synthesized)
+	}
+}
+
+func TestProcessOpportunity_StoresProcurementType(t *testing.T) {
+	pool := testdb.Open(t)
+	testdb.Migrate(t, pool)
+	ctx := context.Background()
+	svc := &IngestionService{db: pool}
+
+	read := func() *string {
+		t.Helper()
+		var procurementType *string
+		if err := pool.QueryRow(ctx, "SELECT procurement_type FROM opportunity WHERE notice_id = 'N1'").Scan(&procurementType); err != nil {
+			t.Fatalf("Failed to read opportunity: %v", err)
+		}
+		return procurementType
+	}
+
+	opp := models.Opportunity{NoticeID: "N1", Title: "Janitorial", Type: "Combined Synopsis/Solicitation", BaseType: "Combined Synopsis/Solicitation"}
+	if _, err := svc.ProcessOpportunity(ctx, opp); err != nil {
+		t.Fatalf("Failed to insert opportunity: %v", err)
+	}
+	if got := read(); got == nil || *got != "k" {
+		t.Errorf("Expected procurement type k, got %v", got)
+	}
+
+	// The award notice keeps the procurement type; its own type is stored as sent
+	opp.Type = "Award Notice"
+	if _, err := svc.ProcessOpportunity(ctx, opp); err != nil {
+		t.Fatalf("Failed to update opportunity: %v", err)
+	}
+	if got := read(); got == nil || *got != "k" {
+		t.Errorf("Expected procurement type k after the award, got %v", got)
+	}
+
+	opp.Type, opp.BaseType = "Something New", ""
+	if _, err := svc.ProcessOpportunity(ctx, opp); err != nil {
+		t.Fatalf("Failed to update opportunity: %v", err)
+	}
+	if got := read(); got != nil {
+		t.Errorf("Expected NULL for an unknown notice type, got %q", *got)
+	}
+}
+
+func TestProcessOpportunity_StoresSAMUIURL(t *testing.T) {
+	pool := testdb.Open(t)
+	testdb.Migrate(t, pool)
+	ctx := context.Background()
+	svc := &IngestionService{db: pool}
+
+	read := func(noticeID string) string {
+		t.Helper()
+		var samUIURL *string
+		if err := pool.QueryRow(ctx, "SELECT sam_ui_url FROM opportunity WHERE notice_id = $1", noticeID).Scan(&samUIURL); err != nil {
+			t.Fatalf("Failed to read opportunity: %v", err)
+		}
+		if samUIURL == nil {
+			return ""
+		}
+		return *samUIURL
+	}
+
+	uiLink := "https://sam.gov/opp/N1/view"
+	if _, err := svc.ProcessOpportunity(ctx, models.Opportunity{NoticeID: "N1", Title: "Janitorial", UILink: &uiLink}); err != nil {
+		t.Fatalf("Failed to insert opportunity: %v", err)
+	}
+	if got := read("N1"); got != uiLink {
+		t.Errorf("Expected SAM's uiLink stored, got %q", got)
+	}
+
+	// A uiLink that isn't a sam.gov page is replaced by the link derived from the notice ID
+	offsite := "https://example.com/N2"
+	if _, err := svc.ProcessOpportunity(ctx, models.Opportunity{NoticeID: "N2", Title: "Grounds", UILink: &offsite}); err != nil {
+		t.Fatalf("Failed to insert opportunity: %v", err)
+	}
+	if got := read("N2"); got != "https://sam.gov/opp/N2/view" {
+		t.Errorf("Expected the derived deep link, got %q", got)
+	}
+}
+
+func TestProcessOpportunity_SetsArchivedAt(t *testing.T) {
+	pool := testdb.Open(t)
+	testdb.Migrate(t, pool)
+	ctx := context.Background()
+	svc := &IngestionService{db: pool}
+
+	read := func() *time.Time {
+		t.Helper()
+		var archivedAt *time.Time
+		if err := pool.QueryRow(ctx, "SELECT archived_at FROM opportunity WHERE notice_id = 'N1'").Scan(&archivedAt); err != nil {
+			t.Fatalf("Failed to read opportunity: %v", err)
+		}
+		return archivedAt
+	}
+
+	opp := models.Opportunity{NoticeID: "N1", Title: "Janitorial", Active: true, ArchiveDate: "2999-01-01"}
+	if _, err := svc.ProcessOpportunity(ctx, opp); err != nil {
+		t.Fatalf("Failed to insert opportunity: %v", err)
+	}
+	if got := read(); got != nil {
+		t.Errorf("Expected no archived_at for an active opportunity, got %v", *got)
+	}
+
+	opp.Active = false
+	if _, err := svc.ProcessOpportunity(ctx, opp); err != nil {
+		t.Fatalf("Failed to update opportunity: %v", err)
+	}
+	archivedAt := read()
+	if archivedAt == nil {
+		t.Fatal("Expected archived_at set once SAM marks the opportunity inactive")
+	}
+
+	// A later change while still archived keeps the time it was first archived
+	opp.Title = "Janitorial (amended)"
+	if _, err := svc.ProcessOpportunity(ctx, opp); err != nil {
+		t.Fatalf("Failed to update opportunity: %v", err)
+	}
+	if got := read(); got == nil || !got.Equal(*archivedAt) {
+		t.Errorf("Expected archived_at kept at %v, got %v", *archivedAt, got)
+	}
+
+	// Reactivated by SAM: no longer archived
+	opp.Active = true
+	if _, err := svc.ProcessOpportunity(ctx, opp); err != nil {
+		t.Fatalf("Failed to update opportunity: %v", err)
+	}
+	if got := read(); got != nil {
+		t.Errorf("Expected archived_at cleared on reactivation, got %v", *got)
+	}
+
+	// An archive date already past counts as archived on insert
+	if _, err := svc.ProcessOpportunity(ctx, models.Opportunity{NoticeID: "N2", Title: "Grounds", Active: true, ArchiveDate: "2020-01-01"}); err != nil {
+		t.Fatalf("Failed to insert opportunity: %v", err)
+	}
+	var archived bool
+	if err := pool.QueryRow(ctx, "SELECT archived_at IS NOT NULL FROM opportunity WHERE notice_id = 'N2'").Scan(&archived); err != nil {
+		t.Fatalf("Failed to read opportunity: %v", err)
+	}
+	if !archived {
+		t.Error("Expected archived_at set for an opportunity whose archive date has passed")
+	}
+}
+
+func TestIngestOpportunities_OnProcessedReportsResults(t *testing.T) {
+	// Two pages, so the hook runs once per committed page
+	sam := samtest.NewServer(t)
+	addNumberedOpportunities(sam, 4)
+	svc, _ := newTestIngestionService(sam.SearchURL())
+	svc.pageSize = 2
+	results := map[string]string{"N000": "new", "N001": "skipped", "N003": "updated"}
+	svc.processOpportunity = func(ctx context.Context, opp models.Opportunity) (string, error) {
+		if opp.NoticeID == "N002" {
+			return "", errors.New("store failed")
+		}
+		return results[opp.NoticeID], nil
+	}
+
+	var reported []string
+	svc.OnProcessed = func(ctx context.Context, p ProcessedOpportunity) {
+		reported = append(reported, p.NoticeID+"="+p.Result)
+	}
+	var emitted []string
+	svc.SetEventEmitter(func(ev OpportunityEvent) { emitted = append(emitted, ev.NoticeID) })
+
+	stats, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The failed N002 is counted as an error and not reported
+	if got := strings.Join(reported, ","); got != "N000=new,N001=skipped,N003=updated" {
+		t.Errorf("Expected each stored opportunity reported with its result, got %s", got)
+	}
+	if stats.Errors != 1 {
+		t.Errorf("Expected 1 error, got %d", stats.Errors)
+	}
+	// The event emitter chains after the hook set before it and still only sees new and updated
+	if got := strings.Join(emitted, ","); got != "N000,N003" {
+		t.Errorf("Expected events for N000 and N003, got %s", got)
+	}
+}
+
+func TestIngestionService_OnProcessedDefaultsToNil(t *testing.T) {
+	if svc := NewIngestionService(nil, nil); svc.OnProcessed != nil {
+		t.Error("Expected no OnProcessed hook by default")
+	}
+}
+
+func TestIngestOpportunities_SkipsExcludedTypes(t *testing.T) {
+	sam := samtest.NewServer(t)
+	award := samtest.Opportunity("AWARD", "2025-01-15")
+	award["type"], award["baseType"] = "Award Notice", "Award Notice"
+	amended := samtest.Opportunity("AMENDED", "2025-01-15")
+	amended["type"], amended["baseType"] = "Special Notice", "Justification"
+	sam.AddOpportunities(samtest.Opportunity("SOL", "2025-01-15"), award, amended)
+
+	t.Setenv("EXCLUDED_NOTICE_TYPES", "Award Notice,Justification")
+	for _, c := range []struct {
+		skip      string
+		processed int
+		excluded  int
+	}{
+		{"", 3, 0}, // Excluded types are still stored by default and only filtered at query time
+		{"true", 1, 2},
+	} {
+		t.Setenv("INGEST_SKIP_EXCLUDED_TYPES", c.skip)
+		svc, processed := newTestIngestionService(sam.SearchURL())
+		svc.skipTypes = getIngestSkippedTypes()
+
+		stats, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(*processed) != c.processed || stats.Excluded != c.excluded || stats.Total != 3 {
+			t.Errorf("INGEST_SKIP_EXCLUDED_TYPES=%q: expected %d processed and %d excluded of 3, got %v, %d excluded of %d",
+				c.skip, c.processed, c.excluded, *processed, stats.Excluded, stats.Total)
+		}
+		if c.processed == 1 && (*processed)[0] != "SOL" {
+			t.Errorf("Expected only SOL to be stored, got %v", *processed)
+		}
+	}
+}
+
+func TestIngestOpportunities_StreamsLargePage(t *testing.T) {
+	// One full-size page of large records. The server sends the first opportunity, then holds the rest back
+	// until ingestion has processed it, which only happens if the page is decoded as it arrives
+	const total = maxSAMPageSize
+	firstProcessed := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"totalRecords":%d,"opportunitiesData":[{"noticeId":"N00000","title":"Opportunity 0"}`, total)
+		w.(http.Flusher).Flush()
+
+		select {
+		case <-firstProcessed:
+		case <-time.After(5 * time.Second):
+			t.Error("Expected the first opportunity to be processed before the rest of the page was sent")
+		}
+		for i := 1; i < total; i++ {
+			fmt.Fprintf(w, `,{"noticeId":"N%05d","title":"Opportunity %d","description":"%s"}`, i, i, strings.Repeat("x", 4096))
+		}
+		fmt.Fprint(w, "]}")
+	}))
+	defer srv.Close()
+
+	// Holding a response back part way is beyond samtest's canned responses, so this one page is served by hand
+	svc, _ := newTestIngestionService(srv.URL)
+	svc.pageSize = maxSAMPageSize
+	processed := 0
+	svc.processOpportunity = func(ctx context.Context, opp models.Opportunity) (string, error) {
+		if processed == 0 {
+			close(firstProcessed)
+		}
+		if expected := fmt.Sprintf("N%05d", processed); opp.NoticeID != expected {
+			t.Fatalf("Expected %s, got %s", expected, opp.NoticeID)
+		}
+		processed++
+		return "new", nil
+	}
+
+	stats, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if processed != total || stats.Total != total || stats.New != total {
+		t.Errorf("Expected %d processed and counted, got processed=%d total=%d new=%d", total, processed, stats.Total, stats.New)
+	}
+}
+
+func TestIngestOpportunities_TruncatedPageIsNotCounted(t *testing.T) {
+	// The page at offset 10 is cut off part way through; what was read of it must not reach the stats
+	sam := samtest.NewServer(t)
+	addNumberedOpportunities(sam, 30)
+	sam.QueueSearch(searchPage(10, 0, 30), truncated(searchPage(10, 10, 30)))
+	svc, _ := newTestIngestionService(sam.SearchURL())
+	svc.pageSize = 10
+	var emitted int
+	svc.SetEventEmitter(func(OpportunityEvent) { emitted++ })
+
+	stats, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stats.Total != 20 || stats.New != 20 || emitted != 20 {
+		t.Errorf("Expected only the 20 opportunities from complete pages, got total=%d new=%d emitted=%d", stats.Total, stats.New, emitted)
+	}
+	if len(stats.SkippedPages) != 1 || stats.SkippedPages[0].Offset != 10 {
+		t.Errorf("Expected the truncated page at offset 10 to be skipped, got %+v", stats.SkippedPages)
+	}
+}
+
+func TestIngestOpportunities_DumpsRawPages(t *testing.T) {
+	sam := samtest.NewServer(t)
+	addNumberedOpportunities(sam, 3)
+	svc, _ := newTestIngestionService(sam.SearchURL())
+	svc.pageSize = 2
+	svc.rawDumpDir = filepath.Join(t.TempDir(), "raw")
+
+	if _, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(svc.rawDumpDir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected one dump per page, got %v", files)
+	}
+	for i, want := range []string{`"N000"`, `"N002"`} {
+		name := filepath.Base(files[i])
+		if !strings.HasSuffix(name, fmt.Sprintf("-01-01-2025_01-31-2025-offset%d.json.gz", i*2)) {
+			t.Errorf("Expected dump %d named for its range and offset, got %s", i, name)
+		}
+		f, err := os.Open(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("Expected %s to be gzipped: %v", name, err)
+		}
+		body, err := io.ReadAll(gz)
+		f.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		var page map[string]any
+		if err := json.Unmarshal(body, &page); err != nil {
+			t.Errorf("Expected %s to hold the raw JSON response, got %q", name, body)
+		}
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %s to contain %s, got %s", name, want, body)
+		}
+	}
+}
+
+func TestIngestOpportunities_RawDumpDropsIncompletePages(t *testing.T) {
+	// The page at offset 2 is cut off part way; it is skipped and leaves no dump, partial or otherwise
+	sam := samtest.NewServer(t)
+	addNumberedOpportunities(sam, 4)
+	sam.QueueSearch(searchPage(2, 0, 4), truncated(searchPage(2, 2, 4)))
+	svc, _ := newTestIngestionService(sam.SearchURL())
+	svc.pageSize = 2
+	svc.rawDumpDir = t.TempDir()
+
+	stats, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(stats.SkippedPages) != 1 {
+		t.Errorf("Expected the truncated page to be skipped, got %+v", stats.SkippedPages)
+	}
+	files, _ := filepath.Glob(filepath.Join(svc.rawDumpDir, "*"))
+	if len(files) != 1 || !strings.HasSuffix(files[0], "-offset0.json.gz") {
+		t.Errorf("Expected only the complete page at offset 0 dumped, got %v", files)
+	}
+}
+
+func TestIngestOpportunities_RawDumpErrorsDontFailIngestion(t *testing.T) {
+	sam := samtest.NewServer(t)
+	addNumberedOpportunities(sam, 3)
+	svc, processed := newTestIngestionService(sam.SearchURL())
+	// A file where the directory should be, so every dump fails to open
+	blocker := filepath.Join(t.TempDir(), "raw")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	svc.rawDumpDir = blocker
+
+	stats, err := svc.IngestOpportunities(context.Background(), "01/01/2025", "01/31/2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if stats.Total != 3 || len(*processed) != 3 {
+		t.Errorf("Expected all 3 opportunities ingested, got stats.Total=%d processed=%d", stats.Total, len(*processed))
+	}
+}
+
+func TestGetRawDumpDir(t *testing.T) {
+	t.Setenv("SAM_RAW_DUMP_DIR", "")
+	if dir := NewIngestionService(nil, nil).rawDumpDir; dir != "" {
+		t.Errorf("Expected no raw dump by default, got %q", dir)
+	}
+	t.Setenv("SAM_RAW_DUMP_DIR", " /var/lib/govcon/sam-raw ")
+	if dir := getRawDumpDir(); dir != "/var/lib/govcon/sam-raw" {
+		t.Errorf("Expected %q, got %q", "/var/lib/govcon/sam-raw", dir)
+	}
+}
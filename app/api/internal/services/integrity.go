@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"govcon/api/internal/models"
+)
+
+// Integrity issue kinds reported by VerifyIntegrity
+const (
+	IssueMissingRaw          = "missing_raw"           // opportunity has no opportunity_raw row
+	IssueRawUnparseable      = "raw_unparseable"       // opportunity_raw.raw_data does not decode as an opportunity
+	IssueHashMismatch        = "hash_mismatch"         // opportunity.content_hash differs from the re-hashed raw data
+	IssueVersionHashMismatch = "version_hash_mismatch" // opportunity_version.content_hash differs from its re-hashed snapshot
+	IssueVersionDuplicate    = "version_duplicate"     // consecutive versions carry the same hash (no change was recorded)
+	IssueVersionStale        = "version_stale"         // latest version hash differs from opportunity.content_hash
+)
+
+// IntegrityIssue is a single discrepancy found between opportunity, opportunity_raw and opportunity_version
+type IntegrityIssue struct {
+	NoticeID string
+	Kind     string
+	Detail   string
+	Fixed    bool
+}
+
+// IntegrityReport summarizes a VerifyIntegrity run
+type IntegrityReport struct {
+	Checked  int // opportunities compared against their raw data
+	Versions int // opportunity_version rows checked
+	Issues   []IntegrityIssue
+}
+
+// Fixed returns how many issues were repaired
+func (r *IntegrityReport) Fixed() int {
+	fixed := 0
+	for _, issue := range r.Issues {
+		if issue.Fixed {
+			fixed++
+		}
+	}
+	return fixed
+}
+
+// versionEntry is one opportunity_version row, in chain order
+type versionEntry struct {
+	ID           int
+	ContentHash  string
+	SnapshotHash string // re-computed from raw_snapshot; empty if the snapshot could not be decoded
+}
+
+// VerifyIntegrity cross-checks opportunity against opportunity_raw and opportunity_version:
+// every opportunity has a raw row, stored hashes match the re-hashed raw data, and version chains are consistent.
+// With fix, opportunities whose hash does not match their raw data are re-derived from raw.
+// Missing raw rows and version chain problems are reported only; history cannot be re-derived.
+func (s *IngestionService) VerifyIntegrity(ctx context.Context, fix bool) (*IntegrityReport, error) {
+	report := &IntegrityReport{}
+
+	// 1. Opportunities without a raw row
+	rows, err := s.db.Query(ctx, `
+		SELECT o.notice_id
+		FROM opportunity o
+		LEFT JOIN opportunity_raw r ON r.notice_id = o.notice_id
+		WHERE r.notice_id IS NULL
+		ORDER BY o.notice_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query missing raw rows: %w", err)
+	}
+	for rows.Next() {
+		var noticeID string
+		if err := rows.Scan(&noticeID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan missing raw row: %w", err)
+		}
+		report.Issues = append(report.Issues, IntegrityIssue{
+			NoticeID: noticeID,
+			Kind:     IssueMissingRaw,
+			Detail:   "no opportunity_raw row",
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate missing raw rows: %w", err)
+	}
+
+	// 2. Stored content hash vs re-hashed raw data
+	rows, err = s.db.Query(ctx, `
+		SELECT o.notice_id, o.content_hash, r.raw_data
+		FROM opportunity o
+		JOIN opportunity_raw r ON r.notice_id = o.notice_id
+		ORDER BY o.notice_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query opportunities: %w", err)
+	}
+	var toFix []models.Opportunity
+	var toFixIdx []int
+	for rows.Next() {
+		var noticeID, storedHash string
+		var rawData []byte
+		if err := rows.Scan(&noticeID, &storedHash, &rawData); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan opportunity: %w", err)
+		}
+		report.Checked++
+
+		var opp models.Opportunity
+		if err := models.DecodeJSON(rawData, &opp); err != nil {
+			report.Issues = append(report.Issues, IntegrityIssue{
+				NoticeID: noticeID,
+				Kind:     IssueRawUnparseable,
+				Detail:   err.Error(),
+			})
+			continue
+		}
+		rawHash, err := ComputeOpportunityHash(opp)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to hash raw data for %s: %w", noticeID, err)
+		}
+		if rawHash != storedHash {
+			report.Issues = append(report.Issues, IntegrityIssue{
+				NoticeID: noticeID,
+				Kind:     IssueHashMismatch,
+				Detail:   fmt.Sprintf("stored %s, raw %s", storedHash, rawHash),
+			})
+			toFix = append(toFix, opp)
+			toFixIdx = append(toFixIdx, len(report.Issues)-1)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate opportunities: %w", err)
+	}
+
+	if fix {
+		now := time.Now()
+		for i, opp := range toFix {
+			hash, _ := ComputeOpportunityHash(opp)
+			if err := s.updateOpportunity(ctx, s.db, opp, hash, now); err != nil {
+				return report, fmt.Errorf("failed to re-derive %s from raw: %w", opp.NoticeID, err)
+			}
+			report.Issues[toFixIdx[i]].Fixed = true
+		}
+	}
+
+	// 3. Version chains, checked against the (possibly just fixed) opportunity hash
+	rows, err = s.db.Query(ctx, `
+		SELECT v.id, v.notice_id, v.content_hash, v.raw_snapshot, o.content_hash
+		FROM opportunity_version v
+		JOIN opportunity o ON o.notice_id = v.notice_id
+		ORDER BY v.notice_id, v.fetched_at, v.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query versions: %w", err)
+	}
+	defer rows.Close()
+
+	var chain []versionEntry
+	var chainNoticeID, chainCurrentHash string
+	for rows.Next() {
+		var entry versionEntry
+		var noticeID, currentHash string
+		var snapshot []byte
+		if err := rows.Scan(&entry.ID, &noticeID, &entry.ContentHash, &snapshot, &currentHash); err != nil {
+			return nil, fmt.Errorf("failed to scan version: %w", err)
+		}
+		report.Versions++
+
+		var opp models.Opportunity
+		if err := models.DecodeJSON(snapshot, &opp); err == nil {
+			entry.SnapshotHash, _ = ComputeOpportunityHash(opp)
+		}
+
+		if noticeID != chainNoticeID && len(chain) > 0 {
+			report.Issues = append(report.Issues, checkVersionChain(chainNoticeID, chainCurrentHash, chain)...)
+			chain = chain[:0]
+		}
+		chainNoticeID = noticeID
+		chainCurrentHash = currentHash
+		chain = append(chain, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate versions: %w", err)
+	}
+	if len(chain) > 0 {
+		report.Issues = append(report.Issues, checkVersionChain(chainNoticeID, chainCurrentHash, chain)...)
+	}
+
+	return report, nil
+}
+
+// checkVersionChain validates one notice's versions (oldest first) against its current opportunity hash
+func checkVersionChain(noticeID, currentHash string, chain []versionEntry) []IntegrityIssue {
+	var issues []IntegrityIssue
+	for i, entry := range chain {
+		if entry.SnapshotHash != entry.ContentHash {
+			issues = append(issues, IntegrityIssue{
+				NoticeID: noticeID,
+				Kind:     IssueVersionHashMismatch,
+				Detail:   fmt.Sprintf("version %d stored %s, snapshot %s", entry.ID, entry.ContentHash, entry.SnapshotHash),
+			})
+		}
+		if i > 0 && chain[i-1].ContentHash == entry.ContentHash {
+			issues = append(issues, IntegrityIssue{
+				NoticeID: noticeID,
+				Kind:     IssueVersionDuplicate,
+				Detail:   fmt.Sprintf("versions %d and %d share hash %s", chain[i-1].ID, entry.ID, entry.ContentHash),
+			})
+		}
+	}
+	latest := chain[len(chain)-1]
+	if latest.ContentHash != currentHash {
+		issues = append(issues, IntegrityIssue{
+			NoticeID: noticeID,
+			Kind:     IssueVersionStale,
+			Detail:   fmt.Sprintf("latest version %d has %s, opportunity has %s", latest.ID, latest.ContentHash, currentHash),
+		})
+	}
+	return issues
+}
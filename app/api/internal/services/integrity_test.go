@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/testdb"
+)
+
+func issueKinds(issues []IntegrityIssue) map[string]int {
+	kinds := make(map[string]int)
+	for _, issue := range issues {
+		kinds[issue.Kind]++
+	}
+	return kinds
+}
+
+func TestCheckVersionChain_Consistent(t *testing.T) {
+	chain := []versionEntry{
+		{ID: 1, ContentHash: "a", SnapshotHash: "a"},
+		{ID: 2, ContentHash: "b", SnapshotHash: "b"},
+	}
+	if issues := checkVersionChain("N1", "b", chain); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", issues)
+	}
+}
+
+func TestCheckVersionChain_DetectsInconsistencies(t *testing.T) {
+	chain := []versionEntry{
+		{ID: 1, ContentHash: "a", SnapshotHash: "a"},
+		{ID: 2, ContentHash: "a", SnapshotHash: "a"},
+		{ID: 3, ContentHash: "c", SnapshotHash: "x"},
+	}
+	kinds := issueKinds(checkVersionChain("N1", "d", chain))
+	for _, kind := range []string{IssueVersionDuplicate, IssueVersionHashMismatch, IssueVersionStale} {
+		if kinds[kind] != 1 {
+			t.Errorf("Expected one %s issue, got %d", kind, kinds[kind])
+		}
+	}
+}
+
+func TestVerifyIntegrity_DetectsAndFixesSeededInconsistencies(t *testing.T) {
+	pool := testdb.Open(t)
+	testdb.Migrate(t, pool)
+	ctx := context.Background()
+	svc := &IngestionService{db: pool}
+
+	for _, id := range []string{"GOOD", "DRIFT"} {
+		if _, err := svc.ProcessOpportunity(ctx, models.Opportunity{NoticeID: id, Title: "Title " + id}); err != nil {
+			t.Fatalf("Failed to seed %s: %v", id, err)
+		}
+	}
+	// Seed drift: raw updated without the opportunity row following, plus an opportunity with no raw row
+	if _, err := pool.Exec(ctx, `UPDATE opportunity_raw SET raw_data = jsonb_set(raw_data, '{title}', '"Changed"') WHERE notice_id = 'DRIFT'`); err != nil {
+		t.Fatalf("Failed to seed drift: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `INSERT INTO opportunity (notice_id, title, content_hash) VALUES ('ORPHAN', 'Orphan', 'x')`); err != nil {
+		t.Fatalf("Failed to seed orphan: %v", err)
+	}
+
+	report, err := svc.VerifyIntegrity(ctx, false)
+	if err != nil {
+		t.Fatalf("VerifyIntegrity failed: %v", err)
+	}
+	kinds := issueKinds(report.Issues)
+	if kinds[IssueMissingRaw] != 1 || kinds[IssueHashMismatch] != 1 || len(report.Issues) != 2 {
+		t.Fatalf("Expected one missing_raw and one hash_mismatch, got %+v", report.Issues)
+	}
+	if report.Fixed() != 0 {
+		t.Errorf("Expected nothing fixed without fix, got %d", report.Fixed())
+	}
+
+	report, err = svc.VerifyIntegrity(ctx, true)
+	if err != nil {
+		t.Fatalf("VerifyIntegrity with fix failed: %v", err)
+	}
+	if report.Fixed() != 1 {
+		t.Errorf("Expected 1 fixed issue, got %d", report.Fixed())
+	}
+	var title string
+	if err := pool.QueryRow(ctx, "SELECT title FROM opportunity WHERE notice_id = 'DRIFT'").Scan(&title); err != nil {
+		t.Fatalf("Failed to read fixed opportunity: %v", err)
+	}
+	if title != "Changed" {
+		t.Errorf("Expected title re-derived from raw %q, got %q", "Changed", title)
+	}
+
+	report, err = svc.VerifyIntegrity(ctx, false)
+	if err != nil {
+		t.Fatalf("VerifyIntegrity failed: %v", err)
+	}
+	if kinds := issueKinds(report.Issues); kinds[IssueHashMismatch] != 0 || kinds[IssueMissingRaw] != 1 {
+		t.Errorf("Expected only the unfixable missing_raw issue after fix, got %+v", report.Issues)
+	}
+}
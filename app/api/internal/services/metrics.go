@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"govcon/api/internal/models"
+)
+
+// FetchOutcomeKey labels a description fetch outcome counter
+type FetchOutcomeKey struct {
+	Status     string // fetch_status written (fetched, not_found, error)
+	SourceType string // url, inline, none
+	HTTPStatus string // HTTP status bucket (2xx, 4xx, 5xx, none)
+}
+
+// FetchMetrics counts description fetch_status transitions in-process
+type FetchMetrics struct {
+	mu     sync.Mutex
+	counts map[FetchOutcomeKey]int64
+}
+
+// DefaultFetchMetrics is the process-wide counter set exposed on /metrics
+var DefaultFetchMetrics = NewFetchMetrics()
+
+func NewFetchMetrics() *FetchMetrics {
+	return &FetchMetrics{counts: make(map[FetchOutcomeKey]int64)}
+}
+
+// HTTPStatusBucket collapses an HTTP status into a low-cardinality label
+// 0 means no HTTP request was made (inline/none sources) or it failed before a response
+func HTTPStatusBucket(status int) string {
+	if status <= 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%dxx", status/100)
+}
+
+// RecordFetchOutcome increments the counter for a fetch_status written to opportunity_description
+func (m *FetchMetrics) RecordFetchOutcome(status models.FetchStatus, sourceType models.DescriptionSourceType, httpStatus int) {
+	key := FetchOutcomeKey{
+		Status:     string(status),
+		SourceType: string(sourceType),
+		HTTPStatus: HTTPStatusBucket(httpStatus),
+	}
+	m.mu.Lock()
+	m.counts[key]++
+	m.mu.Unlock()
+}
+
+// Count returns the current value of a single counter
+func (m *FetchMetrics) Count(key FetchOutcomeKey) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[key]
+}
+
+// FetchOutcomeCount is one counter of a FetchMetrics snapshot
+type FetchOutcomeCount struct {
+	Status     string `json:"status"`
+	SourceType string `json:"sourceType"`
+	HTTPStatus string `json:"httpStatus"`
+	Count      int64  `json:"count"`
+}
+
+// Snapshot returns every counter, sorted by status, source type and HTTP status bucket. The batch commands
+// run in their own process, out of reach of /metrics, so each keeps a FetchMetrics for its run and stores
+// the snapshot in its job_run stats (GET /admin/jobs)
+func (m *FetchMetrics) Snapshot() []FetchOutcomeCount {
+	m.mu.Lock()
+	snapshot := make([]FetchOutcomeCount, 0, len(m.counts))
+	for k, v := range m.counts {
+		snapshot = append(snapshot, FetchOutcomeCount{Status: k.Status, SourceType: k.SourceType, HTTPStatus: k.HTTPStatus, Count: v})
+	}
+	m.mu.Unlock()
+
+	// Sort for stable output
+	sort.Slice(snapshot, func(i, j int) bool {
+		a, b := snapshot[i], snapshot[j]
+		if a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		if a.SourceType != b.SourceType {
+			return a.SourceType < b.SourceType
+		}
+		return a.HTTPStatus < b.HTTPStatus
+	})
+	return snapshot
+}
+
+// WritePrometheus writes the counters in Prometheus text exposition format
+func (m *FetchMetrics) WritePrometheus(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("# HELP govcon_description_fetch_total Description fetch_status transitions by source type and HTTP status bucket.\n")
+	sb.WriteString("# TYPE govcon_description_fetch_total counter\n")
+	for _, c := range m.Snapshot() {
+		fmt.Fprintf(&sb, "govcon_description_fetch_total{status=%q,source_type=%q,http_status=%q} %d\n",
+			c.Status, c.SourceType, c.HTTPStatus, c.Count)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ClassifyFetchResult maps the result of FetchDescription to the fetch_status to store
+// An error wins, so a 404 that FetchDescription reports as an error is stored as error; SAM's
+// "description not found" answer (a 404 status or body without an error) is not_found
+func ClassifyFetchResult(httpStatus int, rawText string, err error) models.FetchStatus {
+	if err != nil {
+		return models.FetchStatusError
+	}
+	if httpStatus == http.StatusNotFound || strings.Contains(strings.ToLower(rawText), "description not found") {
+		return models.FetchStatusNotFound
+	}
+	return models.FetchStatusFetched
+}
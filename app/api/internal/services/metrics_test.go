@@ -0,0 +1,85 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"govcon/api/internal/models"
+)
+
+func TestRecordFetchOutcome_404IncrementsNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"Description Not Found"}`))
+	}))
+	defer srv.Close()
+
+	rawText, _, httpStatus, _, err := FetchDescription(srv.URL, "test-key")
+	status := ClassifyFetchResult(httpStatus, rawText, err)
+	if status != models.FetchStatusNotFound {
+		t.Fatalf("Expected status %q, got %q", models.FetchStatusNotFound, status)
+	}
+
+	m := NewFetchMetrics()
+	m.RecordFetchOutcome(status, models.SourceTypeURL, httpStatus)
+
+	key := FetchOutcomeKey{Status: "not_found", SourceType: "url", HTTPStatus: "4xx"}
+	if got := m.Count(key); got != 1 {
+		t.Errorf("Expected not_found counter 1, got %d", got)
+	}
+	if got := m.Count(FetchOutcomeKey{Status: "error", SourceType: "url", HTTPStatus: "4xx"}); got != 0 {
+		t.Errorf("Expected error counter 0, got %d", got)
+	}
+
+	var sb strings.Builder
+	if err := m.WritePrometheus(&sb); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := `govcon_description_fetch_total{status="not_found",source_type="url",http_status="4xx"} 1`
+	if !strings.Contains(sb.String(), expected) {
+		t.Errorf("Expected output to contain %q, got %q", expected, sb.String())
+	}
+}
+
+func TestClassifyFetchResult(t *testing.T) {
+	if got := ClassifyFetchResult(500, "", http.ErrHandlerTimeout); got != models.FetchStatusError {
+		t.Errorf("Expected %q for 500, got %q", models.FetchStatusError, got)
+	}
+	if got := ClassifyFetchResult(200, "Description Not Found", nil); got != models.FetchStatusNotFound {
+		t.Errorf("Expected %q for not found body, got %q", models.FetchStatusNotFound, got)
+	}
+	// An error wins over the 404: a bare 404 FetchDescription couldn't read as "not found" is an error
+	if got := ClassifyFetchResult(404, `{"error":"Not Found"}`, &HTTPStatusError{StatusCode: 404}); got != models.FetchStatusError {
+		t.Errorf("Expected %q for a 404 with an error, got %q", models.FetchStatusError, got)
+	}
+	if got := ClassifyFetchResult(404, "", nil); got != models.FetchStatusNotFound {
+		t.Errorf("Expected %q for a 404 without an error, got %q", models.FetchStatusNotFound, got)
+	}
+	if got := ClassifyFetchResult(200, "Some description", nil); got != models.FetchStatusFetched {
+		t.Errorf("Expected %q for 200, got %q", models.FetchStatusFetched, got)
+	}
+}
+
+func TestFetchMetrics_Snapshot(t *testing.T) {
+	m := NewFetchMetrics()
+	m.RecordFetchOutcome(models.FetchStatusFetched, models.SourceTypeURL, 200)
+	m.RecordFetchOutcome(models.FetchStatusError, models.SourceTypeURL, 503)
+	m.RecordFetchOutcome(models.FetchStatusFetched, models.SourceTypeURL, 200)
+
+	snapshot := m.Snapshot()
+	expected := []FetchOutcomeCount{
+		{Status: "error", SourceType: "url", HTTPStatus: "5xx", Count: 1},
+		{Status: "fetched", SourceType: "url", HTTPStatus: "2xx", Count: 2},
+	}
+	if len(snapshot) != len(expected) {
+		t.Fatalf("Expected %d counters, got %v", len(expected), snapshot)
+	}
+	for i := range expected {
+		if snapshot[i] != expected[i] {
+			t.Errorf("Expected counter %d to be %+v, got %+v", i, expected[i], snapshot[i])
+		}
+	}
+}
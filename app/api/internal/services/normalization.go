@@ -0,0 +1,96 @@
+package services
+
+import (
+	"time"
+
+	"govcon/api/internal/models"
+)
+
+// optimizeForAI is OptimizeForAI behind a var so tests can observe when AI work happens
+var optimizeForAI = OptimizeForAI
+
+// CanReuseAIFields reports whether prev's AI fields are still valid for normalized text with contentHash:
+// same hash, current normalization and AI input versions, and AI fields already present
+func CanReuseAIFields(prev *models.OpportunityDescription, contentHash string) bool {
+	return prev != nil &&
+		prev.ContentHash != nil && *prev.ContentHash == contentHash &&
+		prev.NormalizationVersion != nil && *prev.NormalizationVersion == NORMALIZATION_VERSION &&
+		prev.AIInputVersion != nil && *prev.AIInputVersion == AI_INPUT_VERSION &&
+		prev.AIInputText != nil
+}
+
+// CopyAIFields copies the AI-derived fields from src to dst
+func CopyAIFields(dst, src *models.OpportunityDescription) {
+	dst.AIInputText = src.AIInputText
+	dst.AIInputHash = src.AIInputHash
+	dst.AIInputVersion = src.AIInputVersion
+	dst.AIGeneratedAt = src.AIGeneratedAt
+	dst.AIMeta = src.AIMeta
+	dst.ExcerptText = src.ExcerptText
+	dst.POCEmailPrimary = src.POCEmailPrimary
+}
+
+// AIFieldLookup finds another stored description with AI output for the same content hash, or nil
+// Used to share AI output between notices with identical descriptions (e.g. agency boilerplate)
+type AIFieldLookup func(contentHash string) *models.OpportunityDescription
+
+// ApplyNormalization unwraps and normalizes rawText into desc (raw, normalized, hash, version),
+// then fills the AI fields. When prev already holds AI output for the same content hash, normalization
+// version and AI input version, those fields are reused and OptimizeForAI is not run.
+// Returns whether OptimizeForAI ran and its error, if any (desc keeps prior AI fields on error).
+func ApplyNormalization(desc *models.OpportunityDescription, rawText string, prev *models.OpportunityDescription, now time.Time) (bool, error) {
+	return ApplyNormalizationWithLookup(desc, rawText, prev, now, nil)
+}
+
+// ApplyNormalizationWithLookup is ApplyNormalization that, when prev can't be reused, also tries
+// lookup (if non-nil) for another description with the same content before running OptimizeForAI
+func ApplyNormalizationWithLookup(desc *models.OpportunityDescription, rawText string, prev *models.OpportunityDescription, now time.Time, lookup AIFieldLookup) (bool, error) {
+	rawText = UnwrapDescriptionText(rawText)
+	rawTextNormalized := NormalizeRaw(rawText)
+	textNormalized := Normalize(rawTextNormalized)
+	contentHash := ComputeContentHash(textNormalized)
+	normalizationVersion := NORMALIZATION_VERSION
+
+	// NORMALIZE_SAMPLE_RATE: log a random fraction of before/after pairs for QA
+	if normalizeSamples.Sample() {
+		logNormalizeSample(desc.NoticeID, rawText, textNormalized)
+	}
+
+	// Check before overwriting: prev may be desc itself
+	reuse := CanReuseAIFields(prev, contentHash)
+	var prevAI models.OpportunityDescription
+	if reuse {
+		CopyAIFields(&prevAI, prev)
+	} else if lookup != nil {
+		if match := lookup(contentHash); CanReuseAIFields(match, contentHash) {
+			reuse = true
+			CopyAIFields(&prevAI, match)
+		}
+	}
+
+	desc.RawText = &rawText
+	desc.RawTextNormalized = &rawTextNormalized
+	desc.TextNormalized = &textNormalized
+	desc.ContentHash = &contentHash
+	desc.NormalizationVersion = &normalizationVersion
+
+	if reuse {
+		CopyAIFields(desc, &prevAI)
+		return false, nil
+	}
+
+	aiInputText, excerptText, aiMeta, pocEmailPrimary, err := optimizeForAI(rawTextNormalized)
+	if err != nil {
+		return true, err
+	}
+	aiInputHash := ComputeContentHash(aiInputText)
+	aiInputVersion := AI_INPUT_VERSION
+	desc.AIInputText = &aiInputText
+	desc.AIInputHash = &aiInputHash
+	desc.AIInputVersion = &aiInputVersion
+	desc.AIGeneratedAt = &now
+	desc.AIMeta = &aiMeta
+	desc.ExcerptText = &excerptText
+	desc.POCEmailPrimary = pocEmailPrimary
+	return true, nil
+}
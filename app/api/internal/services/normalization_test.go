@@ -0,0 +1,183 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"govcon/api/internal/models"
+)
+
+// countOptimizeCalls swaps optimizeForAI for a counting wrapper for the duration of the test
+func countOptimizeCalls(t *testing.T) *int {
+	t.Helper()
+	calls := 0
+	orig := optimizeForAI
+	optimizeForAI = func(rawPostParse string) (string, string, models.AiMeta, *string, error) {
+		calls++
+		return orig(rawPostParse)
+	}
+	t.Cleanup(func() { optimizeForAI = orig })
+	return &calls
+}
+
+func TestApplyNormalization_UnchangedContentReusesAIFields(t *testing.T) {
+	calls := countOptimizeCalls(t)
+	text := "<p>Contractor shall provide janitorial services.</p>"
+
+	prev := &models.OpportunityDescription{NoticeID: "N1"}
+	if _, err := ApplyNormalization(prev, text, nil, time.Now()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("Expected 1 AI call for first fetch, got %d", *calls)
+	}
+
+	desc := &models.OpportunityDescription{NoticeID: "N1"}
+	aiRan, err := ApplyNormalization(desc, text, prev, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if aiRan {
+		t.Error("Expected AI optimization to be skipped for unchanged content")
+	}
+	if *calls != 1 {
+		t.Errorf("Expected no additional AI calls, got %d total", *calls)
+	}
+	if desc.AIInputText == nil || *desc.AIInputText != *prev.AIInputText {
+		t.Errorf("Expected AI input text to be reused from previous record")
+	}
+	if desc.AIGeneratedAt != prev.AIGeneratedAt {
+		t.Errorf("Expected AI generated timestamp to be preserved")
+	}
+}
+
+func TestApplyNormalization_ChangedContentReoptimizes(t *testing.T) {
+	calls := countOptimizeCalls(t)
+
+	prev := &models.OpportunityDescription{NoticeID: "N1"}
+	ApplyNormalization(prev, "Original scope of work.", nil, time.Now())
+
+	desc := &models.OpportunityDescription{NoticeID: "N1"}
+	aiRan, err := ApplyNormalization(desc, "Amended scope of work.", prev, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !aiRan {
+		t.Error("Expected AI optimization to run for changed content")
+	}
+	if *calls != 2 {
+		t.Errorf("Expected 2 AI calls, got %d", *calls)
+	}
+	if *desc.ContentHash == *prev.ContentHash {
+		t.Errorf("Expected content hash to change")
+	}
+}
+
+func TestApplyNormalization_OutdatedVersionReoptimizes(t *testing.T) {
+	calls := countOptimizeCalls(t)
+	text := "Scope of work."
+
+	prev := &models.OpportunityDescription{NoticeID: "N1"}
+	ApplyNormalization(prev, text, nil, time.Now())
+	oldVersion := NORMALIZATION_VERSION - 1
+	prev.NormalizationVersion = &oldVersion
+
+	// Self-heal passes the record itself as prev
+	aiRan, _ := ApplyNormalization(prev, text, prev, time.Now())
+	if !aiRan {
+		t.Error("Expected AI optimization to run when normalization version is outdated")
+	}
+	if *calls != 2 {
+		t.Errorf("Expected 2 AI calls, got %d", *calls)
+	}
+	if *prev.NormalizationVersion != NORMALIZATION_VERSION {
+		t.Errorf("Expected normalization version %d, got %d", NORMALIZATION_VERSION, *prev.NormalizationVersion)
+	}
+}
+
+func TestApplyNormalization_OutdatedAIInputVersionReoptimizes(t *testing.T) {
+	calls := countOptimizeCalls(t)
+	text := "Scope of work."
+
+	prev := &models.OpportunityDescription{NoticeID: "N1"}
+	ApplyNormalization(prev, text, nil, time.Now())
+	if *prev.AIInputVersion != AI_INPUT_VERSION {
+		t.Fatalf("Expected AI input version %d, got %d", AI_INPUT_VERSION, *prev.AIInputVersion)
+	}
+	oldVersion := AI_INPUT_VERSION - 1
+	prev.AIInputVersion = &oldVersion
+
+	// Same text and normalization version, but the AI fields came from an older OptimizeForAI
+	aiRan, _ := ApplyNormalization(prev, text, prev, time.Now())
+	if !aiRan {
+		t.Error("Expected AI optimization to run when the AI input version is outdated")
+	}
+	if *calls != 2 {
+		t.Errorf("Expected 2 AI calls, got %d", *calls)
+	}
+	if *prev.AIInputVersion != AI_INPUT_VERSION {
+		t.Errorf("Expected AI input version %d, got %d", AI_INPUT_VERSION, *prev.AIInputVersion)
+	}
+
+	// Nor is an outdated match from another notice reused
+	match := &models.OpportunityDescription{}
+	CopyAIFields(match, prev)
+	match.ContentHash, match.NormalizationVersion, match.AIInputVersion = prev.ContentHash, prev.NormalizationVersion, &oldVersion
+	desc := &models.OpportunityDescription{NoticeID: "N2"}
+	aiRan, _ = ApplyNormalizationWithLookup(desc, text, nil, time.Now(), func(string) *models.OpportunityDescription { return match })
+	if !aiRan {
+		t.Error("Expected AI optimization to run instead of reusing an outdated match")
+	}
+}
+
+func TestApplyNormalizationWithLookup_IdenticalDescriptionReusesAIOutput(t *testing.T) {
+	calls := countOptimizeCalls(t)
+	boilerplate := "<p>This is a combined synopsis/solicitation for commercial items.</p>"
+
+	first := &models.OpportunityDescription{NoticeID: "N1"}
+	ApplyNormalization(first, boilerplate, nil, time.Now())
+
+	lookupHashes := []string{}
+	lookup := func(contentHash string) *models.OpportunityDescription {
+		lookupHashes = append(lookupHashes, contentHash)
+		if first.ContentHash != nil && *first.ContentHash == contentHash {
+			return first
+		}
+		return nil
+	}
+
+	second := &models.OpportunityDescription{NoticeID: "N2"}
+	aiRan, err := ApplyNormalizationWithLookup(second, boilerplate, nil, time.Now(), lookup)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if aiRan || *calls != 1 {
+		t.Errorf("Expected second identical description to skip AI (aiRan=%v, calls=%d)", aiRan, *calls)
+	}
+	if second.AIInputText == nil || *second.AIInputText != *first.AIInputText {
+		t.Errorf("Expected AI input text reused from the first description")
+	}
+	if len(lookupHashes) != 1 || lookupHashes[0] != *second.ContentHash {
+		t.Errorf("Expected one lookup by the content hash, got %v", lookupHashes)
+	}
+
+	// Different content finds no match and runs AI
+	third := &models.OpportunityDescription{NoticeID: "N3"}
+	if aiRan, _ := ApplyNormalizationWithLookup(third, "Different scope.", nil, time.Now(), lookup); !aiRan || *calls != 2 {
+		t.Errorf("Expected AI to run for different content (aiRan=%v, calls=%d)", aiRan, *calls)
+	}
+}
+
+func TestApplyNormalizationWithLookup_PrevTakesPrecedence(t *testing.T) {
+	countOptimizeCalls(t)
+	text := "Scope of work."
+
+	prev := &models.OpportunityDescription{NoticeID: "N1"}
+	ApplyNormalization(prev, text, nil, time.Now())
+
+	desc := &models.OpportunityDescription{NoticeID: "N1"}
+	ApplyNormalizationWithLookup(desc, text, prev, time.Now(), func(string) *models.OpportunityDescription {
+		t.Error("Expected lookup not to be called when prev can be reused")
+		return nil
+	})
+}
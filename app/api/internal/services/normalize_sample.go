@@ -0,0 +1,78 @@
+package services
+
+import (
+	"log"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// normalizeSampleMaxChars caps each text in a sample log line, so samples show the shape of the output
+// without logging whole descriptions
+const normalizeSampleMaxChars = 300
+
+// getNormalizeSampleRate returns the fraction of normalized descriptions to log (NORMALIZE_SAMPLE_RATE, 0 to 1)
+// Unset, invalid or non-positive values disable sampling; values above 1 log everything
+func getNormalizeSampleRate() float64 {
+	rateStr := os.Getenv("NORMALIZE_SAMPLE_RATE")
+	if rateStr == "" {
+		return 0
+	}
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil || rate <= 0 {
+		if err != nil {
+			log.Printf("⚠️  Invalid NORMALIZE_SAMPLE_RATE %q, sampling disabled", rateStr)
+		}
+		return 0
+	}
+	if rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+// normalizeSampler picks a random fraction of normalized outputs to log for QA
+type normalizeSampler struct {
+	rate float64
+	mu   sync.Mutex
+	rng  *rand.Rand
+}
+
+func newNormalizeSampler(rate float64, seed int64) *normalizeSampler {
+	return &normalizeSampler{rate: rate, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Sample reports whether the next output should be logged
+func (s *normalizeSampler) Sample() bool {
+	if s.rate <= 0 {
+		return false
+	}
+	if s.rate >= 1 {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Float64() < s.rate
+}
+
+// normalizeSamples is the process-wide sampler used by ApplyNormalizationWithLookup
+var normalizeSamples = newNormalizeSampler(getNormalizeSampleRate(), time.Now().UnixNano())
+
+// logNormalizeSample logs a truncated before/after of one description's normalization
+func logNormalizeSample(noticeID, rawText, normalizedText string) {
+	log.Printf("[debug] normalize sample noticeId=%s raw(%d chars)=%q normalized(%d chars)=%q",
+		noticeID,
+		len(rawText), truncateForLog(rawText, normalizeSampleMaxChars),
+		len(normalizedText), truncateForLog(normalizedText, normalizeSampleMaxChars))
+}
+
+// truncateForLog returns the first maxChars runes of s, marking the cut with "…"
+func truncateForLog(s string, maxChars int) string {
+	runes := []rune(s)
+	if len(runes) <= maxChars {
+		return s
+	}
+	return string(runes[:maxChars]) + "…"
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeSampler_RespectsRate(t *testing.T) {
+	const draws = 10000
+	sampler := newNormalizeSampler(0.01, 42)
+	sampled := 0
+	for i := 0; i < draws; i++ {
+		if sampler.Sample() {
+			sampled++
+		}
+	}
+	// 1% of 10000 is 100; a seeded RNG makes the count deterministic, the bounds just document the expectation
+	if sampled < 70 || sampled > 130 {
+		t.Errorf("Expected about 100 samples at rate 0.01, got %d", sampled)
+	}
+
+	// Same seed, same decisions
+	again := newNormalizeSampler(0.01, 42)
+	replayed := 0
+	for i := 0; i < draws; i++ {
+		if again.Sample() {
+			replayed++
+		}
+	}
+	if replayed != sampled {
+		t.Errorf("Expected seeded sampler to repeat %d samples, got %d", sampled, replayed)
+	}
+}
+
+func TestNormalizeSampler_Bounds(t *testing.T) {
+	off := newNormalizeSampler(0, 1)
+	all := newNormalizeSampler(1, 1)
+	for i := 0; i < 100; i++ {
+		if off.Sample() {
+			t.Fatal("Expected rate 0 never to sample")
+		}
+		if !all.Sample() {
+			t.Fatal("Expected rate 1 always to sample")
+		}
+	}
+}
+
+func TestGetNormalizeSampleRate(t *testing.T) {
+	cases := map[string]float64{
+		"":     0,
+		"0.01": 0.01,
+		"abc":  0,
+		"-1":   0,
+		"5":    1,
+	}
+	for input, expected := range cases {
+		t.Setenv("NORMALIZE_SAMPLE_RATE", input)
+		if got := getNormalizeSampleRate(); got != expected {
+			t.Errorf("NORMALIZE_SAMPLE_RATE=%q: expected %v, got %v", input, expected, got)
+		}
+	}
+}
+
+func TestTruncateForLog(t *testing.T) {
+	if got := truncateForLog("short", 10); got != "short" {
+		t.Errorf("Expected short text unchanged, got %q", got)
+	}
+	got := truncateForLog(strings.Repeat("é", 20), 5)
+	if got != strings.Repeat("é", 5)+"…" {
+		t.Errorf("Expected 5 runes and an ellipsis, got %q", got)
+	}
+}
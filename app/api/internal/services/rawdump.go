@@ -0,0 +1,87 @@
+package services
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"govcon/api/internal/models"
+)
+
+// getRawDumpDir returns the directory raw SAM pages are written to for audit (SAM_RAW_DUMP_DIR), or "" for none
+func getRawDumpDir() string {
+	return strings.TrimSpace(os.Getenv("SAM_RAW_DUMP_DIR"))
+}
+
+// rawDumpFileName names a page's dump by fetch time, posted range and offset, e.g.
+// sam-page-20250201T020000.123Z-01-01-2025_01-31-2025-offset100.json.gz; names sort by fetch time
+func rawDumpFileName(req models.OpportunitiesRequest, fetched time.Time) string {
+	date := func(s string) string { return strings.ReplaceAll(s, "/", "-") }
+	return fmt.Sprintf("sam-page-%s-%s_%s-offset%d.json.gz",
+		fetched.UTC().Format("20060102T150405.000Z"), date(req.PostedFrom), date(req.PostedTo), req.Offset)
+}
+
+// rawPageDump writes one SAM search response, gzipped, as it streams through the ingester. It never fails the
+// page: a write error is logged once and the rest of the page goes undumped. The file is written under a
+// .partial name and renamed when the page has been read in full, so the directory only holds complete pages.
+type rawPageDump struct {
+	path string
+	file *os.File
+	gz   *gzip.Writer
+	err  error
+}
+
+// openRawPageDump starts a dump of the page req fetches in dir, or returns nil (logging why) if the file
+// can't be created
+func openRawPageDump(dir string, req models.OpportunitiesRequest) *rawPageDump {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		fmt.Printf("Warning: raw SAM page dump disabled for offset %d: %v\n", req.Offset, err)
+		return nil
+	}
+	path := filepath.Join(dir, rawDumpFileName(req, time.Now()))
+	file, err := os.Create(path + ".partial")
+	if err != nil {
+		fmt.Printf("Warning: raw SAM page dump disabled for offset %d: %v\n", req.Offset, err)
+		return nil
+	}
+	return &rawPageDump{path: path, file: file, gz: gzip.NewWriter(file)}
+}
+
+// Write copies p into the dump. It always reports success so the response keeps streaming to the decoder.
+func (d *rawPageDump) Write(p []byte) (int, error) {
+	if d.err == nil {
+		if _, err := d.gz.Write(p); err != nil {
+			d.fail(err)
+		}
+	}
+	return len(p), nil
+}
+
+func (d *rawPageDump) fail(err error) {
+	d.err = err
+	fmt.Printf("Warning: failed to write raw SAM page %s: %v\n", d.path, err)
+}
+
+// finish closes the dump, keeping it when the page was read in full (complete) and written without error,
+// and removing it otherwise
+func (d *rawPageDump) finish(complete bool) {
+	if d.err == nil {
+		if err := d.gz.Close(); err != nil {
+			d.fail(err)
+		}
+	}
+	if err := d.file.Close(); err != nil && d.err == nil {
+		d.fail(err)
+	}
+	if complete && d.err == nil {
+		if err := os.Rename(d.path+".partial", d.path); err != nil {
+			d.fail(err)
+		} else {
+			return
+		}
+	}
+	os.Remove(d.path + ".partial")
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"govcon/api/internal/models"
+)
+
+// RehashReport summarizes a RehashContent run
+type RehashReport struct {
+	Opportunities   int // opportunities re-hashed from opportunity_raw
+	Changed         int // opportunity.content_hash values that differ (updated unless dry run)
+	Versions        int // opportunity_version rows re-hashed from raw_snapshot
+	VersionsChanged int // opportunity_version.content_hash values that differ (updated unless dry run)
+	Unparseable     int // raw rows or snapshots that don't decode; left unchanged
+}
+
+// rehashRow is a stored hash and the JSON it should be recomputed from
+type rehashRow struct {
+	key  interface{} // notice_id or version id
+	hash string
+	data []byte
+}
+
+// RehashContent recomputes content hashes with the current ComputeOpportunityHash and stores them in place:
+// opportunity.content_hash from opportunity_raw, and opportunity_version.content_hash from each raw_snapshot.
+// Nothing else is touched and no version rows are created, so changing the hash's field set doesn't make the
+// next ingestion treat every opportunity as updated. With dryRun, hashes are compared but not written.
+// Callers should hold the ingestion lock so the hashes don't change underneath the run.
+func (s *IngestionService) RehashContent(ctx context.Context, dryRun bool) (*RehashReport, error) {
+	report := &RehashReport{}
+
+	opps, err := s.loadRehashRows(ctx, `
+		SELECT o.notice_id, o.content_hash, r.raw_data
+		FROM opportunity o
+		JOIN opportunity_raw r ON r.notice_id = o.notice_id
+		ORDER BY o.notice_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query opportunities: %w", err)
+	}
+	versions, err := s.loadRehashRows(ctx, `
+		SELECT id, content_hash, raw_snapshot
+		FROM opportunity_version
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query versions: %w", err)
+	}
+
+	changedOpps, err := rehashChanged(opps, &report.Unparseable)
+	if err != nil {
+		return nil, err
+	}
+	changedVersions, err := rehashChanged(versions, &report.Unparseable)
+	if err != nil {
+		return nil, err
+	}
+	report.Opportunities = len(opps)
+	report.Changed = len(changedOpps)
+	report.Versions = len(versions)
+	report.VersionsChanged = len(changedVersions)
+
+	if dryRun || (len(changedOpps) == 0 && len(changedVersions) == 0) {
+		return report, nil
+	}
+
+	// All or nothing, so a failed run never leaves a mix of old and new hashes
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, row := range changedOpps {
+		if _, err := tx.Exec(ctx, `UPDATE opportunity SET content_hash = $2 WHERE notice_id = $1`, row.key, row.hash); err != nil {
+			return nil, fmt.Errorf("failed to update hash for %v: %w", row.key, err)
+		}
+	}
+	for _, row := range changedVersions {
+		if _, err := tx.Exec(ctx, `UPDATE opportunity_version SET content_hash = $2 WHERE id = $1`, row.key, row.hash); err != nil {
+			return nil, fmt.Errorf("failed to update hash for version %v: %w", row.key, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit rehash: %w", err)
+	}
+	return report, nil
+}
+
+// loadRehashRows reads (key, content_hash, json) rows for RehashContent
+func (s *IngestionService) loadRehashRows(ctx context.Context, query string) ([]rehashRow, error) {
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []rehashRow
+	for rows.Next() {
+		var row rehashRow
+		if err := rows.Scan(&row.key, &row.hash, &row.data); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// rehashChanged returns the rows whose recomputed hash differs from the stored one, carrying the new hash
+// Rows whose JSON doesn't decode are counted in unparseable and skipped
+func rehashChanged(rows []rehashRow, unparseable *int) ([]rehashRow, error) {
+	var changed []rehashRow
+	for _, row := range rows {
+		var opp models.Opportunity
+		if err := models.DecodeJSON(row.data, &opp); err != nil {
+			*unparseable++
+			continue
+		}
+		hash, err := ComputeOpportunityHash(opp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %v: %w", row.key, err)
+		}
+		if hash != row.hash {
+			changed = append(changed, rehashRow{key: row.key, hash: hash})
+		}
+	}
+	return changed, nil
+}
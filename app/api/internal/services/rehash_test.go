@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"govcon/api/internal/models"
+	"govcon/api/internal/testdb"
+)
+
+func TestRehashChanged(t *testing.T) {
+	current, _ := ComputeOpportunityHash(models.Opportunity{NoticeID: "SAME"})
+	rows := []rehashRow{
+		{key: "SAME", hash: current, data: []byte(`{"noticeId": "SAME"}`)},
+		{key: "STALE", hash: "old", data: []byte(`{"noticeId": "STALE"}`)},
+		{key: "BAD", hash: "old", data: []byte(`not json`)},
+	}
+	unparseable := 0
+	changed, err := rehashChanged(rows, &unparseable)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(changed) != 1 || changed[0].key != "STALE" || changed[0].hash == "old" {
+		t.Errorf("Expected only STALE with a new hash, got %+v", changed)
+	}
+	if unparseable != 1 {
+		t.Errorf("Expected 1 unparseable row, got %d", unparseable)
+	}
+}
+
+func TestRehashContent_UpdatesHashesWithoutVersions(t *testing.T) {
+	pool := testdb.Open(t)
+	testdb.Migrate(t, pool)
+	ctx := context.Background()
+	svc := &IngestionService{db: pool}
+
+	seed := []models.Opportunity{
+		{NoticeID: "A", Title: "Title A"},
+		{NoticeID: "B", Title: "Title B"},
+		{NoticeID: "B", Title: "Title B revised"},
+	}
+	for _, opp := range seed {
+		if _, err := svc.ProcessOpportunity(ctx, opp); err != nil {
+			t.Fatalf("Failed to seed %s: %v", opp.NoticeID, err)
+		}
+	}
+	// Simulate a hash algorithm change: every stored hash is stale
+	if _, err := pool.Exec(ctx, `
+		UPDATE opportunity SET content_hash = 'old-' || notice_id;
+		UPDATE opportunity_version SET content_hash = 'old-' || id;
+	`); err != nil {
+		t.Fatalf("Failed to seed stale hashes: %v", err)
+	}
+	var versionsBefore int
+	pool.QueryRow(ctx, "SELECT COUNT(*) FROM opportunity_version").Scan(&versionsBefore)
+
+	report, err := svc.RehashContent(ctx, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if report.Opportunities != 2 || report.Changed != 2 || report.VersionsChanged != versionsBefore {
+		t.Errorf("Expected dry run to report 2 of 2 opportunities and %d versions changed, got %+v", versionsBefore, report)
+	}
+	var stale int
+	pool.QueryRow(ctx, "SELECT COUNT(*) FROM opportunity WHERE content_hash LIKE 'old-%'").Scan(&stale)
+	if stale != 2 {
+		t.Errorf("Expected dry run to leave hashes unchanged, got %d stale", stale)
+	}
+
+	if _, err := svc.RehashContent(ctx, false); err != nil {
+		t.Fatalf("RehashContent failed: %v", err)
+	}
+	report, err = svc.RehashContent(ctx, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if report.Changed != 0 || report.VersionsChanged != 0 {
+		t.Errorf("Expected no stale hashes after rehash, got %+v", report)
+	}
+
+	// The next ingestion of unchanged data is a no-op and records no versions
+	for _, opp := range []models.Opportunity{seed[0], seed[2]} {
+		if action, err := svc.ProcessOpportunity(ctx, opp); err != nil || action != "skipped" {
+			t.Errorf("Expected %s to be skipped after rehash, got %q (%v)", opp.NoticeID, action, err)
+		}
+	}
+	var versionsAfter int
+	pool.QueryRow(ctx, "SELECT COUNT(*) FROM opportunity_version").Scan(&versionsAfter)
+	if versionsAfter != versionsBefore {
+		t.Errorf("Expected %d version rows, got %d", versionsBefore, versionsAfter)
+	}
+
+	integrity, err := svc.VerifyIntegrity(ctx, false)
+	if err != nil {
+		t.Fatalf("VerifyIntegrity failed: %v", err)
+	}
+	if len(integrity.Issues) != 0 {
+		t.Errorf("Expected no integrity issues after rehash, got %+v", integrity.Issues)
+	}
+}
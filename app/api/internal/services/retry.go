@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRetryableHTTPStatuses are the upstream statuses worth retrying when RETRYABLE_HTTP_STATUSES is unset:
+// request timeout, rate limiting, and 5xx responses from SAM or a proxy in front of it
+const defaultRetryableHTTPStatuses = "408,429,500,502,503,504,522"
+
+// HTTPStatusError is returned when an upstream API responds with a non-200 status
+type HTTPStatusError struct {
+	StatusCode int
+	Body       string // Response body, if it was read; informational only
+	Reason     error  // ErrSAMAuth or ErrSAMQuota when the response was recognized as one (see classifySAMStatus)
+}
+
+func (e *HTTPStatusError) Error() string {
+	msg := fmt.Sprintf("SAM API returned status %d", e.StatusCode)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	if e.Reason != nil {
+		return fmt.Sprintf("%v (%s)", e.Reason, msg)
+	}
+	return msg
+}
+
+// Unwrap exposes Reason, so callers can test for errors.Is(err, ErrSAMAuth) or ErrSAMQuota
+func (e *HTTPStatusError) Unwrap() error {
+	return e.Reason
+}
+
+// getRetryableHTTPStatuses parses RETRYABLE_HTTP_STATUSES (comma-separated codes)
+// Invalid entries are ignored; falls back to the defaults if nothing valid is configured
+func getRetryableHTTPStatuses() map[int]bool {
+	statuses := parseHTTPStatusList(os.Getenv("RETRYABLE_HTTP_STATUSES"))
+	if len(statuses) == 0 {
+		statuses = parseHTTPStatusList(defaultRetryableHTTPStatuses)
+	}
+	return statuses
+}
+
+func parseHTTPStatusList(s string) map[int]bool {
+	statuses := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		code, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || code < 100 || code > 599 {
+			continue
+		}
+		statuses[code] = true
+	}
+	return statuses
+}
+
+// IsRetryableHTTPStatus reports whether an upstream response with this status should be retried
+func IsRetryableHTTPStatus(statusCode int) bool {
+	return getRetryableHTTPStatuses()[statusCode]
+}
+
+// RetryConfig controls how Retry re-attempts a failing operation
+type RetryConfig struct {
+	MaxAttempts    int           // Total attempts including the first
+	InitialBackoff time.Duration // Wait before the second attempt; doubles each time
+	IsRetryable    func(error) bool
+}
+
+// DefaultRetryConfig mirrors the backfill job's retry behavior
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: 1 * time.Second,
+		IsRetryable:    IsRetryableError,
+	}
+}
+
+// Retry calls fn until it succeeds, returns a non-retryable error, attempts run out, or ctx is done
+// Returns the last error from fn (or ctx.Err() if cancelled while backing off)
+func Retry(ctx context.Context, cfg RetryConfig, fn func() error) error {
+	attempts := cfg.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	isRetryable := cfg.IsRetryable
+	if isRetryable == nil {
+		isRetryable = IsRetryableError
+	}
+
+	var err error
+	backoff := cfg.InitialBackoff
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2 // Exponential backoff
+		}
+
+		err = fn()
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// IsRetryableError reports whether err looks transient (retryable HTTP status, timeouts, connection problems)
+// HTTP statuses are matched on the HTTPStatusError code, never on the message text
+func IsRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	// A rejected key or an exhausted quota fails the same way until someone acts on it
+	if errors.Is(err, ErrSAMAuth) || errors.Is(err, ErrSAMQuota) {
+		return false
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return IsRetryableHTTPStatus(statusErr.StatusCode)
+	}
+	errStr := err.Error()
+	// Check for network/timeout errors
+	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "connection") || strings.Contains(errStr, "network") {
+		return true
+	}
+	return false
+}
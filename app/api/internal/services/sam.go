@@ -2,12 +2,14 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
 
 	"govcon/api/internal/models"
 )
@@ -17,6 +19,62 @@ type SAMService struct {
 	BaseURL string
 }
 
+// ErrSAMAuth and ErrSAMQuota are the Reason of an HTTPStatusError for a SAM response rejecting the API key
+// or reporting its quota used up. Neither is retried; test for them with errors.Is.
+var (
+	ErrSAMAuth  = errors.New("SAM rejected the API key; check SAM_API_KEY")
+	ErrSAMQuota = errors.New("SAM API quota exhausted; daily quota resets tomorrow")
+)
+
+// samQuotaMarkers appear in SAM's (and the api.data.gov gateway's) quota responses, e.g.
+// {"code": "900804", "message": "Message throttled out", "description": "You have exceeded your quota...",
+// "nextAccessTime": "..."} or {"error": {"code": "OVER_RATE_LIMIT", ...}}; compared lower-cased
+var samQuotaMarkers = []string{"quota", "throttled out", "over_rate_limit", "nextaccesstime"}
+
+// classifySAMStatus returns ErrSAMQuota or ErrSAMAuth when a non-200 SAM response is a quota or key
+// rejection, or nil for any other failure. A 429 without a quota body is ordinary rate limiting and is
+// retried as before; a 401 or 403 whose body mentions the quota is a quota of zero, not a bad key.
+func classifySAMStatus(statusCode int, body string) error {
+	lower := strings.ToLower(body)
+	quota := false
+	for _, marker := range samQuotaMarkers {
+		if strings.Contains(lower, marker) {
+			quota = true
+			break
+		}
+	}
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusUnauthorized, http.StatusForbidden:
+		if quota {
+			return ErrSAMQuota
+		}
+	}
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrSAMAuth
+	}
+	return nil
+}
+
+// defaultUserAgent identifies our traffic in SAM's logs when SAM_USER_AGENT is unset
+const defaultUserAgent = "govcon-api/1.0.0"
+
+// setOutboundHeaders sets the headers every outbound SAM request carries:
+// Accept, a descriptive User-Agent (SAM_USER_AGENT), and a From contact (SAM_CONTACT_EMAIL) if configured
+func setOutboundHeaders(req *http.Request) {
+	req.Header.Set("Accept", "application/json")
+
+	userAgent := os.Getenv("SAM_USER_AGENT")
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	if contact := os.Getenv("SAM_CONTACT_EMAIL"); contact != "" {
+		req.Header.Set("From", contact)
+	}
+}
+
 func NewSAMService() *SAMService {
 	apiKey := os.Getenv("SAM_API_KEY")
 	if apiKey == "" {
@@ -29,7 +87,9 @@ func NewSAMService() *SAMService {
 	}
 }
 
-func (s *SAMService) SearchOpportunities(req models.OpportunitiesRequest) (*models.OpportunitiesResponse, error) {
+// doSearch sends one SAM search request, returning the response when SAM answers 200
+// The caller must close the response body
+func (s *SAMService) doSearch(req models.OpportunitiesRequest) (*http.Response, error) {
 	// Build query parameters
 	params := url.Values{}
 	params.Add("api_key", s.APIKey)
@@ -48,7 +108,7 @@ func (s *SAMService) SearchOpportunities(req models.OpportunitiesRequest) (*mode
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	httpReq.Header.Set("Accept", "application/json")
+	setOutboundHeaders(httpReq)
 
 	// Execute request
 	client := &http.Client{}
@@ -56,13 +116,22 @@ func (s *SAMService) SearchOpportunities(req models.OpportunitiesRequest) (*mode
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
-	defer resp.Body.Close()
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("SAM API returned status %d: %s", resp.StatusCode, string(body))
+		resp.Body.Close()
+		return nil, &HTTPStatusError{StatusCode: resp.StatusCode, Body: string(body), Reason: classifySAMStatus(resp.StatusCode, string(body))}
 	}
+	return resp, nil
+}
+
+func (s *SAMService) SearchOpportunities(req models.OpportunitiesRequest) (*models.OpportunitiesResponse, error) {
+	resp, err := s.doSearch(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
 
 	// Read the response body first for better error messages
 	bodyBytes, err := io.ReadAll(resp.Body)
@@ -76,7 +145,7 @@ func (s *SAMService) SearchOpportunities(req models.OpportunitiesRequest) (*mode
 		OpportunitiesData []models.Opportunity     `json:"opportunitiesData"`
 	}
 
-	if err := json.Unmarshal(bodyBytes, &samResponse); err != nil {
+	if err := models.DecodeJSON(bodyBytes, &samResponse); err != nil {
 		// Return more detailed error with a snippet of the response
 		bodyPreview := string(bodyBytes)
 		if len(bodyPreview) > 500 {
@@ -91,3 +160,106 @@ func (s *SAMService) SearchOpportunities(req models.OpportunitiesRequest) (*mode
 	}, nil
 }
 
+
+// StreamOpportunities runs the same search as SearchOpportunities but decodes opportunitiesData one element at a
+// time, calling fn with each opportunity as soon as it is read, so the page is never held in memory whole.
+// It returns the page's totalRecords and how many opportunities were read. An error from fn stops the
+// stream and is returned as is; fn has already seen every opportunity before the point of failure.
+func (s *SAMService) StreamOpportunities(req models.OpportunitiesRequest, fn func(models.Opportunity) error) (totalRecords int, count int, err error) {
+	return s.streamOpportunities(req, nil, fn)
+}
+
+// streamOpportunities is StreamOpportunities that also copies the raw response body to raw, when set, as it is
+// decoded. After a successful decode the rest of the body is copied too, so raw gets the whole response.
+func (s *SAMService) streamOpportunities(req models.OpportunitiesRequest, raw io.Writer, fn func(models.Opportunity) error) (totalRecords int, count int, err error) {
+	resp, err := s.doSearch(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	if raw == nil {
+		return decodeOpportunitiesStream(resp.Body, fn)
+	}
+	body := io.TeeReader(resp.Body, raw)
+	totalRecords, count, err = decodeOpportunitiesStream(body, fn)
+	if err != nil {
+		return totalRecords, count, err
+	}
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return totalRecords, count, fmt.Errorf("failed to read SAM response: %w", err)
+	}
+	return totalRecords, count, nil
+}
+
+// decodeOpportunitiesStream reads a SAM search response ({"totalRecords": n, "opportunitiesData": [...], ...})
+// token by token, handing each opportunity to fn. Other top-level fields are skipped, and totalRecords may
+// come before or after the data.
+func decodeOpportunitiesStream(r io.Reader, fn func(models.Opportunity) error) (totalRecords int, count int, err error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber() // keep large integers in Award and other untyped fields exact
+	decodeErr := func(err error) error {
+		return fmt.Errorf("failed to decode response at byte %d: %w", dec.InputOffset(), err)
+	}
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return 0, 0, decodeErr(err)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return totalRecords, count, decodeErr(err)
+		}
+		switch tok {
+		case "totalRecords":
+			if err := dec.Decode(&totalRecords); err != nil {
+				return totalRecords, count, decodeErr(err)
+			}
+		case "opportunitiesData":
+			tok, err := dec.Token()
+			if err != nil {
+				return totalRecords, count, decodeErr(err)
+			}
+			if tok == nil {
+				continue // "opportunitiesData": null
+			}
+			if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+				return totalRecords, count, decodeErr(fmt.Errorf("expected opportunitiesData array, got %v", tok))
+			}
+			for dec.More() {
+				var opp models.Opportunity
+				if err := dec.Decode(&opp); err != nil {
+					return totalRecords, count, decodeErr(err)
+				}
+				count++
+				if err := fn(opp); err != nil {
+					return totalRecords, count, err
+				}
+			}
+			if err := expectDelim(dec, ']'); err != nil {
+				return totalRecords, count, decodeErr(err)
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return totalRecords, count, decodeErr(err)
+			}
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return totalRecords, count, decodeErr(err)
+	}
+	return totalRecords, count, nil
+}
+
+// expectDelim reads the next token and checks that it is the delimiter want
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("expected %v, got %v", want, tok)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"govcon/api/internal/models"
+)
+
+func TestSetOutboundHeaders_Defaults(t *testing.T) {
+	t.Setenv("SAM_USER_AGENT", "")
+	t.Setenv("SAM_CONTACT_EMAIL", "")
+	req := httptest.NewRequest(http.MethodGet, "https://api.sam.gov/opportunities/v2/search", nil)
+
+	setOutboundHeaders(req)
+
+	if ua := req.Header.Get("User-Agent"); ua != defaultUserAgent {
+		t.Errorf("Expected User-Agent %q, got %q", defaultUserAgent, ua)
+	}
+	if from := req.Header.Get("From"); from != "" {
+		t.Errorf("Expected no From header, got %q", from)
+	}
+	if accept := req.Header.Get("Accept"); accept != "application/json" {
+		t.Errorf("Expected Accept %q, got %q", "application/json", accept)
+	}
+}
+
+func TestSetOutboundHeaders_EnvOverrides(t *testing.T) {
+	t.Setenv("SAM_USER_AGENT", "acme-govcon/2.0")
+	t.Setenv("SAM_CONTACT_EMAIL", "ops@example.com")
+	req := httptest.NewRequest(http.MethodGet, "https://api.sam.gov/opportunities/v2/search", nil)
+
+	setOutboundHeaders(req)
+
+	if ua := req.Header.Get("User-Agent"); ua != "acme-govcon/2.0" {
+		t.Errorf("Expected User-Agent %q, got %q", "acme-govcon/2.0", ua)
+	}
+	if from := req.Header.Get("From"); from != "ops@example.com" {
+		t.Errorf("Expected From %q, got %q", "ops@example.com", from)
+	}
+}
+
+func TestOutboundRequests_CarryUserAgent(t *testing.T) {
+	t.Setenv("SAM_USER_AGENT", "")
+	t.Setenv("SAM_CONTACT_EMAIL", "ops@example.com")
+
+	var seen []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Clone())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"totalRecords":0,"opportunitiesData":[],"description":"Scope"}`))
+	}))
+	defer srv.Close()
+
+	sam := &SAMService{APIKey: "test", BaseURL: srv.URL}
+	if _, err := sam.SearchOpportunities(models.OpportunitiesRequest{Limit: 1}); err != nil {
+		t.Fatalf("SearchOpportunities failed: %v", err)
+	}
+	if _, _, _, _, err := FetchDescription(srv.URL+"/noticedesc", "test"); err != nil {
+		t.Fatalf("FetchDescription failed: %v", err)
+	}
+
+	if len(seen) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(seen))
+	}
+	for i, h := range seen {
+		if h.Get("User-Agent") != defaultUserAgent || h.Get("From") != "ops@example.com" {
+			t.Errorf("Request %d: expected User-Agent %q and From contact, got %q / %q", i, defaultUserAgent, h.Get("User-Agent"), h.Get("From"))
+		}
+	}
+}
+
+func TestDecodeOpportunitiesStream(t *testing.T) {
+	body := `{"links":[{"rel":"self"}],"opportunitiesData":[{"noticeId":"A","title":"First"},{"noticeId":"B"}],"limit":2,"totalRecords":7}`
+
+	var ids []string
+	total, count, err := decodeOpportunitiesStream(strings.NewReader(body), func(opp models.Opportunity) error {
+		ids = append(ids, opp.NoticeID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != 7 || count != 2 {
+		t.Errorf("Expected totalRecords 7 and 2 read, got %d and %d", total, count)
+	}
+	if len(ids) != 2 || ids[0] != "A" || ids[1] != "B" {
+		t.Errorf("Expected [A B] in order, got %v", ids)
+	}
+
+	if _, count, err := decodeOpportunitiesStream(strings.NewReader(`{"totalRecords":0,"opportunitiesData":null}`), func(models.Opportunity) error {
+		t.Error("Expected no callback for null opportunitiesData")
+		return nil
+	}); err != nil || count != 0 {
+		t.Errorf("Expected an empty page for null opportunitiesData, got count=%d err=%v", count, err)
+	}
+}
+
+func TestDecodeOpportunitiesStream_Errors(t *testing.T) {
+	noop := func(models.Opportunity) error { return nil }
+
+	// Truncated mid-array: the opportunities before the cut are still handed over
+	_, count, err := decodeOpportunitiesStream(strings.NewReader(`{"totalRecords":3,"opportunitiesData":[{"noticeId":"A"},{"noticeId":"B"},{"notice`), noop)
+	if err == nil || count != 2 {
+		t.Errorf("Expected an error after 2 opportunities, got count=%d err=%v", count, err)
+	}
+
+	if _, _, err := decodeOpportunitiesStream(strings.NewReader(`{"opportunitiesData":{"noticeId":"A"}}`), noop); err == nil {
+		t.Error("Expected an error when opportunitiesData is not an array")
+	}
+
+	// A callback error stops the stream and comes back unwrapped
+	stop := errors.New("stop")
+	_, count, err = decodeOpportunitiesStream(strings.NewReader(`{"opportunitiesData":[{"noticeId":"A"},{"noticeId":"B"}]}`), func(models.Opportunity) error {
+		return stop
+	})
+	if err != stop || count != 1 {
+		t.Errorf("Expected the callback error after 1 opportunity, got count=%d err=%v", count, err)
+	}
+}
+
+func TestClassifySAMStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{http.StatusForbidden, `{"error":{"code":"API_KEY_INVALID","message":"An invalid api_key was supplied."}}`, ErrSAMAuth},
+		{http.StatusForbidden, `{"error":{"code":"API_KEY_MISSING","message":"No api_key was supplied."}}`, ErrSAMAuth},
+		{http.StatusUnauthorized, "Unauthorized", ErrSAMAuth},
+		{http.StatusTooManyRequests, `{"code":"900804","message":"Message throttled out","description":"You have exceeded your quota.","nextAccessTime":"2026-Oct-17 00:00:00+0000 UTC"}`, ErrSAMQuota},
+		{http.StatusTooManyRequests, `{"error":{"code":"OVER_RATE_LIMIT","message":"You have exceeded your rate limit."}}`, ErrSAMQuota},
+		{http.StatusForbidden, `{"message":"You have exceeded your quota."}`, ErrSAMQuota},
+		{http.StatusUnauthorized, `{"code":"900804","message":"Message throttled out","description":"You have exceeded your quota."}`, ErrSAMQuota},
+		{http.StatusTooManyRequests, "Too Many Requests", nil},
+		{http.StatusServiceUnavailable, "quota service unavailable", nil},
+		{http.StatusBadRequest, `{"error":"Invalid Date Entered"}`, nil},
+	}
+	for _, tt := range tests {
+		if got := classifySAMStatus(tt.status, tt.body); got != tt.want {
+			t.Errorf("classifySAMStatus(%d, %q): expected %v, got %v", tt.status, tt.body, tt.want, got)
+		}
+	}
+}
+
+func TestSearchOpportunities_AuthAndQuotaErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		want      error
+		retryable bool
+	}{
+		{"invalid key", http.StatusForbidden, `{"error":{"code":"API_KEY_INVALID","message":"An invalid api_key was supplied."}}`, ErrSAMAuth, false},
+		{"quota exhausted", http.StatusTooManyRequests, `{"code":"900804","message":"Message throttled out","description":"You have exceeded your quota."}`, ErrSAMQuota, false},
+		{"rate limited", http.StatusTooManyRequests, "Too Many Requests", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			sam := &SAMService{APIKey: "test", BaseURL: srv.URL}
+			_, err := sam.SearchOpportunities(models.OpportunitiesRequest{Limit: 1})
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			}
+
+			var statusErr *HTTPStatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Errorf("Expected HTTPStatusError with status %d, got %v", tt.status, err)
+			}
+			for _, sentinel := range []error{ErrSAMAuth, ErrSAMQuota} {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("Expected errors.Is(err, %q) = %v, got %v", sentinel, sentinel == tt.want, got)
+				}
+			}
+			if tt.want != nil && !strings.Contains(err.Error(), tt.want.Error()) {
+				t.Errorf("Expected message to include %q, got %q", tt.want, err.Error())
+			}
+			if got := IsRetryableError(err); got != tt.retryable {
+				t.Errorf("Expected IsRetryableError = %v, got %v", tt.retryable, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+// Package testdb gives database tests a throwaway Postgres schema: Open connects to TESTDB_URL with a fresh
+// schema first on the search_path, dropped when the test ends, and skips the test when TESTDB_URL is not set.
+// Migrate builds the real schema from the embedded migrations, so tests follow it as it changes instead of
+// hand-copying CREATE TABLEs:
+//
+//	pool := testdb.Open(t)
+//	testdb.Migrate(t, pool)
+package testdb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"govcon/api/internal/db"
+	"govcon/api/migrations"
+)
+
+// Open connects to TESTDB_URL with an empty throwaway schema first on the search_path
+// Tests using it are skipped when TESTDB_URL is not set
+func Open(t testing.TB) *pgxpool.Pool {
+	t.Helper()
+	dbURL := os.Getenv("TESTDB_URL")
+	if dbURL == "" {
+		t.Skip("TESTDB_URL not set; skipping database test")
+	}
+
+	ctx := context.Background()
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+
+	admin, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if _, err := admin.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		admin.Close()
+		t.Fatalf("Failed to create test schema: %v", err)
+	}
+
+	cfg, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		admin.Close()
+		t.Fatalf("Failed to parse TESTDB_URL: %v", err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema + ", public"
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		admin.Close()
+		t.Fatalf("Failed to connect to test schema: %v", err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+		_, _ = admin.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+		admin.Close()
+	})
+	return pool
+}
+
+// Migrate applies every embedded migration (what setup-db and cmd/migrate run) to the test schema
+func Migrate(t testing.TB, pool *pgxpool.Pool) {
+	t.Helper()
+	all, err := db.LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if _, err := db.Migrate(context.Background(), pool, all); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+}
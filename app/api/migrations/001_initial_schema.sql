@@ -0,0 +1,87 @@
+-- Migration: Create the base tables (previously created by cmd/setup-db)
+-- Apply with: go run ./cmd/migrate up
+
+CREATE TABLE IF NOT EXISTS ping (
+    id SERIAL PRIMARY KEY,
+    message TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Seed the row read by /db-test
+INSERT INTO ping (message)
+SELECT 'hello from postgres'
+WHERE NOT EXISTS (SELECT 1 FROM ping);
+
+-- Enable pg_trgm extension for fuzzy text matching
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Raw JSON snapshots as returned by SAM
+CREATE TABLE IF NOT EXISTS opportunity_raw (
+    id SERIAL PRIMARY KEY,
+    notice_id VARCHAR NOT NULL UNIQUE,
+    raw_data JSONB NOT NULL,
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_raw_notice_id ON opportunity_raw(notice_id);
+CREATE INDEX IF NOT EXISTS idx_opportunity_raw_fetched_at ON opportunity_raw(fetched_at);
+
+-- Normalized opportunity data
+CREATE TABLE IF NOT EXISTS opportunity (
+    notice_id VARCHAR PRIMARY KEY,
+    title TEXT NOT NULL,
+    organization_type VARCHAR,
+    posted_date VARCHAR,
+    type VARCHAR,
+    base_type VARCHAR,
+    archive_type VARCHAR,
+    archive_date VARCHAR,
+    type_of_set_aside VARCHAR,
+    type_of_set_aside_desc VARCHAR,
+    response_deadline VARCHAR,
+    naics JSONB,
+    classification_code VARCHAR,
+    active BOOLEAN NOT NULL DEFAULT false,
+    point_of_contact JSONB,
+    place_of_performance JSONB,
+    description TEXT,
+    department VARCHAR,
+    sub_tier VARCHAR,
+    office VARCHAR,
+    links JSONB,
+    content_hash VARCHAR NOT NULL,
+    last_updated TIMESTAMPTZ NOT NULL DEFAULT now(),
+    first_seen TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_posted_date ON opportunity(posted_date);
+CREATE INDEX IF NOT EXISTS idx_opportunity_response_deadline ON opportunity(response_deadline);
+CREATE INDEX IF NOT EXISTS idx_opportunity_active ON opportunity(active);
+CREATE INDEX IF NOT EXISTS idx_opportunity_content_hash ON opportunity(content_hash);
+
+-- GIN full-text search index on concatenated search document
+CREATE INDEX IF NOT EXISTS idx_opportunity_search_gin
+    ON opportunity USING GIN (
+        to_tsvector('english',
+            COALESCE(title, '') || ' ' ||
+            COALESCE(department, '') || ' ' ||
+            COALESCE(description, '')
+        )
+    );
+
+-- pg_trgm indexes for fuzzy matching
+CREATE INDEX IF NOT EXISTS idx_opportunity_title_trgm ON opportunity USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_opportunity_department_trgm ON opportunity USING GIN (department gin_trgm_ops);
+
+-- Change history
+CREATE TABLE IF NOT EXISTS opportunity_version (
+    id SERIAL PRIMARY KEY,
+    notice_id VARCHAR NOT NULL REFERENCES opportunity(notice_id) ON DELETE CASCADE,
+    content_hash VARCHAR NOT NULL,
+    raw_snapshot JSONB NOT NULL,
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    changed_fields JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_version_notice_id ON opportunity_version(notice_id);
+CREATE INDEX IF NOT EXISTS idx_opportunity_version_fetched_at ON opportunity_version(fetched_at);
@@ -1,5 +1,5 @@
 -- Migration: Add search columns and indexes for fast filtering and full-text search
--- Run with: psql "$DATABASE_URL" -f migrations/002_search_indexes.sql
+-- Apply with: go run ./cmd/migrate up
 
 -- Enable pg_trgm extension if not already enabled (for fuzzy matching)
 CREATE EXTENSION IF NOT EXISTS pg_trgm;
@@ -9,7 +9,7 @@ DO $$
 BEGIN
     IF NOT EXISTS (
         SELECT 1 FROM information_schema.columns 
-        WHERE table_name = 'opportunity' AND column_name = 'solicitation_number'
+        WHERE table_schema = current_schema() AND table_name = 'opportunity' AND column_name = 'solicitation_number'
     ) THEN
         ALTER TABLE opportunity ADD COLUMN solicitation_number VARCHAR;
     END IF;
@@ -20,7 +20,7 @@ DO $$
 BEGIN
     IF NOT EXISTS (
         SELECT 1 FROM information_schema.columns 
-        WHERE table_name = 'opportunity' AND column_name = 'agency_path_name'
+        WHERE table_schema = current_schema() AND table_name = 'opportunity' AND column_name = 'agency_path_name'
     ) THEN
         ALTER TABLE opportunity ADD COLUMN agency_path_name VARCHAR;
     END IF;
@@ -54,7 +54,7 @@ DO $$
 BEGIN
     IF NOT EXISTS (
         SELECT 1 FROM information_schema.columns 
-        WHERE table_name = 'opportunity' AND column_name = 'search_tsv'
+        WHERE table_schema = current_schema() AND table_name = 'opportunity' AND column_name = 'search_tsv'
     ) THEN
         ALTER TABLE opportunity ADD COLUMN search_tsv tsvector 
             GENERATED ALWAYS AS (
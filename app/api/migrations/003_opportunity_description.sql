@@ -1,5 +1,5 @@
 -- Migration: Add opportunity_description table for fetching and normalizing SAM opportunity descriptions
--- Run with: psql "$DATABASE_URL" -f migrations/003_opportunity_description.sql
+-- Apply with: go run ./cmd/migrate up
 
 -- Create opportunity_description table
 CREATE TABLE IF NOT EXISTS opportunity_description (
@@ -1,5 +1,5 @@
 -- Migration: Add AI processing fields to opportunity_description table
--- Run with: psql "$DATABASE_URL" -f migrations/004_add_ai_processing_fields.sql
+-- Apply with: go run ./cmd/migrate up
 
 -- Add AI processing columns
 ALTER TABLE opportunity_description
@@ -12,7 +12,8 @@ ALTER TABLE opportunity_description
     ADD COLUMN IF NOT EXISTS poc_email_primary TEXT NULL;
 
 -- Create partial index for backfill queries
-CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_desc_needs_ai
+-- Not CONCURRENTLY: migrations run inside a transaction
+CREATE INDEX IF NOT EXISTS idx_desc_needs_ai
     ON opportunity_description (notice_id)
     WHERE ai_input_text IS NULL AND raw_text_normalized IS NOT NULL;
 
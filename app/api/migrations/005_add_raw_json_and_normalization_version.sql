@@ -1,5 +1,5 @@
 -- Migration: Add raw_json_response and normalization_version columns to opportunity_description table
--- Run with: psql "$DATABASE_URL" -f migrations/005_add_raw_json_and_normalization_version.sql
+-- Apply with: go run ./cmd/migrate up
 
 -- Add raw_json_response column to store the complete JSON response from SAM API
 ALTER TABLE opportunity_description 
@@ -0,0 +1,16 @@
+-- Migration: Add opportunity_outcome table for tracking bid outcomes (won/lost/no-bid)
+-- Apply with: go run ./cmd/migrate up
+
+CREATE TABLE IF NOT EXISTS opportunity_outcome (
+    notice_id VARCHAR PRIMARY KEY REFERENCES opportunity(notice_id) ON DELETE CASCADE,
+    status VARCHAR NOT NULL CHECK (status IN ('won', 'lost', 'no_bid')),
+    awardee TEXT,
+    award_amount NUMERIC(15, 2),
+    note TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_outcome_status
+    ON opportunity_outcome(status);
+
+COMMENT ON TABLE opportunity_outcome IS 'User-recorded outcome for an opportunity: whether it was won, lost, or not bid, and by whom.';
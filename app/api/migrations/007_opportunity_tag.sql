@@ -0,0 +1,15 @@
+-- Migration: Add opportunity_tag table for organizing the pipeline with internal tags
+-- Apply with: go run ./cmd/migrate up
+-- Allowed tag values are enforced by the API (OPPORTUNITY_TAGS), not here, so the vocabulary can change without a migration
+
+CREATE TABLE IF NOT EXISTS opportunity_tag (
+    notice_id VARCHAR NOT NULL REFERENCES opportunity(notice_id) ON DELETE CASCADE,
+    tag VARCHAR NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (notice_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_tag_tag
+    ON opportunity_tag(tag);
+
+COMMENT ON TABLE opportunity_tag IS 'Internal tags (e.g. rebid, strategic) applied to opportunities; one row per opportunity and tag.';
@@ -0,0 +1,25 @@
+-- Migration: Enforce created_at on opportunity_description and keep it fixed across upserts
+-- Apply with: go run ./cmd/migrate up
+-- UpsertDescription never writes created_at, so tables created without the default (e.g. by external tooling) need it
+
+-- Backfill rows that were inserted without a default
+UPDATE opportunity_description
+SET created_at = COALESCE(updated_at, fetched_at, now())
+WHERE created_at IS NULL;
+
+ALTER TABLE opportunity_description
+    ALTER COLUMN created_at SET DEFAULT now(),
+    ALTER COLUMN created_at SET NOT NULL;
+
+-- created_at records the first insert; an update (including the ON CONFLICT path) can't change it
+CREATE OR REPLACE FUNCTION opportunity_description_keep_created_at() RETURNS trigger AS $$
+BEGIN
+    NEW.created_at := OLD.created_at;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_opportunity_description_keep_created_at ON opportunity_description;
+CREATE TRIGGER trg_opportunity_description_keep_created_at
+    BEFORE UPDATE ON opportunity_description
+    FOR EACH ROW EXECUTE FUNCTION opportunity_description_keep_created_at();
@@ -0,0 +1,20 @@
+-- Migration: Add job_run table recording each ingestion/backfill run (start, end, status, stats)
+-- Apply with: go run ./cmd/migrate up
+
+CREATE TABLE IF NOT EXISTS job_run (
+    id BIGSERIAL PRIMARY KEY,
+    job_type VARCHAR NOT NULL,
+    status VARCHAR NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ,
+    stats JSONB,
+    error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_run_type_started_at
+    ON job_run(job_type, started_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_job_run_started_at
+    ON job_run(started_at DESC);
+
+COMMENT ON TABLE job_run IS 'One row per ingestion/backfill run: when it started and finished, whether it succeeded, and its stats. A run left in running with no finished_at was killed.';
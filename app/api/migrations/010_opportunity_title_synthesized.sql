@@ -0,0 +1,7 @@
+-- Migration: Flag opportunities whose title was synthesized because SAM sent none
+-- Apply with: go run ./cmd/migrate up
+
+ALTER TABLE opportunity
+    ADD COLUMN IF NOT EXISTS title_synthesized BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN opportunity.title_synthesized IS 'True when SAM sent no title and ingestion stored a placeholder built from the solicitation number or notice ID.';
@@ -0,0 +1,53 @@
+-- Migration: Materialize the description status on opportunity so search can filter and list without joining
+-- opportunity_description
+-- Apply with: go run ./cmd/migrate up
+-- A trigger on opportunity_description keeps the column current; go run ./cmd/backfill-desc-status repairs drift
+-- (e.g. after a restore with triggers disabled)
+
+-- Same rules as descriptionStatusExpr in internal/repositories/opportunity.go
+CREATE OR REPLACE FUNCTION compute_description_status(source_type VARCHAR, fetch_status VARCHAR)
+RETURNS VARCHAR
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE
+        WHEN source_type = 'none' OR source_type IS NULL THEN 'none'
+        WHEN fetch_status = 'fetched' THEN 'ready'
+        WHEN fetch_status = 'not_found' THEN 'not_found'
+        WHEN fetch_status = 'error' THEN 'error'
+        ELSE 'available_unfetched'
+    END
+$$;
+
+ALTER TABLE opportunity
+    ADD COLUMN IF NOT EXISTS description_status VARCHAR NOT NULL DEFAULT 'none';
+
+UPDATE opportunity o
+SET description_status = compute_description_status(od.source_type, od.fetch_status)
+FROM opportunity_description od
+WHERE od.notice_id = o.notice_id
+  AND o.description_status IS DISTINCT FROM compute_description_status(od.source_type, od.fetch_status);
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_description_status
+    ON opportunity(description_status);
+
+CREATE OR REPLACE FUNCTION sync_opportunity_description_status()
+RETURNS trigger
+LANGUAGE plpgsql AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE opportunity SET description_status = 'none'
+        WHERE notice_id = OLD.notice_id AND description_status <> 'none';
+        RETURN OLD;
+    END IF;
+    UPDATE opportunity SET description_status = compute_description_status(NEW.source_type, NEW.fetch_status)
+    WHERE notice_id = NEW.notice_id
+      AND description_status IS DISTINCT FROM compute_description_status(NEW.source_type, NEW.fetch_status);
+    RETURN NEW;
+END
+$$;
+
+DROP TRIGGER IF EXISTS trg_opportunity_description_status ON opportunity_description;
+CREATE TRIGGER trg_opportunity_description_status
+    AFTER INSERT OR DELETE OR UPDATE OF source_type, fetch_status ON opportunity_description
+    FOR EACH ROW EXECUTE FUNCTION sync_opportunity_description_status();
+
+COMMENT ON COLUMN opportunity.description_status IS 'Materialized description status (none, ready, not_found, error, available_unfetched) from opportunity_description; maintained by trg_opportunity_description_status.';
@@ -0,0 +1,7 @@
+-- Migration: Flag descriptions rejected for exceeding a size limit, so the API can say so instead of showing nothing
+-- Apply with: go run ./cmd/migrate up
+
+ALTER TABLE opportunity_description
+    ADD COLUMN IF NOT EXISTS oversize BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN opportunity_description.oversize IS 'True when the last fetch was rejected by a description size limit (DESC_MAX_BODY_BYTES and related); last_error names the limit.';
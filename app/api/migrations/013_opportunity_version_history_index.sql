@@ -0,0 +1,7 @@
+-- Migration: Index version history for keyset pagination
+-- Apply with: go run ./cmd/migrate up
+-- ListVersions pages one notice's versions by (fetched_at, id) descending; this index serves each page
+-- without sorting the whole history
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_version_history
+    ON opportunity_version(notice_id, fetched_at DESC, id DESC);
@@ -0,0 +1,18 @@
+-- Migration: Count consecutive failed description fetches so cmd/retry-desc-errors can back off and give up
+-- Apply with: go run ./cmd/migrate up
+-- UpsertDescription maintains the count: +1 for each error, back to 0 once a fetch succeeds or finds nothing
+
+ALTER TABLE opportunity_description
+    ADD COLUMN IF NOT EXISTS fetch_attempts INTEGER NOT NULL DEFAULT 0;
+
+-- Existing error rows count as one failed attempt
+UPDATE opportunity_description
+SET fetch_attempts = 1
+WHERE fetch_status = 'error' AND fetch_attempts = 0;
+
+-- The retry worker only scans error rows
+CREATE INDEX IF NOT EXISTS idx_opportunity_description_fetch_errors
+    ON opportunity_description(fetched_at)
+    WHERE fetch_status = 'error';
+
+COMMENT ON COLUMN opportunity_description.fetch_attempts IS 'Consecutive failed fetches (fetch_status error); reset to 0 by a fetched or not_found result.';
@@ -0,0 +1,11 @@
+-- Migration: Allow source_type 'attachment' for opportunities with no description text but with attachment links
+-- (resourceLinks), which were previously recorded as 'none'
+-- Apply with: go run ./cmd/migrate up
+-- compute_description_status needs no change: attachment rows are stored as not_found
+
+ALTER TABLE opportunity_description
+    DROP CONSTRAINT IF EXISTS opportunity_description_source_type_check;
+
+ALTER TABLE opportunity_description
+    ADD CONSTRAINT opportunity_description_source_type_check
+    CHECK (source_type IN ('none', 'inline', 'url', 'attachment'));
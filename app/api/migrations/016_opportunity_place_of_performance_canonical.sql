@@ -0,0 +1,76 @@
+-- Migration: Store place_of_performance in one shape so the state filter matches every row
+-- Apply with: go run ./cmd/migrate up
+-- Ingestion now writes city, state and country as SAM's {"code", "name"} objects (see
+-- models.CanonicalizePlaceOfPerformance). This rewrites older rows the same way: a JSON null becomes NULL, an
+-- object stored as a JSON string becomes the object, and string city/state/country values become objects
+-- (a stringified object is decoded; a plain state or country is a code, a plain city a name). Rows whose value
+-- can't be parsed are left as they are; the API logs a warning when reading them.
+
+DO $$
+DECLARE
+    rec RECORD;
+    place JSONB;
+    pair TEXT[];
+    value TEXT;
+    parsed JSONB;
+BEGIN
+    FOR rec IN
+        SELECT notice_id, place_of_performance FROM opportunity
+        WHERE jsonb_typeof(place_of_performance) IN ('null', 'string')
+           OR jsonb_typeof(place_of_performance->'city') = 'string'
+           OR jsonb_typeof(place_of_performance->'state') = 'string'
+           OR jsonb_typeof(place_of_performance->'country') = 'string'
+    LOOP
+        place := rec.place_of_performance;
+
+        IF jsonb_typeof(place) = 'string' THEN
+            BEGIN
+                place := (place #>> '{}')::jsonb;
+            EXCEPTION WHEN others THEN
+                CONTINUE;
+            END;
+        END IF;
+        IF jsonb_typeof(place) = 'null' THEN
+            place := NULL;
+        ELSIF jsonb_typeof(place) <> 'object' THEN
+            CONTINUE;
+        END IF;
+
+        IF place IS NOT NULL THEN
+            FOREACH pair SLICE 1 IN ARRAY ARRAY[['city', 'name'], ['state', 'code'], ['country', 'code']] LOOP
+                CONTINUE WHEN jsonb_typeof(place->pair[1]) IS DISTINCT FROM 'string';
+                value := btrim(place->>pair[1]);
+                parsed := NULL;
+                IF value LIKE '{%' THEN
+                    BEGIN
+                        parsed := value::jsonb;
+                    EXCEPTION WHEN others THEN
+                        parsed := NULL;
+                    END;
+                END IF;
+                IF value = '' THEN
+                    place := jsonb_set(place, ARRAY[pair[1]], 'null'::jsonb);
+                ELSIF jsonb_typeof(parsed) = 'object' THEN
+                    place := jsonb_set(place, ARRAY[pair[1]], parsed);
+                ELSE
+                    place := jsonb_set(place, ARRAY[pair[1]], jsonb_build_object(pair[2], value));
+                END IF;
+            END LOOP;
+        END IF;
+
+        UPDATE opportunity SET place_of_performance = place WHERE notice_id = rec.notice_id;
+    END LOOP;
+END
+$$;
+
+-- The state filter reads the code (placeStateExpr in internal/repositories/opportunity.go), so the indexes on
+-- place_of_performance->>'state' from migration 002 no longer apply. The expressions must match exactly.
+DROP INDEX IF EXISTS idx_opportunity_pop_state;
+DROP INDEX IF EXISTS idx_opportunity_filters_composite;
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_pop_state_code
+    ON opportunity((COALESCE(place_of_performance->'state'->>'code', place_of_performance->>'state')));
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_set_aside_state_code
+    ON opportunity(type_of_set_aside, (COALESCE(place_of_performance->'state'->>'code', place_of_performance->>'state')))
+    WHERE type_of_set_aside IS NOT NULL;
@@ -0,0 +1,35 @@
+-- Migration: Store SAM's procurement type (ptype code) separately from the notice type, so search can filter on
+-- either: type is this notice's category ("Award Notice"), procurement_type the procurement it belongs to ("k")
+-- Apply with: go run ./cmd/migrate up
+-- Ingestion sets the column from base_type, falling back to type (models.ProcurementType); this backfills
+-- existing rows with the same mapping
+
+ALTER TABLE opportunity
+    ADD COLUMN IF NOT EXISTS procurement_type VARCHAR;
+
+CREATE OR REPLACE FUNCTION pg_temp.procurement_type_code(notice_type VARCHAR)
+RETURNS VARCHAR
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT CASE LOWER(BTRIM(notice_type))
+        WHEN 'justification' THEN 'u'
+        WHEN 'justification and approval (j&a)' THEN 'u'
+        WHEN 'presolicitation' THEN 'p'
+        WHEN 'award notice' THEN 'a'
+        WHEN 'sources sought' THEN 'r'
+        WHEN 'special notice' THEN 's'
+        WHEN 'solicitation' THEN 'o'
+        WHEN 'sale of surplus property' THEN 'g'
+        WHEN 'combined synopsis/solicitation' THEN 'k'
+        WHEN 'intent to bundle requirements (dod-funded)' THEN 'i'
+    END
+$$;
+
+UPDATE opportunity
+SET procurement_type = COALESCE(pg_temp.procurement_type_code(base_type), pg_temp.procurement_type_code(type))
+WHERE procurement_type IS DISTINCT FROM COALESCE(pg_temp.procurement_type_code(base_type), pg_temp.procurement_type_code(type));
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_procurement_type
+    ON opportunity(procurement_type)
+    WHERE procurement_type IS NOT NULL;
+
+COMMENT ON COLUMN opportunity.procurement_type IS 'SAM procurement type (ptype) code of the base notice type, falling back to type: u, p, a, r, s, o, g, k or i; NULL when unknown.';
@@ -0,0 +1,9 @@
+-- Migration: Index the opportunities that have attachments, for the V2 hasAttachments filter
+-- Apply with: go run ./cmd/migrate up
+-- Attachments are SAM's resourceLinks, kept in opportunity_raw.raw_data (there is no separate attachment table).
+-- The filter is an EXISTS on opportunity_raw by notice_id under this predicate (hasAttachmentsPredicate in
+-- internal/repositories/opportunity.go); the two must match exactly for the planner to use the partial index.
+
+CREATE INDEX IF NOT EXISTS idx_opportunity_raw_has_attachments
+    ON opportunity_raw(notice_id)
+    WHERE jsonb_typeof(raw_data->'resourceLinks') = 'array' AND raw_data->'resourceLinks' <> '[]'::jsonb;
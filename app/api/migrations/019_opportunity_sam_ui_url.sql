@@ -0,0 +1,26 @@
+-- Migration: Store each opportunity's "view on SAM.gov" link, so responses can deep-link to SAM's page
+-- Apply with: go run ./cmd/migrate up
+-- Ingestion sets the column with models.SAMUIURL: SAM's uiLink when it is a sam.gov page, otherwise
+-- https://sam.gov/opp/<noticeId>/view with the notice ID path-escaped (models.SAMOpportunityURL). This backfills
+-- existing rows the same way from opportunity_raw (the links array fallback is left to the next ingestion of the
+-- row; SAM's links only carry the API self link).
+
+ALTER TABLE opportunity
+    ADD COLUMN IF NOT EXISTS sam_ui_url VARCHAR;
+
+UPDATE opportunity o
+SET sam_ui_url = COALESCE(
+    (SELECT BTRIM(r.raw_data->>'uiLink') FROM opportunity_raw r
+     WHERE r.notice_id = o.notice_id
+       AND BTRIM(r.raw_data->>'uiLink') ~* '^https://(www\.)?sam\.gov(/|$|\?|#)'),
+    -- Path-escaped as url.PathEscape does: unreserved characters and $&+:=@ stay, every other UTF-8 byte is %XX
+    'https://sam.gov/opp/' || (
+        SELECT string_agg(
+            CASE WHEN c.ch ~ '^[A-Za-z0-9._~$&+:=@-]$' THEN c.ch
+                 ELSE upper(regexp_replace(encode(convert_to(c.ch, 'UTF8'), 'hex'), '(..)', '%\1', 'g'))
+            END, '' ORDER BY c.ord)
+        FROM regexp_split_to_table(BTRIM(o.notice_id), '') WITH ORDINALITY AS c(ch, ord)
+    ) || '/view')
+WHERE o.sam_ui_url IS NULL AND BTRIM(o.notice_id) <> '';
+
+COMMENT ON COLUMN opportunity.sam_ui_url IS 'SAM.gov page for the notice: SAM''s uiLink when it is a sam.gov URL, else https://sam.gov/opp/<notice_id>/view.';
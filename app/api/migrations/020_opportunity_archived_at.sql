@@ -0,0 +1,16 @@
+-- Migration: Record when each opportunity was archived, so search's archive filter and reports can use it
+-- Apply with: go run ./cmd/migrate up
+-- Ingestion sets archived_at when it stores a notice SAM marks inactive or whose archive date has passed
+-- (models.IsArchived), keeps the first such time while the notice stays archived, and clears it if SAM
+-- reactivates the notice. This backfills existing archived rows with their last_updated time.
+
+ALTER TABLE opportunity
+    ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+UPDATE opportunity
+SET archived_at = last_updated
+WHERE archived_at IS NULL
+  AND (active = false
+       OR (COALESCE(archive_date, '') <> '' AND LEFT(archive_date, 10) < to_char(CURRENT_DATE, 'YYYY-MM-DD')));
+
+COMMENT ON COLUMN opportunity.archived_at IS 'When ingestion first stored the notice as archived (inactive, or archive date passed); NULL while it is not.';
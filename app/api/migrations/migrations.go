@@ -0,0 +1,9 @@
+// Package migrations embeds the versioned SQL migrations applied by cmd/migrate
+package migrations
+
+import "embed"
+
+// FS holds every NNN_name.sql migration in this directory
+//
+//go:embed *.sql
+var FS embed.FS
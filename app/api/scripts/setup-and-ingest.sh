@@ -21,7 +21,7 @@ echo ""
 # Step 1: Setup database schema
 echo "📦 Step 1: Setting up database schema..."
 cd "$(dirname "$0")/.."
-go run ./cmd/setup-db
+go run ./cmd/migrate up
 echo ""
 
 # Step 2: Run initial ingestion
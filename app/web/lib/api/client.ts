@@ -13,10 +13,10 @@ export interface OpportunitiesResponse {
 export interface Opportunity {
   noticeId: string;
   title: string;
-  organizationType: string;
+  organizationType?: string;
   postedDate: string;
   type: string;
-  baseType: string;
+  baseType?: string;
   archiveType?: string;
   archiveDate?: string;
   typeOfSetAside?: string;